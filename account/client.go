@@ -72,6 +72,12 @@ type Client interface {
 	ChangePassword(address string, oldPasswd, newPasswd []byte) error
 	//Change sig scheme to account
 	ChangeSigScheme(address string, sigScheme s.SignatureScheme) error
+	//NewIdentity create a new ONT ID, and save to wallet
+	NewIdentity(label string, typeCode keypair.KeyType, curveCode byte, passwd []byte) (*Identity, error)
+	//GetIdentity return identity by ONT ID or label
+	GetIdentity(idOrLabel string) *Identity
+	//GetIdentityAccount return the account of identity controller to sign transaction
+	GetIdentityAccount(idOrLabel, controllerId string, passwd []byte) (*Account, error)
 	//Get the underlying wallet data
 	GetWalletData() *WalletData
 }
@@ -564,6 +570,58 @@ func (this *ClientImpl) checkSigScheme(keyType, sigScheme string) bool {
 	return true
 }
 
+func (this *ClientImpl) NewIdentity(label string, typeCode keypair.KeyType, curveCode byte, passwd []byte) (*Identity, error) {
+	if len(passwd) == 0 {
+		return nil, fmt.Errorf("password cannot empty")
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if label != "" && this.getIdentity(label) != nil {
+		return nil, fmt.Errorf("duplicate label")
+	}
+	id, err := NewIdentity(label, typeCode, curveCode, passwd)
+	if err != nil {
+		return nil, fmt.Errorf("new identity error:%s", err)
+	}
+	this.walletData.AddIdentity(id)
+	err = this.save()
+	if err != nil {
+		this.walletData.Identities = this.walletData.Identities[:len(this.walletData.Identities)-1]
+		return nil, fmt.Errorf("save error:%s", err)
+	}
+	return id, nil
+}
+
+func (this *ClientImpl) GetIdentity(idOrLabel string) *Identity {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.getIdentity(idOrLabel)
+}
+
+func (this *ClientImpl) getIdentity(idOrLabel string) *Identity {
+	for i := range this.walletData.Identities {
+		identity := &this.walletData.Identities[i]
+		if identity.ID == idOrLabel || (identity.Label != "" && identity.Label == idOrLabel) {
+			return identity
+		}
+	}
+	return nil
+}
+
+func (this *ClientImpl) GetIdentityAccount(idOrLabel, controllerId string, passwd []byte) (*Account, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	identity := this.getIdentity(idOrLabel)
+	if identity == nil {
+		return nil, fmt.Errorf("cannot find identity:%s", idOrLabel)
+	}
+	controller := identity.GetController(controllerId)
+	if controller == nil {
+		return nil, fmt.Errorf("cannot find controller:%s of identity:%s", controllerId, identity.ID)
+	}
+	return controller.GetAccount(passwd)
+}
+
 func (this *ClientImpl) GetWalletData() *WalletData {
 	return this.walletData
 }
@@ -19,6 +19,7 @@
 package account
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 	"testing"
@@ -321,6 +322,33 @@ func TestImportAccount(t *testing.T) {
 	assert.Equal(t, acc.Address.ToBase58() == acc1.Address.ToBase58(), true)
 }
 
+func TestClientNewIdentity(t *testing.T) {
+	identity, err := testWallet.NewIdentity("id1", keypair.PK_ECDSA, keypair.P256, testPasswd)
+	assert.Nil(t, err)
+	assert.True(t, VerifyID(identity.ID))
+
+	_, err = testWallet.NewIdentity("id1", keypair.PK_ECDSA, keypair.P256, testPasswd)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, identity.ID, testWallet.GetIdentity("id1").ID)
+	assert.Equal(t, "id1", testWallet.GetIdentity(identity.ID).Label)
+	assert.Nil(t, testWallet.GetIdentity("id2"))
+
+	acc, err := testWallet.GetIdentityAccount("id1", "1", testPasswd)
+	assert.Nil(t, err)
+	assert.Equal(t, identity.Control[0].Public, hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey)))
+	assert.Equal(t, s.SHA256withECDSA, acc.SigScheme)
+
+	_, err = testWallet.GetIdentityAccount("id1", "2", testPasswd)
+	assert.NotNil(t, err)
+	_, err = testWallet.GetIdentityAccount("id1", "1", []byte("654321"))
+	assert.NotNil(t, err)
+
+	wallet, err := Open(testWalletPath)
+	assert.Nil(t, err)
+	assert.NotNil(t, wallet.GetIdentity(identity.ID))
+}
+
 func TestCheckSigScheme(t *testing.T) {
 	testClient, _ := NewClientImpl("")
 
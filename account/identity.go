@@ -28,6 +28,7 @@ import (
 
 	"github.com/itchyny/base58-go"
 	"github.com/ontio/ontology-crypto/keypair"
+	s "github.com/ontio/ontology-crypto/signature"
 	"github.com/ontio/ontology/core/types"
 	"golang.org/x/crypto/ripemd160"
 )
@@ -141,3 +142,40 @@ func NewIdentity(label string, keyType keypair.KeyType, param interface{}, passw
 
 	return &res, nil
 }
+
+//GetController return the controller of identity by controller id
+func (this *Identity) GetController(id string) *Controller {
+	for i := range this.Control {
+		if this.Control[i].ID == id {
+			return &this.Control[i]
+		}
+	}
+	return nil
+}
+
+//GetAccount decrypt the private key of controller, and return it as an account to sign transaction
+func (this *Controller) GetAccount(password []byte) (*Account, error) {
+	pri, err := keypair.DecryptPrivateKey(&this.ProtectedKey, password)
+	if err != nil {
+		return nil, err
+	}
+	pub := pri.Public()
+	return &Account{
+		PrivateKey: pri,
+		PublicKey:  pub,
+		Address:    types.AddressFromPubKey(pub),
+		SigScheme:  DefaultSigScheme(keypair.GetKeyType(pub)),
+	}, nil
+}
+
+//DefaultSigScheme return the default signature scheme of the key type
+func DefaultSigScheme(keyType keypair.KeyType) s.SignatureScheme {
+	switch keyType {
+	case keypair.PK_SM2:
+		return s.SM3withSM2
+	case keypair.PK_EDDSA:
+		return s.SHA512withEDDSA
+	default:
+		return s.SHA256withECDSA
+	}
+}
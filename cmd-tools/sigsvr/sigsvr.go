@@ -18,6 +18,9 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"runtime"
@@ -31,6 +34,8 @@ import (
 	"github.com/ontio/ontology/cmd/utils"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/common/password"
+	"github.com/ontio/ontology/consensus/signer"
 	"github.com/urfave/cli"
 )
 
@@ -47,6 +52,12 @@ func setupSigSvr() *cli.App {
 		utils.CliAddressFlag,
 		utils.CliRpcPortFlag,
 		utils.CliABIPathFlag,
+		//consensus signer setting
+		utils.CliSignerAccountFlag,
+		utils.CliSignerPortFlag,
+		utils.CliSignerTokenFlag,
+		utils.CliSignerDBFlag,
+		utils.CliSignerConsensusFlag,
 	}
 	app.Commands = []cli.Command{
 		cmdsvr.ImportWalletCommand,
@@ -93,6 +104,11 @@ func startSigSvr(ctx *cli.Context) {
 	abiPath := ctx.GlobalString(utils.GetFlagName(utils.CliABIPathFlag))
 	abi.DefAbiMgr.Init(abiPath)
 
+	if err := startConsensusSigner(ctx, walletStore, rpcAddress); err != nil {
+		log.Errorf("startConsensusSigner error:%s", err)
+		return
+	}
+
 	log.Infof("Sig server init success")
 	log.Infof("Sig server listing on: %s:%d", rpcAddress, rpcPort)
 
@@ -109,6 +125,41 @@ func startSigSvr(ctx *cli.Context) {
 	<-exit
 }
 
+func startConsensusSigner(ctx *cli.Context, walletStore *store.WalletStore, address string) error {
+	signerAccount := ctx.String(utils.GetFlagName(utils.CliSignerAccountFlag))
+	if signerAccount == "" {
+		return nil
+	}
+	tokenFile := ctx.String(utils.GetFlagName(utils.CliSignerTokenFlag))
+	if tokenFile == "" {
+		return fmt.Errorf("please using --%s flag to specific signer token file", utils.GetFlagName(utils.CliSignerTokenFlag))
+	}
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("read signer token file error:%s", err)
+	}
+	token = bytes.TrimSpace(token)
+	if err = signer.CheckToken(token); err != nil {
+		return err
+	}
+	passwd, err := password.GetAccountPassword()
+	if err != nil {
+		return fmt.Errorf("input password error:%s", err)
+	}
+	sig, err := cmdsvr.NewConsensusSigner(walletStore, signerAccount, passwd, ctx.String(utils.GetFlagName(utils.CliSignerDBFlag)),
+		ctx.String(utils.GetFlagName(utils.CliSignerConsensusFlag)))
+	if err != nil {
+		return err
+	}
+	port := ctx.Uint(utils.GetFlagName(utils.CliSignerPortFlag))
+	go func() {
+		if err := cmdsvr.StartConsensusSigner(sig, token, address, port); err != nil {
+			log.Errorf("%s", err)
+		}
+	}()
+	return nil
+}
+
 func main() {
 	if err := setupSigSvr().Run(os.Args); err != nil {
 		cmd.PrintErrorMsg(err.Error())
@@ -20,11 +20,13 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/ontio/ontology/cmd/utils"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/smartcontract/service/native/governance"
 	"github.com/urfave/cli"
 )
@@ -36,12 +38,20 @@ func SetOntologyConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 		return nil, fmt.Errorf("setGenesis error:%s", err)
 	}
 	setCommonConfig(ctx, cfg.Common)
+	if !scom.HasPersistStore(cfg.Common.StoreBackend) {
+		return nil, fmt.Errorf("unknown store backend:%s, available:%v", cfg.Common.StoreBackend, scom.PersistStoreBackends())
+	}
+	if cfg.Common.AddressHistory && !cfg.Common.EnableEventLog {
+		return nil, fmt.Errorf("address history requires event log, can not be enabled with --%s", utils.GetFlagName(utils.DisableEventLogFlag))
+	}
 	setConsensusConfig(ctx, cfg.Consensus)
+	setTxPoolConfig(ctx, cfg.TxPool)
 	setP2PNodeConfig(ctx, cfg.P2PNode)
 	setRpcConfig(ctx, cfg.Rpc)
 	setRestfulConfig(ctx, cfg.Restful)
 	setGraphQLConfig(ctx, cfg.GraphQL)
 	setWebSocketConfig(ctx, cfg.Ws)
+	setMetricsConfig(ctx, cfg.Metrics)
 	if cfg.Genesis.ConsensusType == config.CONSENSUS_TYPE_SOLO {
 		cfg.Ws.EnableHttpWs = true
 		cfg.Restful.EnableHttpRestful = true
@@ -65,6 +75,12 @@ func SetOntologyConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 		}
 	}
 
+	if cfg.P2PNode.EnableNoise && cfg.P2PNode.NodeKeyPath == "" {
+		//keep the node key after restart, so that the node keeps whitelisted by the reserved keys of other nodes
+		cfg.P2PNode.NodeKeyPath = filepath.Join(utils.GetStoreDirPath(cfg.Common.DataDir, cfg.P2PNode.NetworkName),
+			utils.DEFAULT_NODE_KEY_FILE)
+	}
+
 	enableWasmJitVerify := ctx.GlobalBool(utils.GetFlagName(utils.WasmVerifyMethodFlag))
 	if enableWasmJitVerify {
 		log.Infof("Enable wasm jit verifier")
@@ -117,6 +133,16 @@ func setGenesis(ctx *cli.Context, cfg *config.OntologyConfig) error {
 		if cfg.Genesis.DBFT.GenBlockTime <= 0 {
 			cfg.Genesis.DBFT.GenBlockTime = config.DEFAULT_GEN_BLOCK_TIME
 		}
+	case config.CONSENSUS_TYPE_SBFT:
+		if len(cfg.Genesis.SBFT.Bookkeepers) < config.SBFT_MIN_NODE_NUM {
+			return fmt.Errorf("SBFT consensus at least need %d bookkeepers in config", config.SBFT_MIN_NODE_NUM)
+		}
+		if cfg.Genesis.SBFT.GenBlockTime <= 0 {
+			cfg.Genesis.SBFT.GenBlockTime = config.DEFAULT_GEN_BLOCK_TIME
+		}
+		if cfg.Genesis.SBFT.RoundTimeout <= 0 {
+			cfg.Genesis.SBFT.RoundTimeout = cfg.Genesis.SBFT.GenBlockTime
+		}
 	case config.CONSENSUS_TYPE_VBFT:
 		err = governance.CheckVBFTConfig(cfg.Genesis.VBFT)
 		if err != nil {
@@ -135,9 +161,12 @@ func setGenesis(ctx *cli.Context, cfg *config.OntologyConfig) error {
 func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.LogLevel = ctx.Uint(utils.GetFlagName(utils.LogLevelFlag))
 	cfg.EnableEventLog = !ctx.Bool(utils.GetFlagName(utils.DisableEventLogFlag))
+	cfg.StateArchive = ctx.Bool(utils.GetFlagName(utils.EnableStateArchiveFlag))
+	cfg.AddressHistory = ctx.Bool(utils.GetFlagName(utils.EnableAddressHistoryFlag))
 	cfg.GasLimit = ctx.Uint64(utils.GetFlagName(utils.GasLimitFlag))
 	cfg.GasPrice = ctx.Uint64(utils.GetFlagName(utils.GasPriceFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.StoreBackend = ctx.String(utils.GetFlagName(utils.StoreBackendFlag))
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
@@ -145,6 +174,11 @@ func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
 	cfg.MaxTxInBlock = ctx.Uint(utils.GetFlagName(utils.MaxTxInBlockFlag))
 }
 
+func setTxPoolConfig(ctx *cli.Context, cfg *config.TxPoolConfig) {
+	cfg.MaxTxInPool = ctx.Uint(utils.GetFlagName(utils.TxpoolMaxTxInPoolFlag))
+	cfg.MaxTxPerPayer = ctx.Uint(utils.GetFlagName(utils.TxpoolMaxTxPerPayerFlag))
+}
+
 func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.NetworkId = uint32(ctx.Uint(utils.GetFlagName(utils.NetworkIdFlag)))
 	cfg.NetworkMagic = config.GetNetworkMagic(cfg.NetworkId)
@@ -155,6 +189,14 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.MaxConnInBound = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundFlag))
 	cfg.MaxConnOutBound = ctx.Uint(utils.GetFlagName(utils.MaxConnOutBoundFlag))
 	cfg.MaxConnInBoundForSingleIP = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundForSingleIPFlag))
+	cfg.EnableNoise = ctx.Bool(utils.GetFlagName(utils.EnableNoiseFlag))
+	cfg.NodeKeyPath = ctx.String(utils.GetFlagName(utils.NodeKeyFileFlag))
+	cfg.EnableCompression = !ctx.Bool(utils.GetFlagName(utils.DisableCompressionFlag))
+	cfg.MaxPeerRecvRate = ctx.Uint(utils.GetFlagName(utils.MaxPeerRecvRateFlag))
+	cfg.MaxPeerSendRate = ctx.Uint(utils.GetFlagName(utils.MaxPeerSendRateFlag))
+	cfg.SyncMaxFlightHeaders = ctx.Uint(utils.GetFlagName(utils.SyncMaxFlightHeadersFlag))
+	cfg.SyncMaxFlightBlocks = ctx.Uint(utils.GetFlagName(utils.SyncMaxFlightBlocksFlag))
+	cfg.SyncMaxBlockCache = ctx.Uint(utils.GetFlagName(utils.SyncMaxBlockCacheFlag))
 
 	rsvfile := ctx.String(utils.GetFlagName(utils.ReservedPeersFileFlag))
 	if cfg.ReservedPeersOnly {
@@ -173,6 +215,9 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 		for i := 0; i < len(cfg.ReservedCfg.MaskPeers); i++ {
 			log.Info("mask addr: " + cfg.ReservedCfg.MaskPeers[i])
 		}
+		for i := 0; i < len(cfg.ReservedCfg.ReservedPeerKeys); i++ {
+			log.Info("reserved key: " + cfg.ReservedCfg.ReservedPeerKeys[i])
+		}
 	}
 
 }
@@ -181,6 +226,7 @@ func setRpcConfig(ctx *cli.Context, cfg *config.RpcConfig) {
 	cfg.EnableHttpJsonRpc = !ctx.Bool(utils.GetFlagName(utils.RPCDisabledFlag))
 	cfg.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.RPCPortFlag))
 	cfg.HttpLocalPort = ctx.Uint(utils.GetFlagName(utils.RPCLocalProtFlag))
+	cfg.StandardResponse = ctx.Bool(utils.GetFlagName(utils.RPCStandardResponseFlag))
 }
 
 func setRestfulConfig(ctx *cli.Context, cfg *config.RestfulConfig) {
@@ -200,6 +246,12 @@ func setWebSocketConfig(ctx *cli.Context, cfg *config.WebSocketConfig) {
 	cfg.HttpWsPort = ctx.Uint(utils.GetFlagName(utils.WsPortFlag))
 }
 
+func setMetricsConfig(ctx *cli.Context, cfg *config.MetricsConfig) {
+	cfg.EnableMetrics = ctx.Bool(utils.GetFlagName(utils.MetricsEnableFlag))
+	cfg.MetricsPort = ctx.Uint(utils.GetFlagName(utils.MetricsPortFlag))
+	cfg.MetricsPath = ctx.String(utils.GetFlagName(utils.MetricsPathFlag))
+}
+
 func SetRpcPort(ctx *cli.Context) {
 	if ctx.IsSet(utils.GetFlagName(utils.RPCPortFlag)) {
 		config.DefConfig.Rpc.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.RPCPortFlag))
@@ -28,6 +28,7 @@ import (
 	"github.com/ontio/ontology/cmd/utils"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
 	httpcom "github.com/ontio/ontology/http/base/common"
 	"github.com/urfave/cli"
@@ -57,6 +58,7 @@ var (
 					utils.ContractAuthorFlag,
 					utils.ContractEmailFlag,
 					utils.ContractDescFlag,
+					utils.ContractManifestFlag,
 					utils.ContractPrepareDeployFlag,
 					utils.WalletFileFlag,
 					utils.AccountAddressFlag,
@@ -77,6 +79,11 @@ var (
   Note that if string contain some special char like :,[,] and so one, please use '/' char to escape. 
   For example: string:did/:ed1e25c9dccae0c694ee892231407afa20b76008
 
+  Manifest
+     If the contract is deployed with a manifest, or a manifest file is given by --manifest, use --method to invoke
+     a method declared in it. Parameters are then plain values without type prefix, typed by the manifest.
+     For example: --method transfer --params AXK2KtCfcJnSMyRzSwTuwTKgNrtx5aXfFX,AUr5QUfeBADq6BMY6Tp5yuMsUNGpsD7nLZ,100
+
   Return type
      When invoke contract with --prepare flag, you need specifies return type by --return flag, to decode the return value.
      Return type support bytearray(encoded to hex string), string, integer, boolean. 
@@ -90,8 +97,11 @@ var (
 					utils.ContractAddrFlag,
 					utils.ContractVmTypeFlag,
 					utils.ContractParamsFlag,
+					utils.ContractMethodFlag,
+					utils.ContractManifestFlag,
 					utils.ContractVersionFlag,
 					utils.ContractPrepareInvokeFlag,
+					utils.ContractProfileFlag,
 					utils.ContractReturnTypeFlag,
 					utils.WalletFileFlag,
 					utils.AccountAddressFlag,
@@ -146,6 +156,13 @@ func deployContract(ctx *cli.Context) error {
 	email := ctx.String(utils.GetFlagName(utils.ContractEmailFlag))
 	desc := ctx.String(utils.GetFlagName(utils.ContractDescFlag))
 	code := strings.TrimSpace(string(codeStr))
+	var manifestData []byte
+	if ctx.IsSet(utils.GetFlagName(utils.ContractManifestFlag)) {
+		manifestData, err = utils.ReadManifestFile(ctx.String(utils.GetFlagName(utils.ContractManifestFlag)))
+		if err != nil {
+			return err
+		}
+	}
 	gasPrice := ctx.Uint64(utils.GetFlagName(utils.TransactionGasPriceFlag))
 	gasLimit := ctx.Uint64(utils.GetFlagName(utils.TransactionGasLimitFlag))
 	networkId, err := utils.GetNetworkId()
@@ -159,7 +176,7 @@ func deployContract(ctx *cli.Context) error {
 	cversion := version
 
 	if ctx.IsSet(utils.GetFlagName(utils.ContractPrepareDeployFlag)) {
-		preResult, err := utils.PrepareDeployContract(vmtype, code, name, cversion, author, email, desc)
+		preResult, err := utils.PrepareDeployContract(vmtype, code, name, cversion, author, email, desc, manifestData)
 		if err != nil {
 			return fmt.Errorf("PrepareDeployContract error:%s", err)
 		}
@@ -176,7 +193,8 @@ func deployContract(ctx *cli.Context) error {
 		return fmt.Errorf("get signer account error:%s", err)
 	}
 
-	txHash, err := utils.DeployContract(gasPrice, gasLimit, signer, vmtype, code, name, cversion, author, email, desc)
+	txHash, err := utils.DeployContract(gasPrice, gasLimit, signer, vmtype, code, name, cversion, author, email, desc,
+		manifestData)
 	if err != nil {
 		return fmt.Errorf("DeployContract error:%s", err)
 	}
@@ -300,9 +318,36 @@ func invokeContract(ctx *cli.Context) error {
 		return err
 	}
 	paramsStr := ctx.String(utils.GetFlagName(utils.ContractParamsFlag))
-	params, err := utils.ParseParams(paramsStr)
-	if err != nil {
-		return fmt.Errorf("parseParams error:%s", err)
+	var params []interface{}
+	var methodReturnType string
+	if ctx.IsSet(utils.GetFlagName(utils.ContractMethodFlag)) {
+		var m *manifest.Manifest
+		if ctx.IsSet(utils.GetFlagName(utils.ContractManifestFlag)) {
+			m, err = utils.LoadManifestFile(ctx.String(utils.GetFlagName(utils.ContractManifestFlag)))
+			if err != nil {
+				return err
+			}
+		} else {
+			m, err = utils.GetContractManifest(contractAddr)
+			if err != nil {
+				return fmt.Errorf("get contract manifest error:%s", err)
+			}
+		}
+		methodName := ctx.String(utils.GetFlagName(utils.ContractMethodFlag))
+		method := m.GetMethod(methodName)
+		if method == nil {
+			return fmt.Errorf("method %s is not declared in the manifest", methodName)
+		}
+		params, err = utils.NewManifestInvokeParams(vmtype, method, utils.SplitManifestArgs(paramsStr))
+		if err != nil {
+			return err
+		}
+		methodReturnType = utils.ManifestReturnType(method)
+	} else {
+		params, err = utils.ParseParams(paramsStr)
+		if err != nil {
+			return fmt.Errorf("parseParams error:%s", err)
+		}
 	}
 
 	paramData, _ := json.Marshal(params)
@@ -310,12 +355,16 @@ func invokeContract(ctx *cli.Context) error {
 	if ctx.IsSet(utils.GetFlagName(utils.ContractPrepareInvokeFlag)) {
 
 		var preResult *httpcom.PreExecuteResult
-		if vmtype == payload.NEOVM_TYPE {
-			preResult, err = utils.PrepareInvokeNeoVMContract(contractAddr, params)
-
-		}
-		if vmtype == payload.WASMVM_TYPE {
-			preResult, err = utils.PrepareInvokeWasmVMContract(contractAddr, params)
+		if ctx.Bool(utils.GetFlagName(utils.ContractProfileFlag)) {
+			preResult, err = utils.ProfileInvokeContract(vmtype, contractAddr, params)
+		} else {
+			if vmtype == payload.NEOVM_TYPE {
+				preResult, err = utils.PrepareInvokeNeoVMContract(contractAddr, params)
+
+			}
+			if vmtype == payload.WASMVM_TYPE {
+				preResult, err = utils.PrepareInvokeWasmVMContract(contractAddr, params)
+			}
 		}
 
 		if err != nil {
@@ -327,8 +376,15 @@ func invokeContract(ctx *cli.Context) error {
 
 		PrintInfoMsg("Contract invoke successfully")
 		PrintInfoMsg("  Gas limit:%d", preResult.Gas)
+		if preResult.Profile != nil {
+			PrintInfoMsg("  Gas profile:")
+			PrintJsonObject(preResult.Profile)
+		}
 
 		rawReturnTypes := ctx.String(utils.GetFlagName(utils.ContractReturnTypeFlag))
+		if rawReturnTypes == "" {
+			rawReturnTypes = methodReturnType
+		}
 		if rawReturnTypes == "" {
 			PrintInfoMsg("  Return:%s (raw value)", preResult.Result)
 			return nil
@@ -0,0 +1,571 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/cmd/abi"
+	cmdcom "github.com/ontio/ontology/cmd/common"
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native/governance"
+	"github.com/urfave/cli"
+)
+
+var governanceTxFlags = []cli.Flag{
+	utils.RPCPortFlag,
+	utils.WalletFileFlag,
+	utils.TransactionGasPriceFlag,
+	utils.TransactionGasLimitFlag,
+	utils.TransactionPayerFlag,
+	utils.GovernanceBuildTxFlag,
+	utils.CliABIPathFlag,
+}
+
+var GovernanceCommand = cli.Command{
+	Name:        "governance",
+	Usage:       "Manage consensus peers and stake by governance contract",
+	Description: "Governance commands can register and quit consensus peers, authorize ONT to peers, withdraw stake and fee, and show the state of peers. Use --build-tx flag to print the raw transaction for offline or multi signature.",
+	Subcommands: []cli.Command{
+		{
+			Action:      registerCandidate,
+			Name:        "registercandidate",
+			Usage:       "Register consensus candidate",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Register consensus candidate with the peer owner account, which pays init pos and candidate fee. Candidate need approval of governance admin.",
+			Flags: append([]cli.Flag{
+				utils.GovernancePeerPubkeyFlag,
+				utils.GovernancePosFlag,
+				utils.GovernanceOntIdFlag,
+				utils.GovernanceKeyNoFlag,
+			}, governanceTxFlags...),
+		},
+		{
+			Action:      quitNode,
+			Name:        "quitnode",
+			Usage:       "Quit consensus peer",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Quit consensus peer by the peer owner account. Init pos can be withdrawn after the peer quit.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      authorizeForPeer,
+			Name:        "authorize",
+			Usage:       "Authorize ONT to consensus peers",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Authorize ONT to consensus peers. Use ',' to separate peers in --peer-pubkey and amounts in --pos.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      unAuthorizeForPeer,
+			Name:        "unauthorize",
+			Usage:       "Cancel authorization of ONT to consensus peers",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Cancel authorization of ONT to consensus peers. ONT will be unfrozen in next one or two governance views.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      withdrawPos,
+			Name:        "withdraw",
+			Usage:       "Withdraw unfrozen ONT from consensus peers",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Withdraw unfrozen ONT from consensus peers. Use ',' to separate peers in --peer-pubkey and amounts in --pos.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      withdrawGovernanceOng,
+			Name:        "withdrawong",
+			Usage:       "Withdraw unbound ONG of ONT staked in governance contract",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Withdraw unbound ONG of ONT staked in governance contract.",
+			Flags:       governanceTxFlags,
+		},
+		{
+			Action:      withdrawFee,
+			Name:        "withdrawfee",
+			Usage:       "Withdraw fee reward",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Withdraw fee reward of consensus peers and authorization.",
+			Flags:       governanceTxFlags,
+		},
+		{
+			Action:      setPeerCost,
+			Name:        "setpeercost",
+			Usage:       "Set percentage of fee the peer keeps",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Set percentage of fee the peer keeps by the peer owner account, the remainder is shared by authorization.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag, utils.GovernancePeerCostFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      addInitPos,
+			Name:        "addinitpos",
+			Usage:       "Add init pos of consensus peer",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Add init pos of consensus peer by the peer owner account.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      reduceInitPos,
+			Name:        "reduceinitpos",
+			Usage:       "Reduce init pos of consensus peer",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Reduce init pos of consensus peer by the peer owner account. Init pos cannot be less than the promised pos.",
+			Flags:       append([]cli.Flag{utils.GovernancePeerPubkeyFlag, utils.GovernancePosFlag}, governanceTxFlags...),
+		},
+		{
+			Action:      showPeerPool,
+			Name:        "peerpool",
+			Usage:       "Show consensus peers of current governance view",
+			Description: "Show consensus peers of current governance view.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.CliABIPathFlag,
+			},
+		},
+		{
+			Action:      showPeerInfo,
+			Name:        "peerinfo",
+			Usage:       "Show consensus peer",
+			Description: "Show consensus peer of current governance view.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.GovernancePeerPubkeyFlag,
+				utils.CliABIPathFlag,
+			},
+		},
+		{
+			Action:      showAuthorizeInfo,
+			Name:        "authorizeinfo",
+			Usage:       "Show ONT authorized to consensus peer",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Show ONT authorized to consensus peer by the account.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+				utils.GovernancePeerPubkeyFlag,
+				utils.CliABIPathFlag,
+			},
+		},
+		{
+			Action:      showAddressFee,
+			Name:        "addressfee",
+			Usage:       "Show fee reward of account",
+			ArgsUsage:   "<address|label|index>",
+			Description: "Show fee reward of account which can be withdrawn by withdrawfee.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+				utils.CliABIPathFlag,
+			},
+		},
+	},
+}
+
+var peerStatusNames = map[governance.Status]string{
+	governance.RegisterCandidateStatus: "RegisterCandidate",
+	governance.CandidateStatus:         "Candidate",
+	governance.ConsensusStatus:         "Consensus",
+	governance.QuitConsensusStatus:     "QuitConsensus",
+	governance.QuitingStatus:           "Quiting",
+	governance.BlackStatus:             "Black",
+}
+
+func registerCandidate(ctx *cli.Context) error {
+	peerPubkey, err := parsePeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	initPos, err := parsePos(ctx)
+	if err != nil {
+		return err
+	}
+	ontId := ctx.String(utils.GetFlagName(utils.GovernanceOntIdFlag))
+	if ontId == "" {
+		return fmt.Errorf("missing %s flag", utils.GetFlagName(utils.GovernanceOntIdFlag))
+	}
+	keyNo := ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag))
+	return invokeGovernance(ctx, governance.REGISTER_CANDIDATE, func(address string) []interface{} {
+		return []interface{}{peerPubkey, address, initPos, hex.EncodeToString([]byte(ontId)), strconv.FormatUint(uint64(keyNo), 10)}
+	})
+}
+
+func quitNode(ctx *cli.Context) error {
+	peerPubkey, err := parsePeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, governance.QUIT_NODE, func(address string) []interface{} {
+		return []interface{}{peerPubkey, address}
+	})
+}
+
+func authorizeForPeer(ctx *cli.Context) error {
+	return invokeGovernancePosList(ctx, governance.AUTHORIZE_FOR_PEER)
+}
+
+func unAuthorizeForPeer(ctx *cli.Context) error {
+	return invokeGovernancePosList(ctx, governance.UNAUTHORIZE_FOR_PEER)
+}
+
+func withdrawPos(ctx *cli.Context) error {
+	return invokeGovernancePosList(ctx, governance.WITHDRAW)
+}
+
+func withdrawGovernanceOng(ctx *cli.Context) error {
+	return invokeGovernance(ctx, governance.WITHDRAW_ONG, func(address string) []interface{} {
+		return []interface{}{address}
+	})
+}
+
+func withdrawFee(ctx *cli.Context) error {
+	return invokeGovernance(ctx, governance.WITHDRAW_FEE, func(address string) []interface{} {
+		return []interface{}{address}
+	})
+}
+
+func setPeerCost(ctx *cli.Context) error {
+	peerPubkey, err := parsePeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	flagName := utils.GetFlagName(utils.GovernancePeerCostFlag)
+	if !ctx.IsSet(flagName) {
+		return fmt.Errorf("missing %s flag", flagName)
+	}
+	peerCost := ctx.Uint(flagName)
+	if peerCost > 100 {
+		return fmt.Errorf("%s:%d should be between 0 and 100", flagName, peerCost)
+	}
+	return invokeGovernance(ctx, governance.SET_PEER_COST, func(address string) []interface{} {
+		return []interface{}{peerPubkey, address, strconv.FormatUint(uint64(peerCost), 10)}
+	})
+}
+
+func addInitPos(ctx *cli.Context) error {
+	return invokeGovernanceInitPos(ctx, governance.ADD_INIT_POS)
+}
+
+func reduceInitPos(ctx *cli.Context) error {
+	return invokeGovernanceInitPos(ctx, governance.REDUCE_INIT_POS)
+}
+
+func invokeGovernanceInitPos(ctx *cli.Context, method string) error {
+	peerPubkey, err := parsePeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	pos, err := parsePos(ctx)
+	if err != nil {
+		return err
+	}
+	return invokeGovernance(ctx, method, func(address string) []interface{} {
+		return []interface{}{peerPubkey, address, pos}
+	})
+}
+
+func invokeGovernancePosList(ctx *cli.Context, method string) error {
+	peerPubkeys, err := parsePeerPubkeyList(ctx)
+	if err != nil {
+		return err
+	}
+	posList, err := parsePosList(ctx)
+	if err != nil {
+		return err
+	}
+	if len(peerPubkeys) != len(posList) {
+		return fmt.Errorf("%d peers in %s flag mismatch %d amounts in %s flag", len(peerPubkeys),
+			utils.GetFlagName(utils.GovernancePeerPubkeyFlag), len(posList), utils.GetFlagName(utils.GovernancePosFlag))
+	}
+	return invokeGovernance(ctx, method, func(address string) []interface{} {
+		return []interface{}{address, peerPubkeys, posList}
+	})
+}
+
+//invokeGovernance build transaction of governance method with the account in first argument,
+//print the raw transaction if build-tx flag is set, otherwise sign and send it.
+func invokeGovernance(ctx *cli.Context, method string, params func(address string) []interface{}) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing account argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	initGovernanceAbi(ctx)
+	accAddr, err := cmdcom.ParseAddress(ctx.Args().First(), ctx)
+	if err != nil {
+		return err
+	}
+	var payer common.Address
+	payerAddr := ctx.String(utils.GetFlagName(utils.TransactionPayerFlag))
+	if payerAddr != "" {
+		payerAddr, err = cmdcom.ParseAddress(payerAddr, ctx)
+		if err != nil {
+			return err
+		}
+		payer, err = common.AddressFromBase58(payerAddr)
+		if err != nil {
+			return fmt.Errorf("invalid payer address:%s", err)
+		}
+	}
+
+	buildTx := ctx.Bool(utils.GetFlagName(utils.GovernanceBuildTxFlag))
+	gasPrice := ctx.Uint64(utils.TransactionGasPriceFlag.Name)
+	gasLimit := ctx.Uint64(utils.TransactionGasLimitFlag.Name)
+	if !buildTx {
+		networkId, err := utils.GetNetworkId()
+		if err != nil {
+			return err
+		}
+		if networkId == config.NETWORK_ID_SOLO_NET {
+			gasPrice = 0
+		}
+	}
+
+	mutTx, err := utils.NewGovernanceInvokeTx(gasPrice, gasLimit, method, params(accAddr))
+	if err != nil {
+		return err
+	}
+	mutTx.Payer = payer
+	if buildTx {
+		if mutTx.Payer == common.ADDRESS_EMPTY {
+			mutTx.Payer, err = common.AddressFromBase58(accAddr)
+			if err != nil {
+				return err
+			}
+		}
+		tx, err := mutTx.IntoImmutable()
+		if err != nil {
+			return fmt.Errorf("IntoImmutable error:%s", err)
+		}
+		sink := common.ZeroCopySink{}
+		tx.Serialization(&sink)
+		PrintInfoMsg("%s raw tx:", method)
+		PrintInfoMsg(hex.EncodeToString(sink.Bytes()))
+		return nil
+	}
+
+	signer, err := cmdcom.GetAccount(ctx, accAddr)
+	if err != nil {
+		return err
+	}
+	if payer != common.ADDRESS_EMPTY && payer != signer.Address {
+		PrintInfoMsg("Unlock payer %s", payerAddr)
+		payerAcc, err := cmdcom.GetAccount(ctx, payerAddr)
+		if err != nil {
+			return err
+		}
+		if err = utils.SignTransaction(payerAcc, mutTx); err != nil {
+			return fmt.Errorf("SignTransaction error:%s", err)
+		}
+	}
+	txHash, err := utils.InvokeSmartContract(signer, mutTx)
+	if err != nil {
+		return fmt.Errorf("invoke governance %s error:%s", method, err)
+	}
+	PrintInfoMsg("Invoke governance %s", method)
+	PrintInfoMsg("  Account:%s", accAddr)
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './ontology info status %s' to query transaction status.", txHash)
+	return nil
+}
+
+func showPeerPool(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	initGovernanceAbi(ctx)
+	peers, err := utils.GetPeerPool()
+	if err != nil {
+		return fmt.Errorf("GetPeerPool error:%s", err)
+	}
+	PrintInfoMsg("PeerPool:")
+	for _, peer := range peers {
+		printPeer(peer)
+	}
+	return nil
+}
+
+func showPeerInfo(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	peerPubkey, err := parsePeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	peerAddress, err := peerPubkeyToAddress(peerPubkey)
+	if err != nil {
+		return err
+	}
+	initGovernanceAbi(ctx)
+	peer, err := utils.GetPeerInfo(peerAddress.ToBase58())
+	if err != nil {
+		return fmt.Errorf("GetPeerInfo error:%s", err)
+	}
+	if peer.PeerAddress != peerAddress {
+		return fmt.Errorf("cannot find peer:%s", peerPubkey)
+	}
+	printPeer(peer)
+	return nil
+}
+
+func showAuthorizeInfo(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing account argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	accAddr, err := cmdcom.ParseAddress(ctx.Args().First(), ctx)
+	if err != nil {
+		return err
+	}
+	peerPubkey, err := parsePeerPubkey(ctx)
+	if err != nil {
+		return err
+	}
+	initGovernanceAbi(ctx)
+	info, err := utils.GetAuthorizeInfo(peerPubkey, accAddr)
+	if err != nil {
+		return fmt.Errorf("GetAuthorizeInfo error:%s", err)
+	}
+	PrintInfoMsg("AuthorizeInfo:")
+	PrintInfoMsg("  PeerPubkey:%s", peerPubkey)
+	PrintInfoMsg("  Address:%s", accAddr)
+	PrintInfoMsg("  ConsensusPos:%d", info.ConsensusPos)
+	PrintInfoMsg("  CandidatePos:%d", info.CandidatePos)
+	PrintInfoMsg("  NewPos:%d", info.NewPos)
+	PrintInfoMsg("  WithdrawConsensusPos:%d", info.WithdrawConsensusPos)
+	PrintInfoMsg("  WithdrawCandidatePos:%d", info.WithdrawCandidatePos)
+	PrintInfoMsg("  WithdrawUnfreezePos:%d", info.WithdrawUnfreezePos)
+	return nil
+}
+
+func showAddressFee(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing account argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	accAddr, err := cmdcom.ParseAddress(ctx.Args().First(), ctx)
+	if err != nil {
+		return err
+	}
+	initGovernanceAbi(ctx)
+	fee, err := utils.GetAddressFee(accAddr)
+	if err != nil {
+		return fmt.Errorf("GetAddressFee error:%s", err)
+	}
+	PrintInfoMsg("AddressFee:%s", accAddr)
+	PrintInfoMsg("  ONG:%s", utils.FormatOng(fee.Amount))
+	return nil
+}
+
+func initGovernanceAbi(ctx *cli.Context) {
+	//only print the error of loading abi files
+	log.InitLog(log.WarnLog, log.Stdout)
+	abi.DefAbiMgr.Init(ctx.String(utils.GetFlagName(utils.CliABIPathFlag)))
+}
+
+func printPeer(peer *governance.PeerPoolItemForVm) {
+	PrintInfoMsg("  Index:%d", peer.Index)
+	PrintInfoMsg("    PeerAddress:%s", peer.PeerAddress.ToBase58())
+	PrintInfoMsg("    Owner:%s", peer.Address.ToBase58())
+	PrintInfoMsg("    Status:%s", peerStatusNames[peer.Status])
+	PrintInfoMsg("    InitPos:%d", peer.InitPos)
+	PrintInfoMsg("    TotalPos:%d", peer.TotalPos)
+}
+
+func peerPubkeyToAddress(peerPubkey string) (common.Address, error) {
+	data, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return common.ADDRESS_EMPTY, fmt.Errorf("invalid peer pubkey:%s", peerPubkey)
+	}
+	pk, err := keypair.DeserializePublicKey(data)
+	if err != nil {
+		return common.ADDRESS_EMPTY, fmt.Errorf("invalid peer pubkey:%s", peerPubkey)
+	}
+	return types.AddressFromPubKey(pk), nil
+}
+
+func parsePeerPubkey(ctx *cli.Context) (string, error) {
+	peerPubkeys, err := parsePeerPubkeyList(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(peerPubkeys) != 1 {
+		return "", fmt.Errorf("only one peer is allowed in %s flag", utils.GetFlagName(utils.GovernancePeerPubkeyFlag))
+	}
+	return peerPubkeys[0].(string), nil
+}
+
+func parsePeerPubkeyList(ctx *cli.Context) ([]interface{}, error) {
+	flagName := utils.GetFlagName(utils.GovernancePeerPubkeyFlag)
+	value := ctx.String(flagName)
+	if value == "" {
+		return nil, fmt.Errorf("missing %s flag", flagName)
+	}
+	peerPubkeys := make([]interface{}, 0)
+	for _, peerPubkey := range strings.Split(value, ",") {
+		peerPubkey = strings.TrimSpace(peerPubkey)
+		if _, err := peerPubkeyToAddress(peerPubkey); err != nil {
+			return nil, err
+		}
+		peerPubkeys = append(peerPubkeys, peerPubkey)
+	}
+	return peerPubkeys, nil
+}
+
+func parsePos(ctx *cli.Context) (string, error) {
+	posList, err := parsePosList(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(posList) != 1 {
+		return "", fmt.Errorf("only one amount is allowed in %s flag", utils.GetFlagName(utils.GovernancePosFlag))
+	}
+	return posList[0].(string), nil
+}
+
+func parsePosList(ctx *cli.Context) ([]interface{}, error) {
+	flagName := utils.GetFlagName(utils.GovernancePosFlag)
+	value := ctx.String(flagName)
+	if value == "" {
+		return nil, fmt.Errorf("missing %s flag", flagName)
+	}
+	posList := make([]interface{}, 0)
+	for _, pos := range strings.Split(value, ",") {
+		pos = strings.TrimSpace(pos)
+		amount, err := strconv.ParseUint(pos, 10, 64)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("invalid pos:%s", pos)
+		}
+		if err := utils.CheckAssetAmount(utils.ASSET_ONT, amount); err != nil {
+			return nil, err
+		}
+		posList = append(posList, pos)
+	}
+	return posList, nil
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strconv"
 
+	cmdcom "github.com/ontio/ontology/cmd/common"
 	"github.com/ontio/ontology/cmd/utils"
 	"github.com/ontio/ontology/core/types"
 	httpcom "github.com/ontio/ontology/http/base/common"
@@ -73,6 +74,20 @@ var InfoCommand = cli.Command{
 				utils.RPCPortFlag,
 			},
 		},
+		{
+			Action:    addressHistory,
+			Name:      "history",
+			Usage:     "Display ONT, ONG and OEP-4 transfer history of address",
+			ArgsUsage: "<address|label|index>",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.HistoryFromHeightFlag,
+				utils.HistoryLimitFlag,
+				utils.WalletFileFlag,
+			},
+			Description: `Display transfer history of address. The node must run with --enable-address-history.
+Use NextHeight in the output as --from-height to query the next page.`,
+		},
 	},
 	Description: `Query information command can query information such as blocks, transactions, and transaction executions. 
 You can use the ./Ontology info block --help command to view help information.`,
@@ -190,3 +205,24 @@ func showTx(ctx *cli.Context) error {
 	PrintJsonObject(txInfo)
 	return nil
 }
+
+func addressHistory(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing address argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	address, err := cmdcom.ParseAddress(ctx.Args().First(), ctx)
+	if err != nil {
+		return err
+	}
+	fromHeight := ctx.Uint(utils.GetFlagName(utils.HistoryFromHeightFlag))
+	limit := ctx.Uint(utils.GetFlagName(utils.HistoryLimitFlag))
+	history, err := utils.GetAddressHistory(address, uint32(fromHeight), uint32(limit))
+	if err != nil {
+		return fmt.Errorf("GetAddressHistory error:%s", err)
+	}
+	PrintJsonObject(history)
+	return nil
+}
@@ -0,0 +1,515 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	cmdcom "github.com/ontio/ontology/cmd/common"
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/password"
+	"github.com/ontio/ontology/core/types"
+	"github.com/urfave/cli"
+)
+
+var ontIdTxFlags = []cli.Flag{
+	utils.RPCPortFlag,
+	utils.WalletFileFlag,
+	utils.GovernanceKeyNoFlag,
+	utils.TransactionGasPriceFlag,
+	utils.TransactionGasLimitFlag,
+	utils.TransactionPayerFlag,
+	utils.GovernanceBuildTxFlag,
+}
+
+var OntIdCommand = cli.Command{
+	Name:        "ontid",
+	Usage:       "Manage ONT ID in wallet and on chain",
+	Description: "ONT ID commands can create ONT ID in wallet, register it on chain, manage its keys, attributes, controller, recovery and services, and resolve its DID document. Transactions are signed by the ONT ID key of --keyno, and fee is paid by --payer account, default account of wallet by default.",
+	Subcommands: []cli.Command{
+		{
+			Action:    ontIdCreate,
+			Name:      "create",
+			Usage:     "Create ONT ID in wallet",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				utils.WalletFileFlag,
+				utils.AccountLabelFlag,
+				utils.AccountTypeFlag,
+				utils.AccountKeylenFlag,
+			},
+		},
+		{
+			Action:    ontIdList,
+			Name:      "list",
+			Usage:     "List ONT IDs in wallet",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				utils.WalletFileFlag,
+			},
+		},
+		{
+			Action:      ontIdRegister,
+			Name:        "register",
+			Usage:       "Register ONT ID with its public key",
+			ArgsUsage:   "<ontid|label>",
+			Description: "Register ONT ID in wallet on chain, with the public key of --keyno as the first key.",
+			Flags:       ontIdTxFlags,
+		},
+		{
+			Action:      ontIdRegController,
+			Name:        "regcontroller",
+			Usage:       "Register ONT ID controlled by another ONT ID",
+			ArgsUsage:   "<ontid|label>",
+			Description: "Register ONT ID on chain without key, which is controlled by --controller. The transaction is signed by the controller key of --keyno.",
+			Flags:       append([]cli.Flag{utils.OntIdControllerFlag}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdRemoveController,
+			Name:      "removecontroller",
+			Usage:     "Remove controller of ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags:     ontIdTxFlags,
+		},
+		{
+			Action:    ontIdAddKey,
+			Name:      "addkey",
+			Usage:     "Add public key to ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags:     append([]cli.Flag{utils.OntIdPubKeyFlag}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdRemoveKey,
+			Name:      "removekey",
+			Usage:     "Remove public key from ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags:     append([]cli.Flag{utils.OntIdPubKeyFlag}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdAddAttribute,
+			Name:      "addattr",
+			Usage:     "Add or update attribute of ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags: append([]cli.Flag{
+				utils.OntIdAttrKeyFlag,
+				utils.OntIdAttrTypeFlag,
+				utils.OntIdAttrValueFlag,
+			}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdRemoveAttribute,
+			Name:      "removeattr",
+			Usage:     "Remove attribute of ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags:     append([]cli.Flag{utils.OntIdAttrKeyFlag}, ontIdTxFlags...),
+		},
+		{
+			Action:      ontIdSetRecovery,
+			Name:        "setrecovery",
+			Usage:       "Set recovery group of ONT ID",
+			ArgsUsage:   "<ontid|label>",
+			Description: "Set recovery group of ONT ID, which can be set only once. Group members can recover the keys of ONT ID with --threshold signatures.",
+			Flags: append([]cli.Flag{
+				utils.OntIdRecoveryFlag,
+				utils.OntIdThresholdFlag,
+			}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdAddService,
+			Name:      "addservice",
+			Usage:     "Add service to ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags: append([]cli.Flag{
+				utils.OntIdServiceIdFlag,
+				utils.OntIdServiceTypeFlag,
+				utils.OntIdServiceEndpointFlag,
+			}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdRemoveService,
+			Name:      "removeservice",
+			Usage:     "Remove service of ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags:     append([]cli.Flag{utils.OntIdServiceIdFlag}, ontIdTxFlags...),
+		},
+		{
+			Action:    ontIdDocument,
+			Name:      "document",
+			Usage:     "Show DID document of ONT ID",
+			ArgsUsage: "<ontid|label>",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.WalletFileFlag,
+			},
+		},
+	},
+}
+
+func ontIdCreate(ctx *cli.Context) error {
+	keyType, ok := keyTypeMap[ctx.String(utils.GetFlagName(utils.AccountTypeFlag))]
+	if !ok {
+		return fmt.Errorf("invalid key type:%s", ctx.String(utils.GetFlagName(utils.AccountTypeFlag)))
+	}
+	var curve curveInfo
+	switch keyType.code {
+	case keypair.PK_SM2:
+		curve = curveMap["SM2P256V1"]
+	case keypair.PK_EDDSA:
+		curve = curveMap["ED25519"]
+	default:
+		curve, ok = curveMap[ctx.String(utils.GetFlagName(utils.AccountKeylenFlag))]
+		if !ok {
+			return fmt.Errorf("invalid bit length:%s", ctx.String(utils.GetFlagName(utils.AccountKeylenFlag)))
+		}
+	}
+	optionFile := checkFileName(ctx)
+	wallet, err := account.Open(optionFile)
+	if err != nil {
+		return fmt.Errorf("error opening wallet: %s", err)
+	}
+	pass, err := password.GetConfirmedPassword()
+	if err != nil {
+		return err
+	}
+	defer cmdcom.ClearPasswd(pass)
+	id, err := wallet.NewIdentity(checkLabel(ctx), keyType.code, curve.code, pass)
+	if err != nil {
+		return fmt.Errorf("error creating ONT ID: %s", err)
+	}
+	PrintInfoMsg("ONT ID created: %s", id.ID)
+	PrintInfoMsg("Key type: %s %s", keyType.name, curve.name)
+	PrintInfoMsg("Bind public key: %s", id.Control[0].Public)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './ontology ontid register %s' to register it on chain.", id.ID)
+	return nil
+}
+
+func ontIdList(ctx *cli.Context) error {
+	wallet, err := cmdcom.OpenWallet(ctx)
+	if err != nil {
+		return err
+	}
+	identities := wallet.GetWalletData().Identities
+	if len(identities) == 0 {
+		PrintInfoMsg("No ONT ID.")
+		return nil
+	}
+	for i, identity := range identities {
+		PrintInfoMsg("Index:%-4d ONT ID:%s  Label:%s", i+1, identity.ID, identity.Label)
+		for _, ctrl := range identity.Control {
+			PrintInfoMsg("  Key:%s  Public key:%s", ctrl.ID, ctrl.Public)
+		}
+	}
+	return nil
+}
+
+func ontIdRegister(ctx *cli.Context) error {
+	return invokeOntId(ctx, "regIDWithPublicKey", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewRegisterOntIdTx(gasPrice, gasLimit, ontId, signer)
+	})
+}
+
+func ontIdRegController(ctx *cli.Context) error {
+	controller := ctx.String(utils.GetFlagName(utils.OntIdControllerFlag))
+	if controller == "" {
+		PrintErrorMsg("Missing %s flag.", utils.GetFlagName(utils.OntIdControllerFlag))
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing ONT ID argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	ontId, err := parseOntId(ctx, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	keyNo := uint32(ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag)))
+	return signAndSendOntIdTx(ctx, "regIDWithController", controller, func(controllerId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewRegOntIdWithControllerTx(gasPrice, gasLimit, ontId, controllerId, keyNo)
+	})
+}
+
+func ontIdRemoveController(ctx *cli.Context) error {
+	keyNo := uint32(ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag)))
+	return invokeOntId(ctx, "removeController", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewRemoveOntIdControllerTx(gasPrice, gasLimit, ontId, keyNo)
+	})
+}
+
+func ontIdAddKey(ctx *cli.Context) error {
+	return invokeOntIdKey(ctx, "addKey", utils.NewAddOntIdKeyTx)
+}
+
+func ontIdRemoveKey(ctx *cli.Context) error {
+	return invokeOntIdKey(ctx, "removeKey", utils.NewRemoveOntIdKeyTx)
+}
+
+func invokeOntIdKey(ctx *cli.Context, method string,
+	newTx func(gasPrice, gasLimit uint64, ontId string, pubKey []byte, operator keypair.PublicKey) (*types.MutableTransaction, error)) error {
+	pubKey, err := hex.DecodeString(ctx.String(utils.GetFlagName(utils.OntIdPubKeyFlag)))
+	if err != nil {
+		return fmt.Errorf("invalid public key:%s", err)
+	}
+	if _, err = keypair.DeserializePublicKey(pubKey); err != nil {
+		return fmt.Errorf("invalid public key:%s", err)
+	}
+	return invokeOntId(ctx, method, func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return newTx(gasPrice, gasLimit, ontId, pubKey, signer)
+	})
+}
+
+func ontIdAddAttribute(ctx *cli.Context) error {
+	key := ctx.String(utils.GetFlagName(utils.OntIdAttrKeyFlag))
+	if key == "" {
+		return fmt.Errorf("missing %s flag", utils.GetFlagName(utils.OntIdAttrKeyFlag))
+	}
+	attr := utils.OntIdAttribute{
+		Key:       []byte(key),
+		ValueType: []byte(ctx.String(utils.GetFlagName(utils.OntIdAttrTypeFlag))),
+		Value:     []byte(ctx.String(utils.GetFlagName(utils.OntIdAttrValueFlag))),
+	}
+	return invokeOntId(ctx, "addAttributes", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewAddOntIdAttributesTx(gasPrice, gasLimit, ontId, []utils.OntIdAttribute{attr}, signer)
+	})
+}
+
+func ontIdRemoveAttribute(ctx *cli.Context) error {
+	key := ctx.String(utils.GetFlagName(utils.OntIdAttrKeyFlag))
+	if key == "" {
+		return fmt.Errorf("missing %s flag", utils.GetFlagName(utils.OntIdAttrKeyFlag))
+	}
+	return invokeOntId(ctx, "removeAttribute", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewRemoveOntIdAttributeTx(gasPrice, gasLimit, ontId, key, signer)
+	})
+}
+
+func ontIdSetRecovery(ctx *cli.Context) error {
+	members := make([]string, 0)
+	for _, member := range strings.Split(ctx.String(utils.GetFlagName(utils.OntIdRecoveryFlag)), ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		if !account.VerifyID(member) {
+			return fmt.Errorf("invalid recovery ONT ID:%s", member)
+		}
+		members = append(members, member)
+	}
+	threshold := ctx.Uint(utils.GetFlagName(utils.OntIdThresholdFlag))
+	keyNo := uint32(ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag)))
+	return invokeOntId(ctx, "setRecovery", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewSetOntIdRecoveryTx(gasPrice, gasLimit, ontId, members, threshold, keyNo)
+	})
+}
+
+func ontIdAddService(ctx *cli.Context) error {
+	serviceId := ctx.String(utils.GetFlagName(utils.OntIdServiceIdFlag))
+	serviceType := ctx.String(utils.GetFlagName(utils.OntIdServiceTypeFlag))
+	endpoint := ctx.String(utils.GetFlagName(utils.OntIdServiceEndpointFlag))
+	if serviceId == "" || serviceType == "" || endpoint == "" {
+		return fmt.Errorf("missing %s, %s or %s flag", utils.GetFlagName(utils.OntIdServiceIdFlag),
+			utils.GetFlagName(utils.OntIdServiceTypeFlag), utils.GetFlagName(utils.OntIdServiceEndpointFlag))
+	}
+	keyNo := uint32(ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag)))
+	return invokeOntId(ctx, "addService", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewAddOntIdServiceTx(gasPrice, gasLimit, ontId, serviceId, serviceType, endpoint, keyNo)
+	})
+}
+
+func ontIdRemoveService(ctx *cli.Context) error {
+	serviceId := ctx.String(utils.GetFlagName(utils.OntIdServiceIdFlag))
+	if serviceId == "" {
+		return fmt.Errorf("missing %s flag", utils.GetFlagName(utils.OntIdServiceIdFlag))
+	}
+	keyNo := uint32(ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag)))
+	return invokeOntId(ctx, "removeService", func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error) {
+		return utils.NewRemoveOntIdServiceTx(gasPrice, gasLimit, ontId, serviceId, keyNo)
+	})
+}
+
+func ontIdDocument(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing ONT ID argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	ontId, err := parseOntId(ctx, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	document, err := utils.GetOntIdDocument(ontId)
+	if err != nil {
+		return fmt.Errorf("GetOntIdDocument error:%s", err)
+	}
+	if document == nil {
+		return fmt.Errorf("ONT ID:%s is not registered", ontId)
+	}
+	var out bytes.Buffer
+	if err = json.Indent(&out, document, "", "   "); err != nil {
+		return fmt.Errorf("invalid document:%s", err)
+	}
+	PrintInfoMsg(out.String())
+	return nil
+}
+
+//parseOntId return the ONT ID of argument, which maybe ONT ID or label of identity in wallet
+func parseOntId(ctx *cli.Context, idOrLabel string) (string, error) {
+	if account.VerifyID(idOrLabel) {
+		return idOrLabel, nil
+	}
+	wallet, err := cmdcom.OpenWallet(ctx)
+	if err != nil {
+		return "", err
+	}
+	identity := wallet.GetIdentity(idOrLabel)
+	if identity == nil {
+		return "", fmt.Errorf("cannot find ONT ID:%s in wallet", idOrLabel)
+	}
+	return identity.ID, nil
+}
+
+type ontIdTxBuilder func(ontId string, signer keypair.PublicKey, gasPrice, gasLimit uint64) (*types.MutableTransaction, error)
+
+//invokeOntId build transaction of ONT ID method with the ONT ID in first argument, and sign it by the ONT ID key
+func invokeOntId(ctx *cli.Context, method string, newTx ontIdTxBuilder) error {
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing ONT ID argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	return signAndSendOntIdTx(ctx, method, ctx.Args().First(), newTx)
+}
+
+//signAndSendOntIdTx build transaction with the ONT ID of signer, print the raw transaction if build-tx flag is set,
+//otherwise sign it by the signer key of keyno and payer account, and send it.
+func signAndSendOntIdTx(ctx *cli.Context, method, signerId string, newTx ontIdTxBuilder) error {
+	SetRpcPort(ctx)
+	wallet, err := cmdcom.OpenWallet(ctx)
+	if err != nil {
+		return err
+	}
+	identity := wallet.GetIdentity(signerId)
+	if identity == nil {
+		return fmt.Errorf("cannot find ONT ID:%s in wallet", signerId)
+	}
+	keyNo := fmt.Sprintf("%d", ctx.Uint(utils.GetFlagName(utils.GovernanceKeyNoFlag)))
+	controller := identity.GetController(keyNo)
+	if controller == nil {
+		return fmt.Errorf("cannot find key:%s of ONT ID:%s in wallet", keyNo, identity.ID)
+	}
+
+	payerAddr := ctx.String(utils.GetFlagName(utils.TransactionPayerFlag))
+	if payerAddr == "" {
+		defAcc := wallet.GetDefaultAccountMetadata()
+		if defAcc == nil {
+			return fmt.Errorf("missing %s flag, and wallet has no default account", utils.GetFlagName(utils.TransactionPayerFlag))
+		}
+		payerAddr = defAcc.Address
+	}
+	payerAddr, err = cmdcom.ParseAddress(payerAddr, ctx)
+	if err != nil {
+		return err
+	}
+	payer, err := common.AddressFromBase58(payerAddr)
+	if err != nil {
+		return fmt.Errorf("invalid payer address:%s", err)
+	}
+
+	buildTx := ctx.Bool(utils.GetFlagName(utils.GovernanceBuildTxFlag))
+	gasPrice := ctx.Uint64(utils.TransactionGasPriceFlag.Name)
+	gasLimit := ctx.Uint64(utils.TransactionGasLimitFlag.Name)
+	var signer *account.Account
+	if !buildTx {
+		networkId, err := utils.GetNetworkId()
+		if err != nil {
+			return err
+		}
+		if networkId == config.NETWORK_ID_SOLO_NET {
+			gasPrice = 0
+		}
+		PrintInfoMsg("Unlock key %s of ONT ID %s", keyNo, identity.ID)
+		passwd, err := cmdcom.GetPasswd(ctx)
+		if err != nil {
+			return err
+		}
+		signer, err = controller.GetAccount(passwd)
+		cmdcom.ClearPasswd(passwd)
+		if err != nil {
+			return fmt.Errorf("cannot unlock key %s of ONT ID:%s, %s", keyNo, identity.ID, err)
+		}
+	}
+	var signerKey keypair.PublicKey
+	if signer != nil {
+		signerKey = signer.PublicKey
+	} else {
+		data, err := hex.DecodeString(controller.Public)
+		if err == nil {
+			signerKey, err = keypair.DeserializePublicKey(data)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid public key of ONT ID:%s, %s", identity.ID, err)
+		}
+	}
+	mutTx, err := newTx(identity.ID, signerKey, gasPrice, gasLimit)
+	if err != nil {
+		return err
+	}
+	mutTx.Payer = payer
+	if buildTx {
+		tx, err := mutTx.IntoImmutable()
+		if err != nil {
+			return fmt.Errorf("IntoImmutable error:%s", err)
+		}
+		sink := common.ZeroCopySink{}
+		tx.Serialization(&sink)
+		PrintInfoMsg("%s raw tx:", method)
+		PrintInfoMsg(hex.EncodeToString(sink.Bytes()))
+		return nil
+	}
+
+	PrintInfoMsg("Unlock payer %s", payerAddr)
+	payerAcc, err := cmdcom.GetAccount(ctx, payerAddr)
+	if err != nil {
+		return err
+	}
+	if err = utils.SignTransaction(payerAcc, mutTx); err != nil {
+		return fmt.Errorf("SignTransaction error:%s", err)
+	}
+	txHash, err := utils.InvokeSmartContract(signer, mutTx)
+	if err != nil {
+		return fmt.Errorf("invoke ONT ID %s error:%s", method, err)
+	}
+	PrintInfoMsg("Invoke ONT ID %s", method)
+	PrintInfoMsg("  ONT ID:%s", identity.ID)
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './ontology info status %s' to query transaction status.", txHash)
+	return nil
+}
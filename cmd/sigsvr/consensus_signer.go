@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sigsvr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ontio/ontology/cmd/sigsvr/store"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/consensus/signer"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+)
+
+//NewConsensusSigner return signer of consensus account in wallet store, with slashing protection records saved in dbDir
+func NewConsensusSigner(walletStore *store.WalletStore, address string, passwd []byte, dbDir string, consensusType string) (*signer.GuardedSigner, error) {
+	maxView, err := signer.MaxSignView(consensusType)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := walletStore.GetAccountByAddress(address, passwd)
+	if err != nil {
+		return nil, fmt.Errorf("get account:%s error:%s", address, err)
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("cannot find account:%s", address)
+	}
+	db, err := leveldbstore.NewLevelDBStore(dbDir)
+	if err != nil {
+		return nil, fmt.Errorf("open signer db:%s error:%s", dbDir, err)
+	}
+	return signer.NewGuardedSigner(signer.NewLocalSigner(acc), signer.NewSlashingGuard(db, maxView)), nil
+}
+
+//StartConsensusSigner serve remote signer protocol for ontology node at address:port
+func StartConsensusSigner(sig signer.Signer, token []byte, address string, port uint) error {
+	handler, err := signer.NewServer(sig, token)
+	if err != nil {
+		return err
+	}
+	httpSvr := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", address, port),
+		Handler: handler,
+	}
+	log.Infof("Consensus signer listening on: %s:%d", address, port)
+	err = httpSvr.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("consensus signer ListenAndServe error:%s", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sigsvr
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/signature"
+	"github.com/ontio/ontology/cmd/sigsvr/store"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/consensus/signer"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusSigner(t *testing.T) {
+	walletPath := "signer_wallet_tmp"
+	dbPath := "signer_db_tmp"
+	defer os.RemoveAll(walletPath)
+	defer os.RemoveAll(dbPath)
+	pwd := []byte("123456")
+	token := []byte("0123456789abcdef0123456789abcdef")
+
+	walletStore, err := store.NewWalletStore(walletPath)
+	assert.Nil(t, err)
+	accData, err := walletStore.NewAccountData(keypair.PK_ECDSA, keypair.P256, signature.SHA256withECDSA, pwd)
+	assert.Nil(t, err)
+	_, err = walletStore.AddAccountData(accData)
+	assert.Nil(t, err)
+
+	_, err = NewConsensusSigner(walletStore, accData.Address, []byte("wrong"), dbPath, config.CONSENSUS_TYPE_VBFT)
+	assert.NotNil(t, err)
+	_, err = NewConsensusSigner(walletStore, accData.Address, pwd, dbPath, config.CONSENSUS_TYPE_SOLO)
+	assert.NotNil(t, err)
+	sig, err := NewConsensusSigner(walletStore, accData.Address, pwd, dbPath, config.CONSENSUS_TYPE_VBFT)
+	assert.Nil(t, err)
+
+	handler, err := signer.NewServer(sig, token)
+	assert.Nil(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	remote, err := signer.NewRemoteSigner(server.URL, token)
+	assert.Nil(t, err)
+	block1 := (&types.Header{Height: 1}).ToArray()
+	block2 := (&types.Header{Height: 1, ConsensusData: 1}).ToArray()
+	_, err = remote.Sign(&signer.SignRequest{Type: signer.SIGN_COMMIT, Data: block1})
+	assert.Nil(t, err)
+	_, err = remote.Sign(&signer.SignRequest{Type: signer.SIGN_COMMIT, Data: block2})
+	assert.NotNil(t, err)
+	_, err = remote.Sign(&signer.SignRequest{Type: signer.SIGN_COMMIT, View: 2, Data: block2})
+	assert.NotNil(t, err)
+}
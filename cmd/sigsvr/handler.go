@@ -29,5 +29,8 @@ func init() {
 	DefCliRpcSvr.RegHandler("sigtransfertx", handlers.SigTransferTransaction)
 	DefCliRpcSvr.RegHandler("signeovminvoketx", handlers.SigNeoVMInvokeTx)
 	DefCliRpcSvr.RegHandler("signeovminvokeabitx", handlers.SigNeoVMInvokeAbiTx)
+	DefCliRpcSvr.RegHandler("sigmanifestinvoketx", handlers.SigManifestInvokeTx)
 	DefCliRpcSvr.RegHandler("signativeinvoketx", handlers.SigNativeInvokeTx)
+	DefCliRpcSvr.RegHandler("createontid", handlers.CreateOntId)
+	DefCliRpcSvr.RegHandler("sigontidtx", handlers.SigOntIdTx)
 }
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	cliutil "github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+)
+
+type CreateOntIdReq struct {
+	GasPrice uint64 `json:"gas_price"`
+	GasLimit uint64 `json:"gas_limit"`
+	Payer    string `json:"payer"`
+}
+
+type CreateOntIdRsp struct {
+	OntId     string `json:"ontid"`
+	PublicKey string `json:"public_key"`
+	SignedTx  string `json:"signed_tx"`
+}
+
+//CreateOntId generate a new ONT ID bound to the public key of account, and sign the transaction to register it
+func CreateOntId(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &CreateOntIdReq{}
+	if len(req.Params) > 0 {
+		err := json.Unmarshal(req.Params, rawReq)
+		if err != nil {
+			log.Infof("Cli Qid:%s CreateOntId json.Unmarshal CreateOntIdReq:%s error:%s", req.Qid, req.Params, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s CreateOntId GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	ontId, err := account.GenerateID()
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	tx, err := cliutil.NewRegisterOntIdTx(rawReq.GasPrice, rawReq.GasLimit, ontId, signer.PublicKey)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	if rawReq.Payer != "" {
+		payerAddress, err := common.AddressFromBase58(rawReq.Payer)
+		if err != nil {
+			log.Infof("Cli Qid:%s CreateOntId AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+		tx.Payer = payerAddress
+	}
+	err = cliutil.SignTransaction(signer, tx)
+	if err != nil {
+		log.Infof("Cli Qid:%s CreateOntId SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	immutable, err := tx.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s CreateOntId convert to immutable transaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	resp.Result = &CreateOntIdRsp{
+		OntId:     ontId,
+		PublicKey: hex.EncodeToString(keypair.SerializePublicKey(signer.PublicKey)),
+		SignedTx:  hex.EncodeToString(common.SerializeToBytes(immutable)),
+	}
+	log.Infof("[CreateOntId]%s %s", ontId, signer.Address.ToBase58())
+}
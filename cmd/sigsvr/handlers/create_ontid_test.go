@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology/account"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+)
+
+func TestCreateOntId(t *testing.T) {
+	defAcc, err := testWallet.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	data, err := json.Marshal(&CreateOntIdReq{GasLimit: 20000, Payer: defAcc.Address.ToBase58()})
+	if err != nil {
+		t.Errorf("json.Marshal CreateOntIdReq error:%s", err)
+		return
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "createontid",
+		Params:  data,
+		Account: defAcc.Address.ToBase58(),
+		Pwd:     string(pwd),
+	}
+	resp := &clisvrcom.CliRpcResponse{}
+	CreateOntId(req, resp)
+	if resp.ErrorCode != 0 {
+		t.Errorf("CreateOntId failed. ErrorCode:%d ErrorInfo:%s", resp.ErrorCode, resp.ErrorInfo)
+		return
+	}
+	rsp := resp.Result.(*CreateOntIdRsp)
+	if !account.VerifyID(rsp.OntId) {
+		t.Errorf("CreateOntId invalid ontid:%s", rsp.OntId)
+		return
+	}
+
+	req.Pwd = "wrong"
+	resp = &clisvrcom.CliRpcResponse{}
+	CreateOntId(req, resp)
+	if resp.ErrorCode != clisvrcom.CLIERR_ACCOUNT_UNLOCK {
+		t.Errorf("CreateOntId with wrong pwd ErrorCode:%d", resp.ErrorCode)
+		return
+	}
+}
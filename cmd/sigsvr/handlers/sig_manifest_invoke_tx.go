@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	cliutil "github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/manifest"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/types"
+	cutils "github.com/ontio/ontology/core/utils"
+	httpcom "github.com/ontio/ontology/http/base/common"
+)
+
+type SigManifestInvokeTxReq struct {
+	GasPrice uint64          `json:"gas_price"`
+	GasLimit uint64          `json:"gas_limit"`
+	VmType   byte            `json:"vm_type"` //1 for neovm, the default, 3 for wasmvm
+	Address  string          `json:"address"`
+	Method   string          `json:"method"`
+	Params   []string        `json:"params"`
+	Payer    string          `json:"payer"`
+	Manifest json.RawMessage `json:"manifest"`
+}
+
+type SigManifestInvokeTxRsp struct {
+	SignedTx string `json:"signed_tx"`
+}
+
+func SigManifestInvokeTx(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigManifestInvokeTxReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("SigManifestInvokeTx json.Unmarshal SigManifestInvokeTxReq:%s error:%s", req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	vmType := payload.NEOVM_TYPE
+	if rawReq.VmType != 0 {
+		vmType, err = payload.VmTypeFromByte(rawReq.VmType)
+		if err != nil {
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			resp.ErrorInfo = err.Error()
+			return
+		}
+	}
+	m, err := manifest.Parse(rawReq.Manifest)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_ABI_UNMATCH
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	method := m.GetMethod(rawReq.Method)
+	if method == nil {
+		resp.ErrorCode = clisvrcom.CLIERR_ABI_NOT_FOUND
+		return
+	}
+	params, err := cliutil.NewManifestInvokeParams(vmType, method, rawReq.Params)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_ABI_UNMATCH
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	contAddr, err := common.AddressFromHexString(rawReq.Address)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigManifestInvokeTx AddressParseFromBytes:%s error:%s", req.Qid, rawReq.Address, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	var mutable *types.MutableTransaction
+	if vmType == payload.NEOVM_TYPE {
+		mutable, err = httpcom.NewNeovmInvokeTransaction(rawReq.GasPrice, rawReq.GasLimit, contAddr, params)
+	} else {
+		mutable, err = cutils.NewWasmVMInvokeTransaction(rawReq.GasPrice, rawReq.GasLimit, contAddr, params)
+	}
+	if err != nil {
+		log.Infof("Cli Qid:%s SigManifestInvokeTx new invoke transaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	if rawReq.Payer != "" {
+		payerAddress, err := common.AddressFromBase58(rawReq.Payer)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigManifestInvokeTx AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+		mutable.Payer = payerAddress
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigManifestInvokeTx GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	err = cliutil.SignTransaction(signer, mutable)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigManifestInvokeTx SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigManifestInvokeTx tx Serialize error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	resp.Result = &SigManifestInvokeTxRsp{
+		SignedTx: hex.EncodeToString(common.SerializeToBytes(tx)),
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/types"
+)
+
+var testManifest = `{
+  "name": "calc",
+  "methods": [
+    {
+      "name": "add",
+      "parameters": [
+        {"name": "a", "type": "integer"},
+        {"name": "b", "type": "integer"}
+      ],
+      "returntype": "integer"
+    }
+  ]
+}`
+
+func TestSigManifestInvokeTx(t *testing.T) {
+	defAcc, err := testWallet.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	for _, vmType := range []payload.VmType{payload.NEOVM_TYPE, payload.WASMVM_TYPE} {
+		invokeReq := &SigManifestInvokeTxReq{
+			VmType:   byte(vmType),
+			Address:  "e827bf96529b5780ad0702757b8bad315e2bb8ce",
+			Method:   "add",
+			Params:   []string{"12", "13"},
+			Manifest: []byte(testManifest),
+		}
+		data, err := json.Marshal(invokeReq)
+		if err != nil {
+			t.Errorf("json.Marshal SigManifestInvokeTxReq error:%s", err)
+			return
+		}
+		req := &clisvrcom.CliRpcRequest{
+			Qid:     "t",
+			Method:  "sigmanifestinvoketx",
+			Params:  data,
+			Account: defAcc.Address.ToBase58(),
+			Pwd:     string(pwd),
+		}
+		rsp := &clisvrcom.CliRpcResponse{}
+		SigManifestInvokeTx(req, rsp)
+		if rsp.ErrorCode != 0 {
+			t.Errorf("SigManifestInvokeTx failed. ErrorCode:%d ErrorInfo:%s", rsp.ErrorCode, rsp.ErrorInfo)
+			return
+		}
+		raw, _ := hex.DecodeString(rsp.Result.(*SigManifestInvokeTxRsp).SignedTx)
+		tx, err := types.TransactionFromRawBytes(raw)
+		if err != nil {
+			t.Errorf("TransactionFromRawBytes error:%s", err)
+			return
+		}
+		if vmType == payload.WASMVM_TYPE && tx.TxType != types.InvokeWasm {
+			t.Errorf("SigManifestInvokeTx tx type:%d, expect wasm invoke", tx.TxType)
+		}
+	}
+
+	invokeReq := &SigManifestInvokeTxReq{
+		Address:  common.ADDRESS_EMPTY.ToHexString(),
+		Method:   "add",
+		Params:   []string{"12"},
+		Manifest: []byte(testManifest),
+	}
+	data, _ := json.Marshal(invokeReq)
+	req := &clisvrcom.CliRpcRequest{Qid: "t", Method: "sigmanifestinvoketx", Params: data}
+	rsp := &clisvrcom.CliRpcResponse{}
+	SigManifestInvokeTx(req, rsp)
+	if rsp.ErrorCode != clisvrcom.CLIERR_ABI_UNMATCH {
+		t.Errorf("SigManifestInvokeTx with wrong params ErrorCode:%d, expect:%d", rsp.ErrorCode, clisvrcom.CLIERR_ABI_UNMATCH)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+	cliutil "github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+)
+
+type OntIdAttribute struct {
+	Key       string `json:"key"`
+	ValueType string `json:"value_type"`
+	Value     string `json:"value"`
+}
+
+type SigOntIdTxReq struct {
+	GasPrice    uint64           `json:"gas_price"`
+	GasLimit    uint64           `json:"gas_limit"`
+	Payer       string           `json:"payer"`
+	Method      string           `json:"method"`
+	OntId       string           `json:"ontid"`
+	Index       uint32           `json:"index"`
+	PublicKey   string           `json:"public_key"`
+	Attributes  []OntIdAttribute `json:"attributes"`
+	Key         string           `json:"key"`
+	Controller  string           `json:"controller"`
+	Recovery    []string         `json:"recovery"`
+	Threshold   uint             `json:"threshold"`
+	ServiceId   string           `json:"service_id"`
+	ServiceType string           `json:"service_type"`
+	Endpoint    string           `json:"endpoint"`
+}
+
+type SigOntIdTxRsp struct {
+	SignedTx string `json:"signed_tx"`
+}
+
+//SigOntIdTx build transaction of ONT ID contract method, and sign it by the ONT ID key of account
+func SigOntIdTx(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigOntIdTxReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntIdTx json.Unmarshal SigOntIdTxReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	if !account.VerifyID(rawReq.OntId) {
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		resp.ErrorInfo = "invalid ontid"
+		return
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntIdTx GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	tx, err := newOntIdTx(rawReq, signer.PublicKey)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	if rawReq.Payer != "" {
+		payerAddress, err := common.AddressFromBase58(rawReq.Payer)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigOntIdTx AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+		tx.Payer = payerAddress
+	}
+	err = cliutil.SignTransaction(signer, tx)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntIdTx SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	immutable, err := tx.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigOntIdTx convert to immutable transaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	resp.Result = &SigOntIdTxRsp{
+		SignedTx: hex.EncodeToString(common.SerializeToBytes(immutable)),
+	}
+}
+
+func newOntIdTx(req *SigOntIdTxReq, signer keypair.PublicKey) (*types.MutableTransaction, error) {
+	switch req.Method {
+	case "regIDWithPublicKey":
+		return cliutil.NewRegisterOntIdTx(req.GasPrice, req.GasLimit, req.OntId, signer)
+	case "regIDWithController":
+		if !account.VerifyID(req.Controller) {
+			return nil, fmt.Errorf("invalid controller")
+		}
+		return cliutil.NewRegOntIdWithControllerTx(req.GasPrice, req.GasLimit, req.OntId, req.Controller, req.Index)
+	case "removeController":
+		return cliutil.NewRemoveOntIdControllerTx(req.GasPrice, req.GasLimit, req.OntId, req.Index)
+	case "addKey", "removeKey":
+		pubKey, err := hex.DecodeString(req.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public_key")
+		}
+		if _, err = keypair.DeserializePublicKey(pubKey); err != nil {
+			return nil, fmt.Errorf("invalid public_key")
+		}
+		if req.Method == "addKey" {
+			return cliutil.NewAddOntIdKeyTx(req.GasPrice, req.GasLimit, req.OntId, pubKey, signer)
+		}
+		return cliutil.NewRemoveOntIdKeyTx(req.GasPrice, req.GasLimit, req.OntId, pubKey, signer)
+	case "addAttributes":
+		attributes := make([]cliutil.OntIdAttribute, 0, len(req.Attributes))
+		for _, attr := range req.Attributes {
+			attributes = append(attributes, cliutil.OntIdAttribute{
+				Key:       []byte(attr.Key),
+				ValueType: []byte(attr.ValueType),
+				Value:     []byte(attr.Value),
+			})
+		}
+		return cliutil.NewAddOntIdAttributesTx(req.GasPrice, req.GasLimit, req.OntId, attributes, signer)
+	case "removeAttribute":
+		return cliutil.NewRemoveOntIdAttributeTx(req.GasPrice, req.GasLimit, req.OntId, req.Key, signer)
+	case "setRecovery":
+		for _, member := range req.Recovery {
+			if !account.VerifyID(member) {
+				return nil, fmt.Errorf("invalid recovery:%s", member)
+			}
+		}
+		return cliutil.NewSetOntIdRecoveryTx(req.GasPrice, req.GasLimit, req.OntId, req.Recovery, req.Threshold, req.Index)
+	case "addService":
+		return cliutil.NewAddOntIdServiceTx(req.GasPrice, req.GasLimit, req.OntId, req.ServiceId, req.ServiceType, req.Endpoint, req.Index)
+	case "removeService":
+		return cliutil.NewRemoveOntIdServiceTx(req.GasPrice, req.GasLimit, req.OntId, req.ServiceId, req.Index)
+	default:
+		return nil, fmt.Errorf("unsupported method:%s", req.Method)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology/account"
+	clisvrcom "github.com/ontio/ontology/cmd/sigsvr/common"
+)
+
+func TestSigOntIdTx(t *testing.T) {
+	defAcc, err := testWallet.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	ontId, _ := account.GenerateID()
+	recovery, _ := account.GenerateID()
+	reqs := []*SigOntIdTxReq{
+		{Method: "addAttributes", Attributes: []OntIdAttribute{{Key: "name", ValueType: "string", Value: "alice"}}},
+		{Method: "removeAttribute", Key: "name"},
+		{Method: "setRecovery", Recovery: []string{recovery}, Threshold: 1, Index: 1},
+		{Method: "addService", ServiceId: "svc1", ServiceType: "Hub", Endpoint: "https://example.com", Index: 1},
+		{Method: "removeService", ServiceId: "svc1", Index: 1},
+	}
+	for _, rawReq := range reqs {
+		rawReq.GasLimit = 20000
+		rawReq.OntId = ontId
+		resp := sigOntIdTx(t, rawReq, defAcc.Address.ToBase58())
+		if resp.ErrorCode != 0 {
+			t.Errorf("SigOntIdTx %s failed. ErrorCode:%d ErrorInfo:%s", rawReq.Method, resp.ErrorCode, resp.ErrorInfo)
+			return
+		}
+	}
+
+	invalidReqs := []*SigOntIdTxReq{
+		{Method: "unknown", OntId: ontId},
+		{Method: "addKey", OntId: ontId, PublicKey: "00"},
+		{Method: "setRecovery", OntId: ontId, Recovery: []string{recovery}, Threshold: 2},
+		{Method: "addService", OntId: "did:ont:invalid"},
+	}
+	for _, rawReq := range invalidReqs {
+		resp := sigOntIdTx(t, rawReq, defAcc.Address.ToBase58())
+		if resp.ErrorCode != clisvrcom.CLIERR_INVALID_PARAMS {
+			t.Errorf("SigOntIdTx %s should fail, ErrorCode:%d", rawReq.Method, resp.ErrorCode)
+			return
+		}
+	}
+}
+
+func sigOntIdTx(t *testing.T, rawReq *SigOntIdTxReq, address string) *clisvrcom.CliRpcResponse {
+	data, err := json.Marshal(rawReq)
+	if err != nil {
+		t.Fatalf("json.Marshal SigOntIdTxReq error:%s", err)
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "sigontidtx",
+		Params:  data,
+		Account: address,
+		Pwd:     string(pwd),
+	}
+	resp := &clisvrcom.CliRpcResponse{}
+	SigOntIdTx(req, resp)
+	return resp
+}
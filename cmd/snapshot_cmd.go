@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/store"
+	"github.com/ontio/ontology/core/types"
+)
+
+var SnapshotCommand = cli.Command{
+	Action:    cli.ShowSubcommandHelp,
+	Name:      "snapshot",
+	Usage:     "Export or import state snapshot",
+	ArgsUsage: "[arguments...]",
+	Description: `A state snapshot contains the states of current block height and the block header chain.
+A node imported the snapshot syncs blocks forward from the snapshot height instead of from genesis block.
+Note that the node should be stopped when exporting or importing snapshot.`,
+	Subcommands: []cli.Command{
+		{
+			Action:    exportSnapshot,
+			Name:      "export",
+			Usage:     "Export state snapshot of current block height to a file",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+		},
+		{
+			Action:    importSnapshot,
+			Name:      "import",
+			Usage:     "Import state snapshot to an empty DB",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.SnapshotBlockHashFlag,
+				utils.SnapshotStateRootFlag,
+				utils.SnapshotStateDigestFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+		},
+	},
+}
+
+func exportSnapshot(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	ldg, _, err := openSnapshotLedger(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer ldg.Close()
+
+	sf, err := os.OpenFile(snapshotFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return fmt.Errorf("open file:%s error:%s", snapshotFile, err)
+	}
+	defer sf.Close()
+
+	PrintInfoMsg("Start export snapshot.")
+	info, err := ldg.ExportSnapshot(sf)
+	if err != nil {
+		return fmt.Errorf("export snapshot error:%s", err)
+	}
+	err = sf.Sync()
+	if err != nil {
+		return fmt.Errorf("sync file:%s error:%s", snapshotFile, err)
+	}
+	PrintInfoMsg("Export snapshot successfully.")
+	printSnapshotInfo(info)
+	PrintInfoMsg("Snapshot file:%s", snapshotFile)
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	ldg, genesisBlock, err := openSnapshotLedger(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer ldg.Close()
+
+	PrintInfoMsg("Start import snapshot.")
+	blockHash := ctx.String(utils.GetFlagName(utils.SnapshotBlockHashFlag))
+	stateRoot := ctx.String(utils.GetFlagName(utils.SnapshotStateRootFlag))
+	stateDigest := ctx.String(utils.GetFlagName(utils.SnapshotStateDigestFlag))
+	info, err := ImportSnapshotFile(ldg, snapshotFile, genesisBlock, blockHash, stateRoot, stateDigest)
+	if err != nil {
+		return fmt.Errorf("import snapshot error:%s", err)
+	}
+	PrintInfoMsg("Import snapshot successfully.")
+	printSnapshotInfo(info)
+	return nil
+}
+
+//ImportSnapshotFile imports the snapshot file to an empty ledger, blockHash, stateRoot and stateDigest are the
+//trusted block hash, state merkle root and state digest in hex to verify the snapshot, they are ignored if empty.
+//At least one of blockHash and stateRoot is required.
+func ImportSnapshotFile(ldg *ledger.Ledger, snapshotFile string, genesisBlock *types.Block,
+	blockHash, stateRoot, stateDigest string) (*store.SnapshotInfo, error) {
+	if blockHash == "" && stateRoot == "" {
+		return nil, fmt.Errorf("please specify the trusted block hash or state root of snapshot using --%s or --%s flag",
+			utils.GetFlagName(utils.SnapshotBlockHashFlag), utils.GetFlagName(utils.SnapshotStateRootFlag))
+	}
+	var hash, root, digest common.Uint256
+	if blockHash != "" {
+		var err error
+		hash, err = common.Uint256FromHexString(blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block hash:%s", err)
+		}
+	}
+	if stateRoot != "" {
+		var err error
+		root, err = common.Uint256FromHexString(stateRoot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state root:%s", err)
+		}
+	}
+	if stateDigest != "" {
+		var err error
+		digest, err = common.Uint256FromHexString(stateDigest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state digest:%s", err)
+		}
+	}
+	sf, err := os.Open(snapshotFile)
+	if err != nil {
+		return nil, fmt.Errorf("open file:%s error:%s", snapshotFile, err)
+	}
+	defer sf.Close()
+	return ldg.ImportSnapshot(bufio.NewReader(sf), genesisBlock, hash, root, digest)
+}
+
+//openSnapshotLedger opens the ledger DB, and initializes it with genesis block if init is set
+func openSnapshotLedger(ctx *cli.Context, init bool) (*ledger.Ledger, *types.Block, error) {
+	cfg, err := SetOntologyConfig(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SetOntologyConfig error:%s", err)
+	}
+	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
+	stateHashHeight := config.GetStateHashCheckHeight(cfg.P2PNode.NetworkId)
+	ledger.DefLedger, err = ledger.NewLedger(dbDir, stateHashHeight)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewLedger error:%s", err)
+	}
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		return nil, nil, fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	if init {
+		err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+		if err != nil {
+			return nil, nil, fmt.Errorf("init ledger error:%s", err)
+		}
+	}
+	return ledger.DefLedger, genesisBlock, nil
+}
+
+func printSnapshotInfo(info *store.SnapshotInfo) {
+	PrintInfoMsg("BlockHeight:%d", info.Height)
+	PrintInfoMsg("BlockHash:%s", info.BlockHash.ToHexString())
+	PrintInfoMsg("StateMerkleRoot:%s", info.StateMerkleRoot.ToHexString())
+	PrintInfoMsg("StateDigest:%s", info.StateDigest.ToHexString())
+}
@@ -103,7 +103,14 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.LogDirFlag,
 			utils.DisableLogFileFlag,
 			utils.DisableEventLogFlag,
+			utils.EnableStateArchiveFlag,
+			utils.EnableAddressHistoryFlag,
+			utils.SnapshotFileFlag,
+			utils.SnapshotBlockHashFlag,
+			utils.SnapshotStateRootFlag,
+			utils.SnapshotStateDigestFlag,
 			utils.DataDirFlag,
+			utils.StoreBackendFlag,
 			utils.WasmVerifyMethodFlag,
 		},
 	},
@@ -135,6 +142,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.EnableConsensusFlag,
 			utils.MaxTxInBlockFlag,
+			utils.RemoteSignerFlag,
+			utils.RemoteSignerTokenFlag,
 		},
 	},
 	{
@@ -143,6 +152,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.GasPriceFlag,
 			utils.GasLimitFlag,
 			utils.TxpoolPreExecDisableFlag,
+			utils.TxpoolMaxTxInPoolFlag,
+			utils.TxpoolMaxTxPerPayerFlag,
 			utils.DisableSyncVerifyTxFlag,
 			utils.DisableBroadcastNetTxFlag,
 		},
@@ -158,6 +169,14 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MaxConnInBoundFlag,
 			utils.MaxConnOutBoundFlag,
 			utils.MaxConnInBoundForSingleIPFlag,
+			utils.EnableNoiseFlag,
+			utils.NodeKeyFileFlag,
+			utils.DisableCompressionFlag,
+			utils.MaxPeerRecvRateFlag,
+			utils.MaxPeerSendRateFlag,
+			utils.SyncMaxFlightHeadersFlag,
+			utils.SyncMaxFlightBlocksFlag,
+			utils.SyncMaxBlockCacheFlag,
 		},
 	},
 	{
@@ -167,6 +186,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCPortFlag,
 			utils.RPCLocalEnableFlag,
 			utils.RPCLocalProtFlag,
+			utils.RPCStandardResponseFlag,
 		},
 	},
 	{
@@ -192,6 +212,14 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WsPortFlag,
 		},
 	},
+	{
+		Name: "METRICS",
+		Flags: []cli.Flag{
+			utils.MetricsEnableFlag,
+			utils.MetricsPortFlag,
+			utils.MetricsPathFlag,
+		},
+	},
 	{
 		Name: "TEST MODE",
 		Flags: []cli.Flag{
@@ -247,6 +275,32 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ApproveAssetToFlag,
 		},
 	},
+	{
+		Name: "GOVERNANCE",
+		Flags: []cli.Flag{
+			utils.GovernancePeerPubkeyFlag,
+			utils.GovernancePosFlag,
+			utils.GovernanceOntIdFlag,
+			utils.GovernanceKeyNoFlag,
+			utils.GovernancePeerCostFlag,
+			utils.GovernanceBuildTxFlag,
+		},
+	},
+	{
+		Name: "ONT ID",
+		Flags: []cli.Flag{
+			utils.OntIdPubKeyFlag,
+			utils.OntIdAttrKeyFlag,
+			utils.OntIdAttrTypeFlag,
+			utils.OntIdAttrValueFlag,
+			utils.OntIdRecoveryFlag,
+			utils.OntIdThresholdFlag,
+			utils.OntIdControllerFlag,
+			utils.OntIdServiceIdFlag,
+			utils.OntIdServiceTypeFlag,
+			utils.OntIdServiceEndpointFlag,
+		},
+	},
 	{
 		Name: "EXPORT",
 		Flags: []cli.Flag{
@@ -24,6 +24,7 @@ import (
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/smartcontract/service/neovm"
+	tc "github.com/ontio/ontology/txnpool/common"
 	"github.com/urfave/cli"
 )
 
@@ -32,6 +33,10 @@ const (
 	DEFAULT_ABI_PATH      = "./abi"
 	DEFAULT_EXPORT_HEIGHT = 0
 	DEFAULT_WALLET_PATH   = "./wallet_data"
+	DEFAULT_HISTORY_LIMIT = 100
+	DEFAULT_SIGNER_DB     = "./signer_db"
+	DEFAULT_SIGNER_PORT   = uint(20600)
+	DEFAULT_NODE_KEY_FILE = "p2p_node.key"
 )
 
 var (
@@ -58,6 +63,14 @@ var (
 		Name:  "disable-event-log",
 		Usage: "Discard event log output by smart contract execution",
 	}
+	EnableStateArchiveFlag = cli.BoolFlag{
+		Name:  "enable-state-archive",
+		Usage: "Keep history states of following blocks to support state query at a block height",
+	}
+	EnableAddressHistoryFlag = cli.BoolFlag{
+		Name:  "enable-address-history",
+		Usage: "Index the transfers of following blocks by address to support address history query",
+	}
 	WasmVerifyMethodFlag = cli.BoolFlag{
 		Name:  "enable-wasmjit-verifier",
 		Usage: "Enable wasmjit verifier to verify wasm contract",
@@ -82,6 +95,11 @@ var (
 		Usage: "Block data storage `<path>`",
 		Value: config.DEFAULT_DATA_DIR,
 	}
+	StoreBackendFlag = cli.StringFlag{
+		Name:  "store-backend",
+		Usage: "Persist store `<backend>` of ledger: leveldb, badger, or memory (data is lost on exit)",
+		Value: config.DEFAULT_STORE_BACKEND,
+	}
 	//Consensus setting
 	EnableConsensusFlag = cli.BoolFlag{
 		Name:  "enable-consensus",
@@ -92,6 +110,14 @@ var (
 		Usage: "Max transaction `<number>` in block",
 		Value: config.DEFAULT_MAX_TX_IN_BLOCK,
 	}
+	RemoteSignerFlag = cli.StringFlag{
+		Name:  "remote-signer",
+		Usage: "Sign consensus messages by remote signer daemon at `<address>`, such as http://127.0.0.1:20600. Only for vbft",
+	}
+	RemoteSignerTokenFlag = cli.StringFlag{
+		Name:  "remote-signer-token",
+		Usage: "Token `<file>` shared with remote signer daemon",
+	}
 	GasLimitFlag = cli.Uint64Flag{
 		Name:  "gaslimit",
 		Usage: "Min gas limit `<value>` of transaction to be accepted by tx pool.",
@@ -153,6 +179,41 @@ var (
 		Usage: "Max connection `<number>` in bound for single ip",
 		Value: config.DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
 	}
+	EnableNoiseFlag = cli.BoolFlag{
+		Name:  "p2p-noise",
+		Usage: "Encrypt p2p connections and authenticate peers by node key. Peers whitelisted by \"reserved_keys\" in --reserved-file require it.",
+	}
+	NodeKeyFileFlag = cli.StringFlag{
+		Name:  "p2p-node-key",
+		Usage: "Node key `<file>` of p2p identity, created if not exist. Default is " + DEFAULT_NODE_KEY_FILE + " under the data directory with p2p noise enabled, otherwise a random node key.",
+	}
+	DisableCompressionFlag = cli.BoolFlag{
+		Name:  "disable-p2p-compress",
+		Usage: "Disable snappy compression of p2p messages. Compression is negotiated with each peer.",
+	}
+	MaxPeerRecvRateFlag = cli.UintFlag{
+		Name:  "max-peer-recv-rate",
+		Usage: "Max receive bandwidth `<kB/s>` of a peer, peer exceeds it is throttled or disconnected. 0 means unlimited",
+	}
+	MaxPeerSendRateFlag = cli.UintFlag{
+		Name:  "max-peer-send-rate",
+		Usage: "Max send bandwidth `<kB/s>` to a peer, messages exceed it are delayed or dropped. 0 means unlimited",
+	}
+	SyncMaxFlightHeadersFlag = cli.UintFlag{
+		Name:  "sync-flight-headers",
+		Usage: "Max header segments `<number>` downloading from peers in parallel when syncing",
+		Value: config.DEFAULT_SYNC_MAX_FLIGHT_HEADERS,
+	}
+	SyncMaxFlightBlocksFlag = cli.UintFlag{
+		Name:  "sync-flight-blocks",
+		Usage: "Max blocks `<number>` downloading when syncing, adapted to peer throughput",
+		Value: config.DEFAULT_SYNC_MAX_FLIGHT_BLOCKS,
+	}
+	SyncMaxBlockCacheFlag = cli.UintFlag{
+		Name:  "sync-block-cache",
+		Usage: "Max downloaded blocks `<number>` waiting for execution when syncing",
+		Value: config.DEFAULT_SYNC_MAX_BLOCK_CACHE,
+	}
 	// RPC settings
 	RPCDisabledFlag = cli.BoolFlag{
 		Name:  "disable-rpc",
@@ -172,6 +233,10 @@ var (
 		Usage: "Json rpc local server listening port `<number>`",
 		Value: config.DEFAULT_RPC_LOCAL_PORT,
 	}
+	RPCStandardResponseFlag = cli.BoolFlag{
+		Name:  "rpc-standard-response",
+		Usage: "Respond with json rpc 2.0 error objects instead of the legacy error code and desc",
+	}
 
 	//Websocket setting
 	WsEnabledFlag = cli.BoolFlag{
@@ -216,6 +281,22 @@ var (
 		Value: config.DEFAULT_HTTP_MAX_CONN,
 	}
 
+	//Metrics setting
+	MetricsEnableFlag = cli.BoolFlag{
+		Name:  "metrics",
+		Usage: "Enable prometheus metrics server",
+	}
+	MetricsPortFlag = cli.UintFlag{
+		Name:  "metrics-port",
+		Usage: "Metrics server listening port `<number>`",
+		Value: config.DEFAULT_METRICS_PORT,
+	}
+	MetricsPathFlag = cli.StringFlag{
+		Name:  "metrics-path",
+		Usage: "Metrics server http `<path>` to scrape",
+		Value: config.DEFAULT_METRICS_PATH,
+	}
+
 	//Account setting
 	AccountPassFlag = cli.StringFlag{
 		Name:   "password,p",
@@ -334,6 +415,14 @@ var (
 		Name:  "params",
 		Usage: "Contract parameters list to invoke. separate params with comma ','",
 	}
+	ContractMethodFlag = cli.StringFlag{
+		Name:  "method",
+		Usage: "Invoke the `<method>` declared in the contract manifest, --params is then a list of plain values",
+	}
+	ContractManifestFlag = cli.StringFlag{
+		Name:  "manifest",
+		Usage: "Contract manifest json `<file>`, stored alongside the contract when deploying",
+	}
 	ContractPrepareDeployFlag = cli.BoolFlag{
 		Name:  "prepare,p",
 		Usage: "Prepare deploy contract without commit to ledger",
@@ -342,6 +431,10 @@ var (
 		Name:  "prepare,p",
 		Usage: "Prepare invoke contract without commit to ledger",
 	}
+	ContractProfileFlag = cli.BoolFlag{
+		Name:  "profile",
+		Usage: "Report the gas by opcode class, syscall, contract and wasm function when prepare invoke contract",
+	}
 	ContractReturnTypeFlag = cli.StringFlag{
 		Name:  "return",
 		Usage: "Return `<type>` of contract. bytearray(hexstring), string, int, boolean",
@@ -356,6 +449,15 @@ var (
 		Name:  "height",
 		Usage: "Get block info by block height",
 	}
+	HistoryFromHeightFlag = cli.UintFlag{
+		Name:  "from-height",
+		Usage: "Query address history from block height `<number>`",
+	}
+	HistoryLimitFlag = cli.UintFlag{
+		Name:  "limit",
+		Usage: "Max `<number>` of transactions returned per page",
+		Value: DEFAULT_HISTORY_LIMIT,
+	}
 
 	//Transfer setting
 	TransactionAssetFlag = cli.StringFlag{
@@ -449,6 +551,77 @@ var (
 		Usage: "Force to send transaction",
 	}
 
+	//Governance setting
+	GovernancePeerPubkeyFlag = cli.StringFlag{
+		Name:  "peer-pubkey",
+		Usage: "Public `<key>` of consensus peer in hex. Use ',' to separate peers of authorize, unauthorize and withdraw",
+	}
+	GovernancePosFlag = cli.StringFlag{
+		Name:  "pos",
+		Usage: "Amount `<number>` of ONT to stake. Use ',' to separate amounts, one for each peer-pubkey",
+	}
+	GovernanceOntIdFlag = cli.StringFlag{
+		Name:  "ontid",
+		Usage: "Ontology `<id>` of peer owner to register candidate",
+	}
+	GovernanceKeyNoFlag = cli.UintFlag{
+		Name:  "keyno",
+		Usage: "Index `<number>` of the ontid public key which signs the transaction",
+		Value: 1,
+	}
+	GovernancePeerCostFlag = cli.UintFlag{
+		Name:  "peer-cost",
+		Usage: "Percentage `<number>` of fee the peer keeps, from 0 to 100",
+	}
+	GovernanceBuildTxFlag = cli.BoolFlag{
+		Name:  "build-tx",
+		Usage: "Print unsigned raw transaction instead of sending it, for offline or multi signature",
+	}
+
+	//ONT ID setting
+	OntIdPubKeyFlag = cli.StringFlag{
+		Name:  "pubkey",
+		Usage: "Public `<key>` in hex to add to or remove from ONT ID",
+	}
+	OntIdAttrKeyFlag = cli.StringFlag{
+		Name:  "attr-key",
+		Usage: "`<key>` of ONT ID attribute",
+	}
+	OntIdAttrTypeFlag = cli.StringFlag{
+		Name:  "attr-type",
+		Usage: "Value `<type>` of ONT ID attribute",
+		Value: "string",
+	}
+	OntIdAttrValueFlag = cli.StringFlag{
+		Name:  "attr-value",
+		Usage: "`<value>` of ONT ID attribute",
+	}
+	OntIdRecoveryFlag = cli.StringFlag{
+		Name:  "recovery",
+		Usage: "ONT ID `<ids>` of recovery group members. Use ',' to separate members",
+	}
+	OntIdThresholdFlag = cli.UintFlag{
+		Name:  "threshold",
+		Usage: "`<number>` of recovery group members required to sign",
+		Value: 1,
+	}
+	OntIdControllerFlag = cli.StringFlag{
+		Name:  "controller",
+		Usage: "Controller `<ontid|label>` in wallet, which signs the transaction by the key of keyno",
+	}
+	OntIdServiceIdFlag = cli.StringFlag{
+		Name:  "service-id",
+		Usage: "`<id>` of ONT ID service",
+	}
+	OntIdServiceTypeFlag = cli.StringFlag{
+		Name:  "service-type",
+		Usage: "`<type>` of ONT ID service",
+	}
+	OntIdServiceEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "Service `<endpoint>` of ONT ID",
+	}
+
 	//Cli setting
 	CliAddressFlag = cli.StringFlag{
 		Name:  "cliaddress",
@@ -470,6 +643,29 @@ var (
 		Usage: "Wallet data `<path>`",
 		Value: DEFAULT_WALLET_PATH,
 	}
+	CliSignerAccountFlag = cli.StringFlag{
+		Name:  "signer-account",
+		Usage: "Serve consensus remote signer for ontology node by account `<address>` in wallet data",
+	}
+	CliSignerPortFlag = cli.UintFlag{
+		Name:  "signer-port",
+		Usage: "Consensus remote signer bind port `<number>`",
+		Value: DEFAULT_SIGNER_PORT,
+	}
+	CliSignerTokenFlag = cli.StringFlag{
+		Name:  "signer-token",
+		Usage: "Token `<file>` shared with ontology node",
+	}
+	CliSignerDBFlag = cli.StringFlag{
+		Name:  "signer-db",
+		Usage: "Slashing protection records `<path>` of consensus remote signer",
+		Value: DEFAULT_SIGNER_DB,
+	}
+	CliSignerConsensusFlag = cli.StringFlag{
+		Name:  "signer-consensus",
+		Usage: "Consensus `<type>` of ontology node served by consensus remote signer, vbft or sbft",
+		Value: config.CONSENSUS_TYPE_VBFT,
+	}
 
 	//Export setting
 	ExportFileFlag = cli.StringFlag{
@@ -493,12 +689,41 @@ var (
 		Value: "m",
 	}
 
+	//Snapshot setting
+	SnapshotFileFlag = cli.StringFlag{
+		Name:  "snapshot-file",
+		Usage: "State snapshot `<file>` path. An empty node will start from the snapshot if set",
+	}
+	SnapshotBlockHashFlag = cli.StringFlag{
+		Name:  "snapshot-block-hash",
+		Usage: "Trusted block hash `<hex>` of the snapshot height to verify the snapshot",
+	}
+	SnapshotStateRootFlag = cli.StringFlag{
+		Name:  "snapshot-state-root",
+		Usage: "Trusted state merkle root `<hex>` to verify the snapshot",
+	}
+	SnapshotStateDigestFlag = cli.StringFlag{
+		Name:  "snapshot-state-digest",
+		Usage: "Trusted state digest `<hex>` to verify the states in snapshot",
+	}
+
 	//PreExecute switcher
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disable-tx-pool-pre-exec",
 		Usage: "Disable preExecute in tx pool",
 	}
 
+	TxpoolMaxTxInPoolFlag = cli.UintFlag{
+		Name:  "tx-pool-capacity",
+		Usage: "Max transaction `<number>` in tx pool, the cheapest ones are evicted when full",
+		Value: tc.MAX_CAPACITY,
+	}
+	TxpoolMaxTxPerPayerFlag = cli.UintFlag{
+		Name:  "tx-pool-payer-limit",
+		Usage: "Max transaction `<number>` of a single payer in tx pool",
+		Value: tc.MAX_TX_PER_PAYER,
+	}
+
 	//local PreExecute switcher
 	DisableSyncVerifyTxFlag = cli.BoolFlag{
 		Name:  "disable-sync-verify-tx",
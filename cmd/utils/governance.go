@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ontio/ontology/cmd/abi"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native/governance"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const VERSION_CONTRACT_GOVERNANCE = byte(0)
+
+//NewGovernanceInvokeTx return a transaction invoking governance contract method, params are encoded by governance abi
+func NewGovernanceInvokeTx(gasPrice, gasLimit uint64, method string, params []interface{}) (*types.MutableTransaction, error) {
+	nativeAbi := abi.DefAbiMgr.GetNativeAbi(utils.GovernanceContractAddress.ToHexString())
+	if nativeAbi == nil {
+		return nil, fmt.Errorf("cannot find governance abi in path:%s", abi.DefAbiMgr.Path)
+	}
+	funcAbi := nativeAbi.GetFunc(method)
+	if funcAbi == nil {
+		return nil, fmt.Errorf("cannot find method:%s in governance abi", method)
+	}
+	return NewNativeInvokeTransaction(gasPrice, gasLimit, utils.GovernanceContractAddress, VERSION_CONTRACT_GOVERNANCE, params, funcAbi)
+}
+
+func prepareInvokeGovernance(method string, params []interface{}) (*common.ZeroCopySource, error) {
+	mutable, err := NewGovernanceInvokeTx(0, 0, method, params)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+	preResult, err := PrepareSendRawTransaction(hex.EncodeToString(common.SerializeToBytes(tx)))
+	if err != nil {
+		return nil, err
+	}
+	if preResult.State == 0 {
+		return nil, fmt.Errorf("prepare invoke %s failed", method)
+	}
+	rawResult, ok := preResult.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s result:%v", method, preResult.Result)
+	}
+	data, err := hex.DecodeString(rawResult)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	return common.NewZeroCopySource(data), nil
+}
+
+//GetPeerPool return the peers of current governance view
+func GetPeerPool() ([]*governance.PeerPoolItemForVm, error) {
+	source, err := prepareInvokeGovernance(governance.GET_PEER_POOL, []interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	peerPool := &governance.PeerPoolListForVm{}
+	if err := peerPool.Deserialization(source); err != nil {
+		return nil, err
+	}
+	return peerPool.PeerPoolList, nil
+}
+
+//GetPeerInfo return the peer of peerAddress in current governance view
+func GetPeerInfo(peerAddress string) (*governance.PeerPoolItemForVm, error) {
+	source, err := prepareInvokeGovernance(governance.GET_PEER_INFO, []interface{}{peerAddress})
+	if err != nil {
+		return nil, err
+	}
+	peerInfo := &governance.PeerPoolItemForVm{}
+	if err := peerInfo.Deserialization(source); err != nil {
+		return nil, err
+	}
+	return peerInfo, nil
+}
+
+//GetAuthorizeInfo return the stake of address authorized to peer
+func GetAuthorizeInfo(peerPubkey, address string) (*governance.AuthorizeInfo, error) {
+	source, err := prepareInvokeGovernance(governance.GET_AUTHORIZE_INFO, []interface{}{peerPubkey, address})
+	if err != nil {
+		return nil, err
+	}
+	authorizeInfo := &governance.AuthorizeInfo{}
+	if err := authorizeInfo.Deserialization(source); err != nil {
+		return nil, err
+	}
+	return authorizeInfo, nil
+}
+
+//GetAddressFee return the fee of address which can be withdrawn by withdrawFee
+func GetAddressFee(address string) (*governance.SplitFeeAddress, error) {
+	source, err := prepareInvokeGovernance(governance.GET_ADDRESS_FEE, []interface{}{address})
+	if err != nil {
+		return nil, err
+	}
+	addressFee := &governance.SplitFeeAddress{}
+	if err := addressFee.Deserialization(source); err != nil {
+		return nil, err
+	}
+	return addressFee, nil
+}
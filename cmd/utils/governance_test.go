@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ontio/ontology/cmd/abi"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native/governance"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+//decodeGovernanceInvoke decode the input of governance method invoked by tx
+func decodeGovernanceInvoke(t *testing.T, tx *types.MutableTransaction, method string) *common.ZeroCopySource {
+	contract, invoked, source := decodeNativeInvoke(t, tx.Payload.(*payload.InvokeCode).Code)
+	assert.Equal(t, nutils.GovernanceContractAddress, contract)
+	assert.Equal(t, method, invoked)
+	return source
+}
+
+func TestNewGovernanceInvokeTx(t *testing.T) {
+	abi.DefAbiMgr = abi.NewAbiMgr()
+	_, err := NewGovernanceInvokeTx(0, 20000, governance.WITHDRAW_FEE, []interface{}{"AKDFapcoUhewN9Kaj6XhHusurfHzUiZqUA"})
+	assert.Error(t, err)
+
+	abi.DefAbiMgr.Init("../abi/native_abi_script")
+	peerPubkey := "037c9e6c6a446b6b296f89b722cbf686b81e0a122444ef05f0f87096777663284b"
+	peerPubkey2 := "03348c8fe64e1defb408676b6e320038bd2e592c802e27c3d7e88e68270076c2f6"
+	address := "AKDFapcoUhewN9Kaj6XhHusurfHzUiZqUA"
+	addr, err := common.AddressFromBase58(address)
+	assert.Nil(t, err)
+
+	tx, err := NewGovernanceInvokeTx(500, 20000, governance.AUTHORIZE_FOR_PEER,
+		[]interface{}{address, []interface{}{peerPubkey}, []interface{}{"100"}})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), tx.GasPrice)
+	assert.Equal(t, uint64(20000), tx.GasLimit)
+	authorize := &governance.AuthorizeForPeerParam{}
+	assert.Nil(t, authorize.Deserialization(decodeGovernanceInvoke(t, tx, governance.AUTHORIZE_FOR_PEER)))
+	assert.Equal(t, &governance.AuthorizeForPeerParam{
+		Address:        addr,
+		PeerPubkeyList: []string{peerPubkey},
+		PosList:        []uint32{100},
+	}, authorize)
+
+	tx, err = NewGovernanceInvokeTx(0, 20000, governance.WITHDRAW,
+		[]interface{}{address, []interface{}{peerPubkey, peerPubkey2}, []interface{}{"1", "2"}})
+	assert.Nil(t, err)
+	withdraw := &governance.WithdrawParam{}
+	assert.Nil(t, withdraw.Deserialization(decodeGovernanceInvoke(t, tx, governance.WITHDRAW)))
+	assert.Equal(t, addr, withdraw.Address)
+	//PACK reverses the array items, the amount of each peer is kept as both lists are reversed
+	withdrawList := make(map[string]uint32)
+	for i, peer := range withdraw.PeerPubkeyList {
+		withdrawList[peer] = withdraw.WithdrawList[i]
+	}
+	assert.Equal(t, map[string]uint32{peerPubkey: 1, peerPubkey2: 2}, withdrawList)
+
+	ontId := "did:ont:AKDFapcoUhewN9Kaj6XhHusurfHzUiZqUA"
+	tx, err = NewGovernanceInvokeTx(0, 20000, governance.REGISTER_CANDIDATE,
+		[]interface{}{peerPubkey, address, "10000", hex.EncodeToString([]byte(ontId)), "1"})
+	assert.Nil(t, err)
+	register := &governance.RegisterCandidateParam{}
+	assert.Nil(t, register.Deserialization(decodeGovernanceInvoke(t, tx, governance.REGISTER_CANDIDATE)))
+	assert.Equal(t, &governance.RegisterCandidateParam{
+		PeerPubkey: peerPubkey,
+		Address:    addr,
+		InitPos:    10000,
+		Caller:     []byte(ontId),
+		KeyNo:      1,
+	}, register)
+
+	tx, err = NewGovernanceInvokeTx(0, 20000, governance.SET_PEER_COST, []interface{}{peerPubkey, address, "50"})
+	assert.Nil(t, err)
+	peerCost := &governance.SetPeerCostParam{}
+	assert.Nil(t, peerCost.Deserialization(decodeGovernanceInvoke(t, tx, governance.SET_PEER_COST)))
+	assert.Equal(t, &governance.SetPeerCostParam{PeerPubkey: peerPubkey, Address: addr, PeerCost: 50}, peerCost)
+
+	tx, err = NewGovernanceInvokeTx(0, 20000, governance.ADD_INIT_POS, []interface{}{peerPubkey, address, "200"})
+	assert.Nil(t, err)
+	initPos := &governance.ChangeInitPosParam{}
+	assert.Nil(t, initPos.Deserialization(decodeGovernanceInvoke(t, tx, governance.ADD_INIT_POS)))
+	assert.Equal(t, &governance.ChangeInitPosParam{PeerPubkey: peerPubkey, Address: addr, Pos: 200}, initPos)
+
+	tx, err = NewGovernanceInvokeTx(0, 20000, governance.WITHDRAW_ONG, []interface{}{address})
+	assert.Nil(t, err)
+	withdrawOng := &governance.WithdrawOngParam{}
+	assert.Nil(t, withdrawOng.Deserialization(decodeGovernanceInvoke(t, tx, governance.WITHDRAW_ONG)))
+	assert.Equal(t, addr, withdrawOng.Address)
+
+	for _, method := range []string{governance.GET_PEER_POOL, governance.GET_PEER_INFO, governance.GET_AUTHORIZE_INFO, governance.GET_ADDRESS_FEE} {
+		assert.NotNil(t, abi.DefAbiMgr.GetNativeAbi(nutils.GovernanceContractAddress.ToHexString()).GetFunc(method), method)
+	}
+
+	_, err = NewGovernanceInvokeTx(0, 20000, "unknownMethod", []interface{}{})
+	assert.Error(t, err)
+	_, err = NewGovernanceInvokeTx(0, 20000, governance.AUTHORIZE_FOR_PEER, []interface{}{address})
+	assert.Error(t, err)
+}
+
+func TestGetPeerPool(t *testing.T) {
+	abi.DefAbiMgr = abi.NewAbiMgr()
+	abi.DefAbiMgr.Init("../abi/native_abi_script")
+	peers := &governance.PeerPoolListForVm{PeerPoolList: []*governance.PeerPoolItemForVm{
+		{Index: 1, PeerAddress: common.AddressFromVmCode([]byte("peer1")), Status: governance.ConsensusStatus,
+			InitPos: 10000, TotalPos: 20000},
+		{Index: 2, PeerAddress: common.AddressFromVmCode([]byte("peer2")), Status: governance.CandidateStatus,
+			InitPos: 10000},
+	}}
+
+	//pre-execute the getPeerPool transaction, as the rpc server of node does
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &JsonRpcRequest{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(req))
+		assert.Equal(t, "sendrawtransaction", req.Method)
+		raw, err := hex.DecodeString(req.Params[0].(string))
+		assert.Nil(t, err)
+		tx, err := types.TransactionFromRawBytes(raw)
+		assert.Nil(t, err)
+		contract, method, _ := decodeNativeInvoke(t, tx.Payload.(*payload.InvokeCode).Code)
+		assert.Equal(t, nutils.GovernanceContractAddress, contract)
+		assert.Equal(t, governance.GET_PEER_POOL, method)
+
+		sink := common.NewZeroCopySink(nil)
+		peers.Serialization(sink)
+		result, _ := json.Marshal(map[string]interface{}{"State": 1, "Result": hex.EncodeToString(sink.Bytes())})
+		json.NewEncoder(w).Encode(&JsonRpcResponse{Result: result})
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	assert.Nil(t, err)
+	rpcPort := config.DefConfig.Rpc.HttpJsonPort
+	defer func() { config.DefConfig.Rpc.HttpJsonPort = rpcPort }()
+	p, err := strconv.ParseUint(port, 10, 32)
+	assert.Nil(t, err)
+	config.DefConfig.Rpc.HttpJsonPort = uint(p)
+
+	list, err := GetPeerPool()
+	assert.Nil(t, err)
+	assert.Equal(t, peers.PeerPoolList, list)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/manifest"
+	"github.com/ontio/ontology/core/payload"
+)
+
+//LoadManifestFile read and validate the manifest file
+func LoadManifestFile(file string) (*manifest.Manifest, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest:%s error:%s", file, err)
+	}
+	return manifest.Parse(data)
+}
+
+//ReadManifestFile read and validate the manifest file, return the manifest in compact json
+func ReadManifestFile(file string) ([]byte, error) {
+	m, err := LoadManifestFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+//GetContractManifest return the manifest the contract deployed with
+func GetContractManifest(contract common.Address) (*manifest.Manifest, error) {
+	data, ontErr := sendRpcRequest("getcontractmanifest", []interface{}{contract.ToHexString()})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	if string(data) == "null" {
+		return nil, fmt.Errorf("contract %s has no manifest", contract.ToHexString())
+	}
+	return manifest.Parse(data)
+}
+
+//NewManifestInvokeParams build the invoke params of the method from text arguments typed by the manifest.
+//NeoVM contracts take the method name and an argument array, wasm contracts take the method name followed by arguments
+func NewManifestInvokeParams(vmtype payload.VmType, method *manifest.Method, args []string) ([]interface{}, error) {
+	values, err := method.ParseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if vmtype == payload.NEOVM_TYPE {
+		return []interface{}{method.Name, values}, nil
+	}
+	return append([]interface{}{method.Name}, values...), nil
+}
+
+//SplitManifestArgs split the comma separated arguments, an empty string means no argument
+func SplitManifestArgs(rawArgs string) []string {
+	if strings.TrimSpace(rawArgs) == "" {
+		return nil
+	}
+	return strings.Split(rawArgs, PARAMS_SPLIT)
+}
+
+//ManifestReturnType return the --return type of the method return type, empty if it can not be decoded
+func ManifestReturnType(method *manifest.Method) string {
+	switch strings.ToLower(method.ReturnType) {
+	case manifest.PARAM_TYPE_BOOLEAN:
+		return PARAM_TYPE_BOOLEAN
+	case manifest.PARAM_TYPE_INTEGER:
+		return PARAM_TYPE_INTEGER
+	case manifest.PARAM_TYPE_STRING:
+		return PARAM_TYPE_STRING
+	case manifest.PARAM_TYPE_BYTE_ARRAY:
+		return PARAM_TYPE_BYTE_ARRAY
+	}
+	return ""
+}
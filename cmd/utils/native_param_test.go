@@ -25,9 +25,36 @@ import (
 
 	"github.com/ontio/ontology/cmd/abi"
 	"github.com/ontio/ontology/common"
+	svrneovm "github.com/ontio/ontology/smartcontract/service/neovm"
 	"github.com/ontio/ontology/vm/neovm"
+	"github.com/stretchr/testify/assert"
 )
 
+//decodeNativeInvoke run the invoke code until the native invoke syscall, and return the invoked contract, method
+//and the input the native contract reads
+func decodeNativeInvoke(t *testing.T, code []byte) (common.Address, string, *common.ZeroCopySource) {
+	tail := neovm.NewParamsBuilder(new(bytes.Buffer))
+	tail.Emit(neovm.SYSCALL)
+	tail.EmitPushByteArray([]byte(svrneovm.NATIVE_INVOKE_NAME))
+	assert.True(t, bytes.HasSuffix(code, tail.ToArray()))
+
+	engine := neovm.NewExecutor(code[:len(code)-len(tail.ToArray())], neovm.VmFeatureFlag{})
+	assert.Nil(t, engine.Execute())
+	_, err := engine.EvalStack.PopAsInt64()
+	assert.Nil(t, err)
+	address, err := engine.EvalStack.PopAsBytes()
+	assert.Nil(t, err)
+	contract, err := common.AddressParseFromBytes(address)
+	assert.Nil(t, err)
+	method, err := engine.EvalStack.PopAsBytes()
+	assert.Nil(t, err)
+	args, err := engine.EvalStack.Pop()
+	assert.Nil(t, err)
+	sink := common.NewZeroCopySink(nil)
+	assert.Nil(t, args.BuildParamToNative(sink))
+	return contract, string(method), common.NewZeroCopySource(sink.Bytes())
+}
+
 func TestParseNativeParam(t *testing.T) {
 	paramAbi := []*abi.NativeContractParamAbi{
 		{
@@ -36,6 +36,7 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/constants"
 	"github.com/ontio/ontology/common/serialization"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/types"
@@ -454,7 +455,16 @@ func SendRawTransactionData(txData string) (string, error) {
 }
 
 func PrepareSendRawTransaction(txData string) (*httpcom.PreExecuteResult, error) {
-	data, ontErr := sendRpcRequest("sendrawtransaction", []interface{}{txData, 1})
+	return prepareSendRawTransaction([]interface{}{txData, 1})
+}
+
+//ProfileSendRawTransaction pre-execute the transaction with the gas profile
+func ProfileSendRawTransaction(txData string) (*httpcom.PreExecuteResult, error) {
+	return prepareSendRawTransaction([]interface{}{txData, 1, nil, 1})
+}
+
+func prepareSendRawTransaction(params []interface{}) (*httpcom.PreExecuteResult, error) {
+	data, ontErr := sendRpcRequest("sendrawtransaction", params)
 	if ontErr != nil {
 		return nil, ontErr.Error
 	}
@@ -588,6 +598,24 @@ func GetBlockCount() (uint32, error) {
 	return num, nil
 }
 
+//Return transfer history of address in base58 code, starting at fromHeight
+func GetAddressHistory(address string, fromHeight, limit uint32) (*httpcom.AddressHistoryList, error) {
+	result, ontErr := sendRpcRequest("getaddresshistory", []interface{}{address, fromHeight, limit})
+	if ontErr != nil {
+		switch ontErr.ErrorCode {
+		case ERROR_INVALID_PARAMS:
+			return nil, fmt.Errorf("invalid address:%s or limit:%d", address, limit)
+		}
+		return nil, ontErr.Error
+	}
+	history := &httpcom.AddressHistoryList{}
+	err := json.Unmarshal(result, history)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return history, nil
+}
+
 func GetTxHeight(txHash string) (uint32, error) {
 	data, ontErr := sendRpcRequest("getblockheightbytxhash", []interface{}{txHash})
 	if ontErr != nil {
@@ -615,13 +643,15 @@ func DeployContract(
 	cversion,
 	cauthor,
 	cemail,
-	cdesc string) (string, error) {
+	cdesc string,
+	manifest []byte) (string, error) {
 
 	c, err := hex.DecodeString(code)
 	if err != nil {
 		return "", fmt.Errorf("hex.DecodeString error:%s", err)
 	}
-	mutable, err := NewDeployCodeTransaction(gasPrice, gasLimit, c, vmtype, cname, cversion, cauthor, cemail, cdesc)
+	mutable, err := NewDeployCodeTransactionWithManifest(gasPrice, gasLimit, c, vmtype, cname, cversion, cauthor, cemail,
+		cdesc, manifest)
 	if err != nil {
 		return "", err
 	}
@@ -648,12 +678,13 @@ func PrepareDeployContract(
 	cversion,
 	cauthor,
 	cemail,
-	cdesc string) (*httpcom.PreExecuteResult, error) {
+	cdesc string,
+	manifest []byte) (*httpcom.PreExecuteResult, error) {
 	c, err := hex.DecodeString(code)
 	if err != nil {
 		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
 	}
-	mutable, err := NewDeployCodeTransaction(0, 0, c, vmtype, cname, cversion, cauthor, cemail, cdesc)
+	mutable, err := NewDeployCodeTransactionWithManifest(0, 0, c, vmtype, cname, cversion, cauthor, cemail, cdesc, manifest)
 	if err != nil {
 		return nil, fmt.Errorf("NewDeployCodeTransaction error:%s", err)
 	}
@@ -741,6 +772,28 @@ func PrepareInvokeCodeNeoVMContract(code []byte) (*httpcom.PreExecuteResult, err
 	return PrepareSendRawTransaction(txData)
 }
 
+//ProfileInvokeContract prepare invoke neovm or wasm contract with the gas profile
+func ProfileInvokeContract(vmtype payload.VmType, contractAddress common.Address, params []interface{}) (*httpcom.PreExecuteResult, error) {
+	var mutable *types.MutableTransaction
+	var err error
+	if vmtype == payload.WASMVM_TYPE {
+		mutable, err = cutils.NewWasmVMInvokeTransaction(0, 0, contractAddress, params)
+	} else {
+		mutable, err = httpcom.NewNeovmInvokeTransaction(0, 0, contractAddress, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+
+	txData := hex.EncodeToString(common.SerializeToBytes(tx))
+	return ProfileSendRawTransaction(txData)
+}
+
 //prepare invoke wasm
 func PrepareInvokeWasmVMContract(contractAddress common.Address, params []interface{}) (*httpcom.PreExecuteResult, error) {
 	mutable, err := cutils.NewWasmVMInvokeTransaction(0, 0, contractAddress, params)
@@ -777,8 +830,19 @@ func PrepareInvokeNativeContract(
 //NewDeployCodeTransaction return a smart contract deploy transaction instance
 func NewDeployCodeTransaction(gasPrice, gasLimit uint64, code []byte, vmType payload.VmType,
 	cname, cversion, cauthor, cemail, cdesc string) (*types.MutableTransaction, error) {
+	return NewDeployCodeTransactionWithManifest(gasPrice, gasLimit, code, vmType, cname, cversion, cauthor, cemail, cdesc, nil)
+}
 
-	deployPayload, err := payload.NewDeployCode(code, vmType, cname, cversion, cauthor, cemail, cdesc)
+//NewDeployCodeTransactionWithManifest return a deploy transaction which stores the json manifest alongside the contract
+func NewDeployCodeTransactionWithManifest(gasPrice, gasLimit uint64, code []byte, vmType payload.VmType,
+	cname, cversion, cauthor, cemail, cdesc string, manifestData []byte) (*types.MutableTransaction, error) {
+	if len(manifestData) > 0 {
+		if _, err := manifest.Parse(manifestData); err != nil {
+			return nil, fmt.Errorf("invalid manifest:%s", err)
+		}
+	}
+	deployPayload, err := payload.NewDeployCodeWithManifest(code, vmType, cname, cversion, cauthor, cemail, cdesc,
+		manifestData)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,224 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+	httpcom "github.com/ontio/ontology/http/base/common"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const VERSION_CONTRACT_ONTID = byte(0)
+
+//OntIdAttribute is the attribute of ONT ID
+type OntIdAttribute struct {
+	Key       []byte
+	ValueType []byte
+	Value     []byte
+}
+
+type ontIdRegisterParam struct {
+	OntId  []byte
+	PubKey []byte
+}
+
+type ontIdOperatorParam struct {
+	OntId    []byte
+	Data     []byte
+	Operator []byte
+}
+
+type ontIdAttributesParam struct {
+	OntId      []byte
+	Attributes []OntIdAttribute
+	Operator   []byte
+}
+
+type ontIdIndexParam struct {
+	OntId []byte
+	Data  []byte
+	Index uint32
+}
+
+type ontIdServiceParam struct {
+	OntId     []byte
+	ServiceId []byte
+	Type      []byte
+	Endpoint  []byte
+	Index     uint32
+}
+
+type ontIdRemoveControllerParam struct {
+	OntId []byte
+	Index uint32
+}
+
+type ontIdQueryParam struct {
+	OntId []byte
+}
+
+//NewOntIdInvokeTx return a transaction invoking ONT ID contract method, param is a struct encoded field by field
+func NewOntIdInvokeTx(gasPrice, gasLimit uint64, method string, param interface{}) (*types.MutableTransaction, error) {
+	return httpcom.NewNativeInvokeTransaction(gasPrice, gasLimit, utils.OntIDContractAddress, VERSION_CONTRACT_ONTID,
+		method, []interface{}{param})
+}
+
+//NewRegisterOntIdTx return a transaction registering ONT ID with public key, which should be signed by the key
+func NewRegisterOntIdTx(gasPrice, gasLimit uint64, ontId string, pubKey keypair.PublicKey) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "regIDWithPublicKey", &ontIdRegisterParam{
+		OntId:  []byte(ontId),
+		PubKey: keypair.SerializePublicKey(pubKey),
+	})
+}
+
+//NewAddOntIdKeyTx return a transaction adding public key to ONT ID, which should be signed by operator
+func NewAddOntIdKeyTx(gasPrice, gasLimit uint64, ontId string, pubKey []byte, operator keypair.PublicKey) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "addKey", &ontIdOperatorParam{
+		OntId:    []byte(ontId),
+		Data:     pubKey,
+		Operator: keypair.SerializePublicKey(operator),
+	})
+}
+
+//NewRemoveOntIdKeyTx return a transaction removing public key from ONT ID, which should be signed by operator
+func NewRemoveOntIdKeyTx(gasPrice, gasLimit uint64, ontId string, pubKey []byte, operator keypair.PublicKey) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "removeKey", &ontIdOperatorParam{
+		OntId:    []byte(ontId),
+		Data:     pubKey,
+		Operator: keypair.SerializePublicKey(operator),
+	})
+}
+
+//NewAddOntIdAttributesTx return a transaction adding attributes to ONT ID, which should be signed by operator
+func NewAddOntIdAttributesTx(gasPrice, gasLimit uint64, ontId string, attributes []OntIdAttribute, operator keypair.PublicKey) (*types.MutableTransaction, error) {
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("no attribute to add")
+	}
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "addAttributes", &ontIdAttributesParam{
+		OntId:      []byte(ontId),
+		Attributes: attributes,
+		Operator:   keypair.SerializePublicKey(operator),
+	})
+}
+
+//NewRemoveOntIdAttributeTx return a transaction removing attribute of ONT ID, which should be signed by operator
+func NewRemoveOntIdAttributeTx(gasPrice, gasLimit uint64, ontId, key string, operator keypair.PublicKey) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "removeAttribute", &ontIdOperatorParam{
+		OntId:    []byte(ontId),
+		Data:     []byte(key),
+		Operator: keypair.SerializePublicKey(operator),
+	})
+}
+
+//NewSetOntIdRecoveryTx return a transaction setting recovery group of ONT ID, which should be signed by the key of index
+func NewSetOntIdRecoveryTx(gasPrice, gasLimit uint64, ontId string, members []string, threshold uint, index uint32) (*types.MutableTransaction, error) {
+	group, err := SerializeOntIdGroup(members, threshold)
+	if err != nil {
+		return nil, err
+	}
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "setRecovery", &ontIdIndexParam{
+		OntId: []byte(ontId),
+		Data:  group,
+		Index: index,
+	})
+}
+
+//NewRegOntIdWithControllerTx return a transaction registering ONT ID controlled by another ONT ID,
+//which should be signed by the key of index of controller
+func NewRegOntIdWithControllerTx(gasPrice, gasLimit uint64, ontId, controller string, index uint32) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "regIDWithController", &ontIdIndexParam{
+		OntId: []byte(ontId),
+		Data:  []byte(controller),
+		Index: index,
+	})
+}
+
+//NewRemoveOntIdControllerTx return a transaction removing controller of ONT ID, which should be signed by the key of index
+func NewRemoveOntIdControllerTx(gasPrice, gasLimit uint64, ontId string, index uint32) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "removeController", &ontIdRemoveControllerParam{
+		OntId: []byte(ontId),
+		Index: index,
+	})
+}
+
+//NewAddOntIdServiceTx return a transaction adding service to ONT ID, which should be signed by the key of index
+func NewAddOntIdServiceTx(gasPrice, gasLimit uint64, ontId, serviceId, serviceType, endpoint string, index uint32) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "addService", &ontIdServiceParam{
+		OntId:     []byte(ontId),
+		ServiceId: []byte(serviceId),
+		Type:      []byte(serviceType),
+		Endpoint:  []byte(endpoint),
+		Index:     index,
+	})
+}
+
+//NewRemoveOntIdServiceTx return a transaction removing service of ONT ID, which should be signed by the key of index
+func NewRemoveOntIdServiceTx(gasPrice, gasLimit uint64, ontId, serviceId string, index uint32) (*types.MutableTransaction, error) {
+	return NewOntIdInvokeTx(gasPrice, gasLimit, "removeService", &ontIdIndexParam{
+		OntId: []byte(ontId),
+		Data:  []byte(serviceId),
+		Index: index,
+	})
+}
+
+//SerializeOntIdGroup serialize ONT ID group with members and threshold, in the format of ONT ID contract
+func SerializeOntIdGroup(members []string, threshold uint) ([]byte, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("group has no member")
+	}
+	if threshold == 0 || threshold > uint(len(members)) {
+		return nil, fmt.Errorf("invalid threshold:%d of %d members", threshold, len(members))
+	}
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, uint64(len(members)))
+	for _, member := range members {
+		sink.WriteVarBytes([]byte(member))
+	}
+	utils.EncodeVarUint(sink, uint64(threshold))
+	return sink.Bytes(), nil
+}
+
+//GetOntIdDocument return the DID document of ONT ID in json, return nil if ONT ID not registered
+func GetOntIdDocument(ontId string) ([]byte, error) {
+	preResult, err := PrepareInvokeNativeContract(utils.OntIDContractAddress, VERSION_CONTRACT_ONTID,
+		"getDocumentJson", []interface{}{&ontIdQueryParam{OntId: []byte(ontId)}})
+	if err != nil {
+		return nil, err
+	}
+	if preResult.State == 0 {
+		return nil, fmt.Errorf("prepare invoke getDocumentJson failed")
+	}
+	rawResult, ok := preResult.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid getDocumentJson result:%v", preResult.Result)
+	}
+	data, err := hex.DecodeString(rawResult)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeOntIdGroup(t *testing.T) {
+	id1, _ := account.GenerateID()
+	id2, _ := account.GenerateID()
+	data, err := SerializeOntIdGroup([]string{id1, id2}, 1)
+	assert.Nil(t, err)
+
+	source := common.NewZeroCopySource(data)
+	num, err := nutils.DecodeVarUint(source)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), num)
+	for _, id := range []string{id1, id2} {
+		member, err := nutils.DecodeVarBytes(source)
+		assert.Nil(t, err)
+		assert.Equal(t, id, string(member))
+	}
+	threshold, err := nutils.DecodeVarUint(source)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), threshold)
+
+	_, err = SerializeOntIdGroup(nil, 1)
+	assert.Error(t, err)
+	_, err = SerializeOntIdGroup([]string{id1, id2}, 3)
+	assert.Error(t, err)
+	_, err = SerializeOntIdGroup([]string{id1, id2}, 0)
+	assert.Error(t, err)
+}
+
+//decodeOntIdInvoke decode the input of ONT ID method invoked by tx
+func decodeOntIdInvoke(t *testing.T, tx *types.MutableTransaction, method string) *common.ZeroCopySource {
+	contract, invoked, source := decodeNativeInvoke(t, tx.Payload.(*payload.InvokeCode).Code)
+	assert.Equal(t, nutils.OntIDContractAddress, contract)
+	assert.Equal(t, method, invoked)
+	return source
+}
+
+func TestNewOntIdInvokeTx(t *testing.T) {
+	acc := account.NewAccount("")
+	ontId, _ := account.GenerateID()
+	controller, _ := account.GenerateID()
+	tx, err := NewAddOntIdServiceTx(500, 20000, ontId, "svc1", "Hub", "https://example.com", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), tx.GasPrice)
+	service := &ontid.ServiceParam{}
+	assert.Nil(t, service.Deserialization(decodeOntIdInvoke(t, tx, "addService")))
+	assert.Equal(t, &ontid.ServiceParam{
+		OntId:          []byte(ontId),
+		ServiceId:      []byte("svc1"),
+		Type:           []byte("Hub"),
+		ServiceEndpint: []byte("https://example.com"),
+		Index:          1,
+	}, service)
+
+	tx, err = NewRemoveOntIdServiceTx(0, 20000, ontId, "svc1", 2)
+	assert.Nil(t, err)
+	removeService := &ontid.ServiceRemoveParam{}
+	assert.Nil(t, removeService.Deserialization(decodeOntIdInvoke(t, tx, "removeService")))
+	assert.Equal(t, &ontid.ServiceRemoveParam{OntId: []byte(ontId), ServiceId: []byte("svc1"), Index: 2}, removeService)
+
+	//regIDWithController reads the ID, the controller and the key index of controller
+	tx, err = NewRegOntIdWithControllerTx(0, 20000, ontId, controller, 3)
+	assert.Nil(t, err)
+	source := decodeOntIdInvoke(t, tx, "regIDWithController")
+	id, err := nutils.DecodeVarBytes(source)
+	assert.Nil(t, err)
+	assert.Equal(t, ontId, string(id))
+	ctrl, err := nutils.DecodeVarBytes(source)
+	assert.Nil(t, err)
+	assert.Equal(t, controller, string(ctrl))
+	index, err := nutils.DecodeVarUint(source)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), index)
+	assert.Equal(t, uint64(0), source.Len())
+
+	//setRecovery reads the ID, the serialized group and the key index
+	tx, err = NewSetOntIdRecoveryTx(0, 20000, ontId, []string{controller}, 1, 1)
+	assert.Nil(t, err)
+	source = decodeOntIdInvoke(t, tx, "setRecovery")
+	id, err = nutils.DecodeVarBytes(source)
+	assert.Nil(t, err)
+	assert.Equal(t, ontId, string(id))
+	group, err := nutils.DecodeVarBytes(source)
+	assert.Nil(t, err)
+	expected, err := SerializeOntIdGroup([]string{controller}, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, group)
+	index, err = nutils.DecodeVarUint(source)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), index)
+	assert.Equal(t, uint64(0), source.Len())
+
+	_, err = NewAddOntIdAttributesTx(0, 20000, ontId, nil, acc.PublicKey)
+	assert.Error(t, err)
+	tx, err = NewAddOntIdAttributesTx(0, 20000, ontId, []OntIdAttribute{
+		{Key: []byte("name"), ValueType: []byte("string"), Value: []byte("alice")},
+	}, acc.PublicKey)
+	assert.Nil(t, err)
+	//addAttributes reads the ID, the count of attributes, key, type and value of each attribute, and the operator
+	source = decodeOntIdInvoke(t, tx, "addAttributes")
+	id, err = nutils.DecodeVarBytes(source)
+	assert.Nil(t, err)
+	assert.Equal(t, ontId, string(id))
+	num, err := nutils.DecodeVarUint(source)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), num)
+	for _, field := range []string{"name", "string", "alice"} {
+		data, _, irregular, eof := source.NextVarBytes()
+		assert.False(t, irregular || eof)
+		assert.Equal(t, field, string(data))
+	}
+	operator, err := nutils.DecodeVarBytes(source)
+	assert.Nil(t, err)
+	assert.Equal(t, keypair.SerializePublicKey(acc.PublicKey), operator)
+	assert.Equal(t, uint64(0), source.Len())
+
+	_, err = NewSetOntIdRecoveryTx(0, 20000, ontId, []string{ontId}, 2, 1)
+	assert.Error(t, err)
+}
@@ -51,10 +51,12 @@ const (
 	DBFT_MIN_NODE_NUM        = 4 //min node number of dbft consensus
 	SOLO_MIN_NODE_NUM        = 1 //min node number of solo consensus
 	VBFT_MIN_NODE_NUM        = 4 //min node number of vbft consensus
+	SBFT_MIN_NODE_NUM        = 4 //min node number of sbft consensus
 
 	CONSENSUS_TYPE_DBFT = "dbft"
 	CONSENSUS_TYPE_SOLO = "solo"
 	CONSENSUS_TYPE_VBFT = "vbft"
+	CONSENSUS_TYPE_SBFT = "sbft"
 
 	DEFAULT_LOG_LEVEL                       = log.InfoLog
 	DEFAULT_NODE_PORT                       = 20338
@@ -63,6 +65,8 @@ const (
 	DEFAULT_GRAPHQL_PORT                    = 20333
 	DEFAULT_REST_PORT                       = 20334
 	DEFAULT_WS_PORT                         = 20335
+	DEFAULT_METRICS_PORT                    = 20339
+	DEFAULT_METRICS_PATH                    = "/metrics"
 	DEFAULT_HTTP_MAX_CONN                   = 1024
 	DEFAULT_MAX_CONN_IN_BOUND               = 1024
 	DEFAULT_MAX_CONN_OUT_BOUND              = 1024
@@ -70,6 +74,9 @@ const (
 	DEFAULT_HTTP_INFO_PORT                  = 0
 	DEFAULT_MAX_TX_IN_BLOCK                 = 60000
 	DEFAULT_MAX_SYNC_HEADER                 = 500
+	DEFAULT_SYNC_MAX_FLIGHT_HEADERS         = 8
+	DEFAULT_SYNC_MAX_FLIGHT_BLOCKS          = 200
+	DEFAULT_SYNC_MAX_BLOCK_CACHE            = 1000
 	DEFAULT_ENABLE_EVENT_LOG                = true
 	DEFAULT_CLI_RPC_PORT                    = uint(20000)
 	DEFUALT_CLI_RPC_ADDRESS                 = "127.0.0.1"
@@ -80,6 +87,7 @@ const (
 
 	DEFAULT_DATA_DIR      = "./Chain/"
 	DEFAULT_RESERVED_FILE = "./peers.rsv"
+	DEFAULT_STORE_BACKEND = "leveldb"
 )
 
 const (
@@ -198,6 +206,28 @@ func GetCrossChainHeight() uint32 {
 	}
 }
 
+func GetStorageFindHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_STORAGE_FIND_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_STORAGE_FIND_POLARIS
+	default:
+		return 0
+	}
+}
+
+func GetContractManifestHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_CONTRACT_MANIFEST_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_CONTRACT_MANIFEST_POLARIS
+	default:
+		return 0
+	}
+}
+
 func GetOntHolderUnboundDeadline() uint32 {
 	switch DefConfig.P2PNode.NetworkId {
 	case NETWORK_ID_MAIN_NET:
@@ -321,6 +351,7 @@ var PolarisConfig = &GenesisConfig{
 	},
 	DBFT: &DBFTConfig{},
 	SOLO: &SOLOConfig{},
+	SBFT: &SBFTConfig{},
 }
 
 var MainNetConfig = &GenesisConfig{
@@ -384,6 +415,7 @@ var MainNetConfig = &GenesisConfig{
 	},
 	DBFT: &DBFTConfig{},
 	SOLO: &SOLOConfig{},
+	SBFT: &SBFTConfig{},
 }
 
 var DefConfig = NewOntologyConfig()
@@ -394,6 +426,7 @@ type GenesisConfig struct {
 	VBFT          *VBFTConfig
 	DBFT          *DBFTConfig
 	SOLO          *SOLOConfig
+	SBFT          *SBFTConfig
 }
 
 func NewGenesisConfig() *GenesisConfig {
@@ -403,6 +436,7 @@ func NewGenesisConfig() *GenesisConfig {
 		VBFT:          &VBFTConfig{},
 		DBFT:          &DBFTConfig{},
 		SOLO:          &SOLOConfig{},
+		SBFT:          &SBFTConfig{},
 	}
 }
 
@@ -596,14 +630,24 @@ type SOLOConfig struct {
 	Bookkeepers  []string
 }
 
+//SBFTConfig is the config of simple bft consensus with static validators
+type SBFTConfig struct {
+	GenBlockTime uint //seconds between blocks
+	RoundTimeout uint //base timeout of consensus round in seconds, increased linearly with the round
+	Bookkeepers  []string
+}
+
 type CommonConfig struct {
 	LogLevel         uint
 	NodeType         string
 	EnableEventLog   bool
+	StateArchive     bool //Whether keep history states to support query states at a block height
+	AddressHistory   bool //Whether index the transfers by address to support address history query
 	SystemFee        map[string]int64
 	GasLimit         uint64
 	GasPrice         uint64
 	DataDir          string
+	StoreBackend     string //Name of the persist store backend of ledger
 	WasmVerifyMethod VerifyMethod
 }
 
@@ -613,8 +657,9 @@ type ConsensusConfig struct {
 }
 
 type P2PRsvConfig struct {
-	ReservedPeers []string `json:"reserved"`
-	MaskPeers     []string `json:"mask"`
+	ReservedPeers    []string `json:"reserved"`
+	MaskPeers        []string `json:"mask"`
+	ReservedPeerKeys []string `json:"reserved_keys"` //node public keys in hex, require EnableNoise
 }
 
 type P2PNodeConfig struct {
@@ -628,17 +673,27 @@ type P2PNodeConfig struct {
 	CertPath                  string
 	KeyPath                   string
 	CAPath                    string
+	EnableNoise               bool   //encrypt connection and authenticate peer by node key
+	NodeKeyPath               string //file of node key, random node key if empty. Defaults to a file under data dir if noise enabled
 	HttpInfoPort              uint16
 	MaxHdrSyncReqs            uint
 	MaxConnInBound            uint
 	MaxConnOutBound           uint
 	MaxConnInBoundForSingleIP uint
+	SyncMaxFlightHeaders      uint //max header segments downloading in parallel
+	SyncMaxFlightBlocks       uint //upper bound of blocks downloading, adapted to peer throughput
+	SyncMaxBlockCache         uint //max non-empty blocks downloaded and waiting for execution
+	EnableCompression         bool //compress msg with snappy if peer supports
+	MaxPeerRecvRate           uint //max receive bandwidth of a peer in kB/s, 0 means unlimited
+	MaxPeerSendRate           uint //max send bandwidth of a peer in kB/s, 0 means unlimited
 }
 
 type RpcConfig struct {
 	EnableHttpJsonRpc bool
 	HttpJsonPort      uint
 	HttpLocalPort     uint
+	// respond with json-rpc 2.0 error objects instead of the legacy error/desc envelope
+	StandardResponse bool
 }
 
 type RestfulConfig struct {
@@ -662,15 +717,28 @@ type WebSocketConfig struct {
 	HttpKeyPath  string
 }
 
+type MetricsConfig struct {
+	EnableMetrics bool
+	MetricsPort   uint
+	MetricsPath   string
+}
+
+type TxPoolConfig struct {
+	MaxTxInPool   uint //max transactions kept in the pool, cheapest evicted when full, 0 for the default of tx pool
+	MaxTxPerPayer uint //max transactions of a single payer kept in the pool, 0 for the default of tx pool
+}
+
 type OntologyConfig struct {
 	Genesis   *GenesisConfig
 	Common    *CommonConfig
 	Consensus *ConsensusConfig
+	TxPool    *TxPoolConfig
 	P2PNode   *P2PNodeConfig
 	Rpc       *RpcConfig
 	Restful   *RestfulConfig
 	GraphQL   *GraphQLConfig
 	Ws        *WebSocketConfig
+	Metrics   *MetricsConfig
 }
 
 func NewOntologyConfig() *OntologyConfig {
@@ -682,12 +750,14 @@ func NewOntologyConfig() *OntologyConfig {
 			SystemFee:        make(map[string]int64),
 			GasLimit:         DEFAULT_GAS_LIMIT,
 			DataDir:          DEFAULT_DATA_DIR,
+			StoreBackend:     DEFAULT_STORE_BACKEND,
 			WasmVerifyMethod: InterpVerifyMethod,
 		},
 		Consensus: &ConsensusConfig{
 			EnableConsensus: true,
 			MaxTxInBlock:    DEFAULT_MAX_TX_IN_BLOCK,
 		},
+		TxPool: &TxPoolConfig{},
 		P2PNode: &P2PNodeConfig{
 			ReservedCfg:               &P2PRsvConfig{},
 			ReservedPeersOnly:         false,
@@ -704,6 +774,10 @@ func NewOntologyConfig() *OntologyConfig {
 			MaxConnInBound:            DEFAULT_MAX_CONN_IN_BOUND,
 			MaxConnOutBound:           DEFAULT_MAX_CONN_OUT_BOUND,
 			MaxConnInBoundForSingleIP: DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
+			SyncMaxFlightHeaders:      DEFAULT_SYNC_MAX_FLIGHT_HEADERS,
+			SyncMaxFlightBlocks:       DEFAULT_SYNC_MAX_FLIGHT_BLOCKS,
+			SyncMaxBlockCache:         DEFAULT_SYNC_MAX_BLOCK_CACHE,
+			EnableCompression:         true,
 		},
 		Rpc: &RpcConfig{
 			EnableHttpJsonRpc: true,
@@ -722,6 +796,11 @@ func NewOntologyConfig() *OntologyConfig {
 			EnableHttpWs: true,
 			HttpWsPort:   DEFAULT_WS_PORT,
 		},
+		Metrics: &MetricsConfig{
+			EnableMetrics: false,
+			MetricsPort:   DEFAULT_METRICS_PORT,
+			MetricsPath:   DEFAULT_METRICS_PATH,
+		},
 	}
 }
 
@@ -736,6 +815,8 @@ func (this *OntologyConfig) GetBookkeepers() ([]keypair.PublicKey, error) {
 		bookKeepers = this.Genesis.DBFT.Bookkeepers
 	case CONSENSUS_TYPE_SOLO:
 		bookKeepers = this.Genesis.SOLO.Bookkeepers
+	case CONSENSUS_TYPE_SBFT:
+		bookKeepers = this.Genesis.SBFT.Bookkeepers
 	default:
 		return nil, fmt.Errorf("Does not support %s consensus", this.Genesis.ConsensusType)
 	}
@@ -786,6 +867,8 @@ func (this *OntologyConfig) getDefNetworkIDFromGenesisConfig(genCfg *GenesisConf
 		configData, err = json.Marshal(genCfg.VBFT)
 	case CONSENSUS_TYPE_DBFT:
 		configData, err = json.Marshal(genCfg.DBFT)
+	case CONSENSUS_TYPE_SBFT:
+		configData, err = json.Marshal(genCfg.SBFT)
 	case CONSENSUS_TYPE_SOLO:
 		return NETWORK_ID_SOLO_NET, nil
 	default:
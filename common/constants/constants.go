@@ -19,6 +19,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -95,3 +96,11 @@ const BLOCKHEIGHT_CC_POLARIS = 13130000
 //new node cost height
 const BLOCKHEIGHT_NEW_PEER_COST_MAINNET = 9400000
 const BLOCKHEIGHT_NEW_PEER_COST_POLARIS = 13400000
+
+//storage find api height, not scheduled on mainnet and polaris yet
+const BLOCKHEIGHT_STORAGE_FIND_MAINNET = math.MaxUint32
+const BLOCKHEIGHT_STORAGE_FIND_POLARIS = math.MaxUint32
+
+//contract manifest deploy height, not scheduled on mainnet and polaris yet
+const BLOCKHEIGHT_CONTRACT_MANIFEST_MAINNET = math.MaxUint32
+const BLOCKHEIGHT_CONTRACT_MANIFEST_POLARIS = math.MaxUint32
@@ -19,10 +19,13 @@
 package consensus
 
 import (
+	"fmt"
+
 	"github.com/ontio/ontology-eventbus/actor"
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/consensus/dbft"
+	"github.com/ontio/ontology/consensus/sbft"
+	"github.com/ontio/ontology/consensus/signer"
 	"github.com/ontio/ontology/consensus/solo"
 	"github.com/ontio/ontology/consensus/vbft"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
@@ -38,21 +41,32 @@ const (
 	CONSENSUS_DBFT = "dbft"
 	CONSENSUS_SOLO = "solo"
 	CONSENSUS_VBFT = "vbft"
+	CONSENSUS_SBFT = "sbft"
 )
 
-func NewConsensusService(consensusType string, account *account.Account, txpool *actor.PID, ledger *actor.PID, p2p p2p.P2P) (ConsensusService, error) {
+//NewConsensusService return consensus service signing by signer. Only vbft and sbft support remote signer,
+//dbft and solo need local signer.
+func NewConsensusService(consensusType string, sig signer.Signer, txpool *actor.PID, ledger *actor.PID, p2p p2p.P2P) (ConsensusService, error) {
 	if consensusType == "" {
 		consensusType = CONSENSUS_DBFT
 	}
 	var consensus ConsensusService
 	var err error
 	switch consensusType {
-	case CONSENSUS_DBFT:
-		consensus, err = dbft.NewDbftService(account, txpool, p2p)
-	case CONSENSUS_SOLO:
-		consensus, err = solo.NewSoloService(account, txpool)
+	case CONSENSUS_DBFT, CONSENSUS_SOLO:
+		local, ok := sig.(*signer.LocalSigner)
+		if !ok {
+			return nil, fmt.Errorf("consensus %s does not support remote signer", consensusType)
+		}
+		if consensusType == CONSENSUS_DBFT {
+			consensus, err = dbft.NewDbftService(local.Account(), txpool, p2p)
+		} else {
+			consensus, err = solo.NewSoloService(local.Account(), txpool)
+		}
 	case CONSENSUS_VBFT:
-		consensus, err = vbft.NewVbftServer(account, txpool, p2p)
+		consensus, err = vbft.NewVbftServer(sig, txpool, p2p)
+	case CONSENSUS_SBFT:
+		consensus, err = sbft.NewSbftService(sig, txpool, p2p)
 	}
 	log.Infof("ConsensusType:%s", consensusType)
 	return consensus, err
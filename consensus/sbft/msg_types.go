@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+)
+
+type MsgType byte
+
+const (
+	ProposalMsg  MsgType = 1
+	PrevoteMsg   MsgType = 2
+	PrecommitMsg MsgType = 3
+)
+
+func (this MsgType) String() string {
+	switch this {
+	case ProposalMsg:
+		return "proposal"
+	case PrevoteMsg:
+		return "prevote"
+	case PrecommitMsg:
+		return "precommit"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(this))
+	}
+}
+
+//ConsensusMsg is the message exchanged between validators, carried by the data of consensus payload
+type ConsensusMsg interface {
+	Type() MsgType
+	GetRound() uint32
+	Serialization(sink *common.ZeroCopySink)
+	Deserialization(source *common.ZeroCopySource) error
+}
+
+//Proposal is the block proposed by the proposer of the round. It is authenticated by the signature of consensus
+//payload, the bare block hash is only signed by precommits so that a proposal never counts as a commit vote
+type Proposal struct {
+	Round      uint32
+	ValidRound int32 //round the block got a polka in, -1 for new block
+	Block      *types.Block
+}
+
+func (this *Proposal) Type() MsgType {
+	return ProposalMsg
+}
+
+func (this *Proposal) GetRound() uint32 {
+	return this.Round
+}
+
+func (this *Proposal) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Round)
+	sink.WriteInt32(this.ValidRound)
+	this.Block.Serialization(sink)
+}
+
+func (this *Proposal) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Round, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.ValidRound, eof = source.NextInt32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Block = new(types.Block)
+	return this.Block.Deserialization(source)
+}
+
+//Vote is the prevote or precommit of a validator, empty block hash means voting nil
+type Vote struct {
+	VoteType  MsgType
+	Round     uint32
+	BlockHash common.Uint256
+	Signature []byte //signature of block hash, only required by precommit of block
+}
+
+func (this *Vote) Type() MsgType {
+	return this.VoteType
+}
+
+func (this *Vote) GetRound() uint32 {
+	return this.Round
+}
+
+func (this *Vote) IsNil() bool {
+	return this.BlockHash == common.UINT256_EMPTY
+}
+
+func (this *Vote) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Round)
+	sink.WriteHash(this.BlockHash)
+	sink.WriteVarBytes(this.Signature)
+}
+
+func (this *Vote) Deserialization(source *common.ZeroCopySource) error {
+	var eof, irregular bool
+	this.Round, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.BlockHash, eof = source.NextHash()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Signature, _, irregular, eof = source.NextVarBytes()
+	if irregular {
+		return common.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//SerializeMsg serialize consensus message with its type
+func SerializeMsg(msg ConsensusMsg) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteByte(byte(msg.Type()))
+	msg.Serialization(sink)
+	return sink.Bytes()
+}
+
+//DeserializeMsg deserialize consensus message from payload data
+func DeserializeMsg(data []byte) (ConsensusMsg, error) {
+	source := common.NewZeroCopySource(data)
+	t, eof := source.NextByte()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var msg ConsensusMsg
+	switch MsgType(t) {
+	case ProposalMsg:
+		msg = &Proposal{}
+	case PrevoteMsg, PrecommitMsg:
+		msg = &Vote{VoteType: MsgType(t)}
+	default:
+		return nil, fmt.Errorf("unknown consensus message type: %d", t)
+	}
+	if err := msg.Deserialization(source); err != nil {
+		return nil, err
+	}
+	if source.Len() != 0 {
+		return nil, fmt.Errorf("unexpected trailing data of %s", msg.Type())
+	}
+	return msg, nil
+}
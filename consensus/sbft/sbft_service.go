@@ -16,15 +16,690 @@
  * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
  */
 
+//Package sbft implements a simple deterministic bft consensus for consortium chains.
+//Validators are static from genesis config, the proposer of each round is chosen by round robin,
+//and a block is committed after two rounds of votes (prevote and precommit) like tendermint.
 package sbft
 
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	actorTypes "github.com/ontio/ontology/consensus/actor"
+	"github.com/ontio/ontology/consensus/signer"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/store"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/events"
+	"github.com/ontio/ontology/events/message"
+	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
+	p2pmsg "github.com/ontio/ontology/p2pserver/message/types"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	txpool "github.com/ontio/ontology/txnpool/common"
+	"github.com/ontio/ontology/validator/increment"
+)
+
+const (
+	ContextVersion       uint32 = 0
+	MAX_FUTURE_MSGS             = 1024 //max buffered messages of next height
+	MAX_ROUND_AHEAD             = 64   //votes of rounds too far ahead are dropped
+	MAX_BLOCK_TIME_DRIFT        = 10 * time.Minute
+)
+
+//LedgerStore is the ledger used by sbft, implemented by ledger.Ledger
+type LedgerStore interface {
+	GetCurrentBlockHeight() uint32
+	GetCurrentBlockHash() common.Uint256
+	GetHeaderByHash(blockHash common.Uint256) (*types.Header, error)
+	GetBlockRootWithNewTxRoots(startHeight uint32, txRoots []common.Uint256) common.Uint256
+	IsContainBlock(blockHash common.Uint256) (bool, error)
+	ExecuteBlock(b *types.Block) (store.ExecuteResult, error)
+	SubmitBlock(b *types.Block, crossChainMsg *types.CrossChainMsg, exec store.ExecuteResult) error
+}
+
+//TxPool is the tx pool used by sbft, implemented by actor.TxPoolActor
+type TxPool interface {
+	GetTxnPool(byCount bool, height uint32) []*txpool.TXEntry
+	VerifyBlock(txs []*types.Transaction, height uint32) error
+}
+
+//Network broadcasts consensus messages to other validators
+type Network interface {
+	Broadcast(msg p2pmsg.Message)
+}
+
+//timeoutEvent fires when the step of round is timeout
+type timeoutEvent struct {
+	height uint32
+	round  uint32
+	step   Step
+}
+
 type SbftService struct {
+	signer         signer.Signer
+	ledger         LedgerStore
+	poolActor      TxPool
+	net            Network
+	incrValidator  *increment.IncrementValidator
+	validators     []keypair.PublicKey
+	index          int //index of local validator, -1 if not a validator
+	quorum         int
+	nextBookkeeper common.Address
+	blockInterval  time.Duration
+	roundTimeout   time.Duration
+
+	state      *roundState
+	validity   map[common.Uint256]error //verify result of proposed blocks in current height
+	future     []*p2pmsg.ConsensusPayload
+	lastCommit time.Time
+	started    bool
+
+	pid *actor.PID
+	sub *events.ActorSubscriber
+}
+
+func NewSbftService(sig signer.Signer, txpool *actor.PID, p2p p2p.P2P) (*SbftService, error) {
+	validators, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return nil, fmt.Errorf("get bookkeepers error: %s", err)
+	}
+	cfg := config.DefConfig.Genesis.SBFT
+	interval := time.Duration(cfg.GenBlockTime) * time.Second
+	if cfg.GenBlockTime == 0 {
+		interval = config.DEFAULT_GEN_BLOCK_TIME * time.Second
+	}
+	timeout := time.Duration(cfg.RoundTimeout) * time.Second
+	if cfg.RoundTimeout == 0 {
+		timeout = interval
+	}
+	return newSbftService(sig, validators, ledger.DefLedger, &actorTypes.TxPoolActor{Pool: txpool}, p2p,
+		interval, timeout, "consensus_sbft")
+}
+
+//newSbftService return sbft service, the actor is spawned with name if it is not empty
+func newSbftService(sig signer.Signer, validators []keypair.PublicKey, ledger LedgerStore, pool TxPool,
+	net Network, blockInterval, roundTimeout time.Duration, name string) (*SbftService, error) {
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("empty validators")
+	}
+	service := &SbftService{
+		signer:        sig,
+		ledger:        ledger,
+		poolActor:     pool,
+		net:           net,
+		incrValidator: increment.NewIncrementValidator(20),
+		validators:    validators,
+		index:         -1,
+		quorum:        len(validators) - (len(validators)-1)/3,
+		blockInterval: blockInterval,
+		roundTimeout:  roundTimeout,
+	}
+	var err error
+	service.nextBookkeeper, err = types.AddressFromBookkeepers(validators)
+	if err != nil {
+		return nil, fmt.Errorf("GetBookkeeperAddress error:%s", err)
+	}
+	pubKey := keypair.SerializePublicKey(sig.PubKey())
+	for i, v := range validators {
+		if bytes.Equal(keypair.SerializePublicKey(v), pubKey) {
+			service.index = i
+		}
+	}
+	if service.index < 0 {
+		log.Warn("sbft: local peer is not a validator")
+	}
+
+	props := actor.FromProducer(func() actor.Actor {
+		return service
+	})
+	if name != "" {
+		service.pid, err = actor.SpawnNamed(props, name)
+	} else {
+		service.pid = actor.Spawn(props)
+	}
+	service.sub = events.NewActorSubscriber(service.pid)
+	return service, err
+}
+
+func (self *SbftService) Receive(context actor.Context) {
+	if _, ok := context.Message().(*actorTypes.StartConsensus); !self.started && !ok {
+		return
+	}
+
+	switch msg := context.Message().(type) {
+	case *actor.Restarting:
+		log.Warn("sbft actor restarting")
+	case *actor.Stopping:
+		log.Warn("sbft actor stopping")
+	case *actor.Stopped:
+		log.Warn("sbft actor stopped")
+	case *actor.Started:
+		log.Info("sbft actor started")
+	case *actor.Restart:
+		log.Warn("sbft actor restart")
+	case *actorTypes.StartConsensus:
+		self.start()
+	case *actorTypes.StopConsensus:
+		self.halt()
+	case *message.SaveBlockCompleteMsg:
+		self.blockSaved(msg.Block)
+	case *p2pmsg.ConsensusPayload:
+		self.handlePayload(msg)
+	case *timeoutEvent:
+		self.handleTimeout(msg)
+	default:
+		log.Info("sbft actor: Unknown msg ", msg, "type", reflect.TypeOf(msg))
+	}
+}
+
+func (self *SbftService) GetPID() *actor.PID {
+	return self.pid
+}
+
+func (self *SbftService) Start() error {
+	self.pid.Tell(&actorTypes.StartConsensus{})
+	return nil
+}
+
+func (self *SbftService) Halt() error {
+	self.pid.Tell(&actorTypes.StopConsensus{})
+	return nil
+}
+
+func (self *SbftService) start() {
+	if self.started {
+		return
+	}
+	self.started = true
+	self.sub.Subscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	self.newHeight()
+}
+
+func (self *SbftService) halt() {
+	if !self.started {
+		return
+	}
+	self.started = false
+	self.sub.Unsubscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	self.incrValidator.Clean()
+	self.state = nil
+	self.future = nil
+}
+
+func (self *SbftService) isProposer(round uint32) bool {
+	return self.index >= 0 && self.proposer(round) == self.index
+}
+
+//proposer return the validator index of proposer of round in current height
+func (self *SbftService) proposer(round uint32) int {
+	return int((uint64(self.state.height) + uint64(round)) % uint64(len(self.validators)))
+}
+
+//newHeight start consensus of the block next to ledger
+func (self *SbftService) newHeight() {
+	prevHash := self.ledger.GetCurrentBlockHash()
+	header, err := self.ledger.GetHeaderByHash(prevHash)
+	if err != nil || header == nil {
+		log.Errorf("sbft: get header %x error: %v", prevHash, err)
+		return
+	}
+	self.state = newRoundState(header.Height+1, prevHash, header.Timestamp)
+	self.validity = make(map[common.Uint256]error)
+	log.Infof("sbft: new height %d", self.state.height)
+
+	delay := self.blockInterval - time.Since(self.lastCommit)
+	if delay < 0 {
+		delay = 0
+	}
+	self.schedule(delay, 0, StepNewHeight)
+
+	future := self.future
+	self.future = nil
+	for _, payload := range future {
+		if payload.Height >= self.state.height {
+			self.handlePayload(payload)
+		}
+	}
+}
+
+func (self *SbftService) schedule(delay time.Duration, round uint32, step Step) {
+	evt := &timeoutEvent{height: self.state.height, round: round, step: step}
+	pid := self.pid
+	time.AfterFunc(delay, func() {
+		pid.Tell(evt)
+	})
 }
 
-func (ss *SbftService) Halt() error {
+func (self *SbftService) handleTimeout(evt *timeoutEvent) {
+	st := self.state
+	if st == nil || evt.height != st.height || st.step == StepCommit {
+		return
+	}
+	switch evt.step {
+	case StepNewHeight:
+		if st.step == StepNewHeight {
+			self.startRound(0)
+		}
+	case StepPropose:
+		if st.round == evt.round && st.step == StepPropose {
+			log.Infof("sbft: propose timeout, height %d round %d", st.height, st.round)
+			self.prevote(common.UINT256_EMPTY)
+			self.process()
+		}
+	case StepPrevote:
+		if st.round == evt.round && st.step == StepPrevote {
+			self.precommit(common.UINT256_EMPTY)
+			self.process()
+		}
+	case StepPrecommit:
+		if st.round == evt.round {
+			log.Infof("sbft: precommit timeout, height %d round %d", st.height, st.round)
+			self.startRound(st.round + 1)
+		}
+	}
+}
+
+//startRound enter round of current height, and propose if local peer is the proposer
+func (self *SbftService) startRound(round uint32) {
+	st := self.state
+	st.enterRound(round)
+	log.Debugf("sbft: height %d enter round %d", st.height, round)
+	self.schedule(self.roundTimeout*time.Duration(round+1), round, StepPropose)
+	if self.isProposer(round) {
+		if err := self.propose(); err != nil {
+			log.Errorf("sbft: propose at height %d round %d error: %s", st.height, round, err)
+		}
+	}
+	self.process()
+}
+
+func (self *SbftService) propose() error {
+	st := self.state
+	block := st.validBlock
+	if block == nil {
+		var err error
+		if block, err = self.makeBlock(); err != nil {
+			return err
+		}
+	}
+	hash := block.Hash()
+	proposal := &Proposal{
+		Round:      st.round,
+		ValidRound: st.validRound,
+		Block:      block,
+	}
+	log.Infof("sbft: propose block %x at height %d round %d, txs %d", hash, st.height, st.round,
+		len(block.Transactions))
+	self.broadcast(proposal)
+	self.onProposal(self.index, proposal)
 	return nil
 }
 
-func (ds *SbftService) Start() error {
+func (self *SbftService) validHeight() uint32 {
+	height := self.state.height - 1
+	start, end := self.incrValidator.BlockRange()
+	if height+1 == end {
+		return start
+	}
+	self.incrValidator.Clean()
+	log.Infof("sbft: increment validator block height %v != ledger block height %v", int(end)-1, height)
+	return height
+}
+
+func (self *SbftService) makeBlock() (*types.Block, error) {
+	st := self.state
+	validHeight := self.validHeight()
+	txs := self.poolActor.GetTxnPool(true, validHeight)
+	transactions := make([]*types.Transaction, 0, len(txs))
+	for _, txEntry := range txs {
+		if err := self.incrValidator.Verify(txEntry.Tx, validHeight); err == nil {
+			transactions = append(transactions, txEntry.Tx)
+		}
+	}
+	txHash := make([]common.Uint256, 0, len(transactions))
+	for _, t := range transactions {
+		txHash = append(txHash, t.Hash())
+	}
+	txRoot := common.ComputeMerkleRoot(txHash)
+
+	timestamp := uint32(time.Now().Unix())
+	if timestamp <= st.prevTime {
+		timestamp = st.prevTime + 1
+	}
+	header := &types.Header{
+		Version:          ContextVersion,
+		PrevBlockHash:    st.prevHash,
+		TransactionsRoot: txRoot,
+		BlockRoot:        self.ledger.GetBlockRootWithNewTxRoots(st.height, []common.Uint256{txRoot}),
+		Timestamp:        timestamp,
+		Height:           st.height,
+		ConsensusData:    common.GetNonce(),
+		NextBookkeeper:   self.nextBookkeeper,
+	}
+	return &types.Block{Header: header, Transactions: transactions}, nil
+}
+
+//verifyBlock check the proposed block, the result is cached in current height
+func (self *SbftService) verifyBlock(block *types.Block) error {
+	hash := block.Hash()
+	if err, present := self.validity[hash]; present {
+		return err
+	}
+	err := self.checkBlock(block)
+	if err != nil {
+		log.Warnf("sbft: invalid block %x at height %d: %s", hash, self.state.height, err)
+	}
+	self.validity[hash] = err
+	return err
+}
+
+func (self *SbftService) checkBlock(block *types.Block) error {
+	st := self.state
+	header := block.Header
+	if header.Version != ContextVersion || header.Height != st.height || header.PrevBlockHash != st.prevHash {
+		return fmt.Errorf("unmatched version, height or prev hash")
+	}
+	if header.Timestamp <= st.prevTime ||
+		header.Timestamp > uint32(time.Now().Add(MAX_BLOCK_TIME_DRIFT).Unix()) {
+		return fmt.Errorf("invalid timestamp %d", header.Timestamp)
+	}
+	if header.NextBookkeeper != self.nextBookkeeper {
+		return fmt.Errorf("unmatched next bookkeeper")
+	}
+	if len(header.Bookkeepers) != 0 || len(header.SigData) != 0 {
+		return fmt.Errorf("proposed block should not be signed")
+	}
+	txHash := make([]common.Uint256, 0, len(block.Transactions))
+	exist := make(map[common.Uint256]bool, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		hash := tx.Hash()
+		if exist[hash] {
+			return fmt.Errorf("duplicated tx %x", hash)
+		}
+		exist[hash] = true
+		txHash = append(txHash, hash)
+	}
+	txRoot := common.ComputeMerkleRoot(txHash)
+	if header.TransactionsRoot != txRoot {
+		return fmt.Errorf("unmatched transactions root")
+	}
+	if header.BlockRoot != self.ledger.GetBlockRootWithNewTxRoots(st.height, []common.Uint256{txRoot}) {
+		return fmt.Errorf("unmatched block root")
+	}
+	if len(block.Transactions) == 0 {
+		return nil
+	}
+	validHeight := self.validHeight()
+	if err := self.poolActor.VerifyBlock(block.Transactions, validHeight); err != nil {
+		return fmt.Errorf("verify txs error: %s", err)
+	}
+	for _, tx := range block.Transactions {
+		if err := self.incrValidator.Verify(tx, validHeight); err != nil {
+			return fmt.Errorf("increment verify tx %x error: %s", tx.Hash(), err)
+		}
+	}
 	return nil
 }
+
+//broadcast sign the consensus message with payload and send it to network
+func (self *SbftService) broadcast(msg ConsensusMsg) {
+	st := self.state
+	payload := &p2pmsg.ConsensusPayload{
+		Version:         ContextVersion,
+		PrevHash:        st.prevHash,
+		Height:          st.height,
+		BookkeeperIndex: uint16(self.index),
+		Timestamp:       uint32(time.Now().Unix()),
+		Data:            SerializeMsg(msg),
+		Owner:           self.signer.PubKey(),
+	}
+	sink := common.NewZeroCopySink(nil)
+	payload.SerializationUnsigned(sink)
+	sig, err := self.signer.Sign(&signer.SignRequest{Type: signer.SIGN_MESSAGE, Height: st.height,
+		View: st.round, Data: sink.Bytes()})
+	if err != nil {
+		log.Errorf("sbft: sign %s error: %s", msg.Type(), err)
+		return
+	}
+	payload.Signature = sig
+	self.net.Broadcast(msgpack.NewConsensus(payload))
+}
+
+func (self *SbftService) handlePayload(payload *p2pmsg.ConsensusPayload) {
+	st := self.state
+	if st == nil || payload.Version != ContextVersion {
+		return
+	}
+	index := int(payload.BookkeeperIndex)
+	if index >= len(self.validators) || index == self.index {
+		return
+	}
+	if !bytes.Equal(keypair.SerializePublicKey(payload.Owner), keypair.SerializePublicKey(self.validators[index])) {
+		log.Warnf("sbft: unmatched owner of validator %d", index)
+		return
+	}
+	if payload.Height == st.height+1 {
+		if len(self.future) < MAX_FUTURE_MSGS {
+			self.future = append(self.future, payload)
+		}
+		return
+	}
+	if payload.Height != st.height || payload.PrevHash != st.prevHash {
+		return
+	}
+	if err := payload.Verify(); err != nil {
+		log.Warnf("sbft: verify payload of validator %d error: %s", index, err)
+		return
+	}
+	msg, err := DeserializeMsg(payload.Data)
+	if err != nil {
+		log.Warnf("sbft: deserialize message of validator %d error: %s", index, err)
+		return
+	}
+	if msg.GetRound() > st.round+MAX_ROUND_AHEAD {
+		return
+	}
+	switch m := msg.(type) {
+	case *Proposal:
+		self.onProposal(index, m)
+	case *Vote:
+		self.onVote(index, m)
+	}
+	//skip to the round that at least one honest validator is in
+	if st.step != StepNewHeight && st.step != StepCommit && msg.GetRound() > st.round &&
+		st.addSender(msg.GetRound(), uint16(index)) > len(self.validators)-self.quorum {
+		self.startRound(msg.GetRound())
+		return
+	}
+	self.process()
+}
+
+func (self *SbftService) onProposal(index int, proposal *Proposal) {
+	st := self.state
+	if index != self.proposer(proposal.Round) {
+		log.Warnf("sbft: proposal of round %d from non proposer %d", proposal.Round, index)
+		return
+	}
+	if _, present := st.proposals[proposal.Round]; present {
+		return
+	}
+	if proposal.ValidRound < -1 || proposal.ValidRound >= int32(proposal.Round) || proposal.Block.Header == nil {
+		return
+	}
+	hash := proposal.Block.Hash()
+	st.proposals[proposal.Round] = proposal
+	st.blocks[hash] = proposal.Block
+}
+
+func (self *SbftService) onVote(index int, vote *Vote) {
+	if vote.VoteType == PrecommitMsg && !vote.IsNil() {
+		if err := signature.Verify(self.validators[index], vote.BlockHash[:], vote.Signature); err != nil {
+			log.Warnf("sbft: verify precommit signature of validator %d error: %s", index, err)
+			return
+		}
+	}
+	self.state.votes(vote.VoteType, vote.Round).add(uint16(index), vote)
+}
+
+func (self *SbftService) prevote(hash common.Uint256) {
+	st := self.state
+	st.step = StepPrevote
+	if self.index < 0 {
+		return
+	}
+	vote := &Vote{VoteType: PrevoteMsg, Round: st.round, BlockHash: hash}
+	self.broadcast(vote)
+	st.votes(PrevoteMsg, st.round).add(uint16(self.index), vote)
+}
+
+func (self *SbftService) precommit(hash common.Uint256) {
+	st := self.state
+	st.step = StepPrecommit
+	if self.index < 0 {
+		return
+	}
+	vote := &Vote{VoteType: PrecommitMsg, Round: st.round, BlockHash: hash}
+	if !vote.IsNil() {
+		sig, err := self.signer.Sign(&signer.SignRequest{Type: signer.SIGN_COMMIT, View: st.round,
+			Data: st.blocks[hash].Header.ToArray()})
+		if err != nil {
+			log.Errorf("sbft: sign precommit error: %s", err)
+			return
+		}
+		vote.Signature = sig
+	}
+	self.broadcast(vote)
+	st.votes(PrecommitMsg, st.round).add(uint16(self.index), vote)
+}
+
+//process apply the consensus rules until the state does not change
+func (self *SbftService) process() {
+	for self.state != nil && self.processOnce() {
+	}
+}
+
+func (self *SbftService) processOnce() bool {
+	st := self.state
+	if st.step == StepCommit {
+		return false
+	}
+	for round, precommits := range st.precommit {
+		hash, ok := precommits.majority(self.quorum)
+		if !ok || hash == common.UINT256_EMPTY {
+			continue
+		}
+		if block, present := st.blocks[hash]; present {
+			self.commit(round, block)
+			return false
+		}
+	}
+	if st.step == StepNewHeight {
+		return false
+	}
+
+	switch st.step {
+	case StepPropose:
+		proposal := st.proposals[st.round]
+		if proposal == nil {
+			return false
+		}
+		hash := proposal.Block.Hash()
+		if proposal.ValidRound == -1 {
+			if self.verifyBlock(proposal.Block) == nil && (st.lockedRound == -1 || st.lockedBlock.Hash() == hash) {
+				self.prevote(hash)
+			} else {
+				self.prevote(common.UINT256_EMPTY)
+			}
+			return true
+		}
+		polka, ok := st.votes(PrevoteMsg, uint32(proposal.ValidRound)).majority(self.quorum)
+		if !ok || polka != hash {
+			return false
+		}
+		if self.verifyBlock(proposal.Block) == nil &&
+			(st.lockedRound <= proposal.ValidRound || st.lockedBlock.Hash() == hash) {
+			self.prevote(hash)
+		} else {
+			self.prevote(common.UINT256_EMPTY)
+		}
+		return true
+	case StepPrevote, StepPrecommit:
+		prevotes := st.votes(PrevoteMsg, st.round)
+		hash, ok := prevotes.majority(self.quorum)
+		if ok && hash != common.UINT256_EMPTY {
+			block, present := st.blocks[hash]
+			if present && self.verifyBlock(block) == nil && st.validRound < int32(st.round) {
+				if st.step == StepPrevote {
+					st.lockedRound = int32(st.round)
+					st.lockedBlock = block
+					self.precommit(hash)
+				}
+				st.validRound = int32(st.round)
+				st.validBlock = block
+				return true
+			}
+		} else if ok && st.step == StepPrevote {
+			self.precommit(common.UINT256_EMPTY)
+			return true
+		}
+		if st.step == StepPrevote && !st.prevoteWait && prevotes.size() >= self.quorum {
+			st.prevoteWait = true
+			self.schedule(self.roundTimeout*time.Duration(st.round+1), st.round, StepPrevote)
+		}
+		if !st.precommitWait && st.votes(PrecommitMsg, st.round).size() >= self.quorum {
+			st.precommitWait = true
+			self.schedule(self.roundTimeout*time.Duration(st.round+1), st.round, StepPrecommit)
+		}
+	}
+	return false
+}
+
+//commit save the block signed by quorum precommits of round
+func (self *SbftService) commit(round uint32, proposed *types.Block) {
+	st := self.state
+	st.step = StepCommit
+	hash := proposed.Hash()
+	header := *proposed.Header
+	header.Bookkeepers = self.validators
+	header.SigData = st.votes(PrecommitMsg, round).signatures(hash, self.quorum, len(self.validators))
+	block := &types.Block{Header: &header, Transactions: proposed.Transactions}
+	log.Infof("sbft: commit block %x at height %d round %d", hash, st.height, round)
+
+	exist, err := self.ledger.IsContainBlock(hash)
+	if err != nil {
+		log.Errorf("sbft: IsContainBlock %x error: %s", hash, err)
+		return
+	}
+	if !exist {
+		result, err := self.ledger.ExecuteBlock(block)
+		if err != nil {
+			log.Errorf("sbft: execute block at height %d error: %s", block.Header.Height, err)
+			return
+		}
+		if err = self.ledger.SubmitBlock(block, nil, result); err != nil {
+			log.Errorf("sbft: submit block at height %d error: %s", block.Header.Height, err)
+			return
+		}
+		self.net.Broadcast(msgpack.NewInv(msgpack.NewInvPayload(common.BLOCK, []common.Uint256{hash})))
+	}
+	self.lastCommit = time.Now()
+	self.blockSaved(block)
+}
+
+//blockSaved update the increment validator and start next height if the block is saved by ledger
+func (self *SbftService) blockSaved(block *types.Block) {
+	if _, end := self.incrValidator.BlockRange(); end == 0 || end == block.Header.Height {
+		self.incrValidator.AddBlock(block)
+	}
+	if self.started && (self.state == nil || block.Header.Height >= self.state.height) {
+		self.newHeight()
+	}
+}
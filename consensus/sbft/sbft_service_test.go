@@ -0,0 +1,298 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/consensus/signer"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/store"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/events"
+	p2pmsg "github.com/ontio/ontology/p2pserver/message/types"
+	txpool "github.com/ontio/ontology/txnpool/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	events.Init()
+	os.Exit(m.Run())
+}
+
+//testLedger is an in memory ledger verifying block signatures like ledger store
+type testLedger struct {
+	lock   sync.Mutex
+	blocks []*types.Block
+	hashes map[common.Uint256]*types.Header
+}
+
+func newTestLedger(validators []keypair.PublicKey) *testLedger {
+	next, _ := types.AddressFromBookkeepers(validators)
+	genesis := &types.Block{Header: &types.Header{
+		Timestamp:      1,
+		NextBookkeeper: next,
+	}}
+	return &testLedger{
+		blocks: []*types.Block{genesis},
+		hashes: map[common.Uint256]*types.Header{genesis.Hash(): genesis.Header},
+	}
+}
+
+func (this *testLedger) GetCurrentBlockHeight() uint32 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return uint32(len(this.blocks) - 1)
+}
+
+func (this *testLedger) GetCurrentBlockHash() common.Uint256 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.blocks[len(this.blocks)-1].Hash()
+}
+
+func (this *testLedger) GetHeaderByHash(blockHash common.Uint256) (*types.Header, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.hashes[blockHash], nil
+}
+
+func (this *testLedger) GetBlockRootWithNewTxRoots(startHeight uint32, txRoots []common.Uint256) common.Uint256 {
+	return txRoots[0]
+}
+
+func (this *testLedger) IsContainBlock(blockHash common.Uint256) (bool, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.hashes[blockHash] != nil, nil
+}
+
+func (this *testLedger) ExecuteBlock(b *types.Block) (store.ExecuteResult, error) {
+	return store.ExecuteResult{}, nil
+}
+
+func (this *testLedger) SubmitBlock(b *types.Block, crossChainMsg *types.CrossChainMsg, exec store.ExecuteResult) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	prev := this.blocks[len(this.blocks)-1].Header
+	if b.Header.Height != prev.Height+1 || b.Header.PrevBlockHash != prev.Hash() {
+		return fmt.Errorf("unmatched height or prev hash")
+	}
+	addr, err := types.AddressFromBookkeepers(b.Header.Bookkeepers)
+	if err != nil || addr != prev.NextBookkeeper {
+		return fmt.Errorf("bookkeeper address error")
+	}
+	m := len(b.Header.Bookkeepers) - (len(b.Header.Bookkeepers)-1)/3
+	hash := b.Hash()
+	if err := signature.VerifyMultiSignature(hash[:], b.Header.Bookkeepers, m, b.Header.SigData); err != nil {
+		return err
+	}
+	this.blocks = append(this.blocks, b)
+	this.hashes[hash] = b.Header
+	return nil
+}
+
+func (this *testLedger) blockHash(height uint32) common.Uint256 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.blocks[height].Hash()
+}
+
+type testPool struct{}
+
+func (this *testPool) GetTxnPool(byCount bool, height uint32) []*txpool.TXEntry {
+	return nil
+}
+
+func (this *testPool) VerifyBlock(txs []*types.Transaction, height uint32) error {
+	return nil
+}
+
+//testNetwork delivers consensus messages between the nodes in process
+type testNetwork struct {
+	lock  sync.RWMutex
+	nodes []*SbftService
+	down  map[int]bool
+}
+
+type testPeer struct {
+	net   *testNetwork
+	index int
+}
+
+func (this *testPeer) Broadcast(msg p2pmsg.Message) {
+	cons, ok := msg.(*p2pmsg.Consensus)
+	if !ok {
+		return
+	}
+	buf := cons.Cons.ToArray()
+	this.net.lock.RLock()
+	defer this.net.lock.RUnlock()
+	if this.net.down[this.index] {
+		return
+	}
+	for i, node := range this.net.nodes {
+		if i == this.index || this.net.down[i] {
+			continue
+		}
+		payload := &p2pmsg.ConsensusPayload{}
+		if err := payload.Deserialization(common.NewZeroCopySource(buf)); err != nil {
+			panic(err)
+		}
+		if err := payload.Verify(); err != nil {
+			panic(err)
+		}
+		node.GetPID().Tell(payload)
+	}
+}
+
+//newTestNodes return n validators sharing in process network
+func newTestNodes(t *testing.T, n int) ([]*SbftService, []*testLedger, *testNetwork) {
+	accounts := make([]*account.Account, n)
+	validators := make([]keypair.PublicKey, n)
+	for i := 0; i < n; i++ {
+		accounts[i] = account.NewAccount("")
+		validators[i] = accounts[i].PublicKey
+	}
+	keypair.SortPublicKeys(validators)
+
+	net := &testNetwork{down: make(map[int]bool)}
+	nodes := make([]*SbftService, n)
+	ledgers := make([]*testLedger, n)
+	for i := 0; i < n; i++ {
+		ledger := newTestLedger(validators)
+		node, err := newSbftService(signer.NewLocalSigner(accounts[i]), validators, ledger, &testPool{},
+			&testPeer{net: net}, 50*time.Millisecond, 300*time.Millisecond, "")
+		assert.Nil(t, err)
+		node.net.(*testPeer).index = node.index
+		nodes[node.index] = node
+		ledgers[node.index] = ledger
+	}
+	net.nodes = nodes
+	return nodes, ledgers, net
+}
+
+func waitHeight(ledgers []*testLedger, height uint32, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		reached := true
+		for _, l := range ledgers {
+			if l.GetCurrentBlockHeight() < height {
+				reached = false
+				break
+			}
+		}
+		if reached {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
+
+func checkSameChain(t *testing.T, ledgers []*testLedger, height uint32) {
+	for h := uint32(1); h <= height; h++ {
+		for _, l := range ledgers[1:] {
+			assert.Equal(t, ledgers[0].blockHash(h), l.blockHash(h))
+		}
+	}
+}
+
+func TestSbftConsensus(t *testing.T) {
+	nodes, ledgers, _ := newTestNodes(t, 4)
+	for _, node := range nodes {
+		node.Start()
+		defer node.Halt()
+	}
+	assert.True(t, waitHeight(ledgers, 5, 20*time.Second))
+	checkSameChain(t, ledgers, 5)
+}
+
+func TestSbftProposerDown(t *testing.T) {
+	nodes, ledgers, net := newTestNodes(t, 4)
+	//validator 1 is the proposer of round 0 at height 1
+	net.down[1] = true
+	var alive []*testLedger
+	for i, node := range nodes {
+		if i == 1 {
+			continue
+		}
+		node.Start()
+		defer node.Halt()
+		alive = append(alive, ledgers[i])
+	}
+	assert.True(t, waitHeight(alive, 3, 30*time.Second))
+	checkSameChain(t, alive, 3)
+	assert.Equal(t, uint32(0), ledgers[1].GetCurrentBlockHeight())
+}
+
+func TestSbftNoQuorum(t *testing.T) {
+	nodes, ledgers, net := newTestNodes(t, 4)
+	net.down[2] = true
+	net.down[3] = true
+	for _, node := range nodes[:2] {
+		node.Start()
+		defer node.Halt()
+	}
+	assert.False(t, waitHeight(ledgers[:2], 1, time.Second))
+}
+
+func TestMsgSerialization(t *testing.T) {
+	block := &types.Block{Header: &types.Header{Height: 10, Timestamp: 100}, Transactions: []*types.Transaction{}}
+	proposal := &Proposal{Round: 2, ValidRound: -1, Block: block}
+	msg, err := DeserializeMsg(SerializeMsg(proposal))
+	assert.Nil(t, err)
+	assert.Equal(t, ProposalMsg, msg.Type())
+	assert.Equal(t, proposal.ValidRound, msg.(*Proposal).ValidRound)
+	assert.Equal(t, block.Hash(), msg.(*Proposal).Block.Hash())
+
+	vote := &Vote{VoteType: PrecommitMsg, Round: 3, BlockHash: block.Hash(), Signature: []byte{4}}
+	msg, err = DeserializeMsg(SerializeMsg(vote))
+	assert.Nil(t, err)
+	assert.Equal(t, vote, msg)
+
+	_, err = DeserializeMsg([]byte{9})
+	assert.NotNil(t, err)
+	_, err = DeserializeMsg(append(SerializeMsg(vote), 0))
+	assert.NotNil(t, err)
+}
+
+func TestVoteSet(t *testing.T) {
+	hash := common.Uint256{1}
+	vs := newVoteSet()
+	assert.True(t, vs.add(0, &Vote{BlockHash: hash, Signature: []byte{0}}))
+	assert.False(t, vs.add(0, &Vote{}))
+	assert.True(t, vs.add(2, &Vote{BlockHash: hash, Signature: []byte{2}}))
+	assert.True(t, vs.add(1, &Vote{}))
+	_, ok := vs.majority(3)
+	assert.False(t, ok)
+	assert.True(t, vs.add(3, &Vote{BlockHash: hash, Signature: []byte{3}}))
+	major, ok := vs.majority(3)
+	assert.True(t, ok)
+	assert.Equal(t, hash, major)
+	assert.Equal(t, [][]byte{{0}, {2}, {3}}, vs.signatures(hash, 3, 4))
+	assert.Equal(t, 4, vs.size())
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+)
+
+type Step byte
+
+const (
+	StepNewHeight Step = iota
+	StepPropose
+	StepPrevote
+	StepPrecommit
+	StepCommit
+)
+
+func (this Step) String() string {
+	switch this {
+	case StepNewHeight:
+		return "newheight"
+	case StepPropose:
+		return "propose"
+	case StepPrevote:
+		return "prevote"
+	case StepPrecommit:
+		return "precommit"
+	case StepCommit:
+		return "commit"
+	default:
+		return "unknown"
+	}
+}
+
+//voteSet collects the votes of one type in a round, a validator can only vote once
+type voteSet struct {
+	votes  map[uint16]*Vote
+	counts map[common.Uint256]int
+}
+
+func newVoteSet() *voteSet {
+	return &voteSet{
+		votes:  make(map[uint16]*Vote),
+		counts: make(map[common.Uint256]int),
+	}
+}
+
+//add the vote of validator, return false if the validator has voted
+func (this *voteSet) add(index uint16, vote *Vote) bool {
+	if _, present := this.votes[index]; present {
+		return false
+	}
+	this.votes[index] = vote
+	this.counts[vote.BlockHash]++
+	return true
+}
+
+func (this *voteSet) size() int {
+	return len(this.votes)
+}
+
+//majority return the block hash voted by at least quorum validators
+func (this *voteSet) majority(quorum int) (common.Uint256, bool) {
+	for hash, count := range this.counts {
+		if count >= quorum {
+			return hash, true
+		}
+	}
+	return common.UINT256_EMPTY, false
+}
+
+//signatures return quorum signatures of the votes for block hash
+func (this *voteSet) signatures(hash common.Uint256, quorum int, validators int) [][]byte {
+	sigs := make([][]byte, 0, quorum)
+	for i := 0; i < validators && len(sigs) < quorum; i++ {
+		vote, present := this.votes[uint16(i)]
+		if present && vote.BlockHash == hash {
+			sigs = append(sigs, vote.Signature)
+		}
+	}
+	return sigs
+}
+
+//roundState is the consensus state of current height
+type roundState struct {
+	height    uint32
+	prevHash  common.Uint256
+	prevTime  uint32
+	round     uint32
+	step      Step
+	proposals map[uint32]*Proposal //proposal of the proposer in each round
+	blocks    map[common.Uint256]*types.Block
+	prevotes  map[uint32]*voteSet
+	precommit map[uint32]*voteSet
+	senders   map[uint32]map[uint16]bool //validators sent messages in each round
+
+	lockedRound int32
+	lockedBlock *types.Block
+	validRound  int32
+	validBlock  *types.Block
+
+	//whether the timeouts of current round have been scheduled
+	prevoteWait   bool
+	precommitWait bool
+}
+
+func newRoundState(height uint32, prevHash common.Uint256, prevTime uint32) *roundState {
+	return &roundState{
+		height:      height,
+		prevHash:    prevHash,
+		prevTime:    prevTime,
+		step:        StepNewHeight,
+		proposals:   make(map[uint32]*Proposal),
+		blocks:      make(map[common.Uint256]*types.Block),
+		prevotes:    make(map[uint32]*voteSet),
+		precommit:   make(map[uint32]*voteSet),
+		senders:     make(map[uint32]map[uint16]bool),
+		lockedRound: -1,
+		validRound:  -1,
+	}
+}
+
+func (this *roundState) votes(voteType MsgType, round uint32) *voteSet {
+	sets := this.prevotes
+	if voteType == PrecommitMsg {
+		sets = this.precommit
+	}
+	vs, present := sets[round]
+	if !present {
+		vs = newVoteSet()
+		sets[round] = vs
+	}
+	return vs
+}
+
+//addSender record the validator sent message in round, return the number of validators in the round
+func (this *roundState) addSender(round uint32, index uint16) int {
+	senders, present := this.senders[round]
+	if !present {
+		senders = make(map[uint16]bool)
+		this.senders[round] = senders
+	}
+	senders[index] = true
+	return len(senders)
+}
+
+//enterRound reset the round related state
+func (this *roundState) enterRound(round uint32) {
+	this.round = round
+	this.step = StepPropose
+	this.prevoteWait = false
+	this.precommitWait = false
+}
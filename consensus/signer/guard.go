@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ontio/ontology/common/config"
+	scom "github.com/ontio/ontology/core/store/common"
+)
+
+//HASH_SIZE is the size of block hash and state root. Messages of hash size are refused,
+//so that slashing rules can not be bypassed by signing a block hash as message.
+const HASH_SIZE = 32
+
+//VBFT_MAX_VIEW is the max sign view of vbft, which signs normal block in view 0 and empty block in view 1
+const VBFT_MAX_VIEW = 1
+
+//MaxSignView return the max sign view produced by consensus type
+func MaxSignView(consensusType string) (uint32, error) {
+	switch strings.ToLower(consensusType) {
+	case config.CONSENSUS_TYPE_VBFT:
+		return VBFT_MAX_VIEW, nil
+	case config.CONSENSUS_TYPE_SBFT:
+		return math.MaxUint32, nil
+	default:
+		return 0, fmt.Errorf("unsupported consensus type:%s", consensusType)
+	}
+}
+
+//SlashingGuard records signed data in store, and refuses to sign different data of the same type, height and view.
+//The view of the same type and height never goes down, and never exceeds maxView.
+type SlashingGuard struct {
+	lock    sync.Mutex
+	store   scom.PersistStore
+	maxView uint32
+}
+
+//NewSlashingGuard return slashing guard of store, which refuses views greater than maxView
+func NewSlashingGuard(store scom.PersistStore, maxView uint32) *SlashingGuard {
+	return &SlashingGuard{store: store, maxView: maxView}
+}
+
+func guardKey(signType SignType, height, view uint32) []byte {
+	key := make([]byte, 9)
+	key[0] = byte(signType)
+	binary.BigEndian.PutUint32(key[1:5], height)
+	binary.BigEndian.PutUint32(key[5:], view)
+	return key
+}
+
+//viewKey is the key of highest signed view of type and height
+func viewKey(signType SignType, height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(signType)
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
+
+//Check return error if signing request violates slashing rules, otherwise record the request. The rules apply to
+//the height and hash derived from the block header or cross chain message of request, not claimed by the caller.
+func (this *SlashingGuard) Check(req *SignRequest) error {
+	switch req.Type {
+	case SIGN_MESSAGE:
+		if len(req.Data) == HASH_SIZE {
+			return fmt.Errorf("refuse to sign message of hash size")
+		}
+		return nil
+	case SIGN_WITNESS:
+		_, _, err := req.Digest()
+		return err
+	case SIGN_STATE_ROOT:
+		if len(req.Data) != HASH_SIZE {
+			return fmt.Errorf("invalid state root size:%d", len(req.Data))
+		}
+	case SIGN_PROPOSAL, SIGN_ENDORSE, SIGN_COMMIT, SIGN_CROSS_CHAIN:
+	default:
+		return fmt.Errorf("unknown sign type:%d", req.Type)
+	}
+	height, data, err := req.Digest()
+	if err != nil {
+		return err
+	}
+
+	if req.View > this.maxView {
+		return fmt.Errorf("refuse to sign %s at height:%d view:%d, max view is %d", req.Type, height, req.View, this.maxView)
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	vKey := viewKey(req.Type, height)
+	highest, err := this.store.Get(vKey)
+	hasView := err == nil
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("get signed view error:%s", err)
+	}
+	if hasView {
+		if len(highest) != 4 {
+			return fmt.Errorf("invalid signed view record of %s at height:%d", req.Type, height)
+		}
+		if view := binary.BigEndian.Uint32(highest); req.View < view {
+			return fmt.Errorf("refuse to sign %s at height:%d view:%d, has signed view:%d", req.Type, height, req.View, view)
+		}
+	}
+	key := guardKey(req.Type, height, req.View)
+	signed, err := this.store.Get(key)
+	if err == nil {
+		if !bytes.Equal(signed, data) {
+			return fmt.Errorf("refuse to sign %s at height:%d view:%d, has signed different data", req.Type, height, req.View)
+		}
+		return nil
+	}
+	if err != scom.ErrNotFound {
+		return fmt.Errorf("get signed record error:%s", err)
+	}
+	view := make([]byte, 4)
+	binary.BigEndian.PutUint32(view, req.View)
+	this.store.NewBatch()
+	this.store.BatchPut(key, data)
+	this.store.BatchPut(vKey, view)
+	if err = this.store.BatchCommit(); err != nil {
+		return fmt.Errorf("put signed record error:%s", err)
+	}
+	return nil
+}
+
+//GuardedSigner is signer with slashing protection
+type GuardedSigner struct {
+	Signer
+	guard *SlashingGuard
+}
+
+//NewGuardedSigner return signer which checks slashing rules by guard before signing
+func NewGuardedSigner(signer Signer, guard *SlashingGuard) *GuardedSigner {
+	return &GuardedSigner{Signer: signer, guard: guard}
+}
+
+func (this *GuardedSigner) Sign(req *SignRequest) ([]byte, error) {
+	if err := this.guard.Check(req); err != nil {
+		return nil, err
+	}
+	return this.Signer.Sign(req)
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGuardedSigner(t *testing.T) *GuardedSigner {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	return NewGuardedSigner(NewLocalSigner(account.NewAccount("")), NewSlashingGuard(store, VBFT_MAX_VIEW))
+}
+
+//newTestHeader return the serialized header of block at height, blocks with different nonce have different hash
+func newTestHeader(height uint32, nonce uint64) []byte {
+	header := &types.Header{Height: height, ConsensusData: nonce}
+	return header.ToArray()
+}
+
+func TestGuardedSigner(t *testing.T) {
+	signer := newTestGuardedSigner(t)
+	block1 := newTestHeader(10, 1)
+	block2 := newTestHeader(10, 2)
+
+	sig, err := signer.Sign(&SignRequest{Type: SIGN_ENDORSE, View: 0, Data: block1})
+	assert.Nil(t, err)
+	hash := (&types.Header{Height: 10, ConsensusData: 1}).Hash()
+	assert.Nil(t, signature.Verify(signer.PubKey(), hash[:], sig))
+	//sign the same data again
+	_, err = signer.Sign(&SignRequest{Type: SIGN_ENDORSE, View: 0, Data: block1})
+	assert.Nil(t, err)
+	//sign different block at the same height and view, the declared height is ignored
+	_, err = signer.Sign(&SignRequest{Type: SIGN_ENDORSE, Height: 11, View: 0, Data: block2})
+	assert.NotNil(t, err)
+	//different height
+	_, err = signer.Sign(&SignRequest{Type: SIGN_ENDORSE, View: 0, Data: newTestHeader(11, 2)})
+	assert.Nil(t, err)
+	//different type
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 0, Data: block2})
+	assert.Nil(t, err)
+	//bare hash is not a block header
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, Height: 12, View: 0, Data: hash[:]})
+	assert.NotNil(t, err)
+
+	_, err = signer.Sign(&SignRequest{Type: SIGN_STATE_ROOT, Height: 10, Data: hash[:]})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_STATE_ROOT, Height: 10, Data: block1})
+	assert.NotNil(t, err)
+
+	_, err = signer.Sign(&SignRequest{Type: SIGN_MESSAGE, Data: []byte("heartbeat")})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_MESSAGE, Data: hash[:]})
+	assert.NotNil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_WITNESS, Data: []byte("offline witness")})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_WITNESS, Data: hash[:]})
+	assert.NotNil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: 100, Data: []byte("unknown")})
+	assert.NotNil(t, err)
+}
+
+func TestGuardedSignerView(t *testing.T) {
+	signer := newTestGuardedSigner(t)
+	hash1 := newTestHeader(10, 1)
+	hash2 := newTestHeader(10, 2)
+	hash3 := newTestHeader(10, 3)
+
+	//view greater than max view of vbft
+	_, err := signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: VBFT_MAX_VIEW + 1, Data: hash1})
+	assert.NotNil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 100, Data: hash2})
+	assert.NotNil(t, err)
+
+	//empty block in view 1 after normal block in view 0
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 0, Data: hash1})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 1, Data: hash2})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 1, Data: hash2})
+	assert.Nil(t, err)
+	//view goes down
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 0, Data: hash1})
+	assert.NotNil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 0, Data: hash3})
+	assert.NotNil(t, err)
+	//views of other type and height are independent
+	_, err = signer.Sign(&SignRequest{Type: SIGN_ENDORSE, View: 0, Data: hash3})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_COMMIT, View: 0, Data: newTestHeader(9, 3)})
+	assert.Nil(t, err)
+}
+
+func TestMaxSignView(t *testing.T) {
+	view, err := MaxSignView("VBFT")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(VBFT_MAX_VIEW), view)
+	view, err = MaxSignView("sbft")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(math.MaxUint32), view)
+	_, err = MaxSignView("solo")
+	assert.NotNil(t, err)
+
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	signer := NewGuardedSigner(NewLocalSigner(account.NewAccount("")), NewSlashingGuard(store, view))
+	block := newTestHeader(10, 1)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_PROPOSAL, View: 1000, Data: block})
+	assert.Nil(t, err)
+	_, err = signer.Sign(&SignRequest{Type: SIGN_PROPOSAL, View: 999, Data: block})
+	assert.NotNil(t, err)
+}
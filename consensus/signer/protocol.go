@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//Remote signer protocol is json over http post. Each request is authenticated by
+//hmac-sha256 of timestamp, path and body with the token shared by node and signer daemon.
+const (
+	SIGNER_PATH_PUBKEY = "/pubkey"
+	SIGNER_PATH_SIGN   = "/sign"
+	SIGNER_PATH_VRF    = "/vrf"
+
+	SIGNER_HEADER_TIMESTAMP = "X-Signer-Timestamp"
+	SIGNER_HEADER_AUTH      = "X-Signer-Auth"
+
+	MAX_AUTH_TIME_SKEW = 30 * time.Second //max time difference between node and signer daemon
+	MIN_TOKEN_SIZE     = 16
+)
+
+type PubKeyRsp struct {
+	PublicKey string `json:"public_key"`
+}
+
+type SignReq struct {
+	Type   SignType `json:"type"`
+	Height uint32   `json:"height"`
+	View   uint32   `json:"view"`
+	Data   string   `json:"data"`
+}
+
+type SignRsp struct {
+	Signature string `json:"signature"`
+}
+
+type VrfReq struct {
+	Data string `json:"data"`
+}
+
+type VrfRsp struct {
+	Value string `json:"value"`
+	Proof string `json:"proof"`
+}
+
+type ErrorRsp struct {
+	Error string `json:"error"`
+}
+
+func authCode(token []byte, timestamp, path string, body []byte) string {
+	mac := hmac.New(sha256.New, token)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func setAuth(req *http.Request, token []byte, body []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(SIGNER_HEADER_TIMESTAMP, timestamp)
+	req.Header.Set(SIGNER_HEADER_AUTH, authCode(token, timestamp, req.URL.Path, body))
+}
+
+func checkAuth(req *http.Request, token []byte, body []byte) error {
+	timestamp := req.Header.Get(SIGNER_HEADER_TIMESTAMP)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp")
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew > MAX_AUTH_TIME_SKEW || skew < -MAX_AUTH_TIME_SKEW {
+		return fmt.Errorf("timestamp expired")
+	}
+	expected := authCode(token, timestamp, req.URL.Path, body)
+	if !hmac.Equal([]byte(expected), []byte(req.Header.Get(SIGNER_HEADER_AUTH))) {
+		return fmt.Errorf("invalid auth code")
+	}
+	return nil
+}
+
+//CheckToken return error if the token is too short to authenticate
+func CheckToken(token []byte) error {
+	if len(token) < MIN_TOKEN_SIZE {
+		return fmt.Errorf("signer token should be at least %d bytes", MIN_TOKEN_SIZE)
+	}
+	return nil
+}
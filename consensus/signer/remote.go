@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/vrf"
+	"github.com/ontio/ontology/core/signature"
+)
+
+const REMOTE_SIGNER_TIMEOUT = 5 * time.Second
+
+//RemoteSigner sign by the signer daemon, and verifies the signatures returned
+type RemoteSigner struct {
+	address string
+	token   []byte
+	pubKey  keypair.PublicKey
+	client  *http.Client
+}
+
+//NewRemoteSigner connect the signer daemon at address, such as http://127.0.0.1:20600, and fetch its public key
+func NewRemoteSigner(address string, token []byte) (*RemoteSigner, error) {
+	if err := CheckToken(token); err != nil {
+		return nil, err
+	}
+	this := &RemoteSigner{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: REMOTE_SIGNER_TIMEOUT},
+	}
+	rsp := &PubKeyRsp{}
+	if err := this.call(SIGNER_PATH_PUBKEY, struct{}{}, rsp); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(rsp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of remote signer:%s", err)
+	}
+	this.pubKey, err = keypair.DeserializePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of remote signer:%s", err)
+	}
+	return this, nil
+}
+
+func (this *RemoteSigner) PubKey() keypair.PublicKey {
+	return this.pubKey
+}
+
+func (this *RemoteSigner) Sign(req *SignRequest) ([]byte, error) {
+	_, data, err := req.Digest()
+	if err != nil {
+		return nil, err
+	}
+	rsp := &SignRsp{}
+	err = this.call(SIGNER_PATH_SIGN, &SignReq{
+		Type:   req.Type,
+		Height: req.Height,
+		View:   req.View,
+		Data:   hex.EncodeToString(req.Data),
+	}, rsp)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := hex.DecodeString(rsp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature of remote signer:%s", err)
+	}
+	if err = signature.Verify(this.pubKey, data, sig); err != nil {
+		return nil, fmt.Errorf("invalid signature of remote signer:%s", err)
+	}
+	return sig, nil
+}
+
+func (this *RemoteSigner) Vrf(data []byte) ([]byte, []byte, error) {
+	rsp := &VrfRsp{}
+	if err := this.call(SIGNER_PATH_VRF, &VrfReq{Data: hex.EncodeToString(data)}, rsp); err != nil {
+		return nil, nil, err
+	}
+	value, err := hex.DecodeString(rsp.Value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid vrf value of remote signer:%s", err)
+	}
+	proof, err := hex.DecodeString(rsp.Proof)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid vrf proof of remote signer:%s", err)
+	}
+	ok, err := vrf.Verify(this.pubKey, data, value, proof)
+	if err != nil || !ok {
+		return nil, nil, fmt.Errorf("invalid vrf of remote signer")
+	}
+	return value, proof, nil
+}
+
+func (this *RemoteSigner) call(path string, req, rsp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, this.address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	setAuth(httpReq, this.token, body)
+	httpRsp, err := this.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote signer %s error:%s", path, err)
+	}
+	defer httpRsp.Body.Close()
+	data, err := ioutil.ReadAll(httpRsp.Body)
+	if err != nil {
+		return fmt.Errorf("remote signer %s read response error:%s", path, err)
+	}
+	if httpRsp.StatusCode != http.StatusOK {
+		errRsp := &ErrorRsp{}
+		if json.Unmarshal(data, errRsp) == nil && errRsp.Error != "" {
+			return fmt.Errorf("remote signer %s error:%s", path, errRsp.Error)
+		}
+		return fmt.Errorf("remote signer %s error:%s", path, httpRsp.Status)
+	}
+	if err = json.Unmarshal(data, rsp); err != nil {
+		return fmt.Errorf("remote signer %s invalid response:%s", path, err)
+	}
+	return nil
+}
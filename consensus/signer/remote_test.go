@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/vrf"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var testToken = []byte("0123456789abcdef0123456789abcdef")
+
+func TestRemoteSigner(t *testing.T) {
+	guarded := newTestGuardedSigner(t)
+	handler, err := NewServer(guarded, testToken)
+	assert.Nil(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	remote, err := NewRemoteSigner(server.URL, testToken)
+	assert.Nil(t, err)
+	assert.Equal(t, keypair.SerializePublicKey(guarded.PubKey()), keypair.SerializePublicKey(remote.PubKey()))
+
+	header := &types.Header{Height: 100}
+	sig, err := remote.Sign(&SignRequest{Type: SIGN_PROPOSAL, Data: header.ToArray()})
+	assert.Nil(t, err)
+	hash := header.Hash()
+	assert.Nil(t, signature.Verify(remote.PubKey(), hash[:], sig))
+
+	other := &types.Header{Height: 100, ConsensusData: 1}
+	_, err = remote.Sign(&SignRequest{Type: SIGN_PROPOSAL, Data: other.ToArray()})
+	assert.NotNil(t, err)
+
+	value, proof, err := remote.Vrf([]byte("vrf data"))
+	assert.Nil(t, err)
+	ok, err := vrf.Verify(remote.PubKey(), []byte("vrf data"), value, proof)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	_, err = NewRemoteSigner(server.URL, []byte("fedcba9876543210fedcba9876543210"))
+	assert.NotNil(t, err)
+	_, err = NewRemoteSigner(server.URL, []byte("short"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common/log"
+)
+
+const MAX_REQUEST_SIZE = 1024 * 1024
+
+//Server serves the remote signer protocol by signer, which should be guarded by slashing rules
+type Server struct {
+	signer Signer
+	token  []byte
+}
+
+//NewServer return signer daemon handler
+func NewServer(signer Signer, token []byte) (*Server, error) {
+	if err := CheckToken(token); err != nil {
+		return nil, err
+	}
+	return &Server{signer: signer, token: token}, nil
+}
+
+func (this *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MAX_REQUEST_SIZE))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = checkAuth(r, this.token, body); err != nil {
+		log.Warnf("signer: unauthorized request from %s: %s", r.RemoteAddr, err)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	var rsp interface{}
+	switch r.URL.Path {
+	case SIGNER_PATH_PUBKEY:
+		rsp = &PubKeyRsp{PublicKey: hex.EncodeToString(keypair.SerializePublicKey(this.signer.PubKey()))}
+	case SIGNER_PATH_SIGN:
+		rsp, err = this.sign(body)
+	case SIGNER_PATH_VRF:
+		rsp, err = this.vrf(body)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path:%s", r.URL.Path))
+		return
+	}
+	if err != nil {
+		log.Warnf("signer: %s refused: %s", r.URL.Path, err)
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	data, _ := json.Marshal(rsp)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (this *Server) sign(body []byte) (*SignRsp, error) {
+	req := &SignReq{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, fmt.Errorf("invalid sign request:%s", err)
+	}
+	data, err := hex.DecodeString(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign data:%s", err)
+	}
+	sig, err := this.signer.Sign(&SignRequest{
+		Type:   req.Type,
+		Height: req.Height,
+		View:   req.View,
+		Data:   data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("signer: signed %s at height:%d view:%d", req.Type, req.Height, req.View)
+	return &SignRsp{Signature: hex.EncodeToString(sig)}, nil
+}
+
+func (this *Server) vrf(body []byte) (*VrfRsp, error) {
+	req := &VrfReq{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, fmt.Errorf("invalid vrf request:%s", err)
+	}
+	data, err := hex.DecodeString(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vrf data:%s", err)
+	}
+	value, proof, err := this.signer.Vrf(data)
+	if err != nil {
+		return nil, err
+	}
+	return &VrfRsp{Value: hex.EncodeToString(value), Proof: hex.EncodeToString(proof)}, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	data, _ := json.Marshal(&ErrorRsp{Error: err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package signer abstracts the signing of consensus messages, so that the private key of consensus peer
+//can be kept in a separate signer daemon instead of node memory.
+package signer
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/vrf"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+)
+
+//SignType is the kind of data to sign
+type SignType byte
+
+const (
+	SIGN_PROPOSAL    SignType = 1 //block proposed by the peer
+	SIGN_ENDORSE     SignType = 2 //block endorsed by the peer
+	SIGN_COMMIT      SignType = 3 //block committed by the peer
+	SIGN_CROSS_CHAIN SignType = 4 //cross chain message of block
+	SIGN_STATE_ROOT  SignType = 5 //state root of block
+	SIGN_MESSAGE     SignType = 6 //consensus message without slashing rule
+	SIGN_WITNESS     SignType = 7 //offline witness message of p2p subnet
+)
+
+func (this SignType) String() string {
+	switch this {
+	case SIGN_PROPOSAL:
+		return "proposal"
+	case SIGN_ENDORSE:
+		return "endorse"
+	case SIGN_COMMIT:
+		return "commit"
+	case SIGN_CROSS_CHAIN:
+		return "crosschain"
+	case SIGN_STATE_ROOT:
+		return "stateroot"
+	case SIGN_MESSAGE:
+		return "message"
+	case SIGN_WITNESS:
+		return "witness"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(this))
+	}
+}
+
+//SignRequest is the data to sign, with the height and view it belongs to.
+//Signer with slashing protection never signs different data of the same type, height and view.
+//The data of proposal, endorse and commit is the serialized block header, and the data of cross chain is the
+//serialized cross chain message. Their hashes and heights are derived by the signer, so Height is only used by
+//the other types. The data of witness is the unsigned offline witness message, whose sha256 hash is signed.
+type SignRequest struct {
+	Type   SignType
+	Height uint32
+	View   uint32
+	Data   []byte
+}
+
+//Digest return the height of request and the bytes to sign, which are the hash of block header or cross chain
+//message for the types carrying them
+func (this *SignRequest) Digest() (uint32, []byte, error) {
+	switch this.Type {
+	case SIGN_PROPOSAL, SIGN_ENDORSE, SIGN_COMMIT:
+		source := common.NewZeroCopySource(this.Data)
+		header := new(types.Header)
+		if err := header.Deserialization(source); err != nil || source.Len() != 0 {
+			return 0, nil, fmt.Errorf("invalid block header of %s", this.Type)
+		}
+		hash := header.Hash()
+		return header.Height, hash[:], nil
+	case SIGN_CROSS_CHAIN:
+		source := common.NewZeroCopySource(this.Data)
+		msg := new(types.CrossChainMsg)
+		if err := msg.Deserialization(source); err != nil || source.Len() != 0 {
+			return 0, nil, fmt.Errorf("invalid cross chain message")
+		}
+		hash := msg.Hash()
+		return msg.Height, hash[:], nil
+	case SIGN_WITNESS:
+		//the sha256 hash of a hash size data may be the hash of block header
+		if len(this.Data) == HASH_SIZE {
+			return 0, nil, fmt.Errorf("invalid witness message size:%d", len(this.Data))
+		}
+		hash := sha256.Sum256(this.Data)
+		return this.Height, hash[:], nil
+	default:
+		return this.Height, this.Data, nil
+	}
+}
+
+//Signer sign consensus data by the key of consensus peer
+type Signer interface {
+	//PubKey return the public key of signer
+	PubKey() keypair.PublicKey
+	//Sign return the signature of request data
+	Sign(req *SignRequest) ([]byte, error)
+	//Vrf return the vrf value and proof of data
+	Vrf(data []byte) ([]byte, []byte, error)
+}
+
+//LocalSigner sign with the account in node memory
+type LocalSigner struct {
+	account *account.Account
+}
+
+//NewLocalSigner return signer of the account
+func NewLocalSigner(acc *account.Account) *LocalSigner {
+	return &LocalSigner{account: acc}
+}
+
+//Account return the account of local signer
+func (this *LocalSigner) Account() *account.Account {
+	return this.account
+}
+
+func (this *LocalSigner) PubKey() keypair.PublicKey {
+	return this.account.PublicKey
+}
+
+func (this *LocalSigner) Sign(req *SignRequest) ([]byte, error) {
+	_, data, err := req.Digest()
+	if err != nil {
+		return nil, err
+	}
+	return signature.Sign(this.account, data)
+}
+
+func (this *LocalSigner) Vrf(data []byte) ([]byte, []byte, error) {
+	return vrf.Vrf(this.account.PrivateKey, data)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"sync"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	roundsMetric = prom.NewCounter(prom.CounterOpts{
+		Name: "ontology_vbft_rounds_total",
+		Help: "ontology vbft count of the consensus rounds started",
+	})
+
+	viewChangesMetric = prom.NewCounterVec(prom.CounterOpts{
+		Name: "ontology_vbft_view_changes_total",
+		Help: "ontology vbft count of the timeouts moving a round away from the leader proposal",
+	}, []string{"reason"})
+
+	emptyBlocksMetric = prom.NewCounter(prom.CounterOpts{
+		Name: "ontology_vbft_empty_blocks_total",
+		Help: "ontology vbft count of the empty blocks sealed",
+	})
+
+	proposalLatencyMetric = prom.NewHistogramVec(prom.HistogramOpts{
+		Name:    "ontology_vbft_proposal_seconds",
+		Help:    "ontology vbft proposal timings, make: making a proposal, receive: from round start to the leader proposal received, seal: from round start to the block sealed",
+		Buckets: prom.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"stage"})
+)
+
+//view change reasons of the timer events
+var viewChangeReasons = map[TimerEventType]string{
+	EventProposeBlockTimeout:      "proposal_timeout",
+	EventRandomBackoff:            "random_backoff",
+	EventPropose2ndBlockTimeout:   "2nd_proposal_timeout",
+	EventEndorseEmptyBlockTimeout: "empty_endorse_timeout",
+	EventCommitBlockTimeout:       "commit_timeout",
+}
+
+func init() {
+	prom.MustRegister(roundsMetric, viewChangesMetric, emptyBlocksMetric, proposalLatencyMetric)
+}
+
+func increaseViewChanges(evtType TimerEventType) {
+	if reason, present := viewChangeReasons[evtType]; present {
+		viewChangesMetric.WithLabelValues(reason).Inc()
+	}
+}
+
+func observeProposalLatency(stage string, duration time.Duration) {
+	proposalLatencyMetric.WithLabelValues(stage).Observe(duration.Seconds())
+}
+
+//roundTimer records the start time of the current round
+type roundTimer struct {
+	lock     sync.Mutex
+	blkNum   uint32
+	start    time.Time
+	received bool
+}
+
+func (self *roundTimer) startRound(blkNum uint32) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if blkNum == self.blkNum && !self.start.IsZero() {
+		return
+	}
+	self.blkNum = blkNum
+	self.start = time.Now()
+	self.received = false
+	roundsMetric.Inc()
+}
+
+//proposalReceived observes the receive latency of the first leader proposal of the round
+func (self *roundTimer) proposalReceived(blkNum uint32) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if blkNum != self.blkNum || self.start.IsZero() || self.received {
+		return
+	}
+	self.received = true
+	observeProposalLatency("receive", time.Since(self.start))
+}
+
+func (self *roundTimer) blockSealed(blkNum uint32, empty bool) {
+	if empty {
+		emptyBlocksMetric.Inc()
+	}
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if blkNum != self.blkNum || self.start.IsZero() {
+		return
+	}
+	observeProposalLatency("seal", time.Since(self.start))
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTimer(t *testing.T) {
+	round := &roundTimer{}
+	rounds := testutil.ToFloat64(roundsMetric)
+	round.startRound(10)
+	round.startRound(10)
+	assert.Equal(t, rounds+1, testutil.ToFloat64(roundsMetric))
+	round.startRound(11)
+	assert.Equal(t, rounds+2, testutil.ToFloat64(roundsMetric))
+
+	round.proposalReceived(11)
+	assert.True(t, round.received)
+
+	empty := testutil.ToFloat64(emptyBlocksMetric)
+	round.blockSealed(11, false)
+	round.blockSealed(12, true)
+	assert.Equal(t, empty+1, testutil.ToFloat64(emptyBlocksMetric))
+}
+
+func TestIncreaseViewChanges(t *testing.T) {
+	backoff := viewChangesMetric.WithLabelValues(viewChangeReasons[EventRandomBackoff])
+	count := testutil.ToFloat64(backoff)
+	increaseViewChanges(EventTxPool)
+	increaseViewChanges(EventRandomBackoff)
+	assert.Equal(t, count+1, testutil.ToFloat64(backoff))
+}
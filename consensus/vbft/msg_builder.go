@@ -26,9 +26,9 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/core/ledger"
-	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/types"
 )
 
@@ -184,7 +184,7 @@ func (self *Server) constructHeartbeatMsg() (*peerHeartbeatMsg, error) {
 	return msg, nil
 }
 
-func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, txs []*types.Transaction, consensusPayload []byte, blocktimestamp uint32) (*types.Block, error) {
+func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, txs []*types.Transaction, consensusPayload []byte, blocktimestamp uint32, forEmpty bool) (*types.Block, error) {
 	txHash := []common.Uint256{}
 	for _, t := range txs {
 		txHash = append(txHash, t.Hash())
@@ -212,16 +212,37 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 		Transactions: txs,
 	}
 	blkHash := blk.Hash()
-	sig, err := signature.Sign(self.account, blkHash[:])
+	sig, err := self.signer.Sign(&signer.SignRequest{
+		Type: signer.SIGN_PROPOSAL,
+		View: blockView(forEmpty),
+		Data: blkHeader.ToArray(),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("sign block failed, block hash:%s, error: %s", blkHash.ToHexString(), err)
 	}
-	blkHeader.Bookkeepers = []keypair.PublicKey{self.account.PublicKey}
+	blkHeader.Bookkeepers = []keypair.PublicKey{self.signer.PubKey()}
 	blkHeader.SigData = [][]byte{sig}
 
 	return blk, nil
 }
 
+//blockView is the sign view of block, the normal block and empty block of same height are signed in different views
+func blockView(forEmpty bool) uint32 {
+	if forEmpty {
+		return 1
+	}
+	return 0
+}
+
+func (self *Server) signCrossChainMsg(msg *types.CrossChainMsg) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	msg.Serialization(sink)
+	return self.signer.Sign(&signer.SignRequest{
+		Type: signer.SIGN_CROSS_CHAIN,
+		Data: sink.Bytes(),
+	})
+}
+
 func (self *Server) constructCrossChainMsg(blkNum uint32) (*types.CrossChainMsg, error) {
 	root, err := self.blockPool.getCrossStatesRoot(blkNum)
 	if err != nil {
@@ -237,7 +258,7 @@ func (self *Server) constructCrossChainMsg(blkNum uint32) (*types.CrossChainMsg,
 		StatesRoot: root,
 	}
 	hash := msg.Hash()
-	sig, err := signature.Sign(self.account, hash[:])
+	sig, err := self.signCrossChainMsg(msg)
 	if err != nil {
 		return nil, fmt.Errorf("sign cross chain msg root failed,msg hash:%s,err:%s", hash.ToHexString(), err)
 	}
@@ -256,7 +277,7 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 		blocktimestamp = prevBlk.Block.Header.Timestamp + 1
 	}
 
-	vrfValue, vrfProof, err := computeVrf(self.account.PrivateKey, blkNum, prevBlk.getVrfValue())
+	vrfValue, vrfProof, err := computeVrf(self.signer, blkNum, prevBlk.getVrfValue())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vrf and proof: %s", err)
 	}
@@ -280,11 +301,11 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 		return nil, err
 	}
 
-	emptyBlk, err := self.constructBlock(blkNum, prevBlkHash, sysTxs, consensusPayload, blocktimestamp)
+	emptyBlk, err := self.constructBlock(blkNum, prevBlkHash, sysTxs, consensusPayload, blocktimestamp, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct empty block: %s", err)
 	}
-	blk, err := self.constructBlock(blkNum, prevBlkHash, append(sysTxs, userTxs...), consensusPayload, blocktimestamp)
+	blk, err := self.constructBlock(blkNum, prevBlkHash, append(sysTxs, userTxs...), consensusPayload, blocktimestamp, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to constuct blk: %s", err)
 	}
@@ -314,9 +335,11 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 
 	var proposerSig, endorserSig []byte
 	var blkHash common.Uint256
+	var header *types.Header
 	var err error
 	if !forEmpty {
 		proposerSig = proposal.BlockProposerSig
+		header = proposal.Block.Block.Header
 		blkHash = proposal.Block.Block.Hash()
 
 	} else {
@@ -326,9 +349,14 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 		}
 
 		proposerSig = proposal.EmptyBlockProposerSig
+		header = proposal.Block.EmptyBlock.Header
 		blkHash = proposal.Block.EmptyBlock.Hash()
 	}
-	endorserSig, err = signature.Sign(self.account, blkHash[:])
+	endorserSig, err = self.signer.Sign(&signer.SignRequest{
+		Type: signer.SIGN_ENDORSE,
+		View: blockView(forEmpty),
+		Data: header.ToArray(),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, err: %s", blkHash, err)
 	}
@@ -344,7 +372,7 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 	}
 	if proposal.Block.CrossChainMsg != nil {
 		hash := proposal.Block.CrossChainMsg.Hash()
-		sig, err := signature.Sign(self.account, hash[:])
+		sig, err := self.signCrossChainMsg(proposal.Block.CrossChainMsg)
 		if err != nil {
 			return nil, fmt.Errorf("sign cross chain msg root failed,msg hash:%s,err:%s", hash.ToHexString(), err)
 		}
@@ -360,10 +388,12 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 
 	var proposerSig, committerSig []byte
 	var blkHash common.Uint256
+	var header *types.Header
 	var err error
 
 	if !forEmpty {
 		proposerSig = proposal.BlockProposerSig
+		header = proposal.Block.Block.Header
 		blkHash = proposal.Block.Block.Hash()
 	} else {
 		if proposal.Block.EmptyBlock == nil {
@@ -372,9 +402,14 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 		}
 
 		proposerSig = proposal.EmptyBlockProposerSig
+		header = proposal.Block.EmptyBlock.Header
 		blkHash = proposal.Block.EmptyBlock.Hash()
 	}
-	committerSig, err = signature.Sign(self.account, blkHash[:])
+	committerSig, err = self.signer.Sign(&signer.SignRequest{
+		Type: signer.SIGN_COMMIT,
+		View: blockView(forEmpty),
+		Data: header.ToArray(),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, caused by: %s", blkHash, err)
 	}
@@ -412,7 +447,7 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 	}
 
 	if proposal.Block.CrossChainMsg != nil && commitCrossChain {
-		sig, err := signature.Sign(self.account, hash[:])
+		sig, err := self.signCrossChainMsg(proposal.Block.CrossChainMsg)
 		if err != nil {
 			return nil, fmt.Errorf("sign cross chain msg root failed,msg hash:%s,err:%s", hash.ToHexString(), err)
 		}
@@ -455,7 +490,11 @@ func (self *Server) constructProposalFetchMsg(blkNum uint32, proposer uint32) *p
 }
 
 func (self *Server) constructBlockSubmitMsg(blkNum uint32, stateRoot common.Uint256) (*blockSubmitMsg, error) {
-	submitSig, err := signature.Sign(self.account, stateRoot[:])
+	submitSig, err := self.signer.Sign(&signer.SignRequest{
+		Type:   signer.SIGN_STATE_ROOT,
+		Height: blkNum,
+		Data:   stateRoot[:],
+	})
 	if err != nil {
 		return nil, fmt.Errorf("submit failed to sign stateroot hash:%x, err: %s", stateRoot, err)
 	}
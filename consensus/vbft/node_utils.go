@@ -25,8 +25,8 @@ import (
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
-	"github.com/ontio/ontology/core/signature"
 	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
 	p2pmsg "github.com/ontio/ontology/p2pserver/message/types"
 )
@@ -452,12 +452,12 @@ func (self *Server) sendToPeer(peerIdx uint32, data []byte) error {
 	}
 	msg := &p2pmsg.ConsensusPayload{
 		Data:  data,
-		Owner: self.account.PublicKey,
+		Owner: self.signer.PubKey(),
 	}
 
 	sink := common.NewZeroCopySink(nil)
 	msg.SerializationUnsigned(sink)
-	msg.Signature, _ = signature.Sign(self.account, sink.Bytes())
+	msg.Signature, _ = self.signer.Sign(&signer.SignRequest{Type: signer.SIGN_MESSAGE, Data: sink.Bytes()})
 
 	cons := msgpack.NewConsensus(msg)
 	p2pid, present := self.peerPool.getP2pId(peerIdx)
@@ -479,12 +479,12 @@ func (self *Server) broadcast(msg ConsensusMsg) {
 func (self *Server) broadcastToAll(data []byte) {
 	payload := &p2pmsg.ConsensusPayload{
 		Data:  data,
-		Owner: self.account.PublicKey,
+		Owner: self.signer.PubKey(),
 	}
 
 	sink := common.NewZeroCopySink(nil)
 	payload.SerializationUnsigned(sink)
-	payload.Signature, _ = signature.Sign(self.account, sink.Bytes())
+	payload.Signature, _ = self.signer.Sign(&signer.SignRequest{Type: signer.SIGN_MESSAGE, Data: sink.Bytes()})
 
 	msg := msgpack.NewConsensus(payload)
 	go self.p2p.Broadcast(msg)
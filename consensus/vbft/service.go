@@ -29,10 +29,10 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-crypto/vrf"
 	"github.com/ontio/ontology-eventbus/actor"
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
 	actorTypes "github.com/ontio/ontology/consensus/actor"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/core/ledger"
 	"github.com/ontio/ontology/core/payload"
@@ -89,7 +89,7 @@ type p2pMsgPayload struct {
 
 type Server struct {
 	Index         uint32
-	account       *account.Account
+	signer        signer.Signer
 	poolActor     *actorTypes.TxPoolActor
 	p2p           p2p.P2P
 	ledger        *ledger.Ledger
@@ -119,6 +119,8 @@ type Server struct {
 	syncer     *Syncer
 	stateMgr   *StateMgr
 	timer      *EventTimer
+	round      roundTimer
+	timeline   *Timeline
 
 	msgRecvC   *sync.Map // map[uint32]chan *p2pMsgPayload
 	msgC       chan ConsensusMsg
@@ -130,14 +132,15 @@ type Server struct {
 	quitWg     sync.WaitGroup
 }
 
-func NewVbftServer(account *account.Account, txpool *actor.PID, p2p p2p.P2P) (*Server, error) {
+func NewVbftServer(sig signer.Signer, txpool *actor.PID, p2p p2p.P2P) (*Server, error) {
 	server := &Server{
 		msgHistoryDuration: 64,
-		account:            account,
+		signer:             sig,
 		poolActor:          &actorTypes.TxPoolActor{Pool: txpool},
 		p2p:                p2p,
 		ledger:             ledger.DefLedger,
 		incrValidator:      increment.NewIncrementValidator(20),
+		timeline:           newTimeline(publishTimelineEvent),
 	}
 	server.stateMgr = newStateMgr(server)
 
@@ -364,7 +367,7 @@ func (self *Server) updateChainConfig() error {
 	// . reset remove peer connections, create new connections with new peers
 	self.updateTimerParams(self.config)
 
-	pubkey := vconfig.PubkeyID(self.account.PublicKey)
+	pubkey := vconfig.PubkeyID(self.signer.PubKey())
 	peermap := make(map[uint32]string)
 	for _, p := range self.GetChainConfig().Peers {
 		peermap[p.Index] = p.ID
@@ -423,7 +426,7 @@ func (self *Server) initialize() error {
 	// TODO: load config from chain
 
 	// TODO: configurable log
-	selfNodeId := vconfig.PubkeyID(self.account.PublicKey)
+	selfNodeId := vconfig.PubkeyID(self.signer.PubKey())
 	log.Infof("server: %s starting", selfNodeId)
 
 	store, err := OpenBlockStore(self.ledger, self.pid)
@@ -472,7 +475,7 @@ func (self *Server) initialize() error {
 	}
 
 	//index equal math.MaxUint32  is noconsensus node
-	id := vconfig.PubkeyID(self.account.PublicKey)
+	id := vconfig.PubkeyID(self.signer.PubKey())
 	index, present := self.peerPool.GetPeerIndex(id)
 	if present {
 		self.Index = index
@@ -512,7 +515,7 @@ func (self *Server) initialize() error {
 
 func (self *Server) start() error {
 	// check if server pubkey support VRF
-	if !vrf.ValidatePrivateKey(self.account.PrivateKey) || !vrf.ValidatePublicKey(self.account.PublicKey) {
+	if !vrf.ValidatePublicKey(self.signer.PubKey()) {
 		return fmt.Errorf("server %d consensus start failed: invalid account key for VRF", self.Index)
 	}
 
@@ -659,6 +662,8 @@ func (self *Server) updateParticipantConfig() error {
 
 func (self *Server) startNewRound() error {
 	blkNum := self.GetCurrentBlockNo()
+	self.round.startRound(blkNum)
+	self.timeline.record(blkNum, TimelineRoundStart, self.Index, "")
 
 	if err := self.updateParticipantConfig(); err != nil {
 		log.Errorf("startNewRound error:%s", err)
@@ -1168,6 +1173,9 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 		log.Errorf("verify cross chain message error:%+v\n", msg.Block.CrossChainMsg)
 		return
 	}
+	if self.isProposer(msgBlkNum, msg.Block.getProposer()) {
+		self.round.proposalReceived(msgBlkNum)
+	}
 	txs := msg.Block.Block.Transactions
 	if len(txs) > 0 && self.nonSystxs(txs, msgBlkNum) {
 		height := msgBlkNum - 1
@@ -1232,6 +1240,9 @@ func (self *Server) processMsgEvent() error {
 					log.Errorf("failed to add block proposal (%d): %s", msgBlkNum, err)
 					return nil
 				}
+				self.timeline.record(msgBlkNum, TimelineProposal, pMsg.Block.getProposer(),
+					fmt.Sprintf("leader %t, txs %d", self.isProposer(msgBlkNum, pMsg.Block.getProposer()),
+						len(pMsg.Block.Block.Transactions)))
 
 				if self.isProposer(msgBlkNum, pMsg.Block.getProposer()) {
 					// check if agreed on prev-blockhash
@@ -1280,6 +1291,8 @@ func (self *Server) processMsgEvent() error {
 				self.blockPool.newBlockEndorsement(pMsg)
 				log.Infof("server %d received endorse from %d, for proposer %d, block %d, empty: %t",
 					self.Index, pMsg.Endorser, pMsg.EndorsedProposer, msgBlkNum, pMsg.EndorseForEmpty)
+				self.timeline.record(msgBlkNum, TimelineEndorse, pMsg.Endorser,
+					fmt.Sprintf("proposer %d, empty %t", pMsg.EndorsedProposer, pMsg.EndorseForEmpty))
 
 				// if had committed for current round, skip the following steps
 				if self.blockPool.committedForBlock(msgBlkNum) {
@@ -1350,6 +1363,8 @@ func (self *Server) processMsgEvent() error {
 
 				log.Infof("server %d received commit from %d, for proposer %d, block %d, empty: %t",
 					self.Index, pMsg.Committer, pMsg.BlockProposer, msgBlkNum, pMsg.CommitForEmpty)
+				self.timeline.record(msgBlkNum, TimelineCommit, pMsg.Committer,
+					fmt.Sprintf("proposer %d, empty %t", pMsg.BlockProposer, pMsg.CommitForEmpty))
 
 				chainCfg := self.GetChainConfig()
 				if proposer, forEmpty, done := self.blockPool.commitDone(msgBlkNum, chainCfg.C, chainCfg.N); done {
@@ -1665,6 +1680,7 @@ func (self *Server) timerLoop() {
 }
 
 func (self *Server) processTimerEvent(evt *TimerEvent) error {
+	self.recordTimeout(evt)
 	switch evt.evtType {
 	case EventProposalBackoff:
 		// 1. if endorsed, return
@@ -1802,6 +1818,7 @@ func (self *Server) processTimerEvent(evt *TimerEvent) error {
 			return nil
 		} else {
 			log.Errorf("server %d: empty endorse timeout, no quorum", self.Index)
+			self.recordViewChange(evt)
 			if !isActive(self.getState()) {
 				proposals := self.blockPool.getBlockProposals(evt.blockNum)
 				proposal := self.getHighestRankProposal(evt.blockNum, proposals)
@@ -1846,6 +1863,7 @@ func (self *Server) processTimerEvent(evt *TimerEvent) error {
 				return nil
 			} else {
 				log.Errorf("server %d commit blk %d timeout without consensus", self.Index, evt.blockNum)
+				self.recordViewChange(evt)
 				self.restartSyncing()
 			}
 		}
@@ -1899,6 +1917,7 @@ func (self *Server) processHandshakeMsg(peerIdx uint32, msg *peerHandshakeMsg) e
 
 func (self *Server) processHeartbeatMsg(peerIdx uint32, msg *peerHeartbeatMsg) {
 	self.peerPool.peerHeartbeat(peerIdx, msg)
+	self.timeline.heartbeat(self.GetCurrentBlockNo(), peerIdx, msg)
 	log.Debugf("server %d received heartbeat from peer %d, chainview %d, blkNum %d",
 		self.Index, peerIdx, msg.ChainConfigView, msg.CommittedBlockNumber)
 	self.stateMgr.StateEventC <- &StateEvent{
@@ -2062,6 +2081,7 @@ func (self *Server) fastForwardBlock(block *Block) error {
 		return nil
 	}
 	if self.GetCurrentBlockNo() == block.getBlockNum() {
+		self.timeline.record(block.getBlockNum(), TimelineFastForward, block.getProposer(), "")
 		// block from peer syncer, there should only one candidate block
 		flag := false
 		if len(block.Block.Header.SigData) <= 1 {
@@ -2095,8 +2115,11 @@ func (self *Server) sealBlock(block *Block, empty bool, sigdata bool) error {
 	self.timer.onBlockSealed(sealedBlkNum)
 	self.msgPool.onBlockSealed(sealedBlkNum)
 	self.blockPool.onBlockSealed(sealedBlkNum)
+	self.round.blockSealed(sealedBlkNum, empty)
 
 	_, h := self.blockPool.getSealedBlock(sealedBlkNum)
+	self.timeline.record(sealedBlkNum, TimelineSealed, block.getProposer(),
+		fmt.Sprintf("empty %t, hash %s", empty, h.ToHexString()))
 	prevBlkHash := block.getPrevBlockHash()
 	log.Infof("server %d, sealed block %d, proposer %d, prevhash: %s, hash: %s", self.Index,
 		sealedBlkNum, block.getProposer(), prevBlkHash.ToHexString(), h.ToHexString())
@@ -2206,6 +2229,10 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 		return fmt.Errorf("server %d ignore deprecatd blk proposal %d, current %d",
 			self.Index, blkNum, self.GetCurrentBlockNo())
 	}
+	start := time.Now()
+	defer func() {
+		observeProposalLatency("make", time.Since(start))
+	}()
 
 	validHeight := self.validHeight(blkNum)
 	sysTxs := make([]*types.Transaction, 0)
@@ -2329,6 +2356,7 @@ func (self *Server) handleProposalTimeout(evt *TimerEvent) error {
 		return nil
 	}
 	proposals := self.blockPool.getBlockProposals(evt.blockNum)
+	self.recordViewChange(evt)
 
 	log.Infof("server %d proposal timeout, known proposals %d, timeout: %d", self.Index, len(proposals), evt.evtType)
 
@@ -2392,6 +2420,7 @@ func (self *Server) catchConsensus(blkNum uint32) error {
 	if !self.isEndorser(blkNum, self.Index) && !self.isCommitter(blkNum, self.Index) {
 		return nil
 	}
+	self.timeline.record(blkNum, TimelineCatchUp, self.Index, "")
 
 	proposals := make(map[uint32]*blockProposalMsg)
 	pMsgs := self.msgPool.GetProposalMsgs(blkNum)
@@ -0,0 +1,289 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/events"
+	"github.com/ontio/ontology/events/message"
+)
+
+//types of the timeline events
+const (
+	TimelineRoundStart  = "round"
+	TimelineProposal    = "proposal"
+	TimelineEndorse     = "endorse"
+	TimelineCommit      = "commit"
+	TimelineSealed      = "sealed"
+	TimelineTimeout     = "timeout"
+	TimelineViewChange  = "viewchange"
+	TimelineCatchUp     = "catchup"
+	TimelineFastForward = "fastforward"
+	TimelineHeartbeat   = "heartbeat"
+)
+
+const (
+	MAX_TIMELINE_BLOCKS = 256  //max blocks kept in the timeline history
+	MAX_TIMELINE_EVENTS = 1024 //max events kept of a block
+)
+
+//names of the timeout timer events
+var timeoutEventNames = map[TimerEventType]string{
+	EventProposeBlockTimeout:      "proposal_timeout",
+	EventProposalBackoff:          "proposal_backoff",
+	EventRandomBackoff:            "random_backoff",
+	EventPropose2ndBlockTimeout:   "2nd_proposal_timeout",
+	EventEndorseBlockTimeout:      "endorse_timeout",
+	EventEndorseEmptyBlockTimeout: "empty_endorse_timeout",
+	EventCommitBlockTimeout:       "commit_timeout",
+	EventTxBlockTimeout:           "tx_block_timeout",
+}
+
+var serverStateNames = map[ServerState]string{
+	Init:             "Init",
+	LocalConfigured:  "LocalConfigured",
+	Configured:       "Configured",
+	Syncing:          "Syncing",
+	WaitNetworkReady: "WaitNetworkReady",
+	SyncReady:        "SyncReady",
+	Synced:           "Synced",
+	SyncingCheck:     "SyncingCheck",
+}
+
+//TimelineEvent is a consensus event of a block round
+type TimelineEvent struct {
+	Time     time.Time
+	BlockNum uint32
+	Type     string
+	Peer     uint32 //peer index of the event, or index of the local server
+	Detail   string `json:",omitempty"`
+}
+
+//BlockTimeline is the consensus events of a block in order
+type BlockTimeline struct {
+	BlockNum uint32
+	Events   []*TimelineEvent
+}
+
+//Timeline records the per-block consensus events of the recent blocks
+type Timeline struct {
+	lock        sync.RWMutex
+	blocks      map[uint32]*BlockTimeline
+	peerHeights map[uint32]uint32
+	notify      func(evt *TimelineEvent)
+}
+
+func newTimeline(notify func(evt *TimelineEvent)) *Timeline {
+	return &Timeline{
+		blocks:      make(map[uint32]*BlockTimeline),
+		peerHeights: make(map[uint32]uint32),
+		notify:      notify,
+	}
+}
+
+func (self *Timeline) record(blkNum uint32, evtType string, peer uint32, detail string) {
+	if self == nil {
+		return
+	}
+	evt := &TimelineEvent{
+		Time:     time.Now(),
+		BlockNum: blkNum,
+		Type:     evtType,
+		Peer:     peer,
+		Detail:   detail,
+	}
+
+	self.lock.Lock()
+	blk, present := self.blocks[blkNum]
+	if !present {
+		blk = &BlockTimeline{BlockNum: blkNum}
+		self.blocks[blkNum] = blk
+		self.evict()
+	}
+	if len(blk.Events) < MAX_TIMELINE_EVENTS {
+		blk.Events = append(blk.Events, evt)
+	}
+	self.lock.Unlock()
+
+	if self.notify != nil {
+		self.notify(evt)
+	}
+}
+
+//evict drop the oldest blocks beyond MAX_TIMELINE_BLOCKS, called with lock held
+func (self *Timeline) evict() {
+	for len(self.blocks) > MAX_TIMELINE_BLOCKS {
+		oldest := ^uint32(0)
+		for blkNum := range self.blocks {
+			if blkNum < oldest {
+				oldest = blkNum
+			}
+		}
+		delete(self.blocks, oldest)
+	}
+}
+
+//heartbeat record the peer heartbeat only if the committed height of the peer changed
+func (self *Timeline) heartbeat(blkNum uint32, peer uint32, msg *peerHeartbeatMsg) {
+	if self == nil {
+		return
+	}
+	self.lock.Lock()
+	height, present := self.peerHeights[peer]
+	self.peerHeights[peer] = msg.CommittedBlockNumber
+	self.lock.Unlock()
+	if present && height == msg.CommittedBlockNumber {
+		return
+	}
+	self.record(blkNum, TimelineHeartbeat, peer, fmt.Sprintf("committed %d, chain view %d",
+		msg.CommittedBlockNumber, msg.ChainConfigView))
+}
+
+//get return a copy of the timeline of the block
+func (self *Timeline) get(blkNum uint32) *BlockTimeline {
+	if self == nil {
+		return nil
+	}
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	blk, present := self.blocks[blkNum]
+	if !present {
+		return nil
+	}
+	return &BlockTimeline{
+		BlockNum: blk.BlockNum,
+		Events:   append([]*TimelineEvent{}, blk.Events...),
+	}
+}
+
+//publishTimelineEvent publish the timeline event to the event bus
+func publishTimelineEvent(evt *TimelineEvent) {
+	if events.DefActorPublisher != nil {
+		events.DefActorPublisher.Publish(message.TOPIC_CONSENSUS_EVENT, &message.ConsensusEventMsg{Event: evt})
+	}
+}
+
+func (self *Server) recordTimeout(evt *TimerEvent) {
+	if name, present := timeoutEventNames[evt.evtType]; present {
+		self.timeline.record(evt.blockNum, TimelineTimeout, self.Index, name)
+	}
+}
+
+func (self *Server) recordViewChange(evt *TimerEvent) {
+	increaseViewChanges(evt.evtType)
+	self.timeline.record(evt.blockNum, TimelineViewChange, self.Index, timeoutEventNames[evt.evtType])
+}
+
+//ConsensusPeer is the consensus status of a peer
+type ConsensusPeer struct {
+	Index             uint32
+	PubKey            string
+	Positions         []uint32 //positions in the pos table of chain config
+	Connected         bool
+	CommittedBlockNum uint32 //last committed block num seen from the peer
+	ChainConfigView   uint32
+	LastUpdateTime    time.Time
+}
+
+//ConsensusState is the status of vbft consensus
+type ConsensusState struct {
+	Index              uint32
+	State              string
+	CurrentBlockNum    uint32
+	CommittedBlockNum  uint32
+	CompletedBlockNum  uint32
+	LastConfigBlockNum uint32
+	ChainConfig        *vconfig.ChainConfig
+	Peers              []*ConsensusPeer
+	Timeline           *BlockTimeline
+}
+
+//GetConsensusState return the status of the server, the peers and the timeline of current block
+func (self *Server) GetConsensusState() *ConsensusState {
+	self.metaLock.RLock()
+	lastConfigBlkNum := self.LastConfigBlockNum
+	self.metaLock.RUnlock()
+	chainCfg := self.GetChainConfig()
+	currentBlkNum := self.GetCurrentBlockNo()
+	state := &ConsensusState{
+		Index:              self.Index,
+		State:              serverStateNames[self.getState()],
+		CurrentBlockNum:    currentBlkNum,
+		CommittedBlockNum:  self.GetCommittedBlockNo(),
+		CompletedBlockNum:  self.GetCompletedBlockNum(),
+		LastConfigBlockNum: lastConfigBlkNum,
+		ChainConfig:        &chainCfg,
+		Peers:              self.peerPool.getConsensusPeers(chainCfg.PosTable),
+		Timeline:           self.timeline.get(currentBlkNum),
+	}
+	for _, peer := range state.Peers {
+		if peer.Index == self.Index {
+			peer.Connected = true
+			peer.CommittedBlockNum = state.CommittedBlockNum
+			peer.ChainConfigView = chainCfg.View
+		}
+	}
+	return state
+}
+
+//GetConsensusHistory return the recorded timeline of the block
+func (self *Server) GetConsensusHistory(blkNum uint32) (*BlockTimeline, error) {
+	blk := self.timeline.get(blkNum)
+	if blk == nil {
+		return nil, fmt.Errorf("timeline of block %d not found", blkNum)
+	}
+	return blk, nil
+}
+
+func (pool *PeerPool) getConsensusPeers(posTable []uint32) []*ConsensusPeer {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	peers := make([]*ConsensusPeer, 0, len(pool.configs))
+	for idx, cfg := range pool.configs {
+		peer := &ConsensusPeer{
+			Index:     idx,
+			PubKey:    cfg.ID,
+			Positions: []uint32{},
+		}
+		for pos, peerIdx := range posTable {
+			if peerIdx == idx {
+				peer.Positions = append(peer.Positions, uint32(pos))
+			}
+		}
+		if p, present := pool.peers[idx]; present {
+			peer.Connected = p.connected
+			peer.LastUpdateTime = p.LastUpdateTime
+			if p.LatestInfo != nil {
+				peer.CommittedBlockNum = p.LatestInfo.CommittedBlockNumber
+				peer.ChainConfigView = p.LatestInfo.ChainConfigView
+			}
+		}
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Index < peers[j].Index
+	})
+	return peers
+}
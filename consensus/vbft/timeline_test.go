@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"testing"
+
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimelineRecord(t *testing.T) {
+	var notified []*TimelineEvent
+	timeline := newTimeline(func(evt *TimelineEvent) {
+		notified = append(notified, evt)
+	})
+	timeline.record(10, TimelineRoundStart, 1, "")
+	timeline.record(10, TimelineProposal, 2, "leader true, txs 0")
+	timeline.record(11, TimelineRoundStart, 1, "")
+	assert.Equal(t, 3, len(notified))
+
+	blk := timeline.get(10)
+	assert.Equal(t, uint32(10), blk.BlockNum)
+	assert.Equal(t, 2, len(blk.Events))
+	assert.Equal(t, TimelineProposal, blk.Events[1].Type)
+	assert.Equal(t, uint32(2), blk.Events[1].Peer)
+	assert.Nil(t, timeline.get(12))
+
+	for i := uint32(0); i < MAX_TIMELINE_BLOCKS; i++ {
+		timeline.record(100+i, TimelineRoundStart, 1, "")
+	}
+	assert.Equal(t, MAX_TIMELINE_BLOCKS, len(timeline.blocks))
+	assert.Nil(t, timeline.get(10))
+	assert.Nil(t, timeline.get(11))
+	assert.NotNil(t, timeline.get(100))
+
+	var empty *Timeline
+	empty.record(1, TimelineRoundStart, 1, "")
+	assert.Nil(t, empty.get(1))
+}
+
+func TestTimelineHeartbeat(t *testing.T) {
+	timeline := newTimeline(nil)
+	timeline.heartbeat(10, 2, &peerHeartbeatMsg{CommittedBlockNumber: 8})
+	timeline.heartbeat(10, 2, &peerHeartbeatMsg{CommittedBlockNumber: 8})
+	timeline.heartbeat(10, 3, &peerHeartbeatMsg{CommittedBlockNumber: 8})
+	timeline.heartbeat(10, 2, &peerHeartbeatMsg{CommittedBlockNumber: 9})
+	blk := timeline.get(10)
+	assert.Equal(t, 3, len(blk.Events))
+	assert.Equal(t, "committed 9, chain view 0", blk.Events[2].Detail)
+}
+
+func TestGetConsensusPeers(t *testing.T) {
+	peerpool := constructPeerPool(true)
+	peerpool.addPeer(&vconfig.PeerConfig{
+		Index: 1,
+		ID:    "120202c924ed1a67fd1719020ce599d723d09d48362376836e04b0be72dfe825e24d81",
+	})
+	peerpool.addPeer(&vconfig.PeerConfig{
+		Index: 2,
+		ID:    "1202021401156f187ec23ce631a489c3fa17f292171009c6c3162ef642406d3d09c74d",
+	})
+	peerpool.peerHeartbeat(2, &peerHeartbeatMsg{CommittedBlockNumber: 5, ChainConfigView: 1})
+
+	peers := peerpool.getConsensusPeers([]uint32{2, 1, 2})
+	assert.Equal(t, 2, len(peers))
+	assert.Equal(t, uint32(1), peers[0].Index)
+	assert.Equal(t, []uint32{1}, peers[0].Positions)
+	assert.False(t, peers[0].Connected)
+	assert.Equal(t, []uint32{0, 2}, peers[1].Positions)
+	assert.True(t, peers[1].Connected)
+	assert.Equal(t, uint32(5), peers[1].CommittedBlockNum)
+	assert.Equal(t, uint32(1), peers[1].ChainConfigView)
+}
@@ -27,12 +27,11 @@ import (
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-crypto/vrf"
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/core/ledger"
-	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/states"
 	scommon "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/store/overlaydb"
@@ -40,14 +39,14 @@ import (
 	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
 )
 
-func SignMsg(account *account.Account, msg ConsensusMsg) ([]byte, error) {
+func SignMsg(sig signer.Signer, msg ConsensusMsg) ([]byte, error) {
 
 	data, err := msg.Serialize()
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal msg when signing: %s", err)
 	}
 
-	return signature.Sign(account, data)
+	return sig.Sign(&signer.SignRequest{Type: signer.SIGN_MESSAGE, Data: data})
 }
 
 func hashData(data []byte) common.Uint256 {
@@ -95,7 +94,7 @@ type vrfData struct {
 	PrevVrf  []byte `json:"prev_vrf"`
 }
 
-func computeVrf(sk keypair.PrivateKey, blkNum uint32, prevVrf []byte) ([]byte, []byte, error) {
+func computeVrf(sig signer.Signer, blkNum uint32, prevVrf []byte) ([]byte, []byte, error) {
 	data, err := json.Marshal(&vrfData{
 		BlockNum: blkNum,
 		PrevVrf:  prevVrf,
@@ -104,7 +103,7 @@ func computeVrf(sk keypair.PrivateKey, blkNum uint32, prevVrf []byte) ([]byte, [
 		return nil, nil, fmt.Errorf("computeVrf failed to marshal vrfData: %s", err)
 	}
 
-	return vrf.Vrf(sk, data)
+	return sig.Vrf(data)
 }
 
 func verifyVrf(pk keypair.PublicKey, blkNum uint32, prevVrf, newVrf, proof []byte) error {
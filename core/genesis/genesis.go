@@ -61,6 +61,13 @@ var GenesisBookkeepers []keypair.PublicKey
 
 // BuildGenesisBlock returns the genesis block with default consensus bookkeeper list
 func BuildGenesisBlock(defaultBookkeeper []keypair.PublicKey, genesisConfig *config.GenesisConfig) (*types.Block, error) {
+	return BuildGenesisBlockWithOng(defaultBookkeeper, genesisConfig, nil)
+}
+
+// BuildGenesisBlockWithOng returns the genesis block which allocates ONG to the receivers of ongs at ONG init, the
+// allocated ONG is taken from the ONT contract. It is used by private chains which have no unbound ONG for holders
+func BuildGenesisBlockWithOng(defaultBookkeeper []keypair.PublicKey, genesisConfig *config.GenesisConfig,
+	ongs []*ont.State) (*types.Block, error) {
 	//getBookkeeper
 	GenesisBookkeepers = defaultBookkeeper
 	nextBookkeeper, err := types.AddressFromBookkeepers(defaultBookkeeper)
@@ -112,7 +119,7 @@ func BuildGenesisBlock(defaultBookkeeper []keypair.PublicKey, genesisConfig *con
 			auth,
 			govConfigTx,
 			newGoverningInit(),
-			newUtilityInit(),
+			newUtilityInit(ongs),
 			newParamInit(),
 			govConfig,
 		},
@@ -235,8 +242,17 @@ func newGoverningInit() *types.Transaction {
 	return tx
 }
 
-func newUtilityInit() *types.Transaction {
-	mutable := utils.BuildNativeTransaction(nutils.OngContractAddress, ont.INIT_NAME, []byte{})
+func newUtilityInit(ongs []*ont.State) *types.Transaction {
+	args := common.NewZeroCopySink(nil)
+	if len(ongs) > 0 {
+		nutils.EncodeVarUint(args, uint64(len(ongs)))
+		for _, state := range ongs {
+			nutils.EncodeAddress(args, state.To)
+			nutils.EncodeVarUint(args, state.Value)
+		}
+	}
+
+	mutable := utils.BuildNativeTransaction(nutils.OngContractAddress, ont.INIT_NAME, args.Bytes())
 	tx, err := mutable.IntoImmutable()
 	if err != nil {
 		panic("construct genesis utility token transaction error ")
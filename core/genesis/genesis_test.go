@@ -25,6 +25,9 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/utils"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +52,13 @@ func TestNewParamDeployAndInit(t *testing.T) {
 	assert.NotNil(t, deployTx)
 	assert.NotNil(t, initTx)
 }
+
+func TestNewUtilityInit(t *testing.T) {
+	mutable := utils.BuildNativeTransaction(nutils.OngContractAddress, ont.INIT_NAME, []byte{})
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash(), newUtilityInit(nil).Hash())
+
+	ongs := []*ont.State{{From: nutils.OntContractAddress, To: common.ADDRESS_EMPTY, Value: 1}}
+	assert.NotEqual(t, tx.Hash(), newUtilityInit(ongs).Hash())
+}
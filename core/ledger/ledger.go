@@ -20,6 +20,7 @@ package ledger
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common"
@@ -27,10 +28,12 @@ import (
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/store/ledgerstore"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/smartcontract/event"
 	cstate "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 var DefLedger *Ledger
@@ -168,6 +171,31 @@ func (self *Ledger) GetStorageItem(codeHash common.Address, key []byte) ([]byte,
 	return storageItem.Value, nil
 }
 
+func (self *Ledger) GetStorageItemAt(height uint32, codeHash common.Address, key []byte) ([]byte, error) {
+	storageKey := &states.StorageKey{
+		ContractAddress: codeHash,
+		Key:             key,
+	}
+	storageItem, err := self.ldgStore.GetStorageItemAt(height, storageKey)
+	if err != nil {
+		return nil, err
+	}
+	if storageItem == nil {
+		return nil, nil
+	}
+	return storageItem.Value, nil
+}
+
+//GetContractStorageStats return the key count and bytes of the contract storage
+func (self *Ledger) GetContractStorageStats(codeHash common.Address) (*scom.StorageStats, error) {
+	return self.ldgStore.GetContractStorageStats(codeHash)
+}
+
+//FindStorageItems return a page of storage items of contract with the key prefix, next is the start of the following page
+func (self *Ledger) FindStorageItems(codeHash common.Address, prefix, start []byte, limit int) ([]*scom.KeyValue, []byte, error) {
+	return self.ldgStore.FindStorageItems(codeHash, prefix, start, limit)
+}
+
 func (self *Ledger) GetContractState(contractHash common.Address) (*payload.DeployCode, error) {
 	return self.ldgStore.GetContractState(contractHash)
 }
@@ -184,6 +212,22 @@ func (self *Ledger) PreExecuteContractBatch(txes []*types.Transaction, atomic bo
 	return self.ldgStore.PreExecuteContractBatch(txes, atomic)
 }
 
+func (self *Ledger) PreExecuteContractAt(height uint32, tx *types.Transaction) (*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractAt(height, tx)
+}
+
+func (self *Ledger) PreExecuteContractBatchAt(height uint32, txes []*types.Transaction) ([]*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractBatchAt(height, txes)
+}
+
+func (self *Ledger) PreExecuteContractWithProfile(height *uint32, tx *types.Transaction) (*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractWithProfile(height, tx)
+}
+
+func (self *Ledger) TraceTransaction(txHash common.Uint256, tracer trace.Tracer) (*event.ExecuteNotify, error) {
+	return self.ldgStore.TraceTransaction(txHash, tracer)
+}
+
 func (self *Ledger) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return self.ldgStore.GetEventNotifyByTx(tx)
 }
@@ -192,6 +236,10 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) GetAddressHistory(addr common.Address, fromHeight uint32, limit int) ([]*scom.AddressHistory, uint32, bool, error) {
+	return self.ldgStore.GetAddressHistory(addr, fromHeight, limit)
+}
+
 func (self *Ledger) GetCrossChainMsg(height uint32) (*types.CrossChainMsg, error) {
 	return self.ldgStore.GetCrossChainMsg(height)
 }
@@ -207,3 +255,20 @@ func (self *Ledger) Close() error {
 func (self *Ledger) EnableBlockPrune(numBeforeCurr uint32) {
 	self.ldgStore.EnableBlockPrune(numBeforeCurr)
 }
+
+func (self *Ledger) EnableStateArchive() error {
+	return self.ldgStore.EnableStateArchive()
+}
+
+func (self *Ledger) EnableAddressHistory() error {
+	return self.ldgStore.EnableAddressHistory()
+}
+
+func (self *Ledger) ExportSnapshot(w io.Writer) (*store.SnapshotInfo, error) {
+	return self.ldgStore.ExportSnapshot(w)
+}
+
+func (self *Ledger) ImportSnapshot(r io.Reader, genesisBlock *types.Block, blockHash, stateRoot,
+	stateDigest common.Uint256) (*store.SnapshotInfo, error) {
+	return self.ldgStore.ImportSnapshot(r, genesisBlock, blockHash, stateRoot, stateDigest)
+}
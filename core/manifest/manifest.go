@@ -0,0 +1,352 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package manifest defines the manifest of a smart contract, which describes its methods, events and the token
+//standards it supports. A manifest can be stored alongside the contract at deploy time, and is used to build
+//invocations from plain text arguments and to decode the notifies of the contract.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/vm/crossvm_codec"
+)
+
+const MAX_MANIFEST_SIZE = 64 * 1024
+
+const (
+	PARAM_TYPE_BOOLEAN    = "boolean"
+	PARAM_TYPE_INTEGER    = "integer"
+	PARAM_TYPE_STRING     = "string"
+	PARAM_TYPE_BYTE_ARRAY = "bytearray"
+	PARAM_TYPE_ADDRESS    = "address"
+	PARAM_TYPE_HASH256    = "hash256"
+	PARAM_TYPE_ARRAY      = "array"
+	PARAM_TYPE_ANY        = "any"
+	PARAM_TYPE_VOID       = "void" //only used as return type
+)
+
+const (
+	STANDARD_OEP4 = "OEP-4" //fungible token
+	STANDARD_OEP5 = "OEP-5" //non-fungible token
+	STANDARD_OEP8 = "OEP-8" //crypto-collectible token
+)
+
+//standardMethods is the methods a contract must declare to claim the standard
+var standardMethods = map[string][]string{
+	STANDARD_OEP4: {"name", "symbol", "decimals", "totalSupply", "balanceOf", "transfer"},
+	STANDARD_OEP5: {"name", "symbol", "totalSupply", "balanceOf", "ownerOf", "transfer"},
+	STANDARD_OEP8: {"name", "symbol", "totalSupply", "balanceOf", "transfer"},
+}
+
+var paramTypes = map[string]bool{
+	PARAM_TYPE_BOOLEAN:    true,
+	PARAM_TYPE_INTEGER:    true,
+	PARAM_TYPE_STRING:     true,
+	PARAM_TYPE_BYTE_ARRAY: true,
+	PARAM_TYPE_ADDRESS:    true,
+	PARAM_TYPE_HASH256:    true,
+	PARAM_TYPE_ARRAY:      true,
+	PARAM_TYPE_ANY:        true,
+}
+
+type Manifest struct {
+	Name      string    `json:"name"`
+	Standards []string  `json:"standards,omitempty"`
+	Methods   []*Method `json:"methods"`
+	Events    []*Event  `json:"events,omitempty"`
+}
+
+type Parameter struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type Method struct {
+	Name       string       `json:"name"`
+	Parameters []*Parameter `json:"parameters"`
+	ReturnType string       `json:"returntype"`
+}
+
+type Event struct {
+	Name       string       `json:"name"`
+	Parameters []*Parameter `json:"parameters"`
+}
+
+//Parse decode the json manifest and validate it
+func Parse(data []byte) (*Manifest, error) {
+	if len(data) > MAX_MANIFEST_SIZE {
+		return nil, fmt.Errorf("manifest size %d exceeds %d", len(data), MAX_MANIFEST_SIZE)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal manifest error:%s", err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+//Verify return error if the manifest deployed in a block at the height is invalid, or is before the manifest height
+//contract manifest is enabled at, since nodes before it can not deserialize the manifest flag. Empty data means no
+//manifest.
+func Verify(data []byte, height, manifestHeight uint32) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if height < manifestHeight {
+		return fmt.Errorf("contract manifest is not enabled at block height %d", height)
+	}
+	if _, err := Parse(data); err != nil {
+		return fmt.Errorf("invalid manifest: %s", err)
+	}
+	return nil
+}
+
+//Validate check the names and types of the manifest, and that the methods required by its standards are declared
+func (this *Manifest) Validate() error {
+	if this.Name == "" {
+		return fmt.Errorf("manifest name is empty")
+	}
+	methods := make(map[string]bool, len(this.Methods))
+	for _, method := range this.Methods {
+		if method == nil || method.Name == "" {
+			return fmt.Errorf("method name is empty")
+		}
+		name := strings.ToLower(method.Name)
+		if methods[name] {
+			return fmt.Errorf("duplicated method %s", method.Name)
+		}
+		methods[name] = true
+		if err := validateParameters(method.Parameters); err != nil {
+			return fmt.Errorf("method %s: %s", method.Name, err)
+		}
+		retType := strings.ToLower(method.ReturnType)
+		if retType != "" && retType != PARAM_TYPE_VOID && !paramTypes[retType] {
+			return fmt.Errorf("method %s: unknown return type %s", method.Name, method.ReturnType)
+		}
+	}
+	events := make(map[string]bool, len(this.Events))
+	for _, evt := range this.Events {
+		if evt == nil || evt.Name == "" {
+			return fmt.Errorf("event name is empty")
+		}
+		name := strings.ToLower(evt.Name)
+		if events[name] {
+			return fmt.Errorf("duplicated event %s", evt.Name)
+		}
+		events[name] = true
+		if err := validateParameters(evt.Parameters); err != nil {
+			return fmt.Errorf("event %s: %s", evt.Name, err)
+		}
+	}
+	for _, standard := range this.Standards {
+		for _, name := range standardMethods[strings.ToUpper(standard)] {
+			if !methods[strings.ToLower(name)] {
+				return fmt.Errorf("method %s required by %s is missing", name, standard)
+			}
+		}
+	}
+	return nil
+}
+
+func validateParameters(params []*Parameter) error {
+	for _, param := range params {
+		if param == nil || param.Name == "" {
+			return fmt.Errorf("parameter name is empty")
+		}
+		if !paramTypes[strings.ToLower(param.Type)] {
+			return fmt.Errorf("parameter %s has unknown type %s", param.Name, param.Type)
+		}
+	}
+	return nil
+}
+
+//GetMethod return the method with the name, ignoring case
+func (this *Manifest) GetMethod(name string) *Method {
+	for _, method := range this.Methods {
+		if strings.EqualFold(method.Name, name) {
+			return method
+		}
+	}
+	return nil
+}
+
+//GetEvent return the event with the name, ignoring case
+func (this *Manifest) GetEvent(name string) *Event {
+	for _, evt := range this.Events {
+		if strings.EqualFold(evt.Name, name) {
+			return evt
+		}
+	}
+	return nil
+}
+
+//SupportStandard return whether the contract claims the standard
+func (this *Manifest) SupportStandard(standard string) bool {
+	for _, s := range this.Standards {
+		if strings.EqualFold(s, standard) {
+			return true
+		}
+	}
+	return false
+}
+
+//ParseArgs convert the text arguments to the values of the parameter types. Integers are decimal, byte arrays and
+//hash256 are hex, addresses are base58 or hex
+func (this *Method) ParseArgs(args []string) ([]interface{}, error) {
+	if len(args) != len(this.Parameters) {
+		return nil, fmt.Errorf("method %s expects %d arguments, got %d", this.Name, len(this.Parameters), len(args))
+	}
+	values := make([]interface{}, 0, len(args))
+	for i, param := range this.Parameters {
+		value, err := parseArg(param.Type, strings.TrimSpace(args[i]))
+		if err != nil {
+			return nil, fmt.Errorf("parse argument %s:%s error:%s", param.Name, args[i], err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func parseArg(typ, arg string) (interface{}, error) {
+	switch strings.ToLower(typ) {
+	case PARAM_TYPE_BOOLEAN:
+		switch strings.ToLower(arg) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean")
+	case PARAM_TYPE_INTEGER:
+		value, ok := new(big.Int).SetString(arg, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer")
+		}
+		return value, nil
+	case PARAM_TYPE_STRING:
+		return arg, nil
+	case PARAM_TYPE_BYTE_ARRAY:
+		return common.HexToBytes(arg)
+	case PARAM_TYPE_ADDRESS:
+		addr, err := common.AddressFromBase58(arg)
+		if err != nil {
+			return common.AddressFromHexString(arg)
+		}
+		return addr, nil
+	case PARAM_TYPE_HASH256:
+		return common.Uint256FromHexString(arg)
+	default:
+		return nil, fmt.Errorf("type %s can not be parsed from text", typ)
+	}
+}
+
+//DecodedEvent is a notify decoded by the event declared in the manifest, or the typed event of wasm contract
+type DecodedEvent struct {
+	Name   string          `json:"name"`
+	Params []*DecodedParam `json:"params"`
+}
+
+type DecodedParam struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+//DecodeNeoVMEvent decode the states of a neovm notify, which are hex strings with the event name first.
+//Return nil if the states do not match any event of the manifest
+func (this *Manifest) DecodeNeoVMEvent(states interface{}) *DecodedEvent {
+	items, ok := states.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil
+	}
+	name, ok := decodeHex(items[0])
+	if !ok {
+		return nil
+	}
+	evt := this.GetEvent(string(name))
+	if evt == nil || len(evt.Parameters) != len(items)-1 {
+		return nil
+	}
+	decoded := &DecodedEvent{Name: evt.Name, Params: make([]*DecodedParam, 0, len(evt.Parameters))}
+	for i, param := range evt.Parameters {
+		decoded.Params = append(decoded.Params, &DecodedParam{
+			Name:  param.Name,
+			Type:  param.Type,
+			Value: decodeNeoVMValue(param.Type, items[i+1]),
+		})
+	}
+	return decoded
+}
+
+//DecodeWasmEvent decode the notify data of wasm contract, which is the typed event encoded by crossvm_codec.
+//Return nil if the data is not a typed event
+func DecodeWasmEvent(data []byte) *DecodedEvent {
+	if !crossvm_codec.IsTypedEvent(data) {
+		return nil
+	}
+	evt, err := crossvm_codec.DecodeEvent(data)
+	if err != nil {
+		return nil
+	}
+	decoded := &DecodedEvent{Name: evt.Name, Params: make([]*DecodedParam, 0, len(evt.Fields))}
+	for _, field := range evt.Fields {
+		decoded.Params = append(decoded.Params, &DecodedParam{Name: field.Name, Type: field.Type, Value: field.Value})
+	}
+	return decoded
+}
+
+//decodeNeoVMValue decode the hex string of a neovm value, values can not be decoded are returned as it is
+func decodeNeoVMValue(typ string, item interface{}) interface{} {
+	data, ok := decodeHex(item)
+	if !ok {
+		return item
+	}
+	switch strings.ToLower(typ) {
+	case PARAM_TYPE_BOOLEAN:
+		return common.BigIntFromNeoBytes(data).Sign() != 0
+	case PARAM_TYPE_INTEGER:
+		return common.BigIntFromNeoBytes(data)
+	case PARAM_TYPE_STRING:
+		return string(data)
+	case PARAM_TYPE_ADDRESS:
+		if addr, err := common.AddressParseFromBytes(data); err == nil {
+			return addr.ToBase58()
+		}
+	case PARAM_TYPE_HASH256:
+		if hash, err := common.Uint256ParseFromBytes(data); err == nil {
+			return hash.ToHexString()
+		}
+	}
+	return item
+}
+
+func decodeHex(item interface{}) ([]byte, bool) {
+	str, ok := item.(string)
+	if !ok {
+		return nil, false
+	}
+	data, err := common.HexToBytes(str)
+	return data, err == nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package manifest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/vm/crossvm_codec"
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = `{
+  "name": "MyToken",
+  "standards": ["OEP-4"],
+  "methods": [
+    {"name": "name", "parameters": [], "returntype": "string"},
+    {"name": "symbol", "parameters": [], "returntype": "string"},
+    {"name": "decimals", "parameters": [], "returntype": "integer"},
+    {"name": "totalSupply", "parameters": [], "returntype": "integer"},
+    {"name": "balanceOf", "parameters": [{"name": "account", "type": "address"}], "returntype": "integer"},
+    {"name": "transfer", "parameters": [
+      {"name": "from", "type": "address"},
+      {"name": "to", "type": "address"},
+      {"name": "amount", "type": "integer"}
+    ], "returntype": "boolean"}
+  ],
+  "events": [
+    {"name": "transfer", "parameters": [
+      {"name": "from", "type": "address"},
+      {"name": "to", "type": "address"},
+      {"name": "amount", "type": "integer"}
+    ]}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest))
+	assert.Nil(t, err)
+	assert.True(t, manifest.SupportStandard(STANDARD_OEP4))
+	assert.NotNil(t, manifest.GetMethod("BalanceOf"))
+	assert.Nil(t, manifest.GetMethod("approve"))
+
+	invalids := []string{
+		`{"name": "", "methods": []}`,
+		`{"name": "a", "standards": ["OEP-4"], "methods": []}`,
+		`{"name": "a", "methods": [{"name": "f"}, {"name": "F"}]}`,
+		`{"name": "a", "methods": [{"name": "f", "parameters": [{"name": "x", "type": "float"}]}]}`,
+		`{"name": "a", "methods": [{"name": "f", "returntype": "float"}]}`,
+		`{"name": "a", "methods": [], "events": [{"name": "e", "parameters": [{"name": "", "type": "string"}]}]}`,
+	}
+	for _, invalid := range invalids {
+		_, err := Parse([]byte(invalid))
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	assert.NotNil(t, Verify([]byte(testManifest), 99, 100))
+	assert.Nil(t, Verify([]byte(testManifest), 100, 100))
+	assert.Nil(t, Verify(nil, 0, 100))
+	assert.NotNil(t, Verify([]byte(`{"methods":[]}`), 100, 100))
+}
+
+func TestParseArgs(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest))
+	assert.Nil(t, err)
+	from := common.Address{1}
+	to := common.Address{2}
+	method := manifest.GetMethod("transfer")
+	args, err := method.ParseArgs([]string{from.ToBase58(), to.ToHexString(), " 100"})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{from, to, big.NewInt(100)}, args)
+
+	_, err = method.ParseArgs([]string{from.ToBase58(), to.ToBase58()})
+	assert.NotNil(t, err)
+	_, err = method.ParseArgs([]string{from.ToBase58(), to.ToBase58(), "1.5"})
+	assert.NotNil(t, err)
+}
+
+func TestDecodeNeoVMEvent(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest))
+	assert.Nil(t, err)
+	from := common.Address{1}
+	to := common.Address{2}
+	states := []interface{}{
+		common.ToHexString([]byte("transfer")),
+		common.ToHexString(from[:]),
+		common.ToHexString(to[:]),
+		common.ToHexString(common.BigIntToNeoBytes(big.NewInt(1000))),
+	}
+	evt := manifest.DecodeNeoVMEvent(states)
+	assert.NotNil(t, evt)
+	assert.Equal(t, "transfer", evt.Name)
+	assert.Equal(t, from.ToBase58(), evt.Params[0].Value)
+	assert.Equal(t, to.ToBase58(), evt.Params[1].Value)
+	assert.Equal(t, big.NewInt(1000), evt.Params[2].Value)
+
+	assert.Nil(t, manifest.DecodeNeoVMEvent(states[:3]))
+	assert.Nil(t, manifest.DecodeNeoVMEvent(common.ToHexString([]byte("transfer"))))
+	assert.Nil(t, manifest.DecodeNeoVMEvent([]interface{}{common.ToHexString([]byte("approval"))}))
+}
+
+func TestDecodeWasmEvent(t *testing.T) {
+	to := common.AddressFromVmCode([]byte("to"))
+	data, err := crossvm_codec.EncodeEvent("transfer", []*crossvm_codec.EventField{
+		{Name: "to", Value: to},
+		{Name: "amount", Value: big.NewInt(10)},
+	})
+	assert.Nil(t, err)
+
+	evt := DecodeWasmEvent(data)
+	assert.NotNil(t, evt)
+	assert.Equal(t, "transfer", evt.Name)
+	assert.Equal(t, []*DecodedParam{
+		{Name: "to", Type: PARAM_TYPE_ADDRESS, Value: to.ToBase58()},
+		{Name: "amount", Type: PARAM_TYPE_INTEGER, Value: "10"},
+	}, evt.Params)
+
+	assert.Nil(t, DecodeWasmEvent([]byte("legacy")))
+	assert.Nil(t, DecodeWasmEvent(data[:len(data)-1]))
+}
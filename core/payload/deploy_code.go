@@ -23,6 +23,7 @@ import (
 	"io"
 
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/errors"
 )
 
@@ -33,6 +34,9 @@ const (
 	WASMVM_TYPE VmType = 3
 )
 
+//MANIFEST_FLAG is set in the serialized vm flags when a manifest follows the description
+const MANIFEST_FLAG byte = 0x80
+
 func VmTypeFromByte(ty byte) (VmType, error) {
 	switch ty {
 	case 1, 3:
@@ -52,11 +56,18 @@ type DeployCode struct {
 	Author      string
 	Email       string
 	Description string
+	manifest    []byte //json contract manifest, optional
 
 	address common.Address
 }
 
 func NewDeployCode(code []byte, vmType VmType, name, version, author, email, description string) (*DeployCode, error) {
+	return NewDeployCodeWithManifest(code, vmType, name, version, author, email, description, nil)
+}
+
+//NewDeployCodeWithManifest return a deploy code carrying the contract manifest
+func NewDeployCodeWithManifest(code []byte, vmType VmType, name, version, author, email, description string,
+	manifest []byte) (*DeployCode, error) {
 	dc := &DeployCode{
 		code:        code,
 		vmFlags:     byte(vmType),
@@ -65,6 +76,7 @@ func NewDeployCode(code []byte, vmType VmType, name, version, author, email, des
 		Author:      author,
 		Email:       email,
 		Description: description,
+		manifest:    manifest,
 	}
 	err := validateDeployCode(dc)
 	if err != nil {
@@ -84,6 +96,16 @@ func (dc *DeployCode) GetRawCode() []byte {
 	return dc.code
 }
 
+//GetManifest return the json manifest of the contract, nil if not deployed with one
+func (dc *DeployCode) GetManifest() []byte {
+	return dc.manifest
+}
+
+//DeployCodeLen return the length of the code and manifest stored in contract state, which deploy gas is charged by
+func (dc *DeployCode) DeployCodeLen() int {
+	return len(dc.code) + len(dc.manifest)
+}
+
 func (dc *DeployCode) GetWasmCode() ([]byte, error) {
 	if dc.VmType() == WASMVM_TYPE {
 		return dc.code, nil
@@ -128,12 +150,19 @@ func (dc *DeployCode) ToArray() []byte {
 
 func (dc *DeployCode) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteVarBytes(dc.code)
-	sink.WriteByte(dc.vmFlags)
+	if len(dc.manifest) > 0 {
+		sink.WriteByte(dc.vmFlags | MANIFEST_FLAG)
+	} else {
+		sink.WriteByte(dc.vmFlags)
+	}
 	sink.WriteString(dc.Name)
 	sink.WriteString(dc.Version)
 	sink.WriteString(dc.Author)
 	sink.WriteString(dc.Email)
 	sink.WriteString(dc.Description)
+	if len(dc.manifest) > 0 {
+		sink.WriteVarBytes(dc.manifest)
+	}
 }
 
 //note: DeployCode.Code has data reference of param source
@@ -152,6 +181,8 @@ func (dc *DeployCode) Deserialization(source *common.ZeroCopySource) error {
 	if eof {
 		return io.ErrUnexpectedEOF
 	}
+	hasManifest := dc.vmFlags&MANIFEST_FLAG != 0
+	dc.vmFlags &^= MANIFEST_FLAG
 
 	dc.Name, _, irregular, eof = source.NextString()
 	if eof {
@@ -198,6 +229,19 @@ func (dc *DeployCode) Deserialization(source *common.ZeroCopySource) error {
 		return io.ErrUnexpectedEOF
 	}
 
+	if hasManifest {
+		dc.manifest, _, irregular, eof = source.NextVarBytes()
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+		if irregular {
+			return common.ErrIrregularData
+		}
+		if len(dc.manifest) == 0 {
+			return errors.NewErr("[contract] manifest flag set without manifest")
+		}
+	}
+
 	err := validateDeployCode(dc)
 	if err != nil {
 		return err
@@ -244,6 +288,10 @@ func validateDeployCode(dep *DeployCode) error {
 		return errors.NewErr("[contract] description too long!")
 	}
 
+	if len(dep.manifest) > manifest.MAX_MANIFEST_SIZE {
+		return errors.NewErr("[contract] manifest too long!")
+	}
+
 	return nil
 }
 
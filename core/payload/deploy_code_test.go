@@ -41,3 +41,32 @@ func TestDeployCode_Serialize(t *testing.T) {
 	err = deploy2.Deserialization(source)
 	assert.NotNil(t, err)
 }
+
+func TestDeployCode_SerializeManifest(t *testing.T) {
+	manifest := []byte(`{"name":"test","methods":[{"name":"add","parameters":[{"name":"a","type":"integer"}],"returntype":"integer"}]}`)
+	deploy, err := NewDeployCodeWithManifest([]byte{1, 2, 3}, NEOVM_TYPE, "", "", "", "", "", manifest)
+	assert.Nil(t, err)
+	bs := common.SerializeToBytes(deploy)
+	assert.Equal(t, NEOVM_TYPE|VmType(MANIFEST_FLAG), VmType(bs[4]))
+
+	var deploy2 DeployCode
+	err = deploy2.Deserialization(common.NewZeroCopySource(bs))
+	assert.Nil(t, err)
+	assert.Equal(t, NEOVM_TYPE, deploy2.VmType())
+	assert.Equal(t, manifest, deploy2.GetManifest())
+	assert.Equal(t, bs, common.SerializeToBytes(&deploy2))
+
+	//the flag without manifest is not canonical
+	plain, err := NewDeployCode([]byte{1, 2, 3}, NEOVM_TYPE, "", "", "", "", "")
+	assert.Nil(t, err)
+	bs = common.SerializeToBytes(plain)
+	bs[4] |= MANIFEST_FLAG
+	bs = append(bs, 0)
+	assert.NotNil(t, deploy2.Deserialization(common.NewZeroCopySource(bs)))
+
+	//the manifest is validated by the validators instead of decoding
+	deploy, err = NewDeployCodeWithManifest([]byte{1, 2, 3}, NEOVM_TYPE, "", "", "", "", "", []byte(`{"methods":[]}`))
+	assert.Nil(t, err)
+	err = deploy2.Deserialization(common.NewZeroCopySource(common.SerializeToBytes(deploy)))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,467 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package badgerstore is a pure go persist store backend based on BadgerDB
+package badgerstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+//BACKEND_NAME is the name of badger in persist store backends
+const BACKEND_NAME = "badger"
+
+const (
+	VALUE_LOG_GC_INTERVAL      = 10 * time.Minute //Interval of reclaiming value log space
+	VALUE_LOG_GC_DISCARD_RATIO = 0.5              //Rewrite a value log file when at least this ratio of it can be discarded
+)
+
+func init() {
+	common.RegisterPersistStore(BACKEND_NAME, func(dir string) (common.PersistStore, error) {
+		return NewBadgerStore(dir)
+	})
+}
+
+//BadgerStore is a persist store on BadgerDB
+type BadgerStore struct {
+	db      *badger.DB
+	batch   *leveldb.Batch
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+//NewBadgerStore return BadgerStore instance saved in dir
+func NewBadgerStore(dir string) (*BadgerStore, error) {
+	//refuse to mix badger files with the files of a leveldb store
+	if _, err := os.Stat(filepath.Join(dir, "CURRENT")); err == nil {
+		return nil, fmt.Errorf("directory %s is used by leveldb store", dir)
+	}
+	opts := badger.DefaultOptions(dir).WithLogger(logger{}).WithTruncate(true)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	store := &BadgerStore{
+		db:      db,
+		closing: make(chan struct{}),
+	}
+	store.wg.Add(1)
+	go store.runValueLogGC()
+	return store, nil
+}
+
+//Put a key-value pair to badger
+func (self *BadgerStore) Put(key []byte, value []byte) error {
+	return self.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(copyBytes(key), copyBytes(value))
+	})
+}
+
+//Get the value of a key from badger
+func (self *BadgerStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := self.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, common.ErrNotFound
+		}
+		return nil, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	return value, nil
+}
+
+//Has return whether the key is exist in badger
+func (self *BadgerStore) Has(key []byte) (bool, error) {
+	err := self.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Delete the key in badger
+func (self *BadgerStore) Delete(key []byte) error {
+	return self.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(copyBytes(key))
+	})
+}
+
+//NewBatch start commit batch
+func (self *BadgerStore) NewBatch() {
+	self.batch = new(leveldb.Batch)
+}
+
+//BatchPut put a key-value pair to batch
+func (self *BadgerStore) BatchPut(key []byte, value []byte) {
+	self.batch.Put(key, value)
+}
+
+//BatchDelete delete a key to batch
+func (self *BadgerStore) BatchDelete(key []byte) {
+	self.batch.Delete(key)
+}
+
+//BatchCommit commit batch to badger. A batch larger than a badger transaction, such as the states of a big block,
+//is split into several transactions, and the system records such as the current block are written in the last one.
+//So a commit interrupted by crash leaves the store at the previous block, and the block is committed again when
+//the ledger recovers.
+func (self *BadgerStore) BatchCommit() error {
+	rep := &replayer{db: self.db, txn: self.db.NewTransaction(true)}
+	defer func() { rep.txn.Discard() }()
+	if err := self.batch.Replay(rep); err != nil {
+		return err
+	}
+	if err := rep.commit(); err != nil {
+		return err
+	}
+	self.batch = nil
+	return nil
+}
+
+//Close badger
+func (self *BadgerStore) Close() error {
+	close(self.closing)
+	self.wg.Wait()
+	return self.db.Close()
+}
+
+//NewIterator return a iterator of badger with the key prefix
+func (self *BadgerStore) NewIterator(prefix []byte) common.StoreIterator {
+	r := util.BytesPrefix(prefix)
+	return self.NewRangeIterator(r.Start, r.Limit)
+}
+
+//NewRangeIterator return a iterator of badger with the keys in range [start, end)
+func (self *BadgerStore) NewRangeIterator(start, end []byte) common.StoreIterator {
+	return newIterator(self.db.NewTransaction(false), copyBytes(start), copyBytes(end))
+}
+
+func (self *BadgerStore) runValueLogGC() {
+	defer self.wg.Done()
+	ticker := time.NewTicker(VALUE_LOG_GC_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			//each successful run rewrites one file, repeat until nothing left to rewrite
+			for self.db.RunValueLogGC(VALUE_LOG_GC_DISCARD_RATIO) == nil {
+			}
+		case <-self.closing:
+			return
+		}
+	}
+}
+
+const (
+	posBeforeFirst = iota //Iterator is before the first item
+	posValid              //Iterator is at an item
+	posAfterLast          //Iterator is after the last item
+)
+
+//Iterator iterate the keys in a range on a read only badger transaction. Badger iterators are one way,
+//a forward and a reverse iterator are kept and the one of the moving direction is repositioned at the current key
+type Iterator struct {
+	txn     *badger.Txn
+	prefix  []byte //Common prefix of the range, lets badger skip the tables out of the range
+	start   []byte
+	end     []byte
+	forward *badger.Iterator
+	reverse *badger.Iterator
+	pos     int
+	dir     int //1 if the current item is read by the forward iterator, -1 by the reverse one
+	key     []byte
+	value   []byte
+	err     error
+}
+
+func newIterator(txn *badger.Txn, start, end []byte) *Iterator {
+	prefix := start
+	if len(end) < len(prefix) {
+		prefix = prefix[:len(end)]
+	}
+	for i := range prefix {
+		if prefix[i] != end[i] {
+			prefix = prefix[:i]
+			break
+		}
+	}
+	if len(end) == 0 {
+		end = nil
+	}
+	return &Iterator{
+		txn:    txn,
+		prefix: prefix,
+		start:  start,
+		end:    end,
+		pos:    posBeforeFirst,
+	}
+}
+
+//Next item. If item available return true, otherwise return false
+func (self *Iterator) Next() bool {
+	switch {
+	case self.err != nil || self.pos == posAfterLast:
+		return false
+	case self.pos == posBeforeFirst:
+		return self.First()
+	case self.dir > 0:
+		self.forward.Next()
+		return self.loadForward()
+	default:
+		return self.seekForward(self.key, true)
+	}
+}
+
+//Prev item. If item available return true, otherwise return false
+func (self *Iterator) Prev() bool {
+	switch {
+	case self.err != nil || self.pos == posBeforeFirst:
+		return false
+	case self.pos == posAfterLast:
+		return self.Last()
+	case self.dir < 0:
+		self.reverse.Next()
+		return self.loadReverse()
+	default:
+		return self.seekReverse(self.key)
+	}
+}
+
+//First item. If item available return true, otherwise return false
+func (self *Iterator) First() bool {
+	return self.seekForward(self.start, false)
+}
+
+//Last item. If item available return true, otherwise return false
+func (self *Iterator) Last() bool {
+	return self.seekReverse(self.end)
+}
+
+//Seek the first item whose key is greater than or equal to key. If item available return true, otherwise return false
+func (self *Iterator) Seek(key []byte) bool {
+	if bytes.Compare(key, self.start) < 0 {
+		key = self.start
+	}
+	return self.seekForward(key, false)
+}
+
+//Key return the current item key
+func (self *Iterator) Key() []byte {
+	return self.key
+}
+
+//Value return the current item value
+func (self *Iterator) Value() []byte {
+	return self.value
+}
+
+//Release close iterator
+func (self *Iterator) Release() {
+	if self.forward != nil {
+		self.forward.Close()
+	}
+	if self.reverse != nil {
+		self.reverse.Close()
+	}
+	self.txn.Discard()
+}
+
+//Error returns any accumulated error
+func (self *Iterator) Error() error {
+	return self.err
+}
+
+//seekForward move to the first item not less than key, or greater than key if skipEqual
+func (self *Iterator) seekForward(key []byte, skipEqual bool) bool {
+	if self.err != nil {
+		return false
+	}
+	if self.forward == nil {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = self.prefix
+		self.forward = self.txn.NewIterator(opts)
+	}
+	key = copyBytes(key)
+	self.forward.Seek(key)
+	if skipEqual && self.forward.Valid() && bytes.Equal(self.forward.Item().Key(), key) {
+		self.forward.Next()
+	}
+	return self.loadForward()
+}
+
+//seekReverse move to the last item less than key, nil key means the last item
+func (self *Iterator) seekReverse(key []byte) bool {
+	if self.err != nil {
+		return false
+	}
+	if self.reverse == nil {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = self.prefix
+		opts.Reverse = true
+		self.reverse = self.txn.NewIterator(opts)
+	}
+	if key == nil {
+		self.reverse.Rewind()
+	} else {
+		key = copyBytes(key)
+		self.reverse.Seek(key)
+		if self.reverse.Valid() && bytes.Equal(self.reverse.Item().Key(), key) {
+			self.reverse.Next()
+		}
+	}
+	return self.loadReverse()
+}
+
+func (self *Iterator) loadForward() bool {
+	self.dir = 1
+	if !self.forward.Valid() || (self.end != nil && bytes.Compare(self.forward.Item().Key(), self.end) >= 0) {
+		return self.unload(posAfterLast)
+	}
+	return self.load(self.forward.Item())
+}
+
+func (self *Iterator) loadReverse() bool {
+	self.dir = -1
+	if !self.reverse.Valid() || bytes.Compare(self.reverse.Item().Key(), self.start) < 0 {
+		return self.unload(posBeforeFirst)
+	}
+	return self.load(self.reverse.Item())
+}
+
+func (self *Iterator) load(item *badger.Item) bool {
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		self.err = err
+		return self.unload(posAfterLast)
+	}
+	self.key = item.KeyCopy(nil)
+	self.value = value
+	self.pos = posValid
+	return true
+}
+
+func (self *Iterator) unload(pos int) bool {
+	self.key, self.value = nil, nil
+	self.pos = pos
+	return false
+}
+
+//systemPrefixes is the key prefixes of the records which mark the block height of store
+var systemPrefixes = map[byte]bool{
+	byte(common.SYS_CURRENT_BLOCK):        true,
+	byte(common.SYS_VERSION):              true,
+	byte(common.SYS_CURRENT_CROSS_STATES): true,
+	byte(common.SYS_BLOCK_MERKLE_TREE):    true,
+	byte(common.SYS_STATE_MERKLE_TREE):    true,
+	byte(common.SYS_CROSS_CHAIN_MSG):      true,
+	byte(common.SYS_ARCHIVE_START_HEIGHT): true,
+	byte(common.SYS_HISTORY_START_HEIGHT): true,
+	byte(common.SYS_STORAGE_STATS_INIT):   true,
+}
+
+//replayer write batch records to badger, starting a new transaction when the current one is full.
+//The system records are held until all the other records are written.
+type replayer struct {
+	db     *badger.DB
+	txn    *badger.Txn
+	system leveldb.Batch
+	last   bool
+	err    error
+}
+
+func (self *replayer) Put(key, value []byte) {
+	if !self.last && len(key) > 0 && systemPrefixes[key[0]] {
+		self.system.Put(key, value)
+		return
+	}
+	self.apply(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+func (self *replayer) Delete(key []byte) {
+	if !self.last && len(key) > 0 && systemPrefixes[key[0]] {
+		self.system.Delete(key)
+		return
+	}
+	self.apply(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+func (self *replayer) apply(op func(txn *badger.Txn) error) {
+	if self.err != nil {
+		return
+	}
+	err := op(self.txn)
+	if err == badger.ErrTxnTooBig {
+		if err = self.txn.Commit(); err == nil {
+			self.txn = self.db.NewTransaction(true)
+			err = op(self.txn)
+		}
+	}
+	self.err = err
+}
+
+//commit write the system records and commit the last transaction
+func (self *replayer) commit() error {
+	self.last = true
+	if err := self.system.Replay(self); err != nil {
+		return err
+	}
+	if self.err != nil {
+		return self.err
+	}
+	return self.txn.Commit()
+}
+
+func copyBytes(buf []byte) []byte {
+	return append([]byte{}, buf...)
+}
+
+//logger forward badger logs to ontology log, badger infos are treated as debug
+type logger struct{}
+
+func (logger) Errorf(format string, a ...interface{})   { log.Errorf("badger: "+format, a...) }
+func (logger) Warningf(format string, a ...interface{}) { log.Warnf("badger: "+format, a...) }
+func (logger) Infof(format string, a ...interface{})    { log.Debugf("badger: "+format, a...) }
+func (logger) Debugf(format string, a ...interface{})   { log.Debugf("badger: "+format, a...) }
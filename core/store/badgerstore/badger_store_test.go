@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package badgerstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformanceTests(t, func(t *testing.T) (common.PersistStore, func()) {
+		dir, err := ioutil.TempDir("", "badgerstore")
+		require.Nil(t, err)
+		store, err := NewBadgerStore(dir)
+		require.Nil(t, err)
+		return store, func() {
+			store.Close()
+			os.RemoveAll(dir)
+		}
+	})
+}
+
+func TestReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badgerstore")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := common.NewPersistStore(BACKEND_NAME, dir)
+	require.Nil(t, err)
+	require.Nil(t, store.Put([]byte("foo"), []byte("bar")))
+	require.Nil(t, store.Close())
+
+	store, err = common.NewPersistStore(BACKEND_NAME, dir)
+	require.Nil(t, err)
+	defer store.Close()
+	value, err := store.Get([]byte("foo"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("bar"), value)
+}
+
+func TestBatchCommitSplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badgerstore")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewBadgerStore(dir)
+	require.Nil(t, err)
+	defer store.Close()
+
+	//a batch exceeding badger transaction limit is split, with the system records in the last transaction
+	value := []byte("value")
+	current := []byte{byte(common.SYS_CURRENT_BLOCK)}
+	store.NewBatch()
+	store.BatchPut(current, value)
+	store.BatchPut([]byte("first"), value)
+	for i := 0; i < 200000; i++ {
+		store.BatchPut([]byte(fmt.Sprintf("key%d", i)), value)
+	}
+	rep := &replayer{db: store.db, txn: store.db.NewTransaction(true)}
+	require.Nil(t, store.batch.Replay(rep))
+	rep.txn.Discard()
+	require.Equal(t, 1, rep.system.Len())
+	_, err = store.Get(current)
+	require.Equal(t, common.ErrNotFound, err)
+	_, err = store.Get([]byte("first"))
+	require.Nil(t, err)
+
+	require.Nil(t, store.BatchCommit())
+	for _, key := range [][]byte{current, []byte("first"), []byte("key0"), []byte("key199999")} {
+		got, err := store.Get(key)
+		require.Nil(t, err)
+		require.Equal(t, value, got)
+	}
+
+	store.NewBatch()
+	store.BatchPut([]byte("first"), value)
+	store.BatchPut([]byte("key0"), value)
+	store.BatchDelete([]byte("first"))
+	require.Nil(t, store.BatchCommit())
+	_, err = store.Get([]byte("first"))
+	require.Equal(t, common.ErrNotFound, err)
+	got, err := store.Get([]byte("key0"))
+	require.Nil(t, err)
+	require.Equal(t, value, got)
+}
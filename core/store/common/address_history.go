@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/ontio/ontology/common"
+)
+
+//Transfer is a token transfer parsed from the transfer notification of a contract
+type Transfer struct {
+	Contract common.Address
+	From     common.Address
+	To       common.Address
+	Amount   *big.Int
+}
+
+//AddressHistory is a transaction which transfers touched the address
+type AddressHistory struct {
+	TxHash    common.Uint256
+	Height    uint32
+	Transfers []*Transfer //transfers of the tx from or to the address
+}
+
+func (this *Transfer) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteAddress(this.Contract)
+	sink.WriteAddress(this.From)
+	sink.WriteAddress(this.To)
+	sink.WriteVarBytes(common.BigIntToNeoBytes(this.Amount))
+}
+
+func (this *Transfer) Deserialization(source *common.ZeroCopySource) error {
+	var eof, irregular bool
+	this.Contract, eof = source.NextAddress()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.From, eof = source.NextAddress()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.To, eof = source.NextAddress()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	amount, _, irregular, eof := source.NextVarBytes()
+	if irregular {
+		return common.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Amount = common.BigIntFromNeoBytes(amount)
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+//PersistStoreCreator open or create a persist store in the directory
+type PersistStoreCreator func(dir string) (PersistStore, error)
+
+var (
+	backendLock sync.RWMutex
+	backends    = make(map[string]PersistStoreCreator)
+)
+
+//RegisterPersistStore register a persist store backend by name. It panics if the name is registered twice
+func RegisterPersistStore(name string, creator PersistStoreCreator) {
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	if creator == nil {
+		panic("RegisterPersistStore: creator is nil")
+	}
+	if _, ok := backends[name]; ok {
+		panic("RegisterPersistStore: backend " + name + " registered twice")
+	}
+	backends[name] = creator
+}
+
+//NewPersistStore open the persist store in dir with the named backend
+func NewPersistStore(backend string, dir string) (PersistStore, error) {
+	backendLock.RLock()
+	creator, ok := backends[backend]
+	backendLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown store backend:%s, available:%v", backend, PersistStoreBackends())
+	}
+	return creator(dir)
+}
+
+//HasPersistStore return whether the backend is registered
+func HasPersistStore(backend string) bool {
+	backendLock.RLock()
+	defer backendLock.RUnlock()
+	_, ok := backends[backend]
+	return ok
+}
+
+//PersistStoreBackends return the sorted names of registered backends
+func PersistStoreBackends() []string {
+	backendLock.RLock()
+	defer backendLock.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
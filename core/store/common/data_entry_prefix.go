@@ -32,6 +32,11 @@ const (
 	ST_BOOKKEEPER DataEntryPrefix = 0x03 //BookKeeper state key prefix
 	ST_CONTRACT   DataEntryPrefix = 0x04 //Smart contract state key prefix
 	ST_STORAGE    DataEntryPrefix = 0x05 //Smart contract storage key prefix
+	ST_ARCHIVE    DataEntryPrefix = 0x23 //State key + block height => state value before the block, only in archive mode
+
+	ST_ARCHIVE_DELETED DataEntryPrefix = 0x28 //State key => deleted marker, the keys deleted since archive started, only in archive mode
+
+	ST_STORAGE_STATS DataEntryPrefix = 0x26 //Contract address => key count and bytes of the contract storage, derived from ST_STORAGE
 
 	IX_HEADER_HASH_LIST DataEntryPrefix = 0x09 //Block height => block hash key prefix
 
@@ -42,8 +47,14 @@ const (
 	SYS_BLOCK_MERKLE_TREE    DataEntryPrefix = 0x13 // Block merkle tree root key prefix
 	SYS_STATE_MERKLE_TREE    DataEntryPrefix = 0x20 // state merkle tree root key prefix
 	SYS_CROSS_CHAIN_MSG      DataEntryPrefix = 0x22 // state merkle tree root key prefix
+	SYS_ARCHIVE_START_HEIGHT DataEntryPrefix = 0x24 // first and last block height of state archive
+	SYS_HISTORY_START_HEIGHT DataEntryPrefix = 0x25 // first and last block height of address history index
+	SYS_STORAGE_STATS_INIT   DataEntryPrefix = 0x27 // whether the storage stats have been built from the existing storage
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
 
+	IX_ADDRESS_HISTORY       DataEntryPrefix = 0x15 //Address + block height + tx hash => transfers of the address in the tx
+	IX_ADDRESS_HISTORY_BLOCK DataEntryPrefix = 0x16 //Block height => address history keys of the block
+
 	DATA_BLOCK_PRUNE_HEIGHT DataEntryPrefix = 0x80 //  last pruned block height, genesis block can not be pruned
 )
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+//KeyValue is a key-value pair of store
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+}
+
+//ReadPage read at most limit items of iter from the first key not less than start, nil start means from the first item.
+//next is the key to read the following page from, nil if no item left. Keys and values are copied
+func ReadPage(iter StoreIterator, start []byte, limit int) (items []*KeyValue, next []byte, err error) {
+	var has bool
+	if start == nil {
+		has = iter.First()
+	} else {
+		has = iter.Seek(start)
+	}
+	for ; has; has = iter.Next() {
+		if len(items) >= limit {
+			next = append([]byte{}, iter.Key()...)
+			break
+		}
+		items = append(items, &KeyValue{
+			Key:   append([]byte{}, iter.Key()...),
+			Value: append([]byte{}, iter.Value()...),
+		})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, nil, err
+	}
+	return items, next, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+func TestReadPage(t *testing.T) {
+	db := memdb.New(comparer.DefaultComparer, 0)
+	for i := 0; i < 25; i++ {
+		db.Put([]byte(fmt.Sprintf("key%02d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	db.Put([]byte("other"), []byte("value"))
+
+	var keys []string
+	var start []byte
+	pages := 0
+	for {
+		iter := db.NewIterator(util.BytesPrefix([]byte("key")))
+		items, next, err := ReadPage(iter, start, 10)
+		iter.Release()
+		require.Nil(t, err)
+		pages++
+		for _, item := range items {
+			keys = append(keys, string(item.Key))
+		}
+		if next == nil {
+			require.Len(t, items, 5)
+			break
+		}
+		require.Len(t, items, 10)
+		start = next
+	}
+	require.Equal(t, 3, pages)
+	require.Len(t, keys, 25)
+	require.Equal(t, "key00", keys[0])
+	require.Equal(t, "key24", keys[24])
+
+	iter := db.NewIterator(util.BytesPrefix([]byte("key")))
+	items, next, err := ReadPage(iter, []byte("key24"), 10)
+	iter.Release()
+	require.Nil(t, err)
+	require.Nil(t, next)
+	require.Equal(t, []*KeyValue{{Key: []byte("key24"), Value: []byte("value24")}}, items)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"io"
+
+	"github.com/ontio/ontology/common"
+)
+
+//StorageStats is the state size a contract occupies
+type StorageStats struct {
+	KeyCount uint64 //number of the storage keys
+	Bytes    uint64 //total length of the storage keys relative to the contract and the serialized storage items
+}
+
+//AddItem count a storage item in the stats
+func (this *StorageStats) AddItem(keyLen, valueLen int) {
+	this.KeyCount++
+	this.Bytes += uint64(keyLen + valueLen)
+}
+
+//RemoveItem uncount a storage item from the stats
+func (this *StorageStats) RemoveItem(keyLen, valueLen int) {
+	this.KeyCount--
+	this.Bytes -= uint64(keyLen + valueLen)
+}
+
+//Fee return the storage fee of the stats with the fee per byte
+func (this *StorageStats) Fee(feePerByte uint64) uint64 {
+	return this.Bytes * feePerByte
+}
+
+func (this *StorageStats) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.KeyCount)
+	sink.WriteUint64(this.Bytes)
+}
+
+func (this *StorageStats) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.KeyCount, eof = source.NextUint64()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Bytes, eof = source.NextUint64()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -20,24 +20,46 @@ package common
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/smartcontract/event"
 )
 
 var ErrNotFound = errors.New("not found")
+var ErrAlreadyInitialized = errors.New("ledger has already been initialized")
+
+//InvalidBlockError is the error of block failing validation, other errors of adding block are local failures
+type InvalidBlockError struct {
+	msg string
+}
+
+//NewInvalidBlockError return InvalidBlockError with formatted message
+func NewInvalidBlockError(format string, a ...interface{}) error {
+	return &InvalidBlockError{msg: fmt.Sprintf(format, a...)}
+}
+
+func (this *InvalidBlockError) Error() string {
+	return this.msg
+}
+
+//IsInvalidBlock return whether err is caused by an invalid block
+func IsInvalidBlock(err error) bool {
+	_, ok := err.(*InvalidBlockError)
+	return ok
+}
 
 //Store iterator for iterate store
 type StoreIterator interface {
-	Next() bool //Next item. If item available return true, otherwise return false
-	//Prev() bool           //previous item. If item available return true, otherwise return false
-	First() bool //First item. If item available return true, otherwise return false
-	//Last() bool           //Last item. If item available return true, otherwise return false
-	//Seek(key []byte) bool //Seek key. If item available return true, otherwise return false
-	Key() []byte   //Return the current item key
-	Value() []byte //Return the current item value
-	Release()      //Close iterator
-	Error() error  // Error returns any accumulated error.
+	Next() bool           //Next item. If item available return true, otherwise return false
+	Prev() bool           //previous item. If item available return true, otherwise return false
+	First() bool          //First item. If item available return true, otherwise return false
+	Last() bool           //Last item. If item available return true, otherwise return false
+	Seek(key []byte) bool //Seek the first item whose key is greater than or equal to key. If item available return true, otherwise return false
+	Key() []byte          //Return the current item key
+	Value() []byte        //Return the current item value
+	Release()             //Close iterator
+	Error() error         // Error returns any accumulated error.
 }
 
 //PersistStore of ledger
@@ -52,6 +74,8 @@ type PersistStore interface {
 	BatchCommit() error                      //Commit batch to store
 	Close() error                            //Close store
 	NewIterator(prefix []byte) StoreIterator //Return the iterator of store
+	//Return the iterator of the keys in range [start, end) of store, nil start or end means unbounded
+	NewRangeIterator(start, end []byte) StoreIterator
 }
 
 //EventStore save event notify
@@ -0,0 +1,275 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
+)
+
+//hex string of transfer name, neovm notifies the states in hex string
+var neovmTransferName = hex.EncodeToString([]byte(ont.TRANSFER_NAME))
+
+//EnableAddressHistory index the transfers of the following blocks by address. The index built before is kept only
+//if it has indexed every block before startHeight, otherwise it restarts from startHeight
+func (this *EventStore) EnableAddressHistory(startHeight uint32) error {
+	start, last, err := this.getAddressHistoryRange()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if err == nil && start <= startHeight && last+1 >= startHeight {
+		this.addressHistoryStart = start
+		this.addressHistory = true
+		return nil
+	}
+	if err == nil {
+		log.Warnf("address history of blocks [%d, %d] is not continuous with block %d, restart index", start, last,
+			startHeight)
+	}
+	err = this.store.Put([]byte{byte(scom.SYS_HISTORY_START_HEIGHT)}, genHeightRange(startHeight, startHeight-1))
+	if err != nil {
+		return err
+	}
+	this.addressHistoryStart = startHeight
+	this.addressHistory = true
+	return nil
+}
+
+//GetAddressHistoryStartHeight return the first block height which transfers are indexed by address
+func (this *EventStore) GetAddressHistoryStartHeight() (uint32, error) {
+	start, _, err := this.getAddressHistoryRange()
+	return start, err
+}
+
+//getAddressHistoryRange return the first and last block height which transfers are indexed by address
+func (this *EventStore) getAddressHistoryRange() (uint32, uint32, error) {
+	value, err := this.store.Get([]byte{byte(scom.SYS_HISTORY_START_HEIGHT)})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(value) != 8 {
+		return 0, 0, fmt.Errorf("invalid address history start height")
+	}
+	return binary.LittleEndian.Uint32(value), binary.LittleEndian.Uint32(value[4:]), nil
+}
+
+//batchAddAddressHistory index the transfers in the notifies of block by the from and to addresses
+func (this *EventStore) batchAddAddressHistory(height uint32, notifies []*event.ExecuteNotify) {
+	if !this.addressHistory {
+		return
+	}
+	this.store.BatchPut([]byte{byte(scom.SYS_HISTORY_START_HEIGHT)}, genHeightRange(this.addressHistoryStart, height))
+	var keys [][]byte
+	for _, notify := range notifies {
+		histories := make(map[common.Address][]*scom.Transfer)
+		var addrs []common.Address
+		addTransfer := func(addr common.Address, transfer *scom.Transfer) {
+			if _, present := histories[addr]; !present {
+				addrs = append(addrs, addr)
+			}
+			histories[addr] = append(histories[addr], transfer)
+		}
+		for _, info := range notify.Notify {
+			transfer := parseTransfer(info)
+			if transfer == nil {
+				continue
+			}
+			addTransfer(transfer.From, transfer)
+			if transfer.To != transfer.From {
+				addTransfer(transfer.To, transfer)
+			}
+		}
+		for _, addr := range addrs {
+			key := genAddressHistoryKey(addr, height, notify.TxHash)
+			value := common.NewZeroCopySink(nil)
+			value.WriteVarUint(uint64(len(histories[addr])))
+			for _, transfer := range histories[addr] {
+				transfer.Serialization(value)
+			}
+			this.store.BatchPut(key, value.Bytes())
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	value := common.NewZeroCopySink(nil)
+	value.WriteVarUint(uint64(len(keys)))
+	for _, key := range keys {
+		value.WriteVarBytes(key)
+	}
+	this.store.BatchPut(genAddressHistoryBlockKey(height), value.Bytes())
+}
+
+//pruneAddressHistory delete the address history of the block
+func (this *EventStore) pruneAddressHistory(height uint32) {
+	blockKey := genAddressHistoryBlockKey(height)
+	data, err := this.store.Get(blockKey)
+	if err != nil {
+		return
+	}
+	source := common.NewZeroCopySource(data)
+	count, _, irregular, eof := source.NextVarUint()
+	for i := uint64(0); i < count && !eof && !irregular; i++ {
+		var key []byte
+		key, _, irregular, eof = source.NextVarBytes()
+		if !eof && !irregular {
+			this.store.BatchDelete(key)
+		}
+	}
+	this.store.BatchDelete(blockKey)
+}
+
+//GetAddressHistory return the transactions touched the address from the block height in ascending order.
+//the transactions of a block are kept in one page, so the page may exceed limit. next is the height to read
+//the following page from, more is false if no transaction left. The blocks before the index started are not indexed,
+//so fromHeight should not be lower than the start height
+func (this *EventStore) GetAddressHistory(addr common.Address, fromHeight uint32, limit int) (histories []*scom.AddressHistory, next uint32, more bool, err error) {
+	if fromHeight < this.addressHistoryStart {
+		return nil, 0, false, fmt.Errorf("transfers before height %d are not indexed", this.addressHistoryStart)
+	}
+	iter := this.store.NewIterator(genAddressHistoryPrefix(addr))
+	defer iter.Release()
+	for has := iter.Seek(genAddressHistoryKey(addr, fromHeight, common.UINT256_EMPTY)); has; has = iter.Next() {
+		height, txHash, err := parseAddressHistoryKey(iter.Key())
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if len(histories) >= limit && histories[len(histories)-1].Height != height {
+			return histories, height, true, nil
+		}
+		history := &scom.AddressHistory{
+			TxHash: txHash,
+			Height: height,
+		}
+		source := common.NewZeroCopySource(iter.Value())
+		count, _, irregular, eof := source.NextVarUint()
+		if irregular || eof {
+			return nil, 0, false, fmt.Errorf("invalid address history of tx:%s", txHash.ToHexString())
+		}
+		for i := uint64(0); i < count; i++ {
+			transfer := &scom.Transfer{}
+			if err := transfer.Deserialization(source); err != nil {
+				return nil, 0, false, fmt.Errorf("invalid address history of tx:%s, %s", txHash.ToHexString(), err)
+			}
+			history.Transfers = append(history.Transfers, transfer)
+		}
+		histories = append(histories, history)
+	}
+	return histories, 0, false, iter.Error()
+}
+
+//parseTransfer parse the transfer notification of ont, ong and oep-4 style contracts, nil if not a transfer
+func parseTransfer(info *event.NotifyEventInfo) *scom.Transfer {
+	states, ok := info.States.([]interface{})
+	if !ok || len(states) != 4 {
+		return nil
+	}
+	name, ok := states[0].(string)
+	if !ok || (name != ont.TRANSFER_NAME && name != neovmTransferName) {
+		return nil
+	}
+	from, err := parseNotifyAddress(states[1])
+	if err != nil {
+		return nil
+	}
+	to, err := parseNotifyAddress(states[2])
+	if err != nil {
+		return nil
+	}
+	var amount *big.Int
+	switch value := states[3].(type) {
+	case uint64:
+		amount = new(big.Int).SetUint64(value)
+	case string:
+		if name == neovmTransferName {
+			buf, err := hex.DecodeString(value)
+			if err != nil {
+				return nil
+			}
+			amount = common.BigIntFromNeoBytes(buf)
+		} else if amount, ok = new(big.Int).SetString(value, 10); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if amount.Sign() < 0 {
+		return nil
+	}
+	return &scom.Transfer{
+		Contract: info.ContractAddress,
+		From:     from,
+		To:       to,
+		Amount:   amount,
+	}
+}
+
+//parseNotifyAddress parse the address in base58 or hex string of notification
+func parseNotifyAddress(state interface{}) (common.Address, error) {
+	value, ok := state.(string)
+	if !ok {
+		return common.ADDRESS_EMPTY, fmt.Errorf("invalid address type")
+	}
+	if addr, err := common.AddressFromBase58(value); err == nil {
+		return addr, nil
+	}
+	buf, err := hex.DecodeString(value)
+	if err != nil {
+		return common.ADDRESS_EMPTY, err
+	}
+	return common.AddressParseFromBytes(buf)
+}
+
+func genAddressHistoryPrefix(addr common.Address) []byte {
+	key := make([]byte, 0, 1+common.ADDR_LEN+4+common.UINT256_SIZE)
+	key = append(key, byte(scom.IX_ADDRESS_HISTORY))
+	return append(key, addr[:]...)
+}
+
+func genAddressHistoryKey(addr common.Address, height uint32, txHash common.Uint256) []byte {
+	key := genAddressHistoryPrefix(addr)
+	key = key[:len(key)+4]
+	binary.BigEndian.PutUint32(key[len(key)-4:], height)
+	return append(key, txHash[:]...)
+}
+
+func parseAddressHistoryKey(key []byte) (height uint32, txHash common.Uint256, err error) {
+	if len(key) != 1+common.ADDR_LEN+4+common.UINT256_SIZE {
+		return 0, txHash, fmt.Errorf("invalid address history key:%x", key)
+	}
+	height = binary.BigEndian.Uint32(key[1+common.ADDR_LEN:])
+	copy(txHash[:], key[1+common.ADDR_LEN+4:])
+	return height, txHash, nil
+}
+
+func genAddressHistoryBlockKey(height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(scom.IX_ADDRESS_HISTORY_BLOCK)
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
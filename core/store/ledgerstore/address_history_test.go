@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTransfer(t *testing.T) {
+	from := common.Address{1}
+	to := common.Address{2}
+	contract := common.Address{3}
+
+	native := &event.NotifyEventInfo{
+		ContractAddress: utils.OntContractAddress,
+		States:          []interface{}{"transfer", from.ToBase58(), to.ToBase58(), uint64(100)},
+	}
+	transfer := parseTransfer(native)
+	assert.NotNil(t, transfer)
+	assert.Equal(t, utils.OntContractAddress, transfer.Contract)
+	assert.Equal(t, from, transfer.From)
+	assert.Equal(t, to, transfer.To)
+	assert.Equal(t, big.NewInt(100), transfer.Amount)
+
+	neovm := &event.NotifyEventInfo{
+		ContractAddress: contract,
+		States: []interface{}{hex.EncodeToString([]byte("transfer")), hex.EncodeToString(from[:]),
+			hex.EncodeToString(to[:]), hex.EncodeToString(common.BigIntToNeoBytes(big.NewInt(1000000)))},
+	}
+	transfer = parseTransfer(neovm)
+	assert.NotNil(t, transfer)
+	assert.Equal(t, contract, transfer.Contract)
+	assert.Equal(t, from, transfer.From)
+	assert.Equal(t, big.NewInt(1000000), transfer.Amount)
+
+	wasm := &event.NotifyEventInfo{
+		ContractAddress: contract,
+		States:          []interface{}{"transfer", from.ToBase58(), to.ToBase58(), "123456789012345678901234567890"},
+	}
+	transfer = parseTransfer(wasm)
+	assert.NotNil(t, transfer)
+	assert.Equal(t, "123456789012345678901234567890", transfer.Amount.String())
+
+	invalids := []interface{}{
+		"transfer",
+		[]interface{}{"approve", from.ToBase58(), to.ToBase58(), uint64(1)},
+		[]interface{}{"transfer", "not address", to.ToBase58(), uint64(1)},
+		[]interface{}{"transfer", from.ToBase58(), to.ToBase58(), "not amount"},
+		[]interface{}{"transfer", from.ToBase58(), to.ToBase58(), "-1"},
+		[]interface{}{"transfer", from.ToBase58(), to.ToBase58()},
+	}
+	for _, states := range invalids {
+		assert.Nil(t, parseTransfer(&event.NotifyEventInfo{ContractAddress: contract, States: states}))
+	}
+}
+
+func TestAddressHistory(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	eventStore := &EventStore{store: store}
+	alice := common.Address{1}
+	bob := common.Address{2}
+	transferNotify := func(from, to common.Address, amount uint64) *event.NotifyEventInfo {
+		return &event.NotifyEventInfo{
+			ContractAddress: utils.OngContractAddress,
+			States:          []interface{}{"transfer", from.ToBase58(), to.ToBase58(), amount},
+		}
+	}
+	applyBlock := func(height uint32, notifies ...*event.ExecuteNotify) {
+		eventStore.NewBatch()
+		eventStore.batchAddAddressHistory(height, notifies)
+		assert.Nil(t, eventStore.CommitTo())
+	}
+
+	applyBlock(1, &event.ExecuteNotify{TxHash: common.Uint256{1}, Notify: []*event.NotifyEventInfo{transferNotify(alice, bob, 1)}})
+	histories, _, _, err := eventStore.GetAddressHistory(alice, 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, histories, 0)
+
+	assert.Nil(t, eventStore.EnableAddressHistory(2))
+	start, err := eventStore.GetAddressHistoryStartHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), start)
+	applyBlock(2, &event.ExecuteNotify{TxHash: common.Uint256{2}, Notify: []*event.NotifyEventInfo{
+		transferNotify(alice, bob, 2), transferNotify(bob, alice, 3)}})
+	applyBlock(3, &event.ExecuteNotify{TxHash: common.Uint256{3}, Notify: []*event.NotifyEventInfo{transferNotify(bob, bob, 4)}},
+		&event.ExecuteNotify{TxHash: common.Uint256{4}, Notify: []*event.NotifyEventInfo{transferNotify(alice, bob, 5)}})
+	applyBlock(4, &event.ExecuteNotify{TxHash: common.Uint256{5}, Notify: []*event.NotifyEventInfo{transferNotify(alice, bob, 6)}})
+
+	_, _, _, err = eventStore.GetAddressHistory(alice, 1, 10)
+	assert.NotNil(t, err)
+	histories, _, more, err := eventStore.GetAddressHistory(alice, 2, 10)
+	assert.Nil(t, err)
+	assert.False(t, more)
+	assert.Len(t, histories, 3)
+	assert.Equal(t, uint32(2), histories[0].Height)
+	assert.Equal(t, common.Uint256{2}, histories[0].TxHash)
+	assert.Len(t, histories[0].Transfers, 2)
+	assert.Equal(t, big.NewInt(3), histories[0].Transfers[1].Amount)
+	assert.Equal(t, common.Uint256{4}, histories[1].TxHash)
+
+	// the transactions of block 3 are kept in one page
+	histories, next, more, err := eventStore.GetAddressHistory(bob, 2, 2)
+	assert.Nil(t, err)
+	assert.True(t, more)
+	assert.Equal(t, uint32(4), next)
+	assert.Len(t, histories, 3)
+	assert.Len(t, histories[1].Transfers, 1)
+	histories, _, more, err = eventStore.GetAddressHistory(bob, next, 2)
+	assert.Nil(t, err)
+	assert.False(t, more)
+	assert.Len(t, histories, 1)
+	assert.Equal(t, common.Uint256{5}, histories[0].TxHash)
+
+	eventStore.NewBatch()
+	eventStore.PruneBlock(2, nil)
+	eventStore.PruneBlock(3, nil)
+	assert.Nil(t, eventStore.CommitTo())
+	histories, _, _, err = eventStore.GetAddressHistory(bob, 2, 10)
+	assert.Nil(t, err)
+	assert.Len(t, histories, 1)
+	assert.Equal(t, uint32(4), histories[0].Height)
+
+	// restarted with index continuous with the last block
+	eventStore = &EventStore{store: store}
+	assert.Nil(t, eventStore.EnableAddressHistory(5))
+	start, err = eventStore.GetAddressHistoryStartHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), start)
+
+	// block 5 committed without index, the index restarts
+	eventStore = &EventStore{store: store}
+	applyBlock(5, &event.ExecuteNotify{TxHash: common.Uint256{6}, Notify: []*event.NotifyEventInfo{transferNotify(alice, bob, 7)}})
+	assert.Nil(t, eventStore.EnableAddressHistory(6))
+	start, err = eventStore.GetAddressHistoryStartHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(6), start)
+	_, _, _, err = eventStore.GetAddressHistory(bob, 4, 10)
+	assert.NotNil(t, err)
+}
@@ -27,16 +27,15 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/serialization"
 	scom "github.com/ontio/ontology/core/store/common"
-	"github.com/ontio/ontology/core/store/leveldbstore"
 	"github.com/ontio/ontology/core/types"
 )
 
 //Block store save the data of block & transaction
 type BlockStore struct {
-	enableCache bool                       //Is enable lru cache
-	dbDir       string                     //The path of store file
-	cache       *BlockCache                //The cache of block, if have.
-	store       *leveldbstore.LevelDBStore //block store handler
+	enableCache bool              //Is enable lru cache
+	dbDir       string            //The path of store file
+	cache       *BlockCache       //The cache of block, if have.
+	store       scom.PersistStore //block store handler
 }
 
 //NewBlockStore return the block store instance
@@ -50,7 +49,7 @@ func NewBlockStore(dbDir string, enableCache bool) (*BlockStore, error) {
 		}
 	}
 
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := newPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -446,8 +445,18 @@ func (this *BlockStore) SaveVersion(ver byte) error {
 func (this *BlockStore) ClearAll() error {
 	this.NewBatch()
 	iter := this.store.NewIterator(nil)
+	count := 0
 	for iter.Next() {
 		this.store.BatchDelete(iter.Key())
+		count++
+		//commit in pieces, persist stores may limit the size of a batch
+		if count%SNAPSHOT_BATCH_SIZE == 0 {
+			if err := this.CommitTo(); err != nil {
+				iter.Release()
+				return err
+			}
+			this.NewBatch()
+		}
 	}
 	iter.Release()
 	if err := iter.Error(); err != nil {
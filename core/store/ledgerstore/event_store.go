@@ -28,19 +28,20 @@ import (
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/common/serialization"
 	scom "github.com/ontio/ontology/core/store/common"
-	"github.com/ontio/ontology/core/store/leveldbstore"
 	"github.com/ontio/ontology/smartcontract/event"
 )
 
 //Saving event notifies gen by smart contract execution
 type EventStore struct {
-	dbDir string                     //Store path
-	store *leveldbstore.LevelDBStore //Store handler
+	dbDir               string            //Store path
+	store               scom.PersistStore //Store handler
+	addressHistory      bool              //Whether index the transfers by address
+	addressHistoryStart uint32            //First block height of address history index
 }
 
 //NewEventStore return event store instance
 func NewEventStore(dbDir string) (*EventStore, error) {
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := newPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +127,7 @@ func (this *EventStore) PruneBlock(height uint32, hashes []common.Uint256) {
 	for _, hash := range hashes {
 		this.store.BatchDelete(genEventNotifyByTxKey(hash))
 	}
+	this.pruneAddressHistory(height)
 }
 
 //CommitTo event store batch to store
@@ -142,8 +144,18 @@ func (this *EventStore) Close() error {
 func (this *EventStore) ClearAll() error {
 	this.NewBatch()
 	iter := this.store.NewIterator(nil)
+	count := 0
 	for iter.Next() {
 		this.store.BatchDelete(iter.Key())
+		count++
+		//commit in pieces, persist stores may limit the size of a batch
+		if count%SNAPSHOT_BATCH_SIZE == 0 {
+			if err := this.CommitTo(); err != nil {
+				iter.Release()
+				return err
+			}
+			this.NewBatch()
+		}
 	}
 	iter.Release()
 	if err := iter.Error(); err != nil {
@@ -36,11 +36,15 @@ import (
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/core/store"
+	_ "github.com/ontio/ontology/core/store/badgerstore"
 	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	_ "github.com/ontio/ontology/core/store/memstore"
 	"github.com/ontio/ontology/core/store/overlaydb"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/errors"
@@ -54,6 +58,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/service/wasmvm"
 	sstate "github.com/ontio/ontology/smartcontract/states"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/smartcontract/trace"
 	types2 "github.com/ontio/ontology/vm/neovm/types"
 )
 
@@ -70,10 +75,20 @@ var (
 	MerkleTreeStorePath = "merkle_tree.db"
 )
 
+//newPersistStore open the persist store in dbDir with the backend of config
+func newPersistStore(dbDir string) (scom.PersistStore, error) {
+	backend := config.DefConfig.Common.StoreBackend
+	if backend == "" {
+		backend = leveldbstore.BACKEND_NAME
+	}
+	return scom.NewPersistStore(backend, dbDir)
+}
+
 type PrexecuteParam struct {
 	JitMode    bool
 	WasmFactor uint64
 	MinGas     bool
+	Profile    bool // profile the gas of the invocation, wasm contracts run in interpreter if enabled
 }
 
 //LedgerStoreImp is main store struct fo ledger
@@ -154,6 +169,10 @@ func (this *LedgerStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.
 		if err != nil {
 			return fmt.Errorf("eventStore.ClearAll error %s", err)
 		}
+		err = this.stateStore.initStorageStats()
+		if err != nil {
+			return fmt.Errorf("initStorageStats error %s", err)
+		}
 		defaultBookkeeper = keypair.SortPublicKeys(defaultBookkeeper)
 		bookkeeperState := &states.BookkeeperState{
 			CurrBookkeeper: defaultBookkeeper,
@@ -187,6 +206,10 @@ func (this *LedgerStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.
 		if !exist {
 			return fmt.Errorf("GenesisBlock arenot init correctly")
 		}
+		err = this.stateStore.initStorageStats()
+		if err != nil {
+			return fmt.Errorf("initStorageStats error %s", err)
+		}
 		err = this.init()
 		if err != nil {
 			return fmt.Errorf("init error %s", err)
@@ -441,6 +464,15 @@ func (this *LedgerStoreImp) verifyHeader(header *types.Header) error {
 	if err != nil && err != scom.ErrNotFound {
 		return fmt.Errorf("get prev header error %s", err)
 	}
+	if err := this.checkHeader(header, prevHeader); err != nil {
+		return scom.NewInvalidBlockError("%s", err)
+	}
+	return nil
+}
+
+//checkHeader return error if header is invalid on prevHeader
+func (this *LedgerStoreImp) checkHeader(header, prevHeader *types.Header) error {
+	prevHeaderHash := header.PrevBlockHash
 	if prevHeader == nil {
 		return fmt.Errorf("cannot find pre header by blockHash %s", prevHeaderHash.ToHexString())
 	}
@@ -662,28 +694,34 @@ func (this *LedgerStoreImp) AddBlock(block *types.Block, ccMsg *types.CrossChain
 	}
 	err := this.verifyHeader(block.Header)
 	if err != nil {
+		if scom.IsInvalidBlock(err) {
+			return scom.NewInvalidBlockError("verifyHeader error %s", err)
+		}
 		return fmt.Errorf("verifyHeader error %s", err)
 	}
 	if ccMsg != nil {
 		if ccMsg.Height != currBlockHeight {
-			return fmt.Errorf("cross chain msg height %d not equal next block height %d", blockHeight, ccMsg.Height)
+			return scom.NewInvalidBlockError("cross chain msg height %d not equal next block height %d", blockHeight, ccMsg.Height)
 		}
 		if ccMsg.Version != types.CURR_CROSS_STATES_VERSION {
-			return fmt.Errorf("error cross chain msg version excepted:%d actual:%d", types.CURR_CROSS_STATES_VERSION, ccMsg.Version)
+			return scom.NewInvalidBlockError("error cross chain msg version excepted:%d actual:%d", types.CURR_CROSS_STATES_VERSION, ccMsg.Version)
 		}
 		root, err := this.stateStore.GetCrossStatesRoot(ccMsg.Height)
 		if err != nil {
 			return fmt.Errorf("get cross states root fail:%s", err)
 		}
 		if root != ccMsg.StatesRoot {
-			return fmt.Errorf("cross state root compare fail, expected:%x actual:%x", ccMsg.StatesRoot, root)
+			return scom.NewInvalidBlockError("cross state root compare fail, expected:%x actual:%x", ccMsg.StatesRoot, root)
 		}
 		if err := this.verifyCrossChainMsg(ccMsg, block.Header.Bookkeepers); err != nil {
-			return fmt.Errorf("verifyCrossChainMsg error: %s", err)
+			return scom.NewInvalidBlockError("verifyCrossChainMsg error: %s", err)
 		}
 	}
 	err = this.saveBlock(block, ccMsg, stateMerkleRoot)
 	if err != nil {
+		if scom.IsInvalidBlock(err) {
+			return scom.NewInvalidBlockError("saveBlock error %s", err)
+		}
 		return fmt.Errorf("saveBlock error %s", err)
 	}
 	this.delHeaderCache(block.Hash())
@@ -712,6 +750,7 @@ func (this *LedgerStoreImp) saveBlockToBlockStore(block *types.Block) error {
 }
 
 func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.ExecuteResult, err error) {
+	defer observeLatency(blockExecuteLatencyMetric, time.Now())
 	overlay := this.stateStore.NewOverlayDB()
 	if block.Header.Height != 0 {
 		config := &smartcontract.Config{
@@ -725,19 +764,12 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 			return
 		}
 	}
-	gasTable := make(map[string]uint64)
-	neovm.GAS_TABLE.Range(func(k, value interface{}) bool {
-		key := k.(string)
-		val := value.(uint64)
-		gasTable[key] = val
-
-		return true
-	})
+	gasTable := getGasTable()
 
 	cache := storage.NewCacheDB(overlay)
 	for _, tx := range block.Transactions {
 		cache.Reset()
-		notify, crossStateHashes, e := this.handleTransaction(overlay, cache, gasTable, block, tx)
+		notify, crossStateHashes, e := this.handleTransaction(overlay, cache, gasTable, block, tx, nil)
 		if e != nil {
 			err = e
 			return
@@ -771,6 +803,62 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 	return
 }
 
+func getGasTable() map[string]uint64 {
+	gasTable := make(map[string]uint64)
+	neovm.GAS_TABLE.Range(func(k, value interface{}) bool {
+		key := k.(string)
+		val := value.(uint64)
+		gasTable[key] = val
+
+		return true
+	})
+	return gasTable
+}
+
+//TraceTransaction re-execute the committed transaction on the states before it with the tracer.
+//The transactions before it in the block are replayed, so the state archive should be enabled before the block
+func (this *LedgerStoreImp) TraceTransaction(txHash common.Uint256, tracer trace.Tracer) (*event.ExecuteNotify, error) {
+	_, height, err := this.GetTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if height == 0 {
+		return nil, fmt.Errorf("transaction in genesis block can not be traced")
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := this.stateStore.NewOverlayDBAt(height - 1)
+	//replay with the global params of the block, as executeBlock does, without changing the params of the tip
+	config := &smartcontract.Config{
+		Time:   block.Header.Timestamp,
+		Height: block.Header.Height,
+		Tx:     &types.Transaction{},
+	}
+	gasTable, err := loadGasTable(config, storage.NewCacheDB(this.stateStore.NewOverlayDBAt(height-1)), this)
+	if err != nil {
+		return nil, fmt.Errorf("load global params at height %d error %s", height-1, err)
+	}
+	cache := storage.NewCacheDB(overlay)
+	for _, tx := range block.Transactions {
+		var txTracer trace.Tracer
+		if tx.Hash() == txHash {
+			txTracer = tracer
+		}
+		cache.Reset()
+		notify, _, err := this.handleTransaction(overlay, cache, gasTable, block, tx, txTracer)
+		if err != nil {
+			return nil, err
+		}
+		if txTracer != nil {
+			return notify, nil
+		}
+	}
+	return nil, fmt.Errorf("transaction %s not found in block %d", txHash.ToHexString(), height)
+}
+
 func calculateTotalStateHash(overlay *overlaydb.OverlayDB) (result common.Uint256, err error) {
 	stateDiff := sha256.New()
 	iter := overlay.NewIterator([]byte{byte(scom.ST_CONTRACT)})
@@ -808,6 +896,7 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 	for _, notify := range result.Notify {
 		SaveNotify(this.eventStore, notify.TxHash, notify)
 	}
+	this.eventStore.batchAddAddressHistory(blockHeight, result.Notify)
 
 	err := this.stateStore.AddStateMerkleTreeRoot(blockHeight, result.Hash)
 	if err != nil {
@@ -831,6 +920,15 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 
 	log.Debugf("the state transition hash of block %d is:%s", blockHeight, result.Hash.ToHexString())
 
+	err = this.stateStore.batchAddArchiveJournal(blockHeight, result.WriteSet)
+	if err != nil {
+		return fmt.Errorf("batchAddArchiveJournal error %s", err)
+	}
+	err = this.stateStore.batchUpdateStorageStats(result.WriteSet)
+	if err != nil {
+		return fmt.Errorf("batchUpdateStorageStats error %s", err)
+	}
+
 	result.WriteSet.ForEach(func(key, val []byte) {
 		if len(val) == 0 {
 			this.stateStore.BatchDeleteRawKey(key)
@@ -908,6 +1006,7 @@ func (this *LedgerStoreImp) tryPruneBlock(header *types.Header) bool {
 
 //saveBlock do the job of execution samrt contract and commit block to store.
 func (this *LedgerStoreImp) submitBlock(block *types.Block, crossChainMsg *types.CrossChainMsg, result store.ExecuteResult) error {
+	defer observeLatency(blockCommitLatencyMetric, time.Now())
 	blockHash := block.Hash()
 	blockHeight := block.Header.Height
 	blockRoot := this.GetBlockRootWithNewTxRoots(block.Header.Height, []common.Uint256{block.Header.TransactionsRoot})
@@ -947,6 +1046,7 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, crossChainMsg *types
 		return fmt.Errorf("stateStore.CommitTo height:%d error %s", blockHeight, err)
 	}
 	this.setCurrentBlock(blockHeight, blockHash)
+	blockTxsMetric.Add(float64(len(block.Transactions)))
 
 	if events.DefActorPublisher != nil {
 		events.DefActorPublisher.Publish(
@@ -981,7 +1081,7 @@ func (this *LedgerStoreImp) saveBlock(block *types.Block, ccMsg *types.CrossChai
 	//empty block does not check stateMerkleRoot
 	if len(block.Transactions) != 0 && result.MerkleRoot != stateMerkleRoot {
 		log.Infof("state mismatch at block height: %d, changeset: %s", block.Header.Height, result.WriteSet.DumpToDot())
-		return fmt.Errorf("state merkle root mismatch. expected: %s, got: %s",
+		return scom.NewInvalidBlockError("state merkle root mismatch. expected: %s, got: %s",
 			result.MerkleRoot.ToHexString(), stateMerkleRoot.ToHexString())
 	}
 
@@ -989,7 +1089,7 @@ func (this *LedgerStoreImp) saveBlock(block *types.Block, ccMsg *types.CrossChai
 }
 
 func (this *LedgerStoreImp) handleTransaction(overlay *overlaydb.OverlayDB, cache *storage.CacheDB, gasTable map[string]uint64,
-	block *types.Block, tx *types.Transaction) (*event.ExecuteNotify, []common.Uint256, error) {
+	block *types.Block, tx *types.Transaction, tracer trace.Tracer) (*event.ExecuteNotify, []common.Uint256, error) {
 	txHash := tx.Hash()
 	notify := &event.ExecuteNotify{TxHash: txHash, State: event.CONTRACT_STATE_FAIL}
 	var crossStateHashes []common.Uint256
@@ -1004,7 +1104,7 @@ func (this *LedgerStoreImp) handleTransaction(overlay *overlaydb.OverlayDB, cach
 			log.Debugf("HandleDeployTransaction tx %s error %s", txHash.ToHexString(), err)
 		}
 	case types.InvokeNeo, types.InvokeWasm:
-		crossStateHashes, err = this.stateStore.HandleInvokeTransaction(this, overlay, gasTable, cache, tx, block, notify)
+		crossStateHashes, err = this.stateStore.HandleInvokeTransaction(this, overlay, gasTable, cache, tx, block, notify, tracer)
 		if overlay.Error() != nil {
 			return nil, nil, fmt.Errorf("HandleInvokeTransaction tx %s error %s", txHash.ToHexString(), overlay.Error())
 		}
@@ -1166,6 +1266,25 @@ func (this *LedgerStoreImp) GetStorageItem(key *states.StorageKey) (*states.Stor
 	return this.stateStore.GetStorageState(key)
 }
 
+//GetStorageItemAt return the storage value of the key at the end of block height. The state archive should be enabled before the height
+func (this *LedgerStoreImp) GetStorageItemAt(height uint32, key *states.StorageKey) (*states.StorageItem, error) {
+	if height > this.GetCurrentBlockHeight() {
+		return nil, fmt.Errorf("height %d is higher than current block height", height)
+	}
+	return this.stateStore.GetStorageStateAt(height, key)
+}
+
+//GetContractStorageStats return the key count and bytes of the contract storage at the end of current block
+func (this *LedgerStoreImp) GetContractStorageStats(contract common.Address) (*scom.StorageStats, error) {
+	return this.stateStore.GetStorageStats(contract)
+}
+
+//FindStorageItems return a page of at most limit storage items of contract whose keys have the prefix, from the key not less than start.
+//next is the start of the following page, nil if no item left
+func (this *LedgerStoreImp) FindStorageItems(contract common.Address, prefix, start []byte, limit int) ([]*scom.KeyValue, []byte, error) {
+	return this.stateStore.FindStorageStates(contract, prefix, start, limit)
+}
+
 //GetEventNotifyByTx return the events notify gen by executing of smart contract.  Wrap function of EventStore.GetEventNotifyByTx
 func (this *LedgerStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return this.eventStore.GetEventNotifyByTx(tx)
@@ -1176,6 +1295,14 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetAddressHistory return a page of the transactions which transfers touched the address from the block height
+func (this *LedgerStoreImp) GetAddressHistory(addr common.Address, fromHeight uint32, limit int) ([]*scom.AddressHistory, uint32, bool, error) {
+	if !this.eventStore.addressHistory {
+		return nil, 0, false, fmt.Errorf("address history index is disabled")
+	}
+	return this.eventStore.GetAddressHistory(addr, fromHeight, limit)
+}
+
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContractBatch(txes []*types.Transaction, atomic bool) ([]*sstate.PreExecResult, uint32, error) {
 	if atomic {
@@ -1199,6 +1326,60 @@ func (this *LedgerStoreImp) PreExecuteContractBatch(txes []*types.Transaction, a
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContractWithParam(tx *types.Transaction, preParam PrexecuteParam) (*sstate.PreExecResult, error) {
 	height := this.GetCurrentBlockHeight()
+	return this.preExecuteContract(tx, preParam, height, this.stateStore.NewOverlayDB())
+}
+
+//PreExecuteContractAt return the result of smart contract execution on the states at the end of block height.
+//The state archive should be enabled before the height
+func (this *LedgerStoreImp) PreExecuteContractAt(height uint32, tx *types.Transaction) (*sstate.PreExecResult, error) {
+	if height > this.GetCurrentBlockHeight() {
+		return nil, fmt.Errorf("height %d is higher than current block height", height)
+	}
+	param := PrexecuteParam{
+		JitMode:    false,
+		WasmFactor: 0,
+		MinGas:     true,
+	}
+
+	return this.preExecuteContract(tx, param, height, this.stateStore.NewOverlayDBAt(height))
+}
+
+//PreExecuteContractWithProfile return the result of smart contract execution with the gas profile, on the states at
+//the end of block height, or the current states if height is nil
+func (this *LedgerStoreImp) PreExecuteContractWithProfile(height *uint32, tx *types.Transaction) (*sstate.PreExecResult, error) {
+	param := PrexecuteParam{
+		JitMode:    false,
+		WasmFactor: 0,
+		MinGas:     true,
+		Profile:    true,
+	}
+	if height == nil {
+		return this.PreExecuteContractWithParam(tx, param)
+	}
+	if *height > this.GetCurrentBlockHeight() {
+		return nil, fmt.Errorf("height %d is higher than current block height", *height)
+	}
+
+	return this.preExecuteContract(tx, param, *height, this.stateStore.NewOverlayDBAt(*height))
+}
+
+//PreExecuteContractBatchAt return the results of smart contracts execution on the states at the end of block height
+func (this *LedgerStoreImp) PreExecuteContractBatchAt(height uint32, txes []*types.Transaction) ([]*sstate.PreExecResult, error) {
+	results := make([]*sstate.PreExecResult, 0, len(txes))
+	for _, tx := range txes {
+		res, err := this.PreExecuteContractAt(height, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+func (this *LedgerStoreImp) preExecuteContract(tx *types.Transaction, preParam PrexecuteParam, height uint32,
+	overlay *overlaydb.OverlayDB) (*sstate.PreExecResult, error) {
 	// use previous block time to make it predictable for easy test
 	blockTime := uint32(time.Now().Unix())
 	if header, err := this.GetHeaderByHeight(height); err == nil {
@@ -1213,7 +1394,6 @@ func (this *LedgerStoreImp) PreExecuteContractWithParam(tx *types.Transaction, p
 		BlockHash: this.GetBlockHash(height),
 	}
 
-	overlay := this.stateStore.NewOverlayDB()
 	cache := storage.NewCacheDB(overlay)
 	gasTable := make(map[string]uint64)
 	neovm.GAS_TABLE.Range(func(k, value interface{}) bool {
@@ -1241,6 +1421,12 @@ func (this *LedgerStoreImp) PreExecuteContractWithParam(tx *types.Transaction, p
 			JitMode:      preParam.JitMode,
 			PreExec:      true,
 		}
+		var profiler *trace.GasProfiler
+		if preParam.Profile {
+			profiler = trace.NewGasProfiler()
+			sc.Tracer = profiler
+			cache.SetTracer(profiler)
+		}
 		//start the smart contract executive function
 		engine, _ := sc.NewExecuteEngine(invoke.Code, tx.TxType)
 
@@ -1271,9 +1457,16 @@ func (this *LedgerStoreImp) PreExecuteContractWithParam(tx *types.Transaction, p
 			cv = common.ToHexString(result.([]byte))
 		}
 
-		return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: gasCost, Result: cv, Notify: sc.Notifications}, nil
+		preResult := &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: gasCost, Result: cv, Notify: sc.Notifications}
+		if profiler != nil {
+			preResult.Profile = profiler.Result().(*trace.GasProfile)
+		}
+		return preResult, nil
 	} else if tx.TxType == types.Deploy {
 		deploy := tx.Payload.(*payload.DeployCode)
+		if err := manifest.Verify(deploy.GetManifest(), sconfig.Height, config.GetContractManifestHeight()); err != nil {
+			return stf, err
+		}
 
 		if deploy.VmType() == payload.WASMVM_TYPE {
 			wasmCode := deploy.GetRawCode()
@@ -1291,7 +1484,7 @@ func (this *LedgerStoreImp) PreExecuteContractWithParam(tx *types.Transaction, p
 			}
 		}
 
-		return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: gasTable[neovm.CONTRACT_CREATE_NAME] + calcGasByCodeLen(len(deploy.GetRawCode()), gasTable[neovm.UINT_DEPLOY_CODE_LEN_NAME]), Result: nil}, nil
+		return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: gasTable[neovm.CONTRACT_CREATE_NAME] + calcGasByCodeLen(deploy.DeployCodeLen(), gasTable[neovm.UINT_DEPLOY_CODE_LEN_NAME]), Result: nil}, nil
 	} else {
 		return stf, errors.NewErr("transaction type error")
 	}
@@ -1331,6 +1524,22 @@ func (this *LedgerStoreImp) Close() error {
 	return nil
 }
 
+//EnableStateArchive journal the state changes of following blocks to support history state query
+func (this *LedgerStoreImp) EnableStateArchive() error {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	return this.stateStore.EnableArchive(this.GetCurrentBlockHeight() + 1)
+}
+
+//EnableAddressHistory index the transfers of following blocks by address to support address history query
+func (this *LedgerStoreImp) EnableAddressHistory() error {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	return this.eventStore.EnableAddressHistory(this.GetCurrentBlockHeight() + 1)
+}
+
 const minPruneBlocksBeforeCurr = 1000
 
 func (this *LedgerStoreImp) EnableBlockPrune(numBeforeCurr uint32) {
@@ -25,9 +25,13 @@ import (
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/genesis"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
 )
 
 var testBlockStore *BlockStore
@@ -138,3 +142,30 @@ func TestInitLedgerStoreWithGenesisBlock(t *testing.T) {
 		return
 	}
 }
+
+func TestAddInvalidBlock(t *testing.T) {
+	bookkeepers := []keypair.PublicKey{account.NewAccount("").PublicKey}
+	block, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	store, err := NewLedgerStore("test/invalid", 0)
+	assert.Nil(t, err)
+	defer store.Close()
+	assert.Nil(t, store.InitLedgerStoreWithGenesisBlock(block, bookkeepers))
+
+	header := &types.Header{
+		PrevBlockHash:    common.Uint256{1},
+		TransactionsRoot: common.ComputeMerkleRoot(nil),
+		Timestamp:        block.Header.Timestamp + 1,
+		Height:           1,
+		Bookkeepers:      bookkeepers,
+	}
+	err = store.AddBlock(&types.Block{Header: header}, nil, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	assert.True(t, scom.IsInvalidBlock(err))
+
+	header.PrevBlockHash = block.Hash()
+	ccMsg := &types.CrossChainMsg{Version: types.CURR_CROSS_STATES_VERSION + 1}
+	err = store.AddBlock(&types.Block{Header: header}, ccMsg, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	assert.True(t, scom.IsInvalidBlock(err))
+}
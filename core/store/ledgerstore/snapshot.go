@@ -0,0 +1,665 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/common/serialization"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/merkle"
+)
+
+const (
+	SNAPSHOT_VERSION    = byte(1)
+	SNAPSHOT_BATCH_SIZE = 10000 //Count of records committed in one batch when importing snapshot
+)
+
+var snapshotMagic = []byte("ONTSNAP")
+
+// A snapshot at height H is laid out as:
+//   magic, version, H, block hash of H, state merkle root of H
+//   headers of block 0 .. H-1
+//   block H
+//   cross chain msg of H-1 and H
+//   block merkle tree hash store file
+//   state key-values, except the local index
+//   state digest of the key-values
+//   sha256 digest of all the above
+
+//ExportSnapshot writes the state of current block height with the header chain to w.
+//The state merkle root and the state digest are recorded to verify the snapshot when importing.
+func (this *LedgerStoreImp) ExportSnapshot(w io.Writer) (*store.SnapshotInfo, error) {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	height, blockHash := this.GetCurrentBlock()
+	stateHash, stateHeight, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if stateHeight != height || stateHash != blockHash {
+		return nil, fmt.Errorf("state height %d is inconsistent with block height %d", stateHeight, height)
+	}
+	stateRoot, err := this.stateStore.GetStateMerkleRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetStateMerkleRoot height:%d error %s", height, err)
+	}
+	info := &store.SnapshotInfo{
+		Version:         SNAPSHOT_VERSION,
+		Height:          height,
+		BlockHash:       blockHash,
+		StateMerkleRoot: stateRoot,
+	}
+
+	bw := bufio.NewWriter(w)
+	hasher := sha256.New()
+	writer := io.MultiWriter(bw, hasher)
+	err = writeSnapshotInfo(writer, info)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < height; i++ {
+		header, err := this.blockStore.GetHeader(this.getHeaderIndex(i))
+		if err != nil {
+			return nil, fmt.Errorf("GetHeader height:%d error %s", i, err)
+		}
+		err = serialization.WriteVarBytes(writer, header.ToArray())
+		if err != nil {
+			return nil, err
+		}
+	}
+	block, err := this.blockStore.GetBlock(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlock height:%d error %s", height, err)
+	}
+	err = serialization.WriteVarBytes(writer, block.ToArray())
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range snapshotCrossChainMsgHeights(height) {
+		msg, err := this.crossChainStore.GetCrossChainMsg(h)
+		if err != nil {
+			return nil, fmt.Errorf("GetCrossChainMsg height:%d error %s", h, err)
+		}
+		var data []byte
+		if msg != nil {
+			sink := common.NewZeroCopySink(nil)
+			msg.Serialization(sink)
+			data = sink.Bytes()
+		}
+		err = serialization.WriteVarBytes(writer, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = this.exportMerkleHashStore(writer)
+	if err != nil {
+		return nil, fmt.Errorf("export merkle hash store error %s", err)
+	}
+
+	stateHasher := sha256.New()
+	err = this.iterateSnapshotState(func(key, value []byte) error {
+		err := writeSnapshotRecord(writer, key, value)
+		if err != nil {
+			return err
+		}
+		return writeSnapshotRecord(stateHasher, key, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = serialization.WriteByte(writer, 0)
+	if err != nil {
+		return nil, err
+	}
+	copy(info.StateDigest[:], stateHasher.Sum(nil))
+	err = info.StateDigest.Serialize(writer)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = bw.Write(hasher.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return info, bw.Flush()
+}
+
+//ImportSnapshot restores an empty ledger store from snapshot. The header chain must start from
+//genesisBlock and be signed by the bookkeepers, and the block hash, the state merkle root and the state digest
+//must equal blockHash, stateRoot and stateDigest if they are not empty. At least one of the trusted blockHash
+//and stateRoot is required.
+func (this *LedgerStoreImp) ImportSnapshot(r io.Reader, genesisBlock *types.Block,
+	blockHash, stateRoot, stateDigest common.Uint256) (*store.SnapshotInfo, error) {
+	if blockHash == common.UINT256_EMPTY && stateRoot == common.UINT256_EMPTY {
+		return nil, fmt.Errorf("trusted block hash or state merkle root is required to import snapshot")
+	}
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	hasInit, err := this.hasAlreadyInitGenesisBlock()
+	if err != nil {
+		return nil, fmt.Errorf("hasAlreadyInit error %s", err)
+	}
+	if hasInit {
+		return nil, scom.ErrAlreadyInitialized
+	}
+	err = this.clearStores()
+	if err != nil {
+		return nil, err
+	}
+	info, err := this.importSnapshot(r, genesisBlock, blockHash, stateRoot, stateDigest)
+	if err != nil {
+		if e := this.clearStores(); e != nil {
+			log.Errorf("ImportSnapshot: clear stores error %s", e)
+		}
+		return nil, err
+	}
+	//the version marks the ledger initialized, save it at last
+	err = this.blockStore.SaveVersion(SYSTEM_VERSION)
+	if err != nil {
+		return nil, fmt.Errorf("SaveVersion error %s", err)
+	}
+	log.Infof("ImportSnapshot: height %d block hash %s state merkle root %s", info.Height,
+		info.BlockHash.ToHexString(), info.StateMerkleRoot.ToHexString())
+	return info, nil
+}
+
+func (this *LedgerStoreImp) importSnapshot(r io.Reader, genesisBlock *types.Block,
+	blockHash, stateRoot, stateDigest common.Uint256) (*store.SnapshotInfo, error) {
+	br := bufio.NewReader(r)
+	hasher := sha256.New()
+	reader := io.TeeReader(br, hasher)
+
+	info, err := readSnapshotInfo(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot info error %s", err)
+	}
+	if blockHash != common.UINT256_EMPTY && blockHash != info.BlockHash {
+		return nil, fmt.Errorf("block hash %s is not the expected %s", info.BlockHash.ToHexString(),
+			blockHash.ToHexString())
+	}
+	if stateRoot != common.UINT256_EMPTY && stateRoot != info.StateMerkleRoot {
+		return nil, fmt.Errorf("state merkle root %s is not the expected %s",
+			info.StateMerkleRoot.ToHexString(), stateRoot.ToHexString())
+	}
+	err = this.initGenesisPeerInfo(genesisBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	var prevHeader *types.Header
+	headerIndex := make([]common.Uint256, 0, HEADER_INDEX_BATCH_SIZE)
+	this.blockStore.NewBatch()
+	for i := uint32(0); i < info.Height; i++ {
+		data, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read header height:%d error %s", i, err)
+		}
+		header, err := types.HeaderFromRawBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("header height:%d deserialize error %s", i, err)
+		}
+		err = this.checkSnapshotHeader(header, prevHeader, i, genesisBlock)
+		if err != nil {
+			return nil, err
+		}
+		blockHash := header.Hash()
+		err = this.blockStore.SaveHeader(&types.Block{Header: header}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("SaveHeader height:%d error %s", i, err)
+		}
+		this.blockStore.SaveBlockHash(i, blockHash)
+		headerIndex = append(headerIndex, blockHash)
+		if len(headerIndex) == int(HEADER_INDEX_BATCH_SIZE) {
+			this.blockStore.SaveHeaderIndexList(i+1-HEADER_INDEX_BATCH_SIZE, headerIndex)
+			headerIndex = make([]common.Uint256, 0, HEADER_INDEX_BATCH_SIZE)
+		}
+		if (i+1)%SNAPSHOT_BATCH_SIZE == 0 {
+			err = this.blockStore.CommitTo()
+			if err != nil {
+				return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+			}
+			this.blockStore.NewBatch()
+		}
+		prevHeader = header
+	}
+	data, err := serialization.ReadVarBytes(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read block height:%d error %s", info.Height, err)
+	}
+	block, err := types.BlockFromRawBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("block height:%d deserialize error %s", info.Height, err)
+	}
+	err = this.checkSnapshotHeader(block.Header, prevHeader, info.Height, genesisBlock)
+	if err != nil {
+		return nil, err
+	}
+	if blockHash := block.Hash(); blockHash != info.BlockHash {
+		return nil, fmt.Errorf("block hash %s is not the expected %s", blockHash.ToHexString(),
+			info.BlockHash.ToHexString())
+	}
+	err = this.blockStore.SaveBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("SaveBlock height:%d error %s", info.Height, err)
+	}
+	this.blockStore.SaveBlockHash(info.Height, info.BlockHash)
+	err = this.blockStore.SaveCurrentBlock(info.Height, info.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("SaveCurrentBlock error %s", err)
+	}
+	err = this.blockStore.CommitTo()
+	if err != nil {
+		return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+
+	for _, h := range snapshotCrossChainMsgHeights(info.Height) {
+		data, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read cross chain msg height:%d error %s", h, err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		msg := new(types.CrossChainMsg)
+		err = msg.Deserialization(common.NewZeroCopySource(data))
+		if err != nil {
+			return nil, fmt.Errorf("cross chain msg height:%d deserialize error %s", h, err)
+		}
+		if msg.Height != h {
+			return nil, fmt.Errorf("cross chain msg height %d is not the expected %d", msg.Height, h)
+		}
+		err = this.crossChainStore.SaveMsgToCrossChainStore(msg)
+		if err != nil {
+			return nil, fmt.Errorf("SaveMsgToCrossChainStore height:%d error %s", h, err)
+		}
+	}
+	err = this.importMerkleHashStore(reader)
+	if err != nil {
+		return nil, fmt.Errorf("import merkle hash store error %s", err)
+	}
+
+	count := 0
+	this.stateStore.NewBatch()
+	for {
+		key, value, err := readSnapshotRecord(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read state error %s", err)
+		}
+		if key == nil {
+			break
+		}
+		if isLocalIndexKey(key) {
+			return nil, fmt.Errorf("unexpected local index key %x", key)
+		}
+		this.stateStore.BatchPutRawKeyVal(key, value)
+		count++
+		if count%SNAPSHOT_BATCH_SIZE == 0 {
+			err = this.stateStore.CommitTo()
+			if err != nil {
+				return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+			}
+			this.stateStore.NewBatch()
+		}
+	}
+	err = this.stateStore.CommitTo()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+	}
+	err = info.StateDigest.Deserialize(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read state digest error %s", err)
+	}
+	if stateDigest != common.UINT256_EMPTY && stateDigest != info.StateDigest {
+		return nil, fmt.Errorf("state digest %s is not the expected %s",
+			info.StateDigest.ToHexString(), stateDigest.ToHexString())
+	}
+	err = this.stateStore.initStorageStats()
+	if err != nil {
+		return nil, fmt.Errorf("initStorageStats error %s", err)
+	}
+
+	digest := make([]byte, sha256.Size)
+	_, err = io.ReadFull(br, digest)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot digest error %s", err)
+	}
+	if !bytes.Equal(digest, hasher.Sum(nil)) {
+		return nil, fmt.Errorf("snapshot digest mismatch, the file may be corrupted")
+	}
+
+	err = this.stateStore.init(info.Height)
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.init error %s", err)
+	}
+	err = this.verifySnapshotState(info, block.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	this.eventStore.NewBatch()
+	this.eventStore.SaveCurrentBlock(info.Height, info.BlockHash)
+	err = this.eventStore.CommitTo()
+	if err != nil {
+		return nil, fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	return info, nil
+}
+
+//verifySnapshotState checks the imported state against the block header, the state digest and the state merkle root.
+func (this *LedgerStoreImp) verifySnapshotState(info *store.SnapshotInfo, header *types.Header) error {
+	blockHash, height, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if height != info.Height || blockHash != info.BlockHash {
+		return fmt.Errorf("state height %d is inconsistent with block height %d", height, info.Height)
+	}
+	if this.stateStore.merkleTree.TreeSize() != info.Height+1 {
+		return fmt.Errorf("block merkle tree size %d is inconsistent with block height %d",
+			this.stateStore.merkleTree.TreeSize(), info.Height)
+	}
+	if blockRoot := this.stateStore.merkleTree.Root(); info.Height > 0 && blockRoot != header.BlockRoot {
+		return fmt.Errorf("block merkle root %s is not the expected %s", blockRoot.ToHexString(),
+			header.BlockRoot.ToHexString())
+	}
+
+	//the state digest is recomputed from the imported state, so that any tampered key-value is detected
+	stateHasher := sha256.New()
+	err = this.iterateSnapshotState(func(key, value []byte) error {
+		return writeSnapshotRecord(stateHasher, key, value)
+	})
+	if err != nil {
+		return err
+	}
+	var digest common.Uint256
+	copy(digest[:], stateHasher.Sum(nil))
+	if digest != info.StateDigest {
+		return fmt.Errorf("state digest %s is not the expected %s", digest.ToHexString(),
+			info.StateDigest.ToHexString())
+	}
+
+	stateRoot, err := this.stateStore.GetStateMerkleRoot(info.Height)
+	if err != nil {
+		return fmt.Errorf("GetStateMerkleRoot height:%d error %s", info.Height, err)
+	}
+	if stateRoot != info.StateMerkleRoot {
+		return fmt.Errorf("state merkle root %s is not the expected %s", stateRoot.ToHexString(),
+			info.StateMerkleRoot.ToHexString())
+	}
+	if info.Height >= this.stateHashCheckHeight {
+		treeSize, hashes, err := this.stateStore.GetStateMerkleTree()
+		if err != nil {
+			return fmt.Errorf("GetStateMerkleTree error %s", err)
+		}
+		root := merkle.NewTree(treeSize, hashes, nil).Root()
+		if root != stateRoot {
+			return fmt.Errorf("state merkle tree root %s is not the expected %s", root.ToHexString(),
+				stateRoot.ToHexString())
+		}
+	}
+	return nil
+}
+
+//iterateSnapshotState calls f with each state key-value in key order, except the local index
+func (this *LedgerStoreImp) iterateSnapshotState(f func(key, value []byte) error) error {
+	iter := this.stateStore.store.NewIterator(nil)
+	defer iter.Release()
+	for iter.Next() {
+		key := iter.Key()
+		if isLocalIndexKey(key) {
+			continue
+		}
+		if err := f(key, iter.Value()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("iterate state error %s", err)
+	}
+	return nil
+}
+
+func (this *LedgerStoreImp) exportMerkleHashStore(w io.Writer) error {
+	if this.stateStore.merkleHashStore != nil {
+		err := this.stateStore.merkleHashStore.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	f, err := os.Open(this.stateStore.merklePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	err = serialization.WriteUint64(w, uint64(stat.Size()))
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(w, f, stat.Size())
+	return err
+}
+
+func (this *LedgerStoreImp) importMerkleHashStore(r io.Reader) error {
+	size, err := serialization.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	if this.stateStore.merkleHashStore != nil {
+		this.stateStore.merkleHashStore.Close()
+		this.stateStore.merkleHashStore = nil
+	}
+	f, err := os.OpenFile(this.stateStore.merklePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyN(f, r, int64(size))
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func (this *LedgerStoreImp) clearStores() error {
+	err := this.blockStore.ClearAll()
+	if err != nil {
+		return fmt.Errorf("blockStore.ClearAll error %s", err)
+	}
+	err = this.stateStore.ClearAll()
+	if err != nil {
+		return fmt.Errorf("stateStore.ClearAll error %s", err)
+	}
+	err = this.eventStore.ClearAll()
+	if err != nil {
+		return fmt.Errorf("eventStore.ClearAll error %s", err)
+	}
+	return nil
+}
+
+//initGenesisPeerInfo loads the vbft peers of genesis block, which the bookkeepers of the snapshot headers are
+//verified against until the next chain config
+func (this *LedgerStoreImp) initGenesisPeerInfo(genesisBlock *types.Block) error {
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) != "vbft" {
+		return nil
+	}
+	blkInfo, err := vconfig.VbftBlock(genesisBlock.Header)
+	if err != nil {
+		return fmt.Errorf("genesis block info error %s", err)
+	}
+	if blkInfo.NewChainConfig == nil {
+		return fmt.Errorf("genesis block has no chain config")
+	}
+	peerInfo := make(map[string]uint32)
+	for _, p := range blkInfo.NewChainConfig.Peers {
+		peerInfo[p.ID] = p.Index
+	}
+	this.lock.Lock()
+	this.vbftPeerInfoMap = map[uint32]map[string]uint32{0: peerInfo}
+	this.lock.Unlock()
+	return nil
+}
+
+//checkSnapshotHeader checks the header links to prevHeader and is signed by the bookkeepers as the headers
+//synced from network, so that the whole header chain is verified from genesisBlock
+func (this *LedgerStoreImp) checkSnapshotHeader(header, prevHeader *types.Header, height uint32,
+	genesisBlock *types.Block) error {
+	if header.Height != height {
+		return fmt.Errorf("header height %d is not the expected %d", header.Height, height)
+	}
+	if height == 0 {
+		hash, genesisHash := header.Hash(), genesisBlock.Hash()
+		if hash != genesisHash {
+			return fmt.Errorf("genesis block hash %s is not the expected %s", hash.ToHexString(),
+				genesisHash.ToHexString())
+		}
+		return nil
+	}
+	if header.PrevBlockHash != prevHeader.Hash() {
+		return fmt.Errorf("header height:%d does not link to the previous block", height)
+	}
+	if err := this.checkHeader(header, prevHeader); err != nil {
+		return fmt.Errorf("header height:%d verify error %s", height, err)
+	}
+	return nil
+}
+
+func snapshotCrossChainMsgHeights(height uint32) []uint32 {
+	if height == 0 {
+		return []uint32{height}
+	}
+	return []uint32{height - 1, height}
+}
+
+//isLocalIndexKey check whether the key is of the state archive or storage stats, which are derived by each node and not exported
+func isLocalIndexKey(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	switch scom.DataEntryPrefix(key[0]) {
+	case scom.ST_ARCHIVE, scom.ST_ARCHIVE_DELETED, scom.SYS_ARCHIVE_START_HEIGHT, scom.ST_STORAGE_STATS, scom.SYS_STORAGE_STATS_INIT:
+		return true
+	}
+	return false
+}
+
+func writeSnapshotInfo(w io.Writer, info *store.SnapshotInfo) error {
+	_, err := w.Write(snapshotMagic)
+	if err != nil {
+		return err
+	}
+	err = serialization.WriteByte(w, info.Version)
+	if err != nil {
+		return err
+	}
+	err = serialization.WriteUint32(w, info.Height)
+	if err != nil {
+		return err
+	}
+	err = info.BlockHash.Serialize(w)
+	if err != nil {
+		return err
+	}
+	return info.StateMerkleRoot.Serialize(w)
+}
+
+func readSnapshotInfo(r io.Reader) (*store.SnapshotInfo, error) {
+	magic := make([]byte, len(snapshotMagic))
+	_, err := io.ReadFull(r, magic)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, snapshotMagic) {
+		return nil, fmt.Errorf("not a snapshot file")
+	}
+	info := new(store.SnapshotInfo)
+	info.Version, err = serialization.ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	if info.Version != SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("unsupported snapshot version %d", info.Version)
+	}
+	info.Height, err = serialization.ReadUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	err = info.BlockHash.Deserialize(r)
+	if err != nil {
+		return nil, err
+	}
+	err = info.StateMerkleRoot.Deserialize(r)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func writeSnapshotRecord(w io.Writer, key, value []byte) error {
+	err := serialization.WriteByte(w, 1)
+	if err != nil {
+		return err
+	}
+	err = serialization.WriteVarBytes(w, key)
+	if err != nil {
+		return err
+	}
+	return serialization.WriteVarBytes(w, value)
+}
+
+//readSnapshotRecord returns nil key at the end of records
+func readSnapshotRecord(r io.Reader) ([]byte, []byte, error) {
+	flag, err := serialization.ReadByte(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if flag == 0 {
+		return nil, nil, nil
+	}
+	key, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(key) == 0 {
+		return nil, nil, fmt.Errorf("empty state key")
+	}
+	value, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, value, nil
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/signature"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+//newSnapshotSource return a ledger store of 3 blocks after genesis block, whose headers are signed by signer
+func newSnapshotSource(t *testing.T, dir string, block *types.Block, bookkeepers []keypair.PublicKey,
+	signer *account.Account) *LedgerStoreImp {
+	src, err := NewLedgerStore(dir, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, src.InitLedgerStoreWithGenesisBlock(block, bookkeepers))
+	for height := uint32(1); height <= 3; height++ {
+		prev, err := src.GetHeaderByHeight(height - 1)
+		assert.Nil(t, err)
+		header := &types.Header{
+			PrevBlockHash:    prev.Hash(),
+			TransactionsRoot: common.ComputeMerkleRoot(nil),
+			Timestamp:        prev.Timestamp + 1,
+			Height:           height,
+			NextBookkeeper:   prev.NextBookkeeper,
+			Bookkeepers:      []keypair.PublicKey{signer.PublicKey},
+		}
+		header.BlockRoot = src.GetBlockRootWithNewTxRoots(height, []common.Uint256{header.TransactionsRoot})
+		hash := header.Hash()
+		sig, err := signature.Sign(signer, hash[:])
+		assert.Nil(t, err)
+		header.SigData = [][]byte{sig}
+		blk := &types.Block{Header: header}
+		result, err := src.executeBlock(blk)
+		assert.Nil(t, err)
+		assert.Nil(t, src.submitBlock(blk, nil, result))
+	}
+	return src
+}
+
+func TestSnapshot(t *testing.T) {
+	bookkeeper := account.NewAccount("")
+	bookkeepers := []keypair.PublicKey{bookkeeper.PublicKey}
+	block, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	//the headers are signed by the bookkeepers of genesis block instead of vbft peers
+	consensusType := config.DefConfig.Genesis.ConsensusType
+	defer func() { config.DefConfig.Genesis.ConsensusType = consensusType }()
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+
+	src := newSnapshotSource(t, "test/snapshot/src", block, bookkeepers, bookkeeper)
+	defer src.Close()
+	currHash := src.GetCurrentBlockHash()
+
+	buf := bytes.NewBuffer(nil)
+	info, err := src.ExportSnapshot(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), info.Height)
+	assert.Equal(t, currHash, info.BlockHash)
+	stateRoot, err := src.GetStateMerkleRoot(3)
+	assert.Nil(t, err)
+	assert.Equal(t, stateRoot, info.StateMerkleRoot)
+	data := buf.Bytes()
+
+	dst, err := NewLedgerStore("test/snapshot/dst", 0)
+	assert.Nil(t, err)
+	defer dst.Close()
+
+	// a corrupted snapshot, an unexpected block hash or state root, or no trusted one is rejected
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)-sha256.Size-2] ^= 0xff
+	_, err = dst.ImportSnapshot(bytes.NewReader(corrupted), block, currHash, common.UINT256_EMPTY, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	_, err = dst.ImportSnapshot(bytes.NewReader(data), block, common.UINT256_EMPTY, common.Uint256{1},
+		common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	_, err = dst.ImportSnapshot(bytes.NewReader(data), block, common.Uint256{1}, stateRoot, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	_, err = dst.ImportSnapshot(bytes.NewReader(data), block, common.UINT256_EMPTY, stateRoot, common.Uint256{1})
+	assert.NotNil(t, err)
+	_, err = dst.ImportSnapshot(bytes.NewReader(data), block, common.UINT256_EMPTY, common.UINT256_EMPTY,
+		info.StateDigest)
+	assert.NotNil(t, err)
+
+	// the headers not signed by the bookkeepers are rejected
+	forged := newSnapshotSource(t, "test/snapshot/forged", block, bookkeepers, account.NewAccount(""))
+	buf = bytes.NewBuffer(nil)
+	forgedInfo, err := forged.ExportSnapshot(buf)
+	forged.Close()
+	assert.Nil(t, err)
+	_, err = dst.ImportSnapshot(buf, block, forgedInfo.BlockHash, forgedInfo.StateMerkleRoot, forgedInfo.StateDigest)
+	assert.NotNil(t, err)
+
+	// a tampered state value is rejected even if the file digest is recomputed
+	iter := src.stateStore.store.NewIterator([]byte{byte(scom.ST_STORAGE)})
+	assert.True(t, iter.Next())
+	key, value := append([]byte{}, iter.Key()...), append([]byte{}, iter.Value()...)
+	iter.Release()
+	record := bytes.NewBuffer(nil)
+	assert.Nil(t, writeSnapshotRecord(record, key, value))
+	tampered := append([]byte{}, data...)
+	pos := bytes.Index(tampered, record.Bytes())
+	assert.True(t, pos > 0)
+	tampered[pos+record.Len()-1] ^= 0xff
+	resetSnapshotDigest(tampered)
+	_, err = dst.ImportSnapshot(bytes.NewReader(tampered), block, common.UINT256_EMPTY, stateRoot, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	// the forged state digest is rejected by the trusted one
+	tamperedValue := append([]byte{}, value...)
+	tamperedValue[len(tamperedValue)-1] ^= 0xff
+	stateHasher := sha256.New()
+	assert.Nil(t, src.iterateSnapshotState(func(k, v []byte) error {
+		if bytes.Equal(k, key) {
+			v = tamperedValue
+		}
+		return writeSnapshotRecord(stateHasher, k, v)
+	}))
+	copy(tampered[len(tampered)-2*sha256.Size:], stateHasher.Sum(nil))
+	resetSnapshotDigest(tampered)
+	_, err = dst.ImportSnapshot(bytes.NewReader(tampered), block, common.UINT256_EMPTY, stateRoot, info.StateDigest)
+	assert.NotNil(t, err)
+
+	imported, err := dst.ImportSnapshot(bytes.NewReader(data), block, currHash, stateRoot, info.StateDigest)
+	assert.Nil(t, err)
+	assert.Equal(t, info, imported)
+	assert.Nil(t, dst.init())
+	assert.Equal(t, currHash, dst.GetCurrentBlockHash())
+	assert.Equal(t, uint32(3), dst.GetCurrentHeaderHeight())
+	header, err := dst.GetHeaderByHeight(1)
+	assert.Nil(t, err)
+	expectedHeader, err := src.GetHeaderByHeight(1)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedHeader.Hash(), header.Hash())
+	expectedProof, err := src.GetMerkleProof(1, 3)
+	assert.Nil(t, err)
+	proof, err := dst.GetMerkleProof(1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedProof, proof)
+
+	root, err := dst.GetStateMerkleRoot(3)
+	assert.Nil(t, err)
+	assert.Equal(t, stateRoot, root)
+	iter = src.stateStore.store.NewIterator([]byte{byte(scom.ST_STORAGE)})
+	count := 0
+	for iter.Next() {
+		value, err := dst.stateStore.store.Get(iter.Key())
+		assert.Nil(t, err)
+		assert.Equal(t, iter.Value(), value)
+		count++
+	}
+	iter.Release()
+	assert.True(t, count > 0)
+
+	_, err = dst.ImportSnapshot(bytes.NewReader(data), block, currHash, common.UINT256_EMPTY, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+}
+
+//resetSnapshotDigest recomputes the sha256 digest at the end of snapshot data
+func resetSnapshotDigest(data []byte) {
+	digest := sha256.Sum256(data[:len(data)-sha256.Size])
+	copy(data[len(data)-sha256.Size:], digest[:])
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/states"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+)
+
+// The state archive is a per block undo journal. When a block at height H changes a state key,
+// the value the key held before H is saved under (key, H). The value of the key at height h is then
+// the journaled value of the first change after h, or the current value if the key never changed since.
+// The keys deleted since the archive started are kept as tombstones, so that history iteration visits them.
+
+var errStateArchiveReadOnly = errors.New("state archive view is read only")
+
+//EnableArchive start journaling state changes from block height startHeight. The archive started before is kept only
+//if it has journaled every block before startHeight, otherwise it restarts from startHeight
+func (self *StateStore) EnableArchive(startHeight uint32) error {
+	start, last, err := self.getArchiveRange()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if err == nil && start <= startHeight && last+1 >= startHeight {
+		self.archiveStart = start
+		self.archive = true
+		return nil
+	}
+	if err == nil {
+		log.Warnf("state archive of blocks [%d, %d] is not continuous with block %d, restart archive", start, last,
+			startHeight)
+	}
+	// the journal of blocks before start is unreachable, which is kept to avoid scanning the db
+	err = self.store.Put([]byte{byte(scom.SYS_ARCHIVE_START_HEIGHT)}, genHeightRange(startHeight, startHeight-1))
+	if err != nil {
+		return err
+	}
+	self.archiveStart = startHeight
+	self.archive = true
+	return nil
+}
+
+//GetArchiveStartHeight return the first block height which state changes are journaled
+func (self *StateStore) GetArchiveStartHeight() (uint32, error) {
+	start, _, err := self.getArchiveRange()
+	return start, err
+}
+
+//getArchiveRange return the first and last block height which state changes are journaled
+func (self *StateStore) getArchiveRange() (uint32, uint32, error) {
+	value, err := self.store.Get([]byte{byte(scom.SYS_ARCHIVE_START_HEIGHT)})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(value) != 8 {
+		return 0, 0, fmt.Errorf("invalid archive start height")
+	}
+	return binary.LittleEndian.Uint32(value), binary.LittleEndian.Uint32(value[4:]), nil
+}
+
+func genHeightRange(start, last uint32) []byte {
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint32(value, start)
+	binary.LittleEndian.PutUint32(value[4:], last)
+	return value
+}
+
+// batchAddArchiveJournal save the values before the write set of block applied, must be called before the write set batched
+func (self *StateStore) batchAddArchiveJournal(height uint32, writeSet *overlaydb.MemDB) error {
+	if !self.archive {
+		return nil
+	}
+	var err error
+	writeSet.ForEach(func(key, val []byte) {
+		if err != nil {
+			return
+		}
+		prev, e := self.store.Get(key)
+		if e != nil && e != scom.ErrNotFound {
+			err = e
+			return
+		}
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteBool(e == nil)
+		sink.WriteVarBytes(prev)
+		self.store.BatchPut(genArchiveKey(key, height), sink.Bytes())
+		if e == nil && len(val) == 0 {
+			self.store.BatchPut(genArchiveDeletedKey(key), []byte{1})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	self.store.BatchPut([]byte{byte(scom.SYS_ARCHIVE_START_HEIGHT)}, genHeightRange(self.archiveStart, height))
+	return nil
+}
+
+// GetRawAt return the raw state value of key at the end of block height
+func (self *StateStore) GetRawAt(height uint32, key []byte) ([]byte, error) {
+	if !self.archive {
+		return nil, fmt.Errorf("state archive is not enabled")
+	}
+	// the archive restarts after blocks committed without journal, so the blocks from start are all journaled
+	if height+1 < self.archiveStart {
+		return nil, fmt.Errorf("state at height %d is not archived, archive starts from %d", height, self.archiveStart)
+	}
+	// a block commits the journal before or together with the states, so read the current value before the journal:
+	// it is either the value at height, or the value journaled by the first later block changing it
+	curr, err := self.store.Get(key)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+
+	prefix := genArchiveKey(key, 0)
+	prefix = prefix[:len(prefix)-4]
+	iter := self.store.NewIterator(prefix)
+	defer iter.Release()
+	has := iter.Seek(genArchiveKey(key, height+1))
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	if !has {
+		return curr, err
+	}
+
+	source := common.NewZeroCopySource(iter.Value())
+	exist, irr, eof := source.NextBool()
+	if irr || eof {
+		return nil, fmt.Errorf("invalid archive journal of key %x", key)
+	}
+	value, _, irr, eof := source.NextVarBytes()
+	if irr || eof {
+		return nil, fmt.Errorf("invalid archive journal of key %x", key)
+	}
+	if !exist {
+		return nil, scom.ErrNotFound
+	}
+	return value, nil
+}
+
+//GetStorageStateAt return the storage value of the key at the end of block height
+func (self *StateStore) GetStorageStateAt(height uint32, key *states.StorageKey) (*states.StorageItem, error) {
+	storeKey, err := self.getStorageKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := self.GetRawAt(height, storeKey)
+	if err != nil {
+		return nil, err
+	}
+	storageState := new(states.StorageItem)
+	err = storageState.Deserialization(common.NewZeroCopySource(data))
+	if err != nil {
+		return nil, err
+	}
+	return storageState, nil
+}
+
+// NewOverlayDBAt return an overlay db reading the states at the end of block height
+func (self *StateStore) NewOverlayDBAt(height uint32) *overlaydb.OverlayDB {
+	return overlaydb.NewOverlayDB(&archiveView{store: self, height: height})
+}
+
+func genArchiveDeletedKey(key []byte) []byte {
+	return append([]byte{byte(scom.ST_ARCHIVE_DELETED)}, key...)
+}
+
+func genArchiveKey(key []byte, height uint32) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteByte(byte(scom.ST_ARCHIVE))
+	sink.WriteVarBytes(key)
+	var h [4]byte
+	binary.BigEndian.PutUint32(h[:], height)
+	sink.WriteBytes(h[:])
+	return sink.Bytes()
+}
+
+// archiveView is a read only PersistStore of the states at a history height.
+// Iteration visits the keys existing at current height and the keys deleted since archive started.
+type archiveView struct {
+	store  *StateStore
+	height uint32
+}
+
+func (self *archiveView) Get(key []byte) ([]byte, error) {
+	return self.store.GetRawAt(self.height, key)
+}
+
+func (self *archiveView) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == scom.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (self *archiveView) NewIterator(prefix []byte) scom.StoreIterator {
+	deleted := &deletedIterator{iter: self.store.store.NewIterator(genArchiveDeletedKey(prefix))}
+	iter := overlaydb.NewJoinIter(deleted, self.store.store.NewIterator(prefix))
+	return &archiveIterator{view: self, iter: iter}
+}
+
+func (self *archiveView) NewRangeIterator(start, end []byte) scom.StoreIterator {
+	deletedEnd := []byte{byte(scom.ST_ARCHIVE_DELETED) + 1}
+	if len(end) != 0 {
+		deletedEnd = genArchiveDeletedKey(end)
+	}
+	deleted := &deletedIterator{iter: self.store.store.NewRangeIterator(genArchiveDeletedKey(start), deletedEnd)}
+	iter := overlaydb.NewJoinIter(deleted, self.store.store.NewRangeIterator(start, end))
+	return &archiveIterator{view: self, iter: iter}
+}
+
+func (self *archiveView) Put(key []byte, value []byte) error { return errStateArchiveReadOnly }
+func (self *archiveView) Delete(key []byte) error            { return errStateArchiveReadOnly }
+func (self *archiveView) NewBatch()                          {}
+func (self *archiveView) BatchPut(key []byte, value []byte)  {}
+func (self *archiveView) BatchDelete(key []byte)             {}
+func (self *archiveView) BatchCommit() error                 { return errStateArchiveReadOnly }
+func (self *archiveView) Close() error                       { return nil }
+
+type archiveIterator struct {
+	view  *archiveView
+	iter  scom.StoreIterator
+	value []byte
+	err   error
+}
+
+func (self *archiveIterator) First() bool {
+	return self.skip(self.iter.First())
+}
+
+func (self *archiveIterator) Next() bool {
+	return self.skip(self.iter.Next())
+}
+
+func (self *archiveIterator) Last() bool {
+	return self.skipBack(self.iter.Last())
+}
+
+func (self *archiveIterator) Prev() bool {
+	return self.skipBack(self.iter.Prev())
+}
+
+func (self *archiveIterator) Seek(key []byte) bool {
+	return self.skip(self.iter.Seek(key))
+}
+
+// skip the keys not existing at history height
+func (self *archiveIterator) skip(has bool) bool {
+	return self.skipWith(has, self.iter.Next)
+}
+
+func (self *archiveIterator) skipBack(has bool) bool {
+	return self.skipWith(has, self.iter.Prev)
+}
+
+func (self *archiveIterator) skipWith(has bool, move func() bool) bool {
+	for ; has; has = move() {
+		value, err := self.view.Get(self.iter.Key())
+		if err == scom.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			self.err = err
+			return false
+		}
+		self.value = value
+		return true
+	}
+	return false
+}
+
+func (self *archiveIterator) Key() []byte {
+	return self.iter.Key()
+}
+
+func (self *archiveIterator) Value() []byte {
+	return self.value
+}
+
+func (self *archiveIterator) Release() {
+	self.iter.Release()
+}
+
+func (self *archiveIterator) Error() error {
+	if self.err != nil {
+		return self.err
+	}
+	return self.iter.Error()
+}
+
+// deletedIterator iterate the tombstones of deleted keys as the state keys
+type deletedIterator struct {
+	iter scom.StoreIterator
+}
+
+func (self *deletedIterator) First() bool          { return self.iter.First() }
+func (self *deletedIterator) Last() bool           { return self.iter.Last() }
+func (self *deletedIterator) Next() bool           { return self.iter.Next() }
+func (self *deletedIterator) Prev() bool           { return self.iter.Prev() }
+func (self *deletedIterator) Seek(key []byte) bool { return self.iter.Seek(genArchiveDeletedKey(key)) }
+func (self *deletedIterator) Key() []byte          { return self.iter.Key()[1:] }
+func (self *deletedIterator) Value() []byte        { return self.iter.Value() }
+func (self *deletedIterator) Release()             { self.iter.Release() }
+func (self *deletedIterator) Error() error         { return self.iter.Error() }
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateArchive(t *testing.T) {
+	db := NewMemStateStore(0)
+	key := []byte{byte(scom.ST_STORAGE), 1, 2, 3}
+	key2 := []byte{byte(scom.ST_STORAGE), 1, 2, 4}
+
+	applyBlock := func(db *StateStore, height uint32, key, value []byte) {
+		writeSet := overlaydb.NewMemDB(0, 0)
+		writeSet.Put(key, value)
+		db.NewBatch()
+		err := db.batchAddArchiveJournal(height, writeSet)
+		assert.Nil(t, err)
+		if len(value) == 0 {
+			db.BatchDeleteRawKey(key)
+		} else {
+			db.BatchPutRawKeyVal(key, value)
+		}
+		assert.Nil(t, db.CommitTo())
+	}
+
+	applyBlock(db, 1, key, []byte("v1"))
+	_, err := db.GetRawAt(1, key)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, db.EnableArchive(2))
+	applyBlock(db, 2, key, []byte("v2"))
+	applyBlock(db, 3, key2, []byte("k2"))
+	applyBlock(db, 4, key, nil)
+	applyBlock(db, 5, key, []byte("v5"))
+	applyBlock(db, 6, key2, nil)
+
+	_, err = db.GetRawAt(0, key)
+	assert.NotNil(t, err)
+	value, err := db.GetRawAt(1, key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("v1"), value)
+	for _, height := range []uint32{2, 3} {
+		value, err = db.GetRawAt(height, key)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("v2"), value)
+	}
+	_, err = db.GetRawAt(4, key)
+	assert.Equal(t, scom.ErrNotFound, err)
+	for _, height := range []uint32{5, 6} {
+		value, err = db.GetRawAt(height, key)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("v5"), value)
+	}
+
+	overlay := db.NewOverlayDBAt(3)
+	value, err = overlay.Get(key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("v2"), value)
+	iter := overlay.NewIterator([]byte{byte(scom.ST_STORAGE)})
+	assert.True(t, iter.First())
+	assert.Equal(t, key, iter.Key())
+	assert.Equal(t, []byte("v2"), iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, key2, iter.Key())
+	assert.Equal(t, []byte("k2"), iter.Value())
+	assert.False(t, iter.Next())
+	iter.Release()
+	iter = db.NewOverlayDBAt(4).NewIterator([]byte{byte(scom.ST_STORAGE)})
+	assert.True(t, iter.First())
+	assert.Equal(t, key2, iter.Key())
+	assert.False(t, iter.Next())
+	iter.Release()
+
+	// restarted with archive continuous with the last block
+	restarted := &StateStore{store: db.store}
+	_, err = restarted.GetRawAt(3, key)
+	assert.NotNil(t, err)
+	assert.Nil(t, restarted.EnableArchive(7))
+	value, err = restarted.GetRawAt(3, key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("v2"), value)
+
+	// block 7 committed without archive, the archive restarts
+	restarted = &StateStore{store: db.store}
+	applyBlock(restarted, 7, key, []byte("v7"))
+	assert.Nil(t, restarted.EnableArchive(8))
+	start, err := restarted.GetArchiveStartHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(8), start)
+	_, err = restarted.GetRawAt(3, key)
+	assert.NotNil(t, err)
+	applyBlock(restarted, 8, key, []byte("v8"))
+	value, err = restarted.GetRawAt(7, key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("v7"), value)
+}
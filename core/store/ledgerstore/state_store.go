@@ -51,12 +51,14 @@ type StateStore struct {
 	deltaMerkleTree      *merkle.CompactMerkleTree //Merkle tree of delta state root
 	merkleHashStore      merkle.HashStore
 	stateHashCheckHeight uint32
+	archive              bool   //Whether journal state changes for history query
+	archiveStart         uint32 //First block height of state archive
 }
 
 //NewStateStore return state store instance
 func NewStateStore(dbDir, merklePath string, stateHashCheckHeight uint32) (*StateStore, error) {
 	var err error
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := newPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -415,6 +417,40 @@ func (self *StateStore) getContractStateKey(contractHash common.Address) ([]byte
 	return key, nil
 }
 
+//FindStorageStates return a page of at most limit storage items of contract whose keys have the prefix, from the key not less
+//than start. Keys are relative to contract, next is the start of the following page, nil if no item left
+func (self *StateStore) FindStorageStates(contract common.Address, prefix, start []byte, limit int) ([]*scom.KeyValue, []byte, error) {
+	storePrefix, err := self.getStorageKey(&states.StorageKey{ContractAddress: contract, Key: prefix})
+	if err != nil {
+		return nil, nil, err
+	}
+	var storeStart []byte
+	if start != nil {
+		storeStart, err = self.getStorageKey(&states.StorageKey{ContractAddress: contract, Key: start})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	iter := self.store.NewIterator(storePrefix)
+	defer iter.Release()
+	items, next, err := scom.ReadPage(iter, storeStart, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyOffset := 1 + common.ADDR_LEN
+	for _, item := range items {
+		item.Key = item.Key[keyOffset:]
+		item.Value, err = states.GetValueFromRawStorageItem(item.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if next != nil {
+		next = next[keyOffset:]
+	}
+	return items, next, nil
+}
+
 func (self *StateStore) getStorageKey(key *states.StorageKey) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 	buf.WriteByte(byte(scom.ST_STORAGE))
@@ -450,8 +486,18 @@ func (self *StateStore) genStateMerkleRootKey(height uint32) []byte {
 func (self *StateStore) ClearAll() error {
 	self.store.NewBatch()
 	iter := self.store.NewIterator(nil)
+	count := 0
 	for iter.Next() {
 		self.store.BatchDelete(iter.Key())
+		count++
+		//commit in pieces, persist stores may limit the size of a batch
+		if count%SNAPSHOT_BATCH_SIZE == 0 {
+			if err := self.store.BatchCommit(); err != nil {
+				iter.Release()
+				return err
+			}
+			self.store.NewBatch()
+		}
 	}
 	iter.Release()
 	if err := iter.Error(); err != nil {
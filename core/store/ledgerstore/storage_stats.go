@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+)
+
+// The storage stats are the key count and bytes of the storage of each contract. They are derived from the storage
+// items and updated in the same batch as the write set of each block, so they are not part of the state merkle root
+// and must not be read by contract execution.
+
+const storageKeyOffset = 1 + common.ADDR_LEN
+
+//initStorageStats build the storage stats from the existing storage items if they are not built before
+func (self *StateStore) initStorageStats() error {
+	_, err := self.store.Get([]byte{byte(scom.SYS_STORAGE_STATS_INIT)})
+	if err == nil {
+		return nil
+	}
+	if err != scom.ErrNotFound {
+		return err
+	}
+
+	stats := make(map[common.Address]*scom.StorageStats)
+	iter := self.store.NewIterator([]byte{byte(scom.ST_STORAGE)})
+	for has := iter.First(); has; has = iter.Next() {
+		key := iter.Key()
+		if len(key) < storageKeyOffset {
+			continue
+		}
+		var contract common.Address
+		copy(contract[:], key[1:storageKeyOffset])
+		stat := stats[contract]
+		if stat == nil {
+			stat = &scom.StorageStats{}
+			stats[contract] = stat
+		}
+		stat.AddItem(len(key)-storageKeyOffset, len(iter.Value()))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	self.store.NewBatch()
+	for contract, stat := range stats {
+		self.store.BatchPut(genStorageStatsKey(contract), common.SerializeToBytes(stat))
+	}
+	self.store.BatchPut([]byte{byte(scom.SYS_STORAGE_STATS_INIT)}, []byte{1})
+	err = self.store.BatchCommit()
+	if err != nil {
+		return err
+	}
+	log.Infof("storage stats of %d contracts built", len(stats))
+	return nil
+}
+
+//GetStorageStats return the storage stats of contract, zero if the contract has no storage
+func (self *StateStore) GetStorageStats(contract common.Address) (*scom.StorageStats, error) {
+	stats := &scom.StorageStats{}
+	value, err := self.store.Get(genStorageStatsKey(contract))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return stats, nil
+		}
+		return nil, err
+	}
+	err = stats.Deserialization(common.NewZeroCopySource(value))
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// batchUpdateStorageStats count the storage changes of the write set of block, must be called before the write set batched
+func (self *StateStore) batchUpdateStorageStats(writeSet *overlaydb.MemDB) error {
+	stats := make(map[common.Address]*scom.StorageStats)
+	var err error
+	writeSet.ForEach(func(key, val []byte) {
+		if err != nil || len(key) < storageKeyOffset || key[0] != byte(scom.ST_STORAGE) {
+			return
+		}
+		var contract common.Address
+		copy(contract[:], key[1:storageKeyOffset])
+		stat := stats[contract]
+		if stat == nil {
+			stat, err = self.GetStorageStats(contract)
+			if err != nil {
+				return
+			}
+			stats[contract] = stat
+		}
+		prev, e := self.store.Get(key)
+		if e != nil && e != scom.ErrNotFound {
+			err = e
+			return
+		}
+		if e == nil {
+			stat.RemoveItem(len(key)-storageKeyOffset, len(prev))
+		}
+		if len(val) != 0 {
+			stat.AddItem(len(key)-storageKeyOffset, len(val))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for contract, stat := range stats {
+		if stat.KeyCount == 0 {
+			self.store.BatchDelete(genStorageStatsKey(contract))
+		} else {
+			self.store.BatchPut(genStorageStatsKey(contract), common.SerializeToBytes(stat))
+		}
+	}
+	return nil
+}
+
+func genStorageStatsKey(contract common.Address) []byte {
+	key := make([]byte, 0, 1+common.ADDR_LEN)
+	key = append(key, byte(scom.ST_STORAGE_STATS))
+	return append(key, contract[:]...)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageStats(t *testing.T) {
+	db := NewMemStateStore(0)
+	contract := common.Address{1}
+	other := common.Address{2}
+	storageKey := func(addr common.Address, key string) []byte {
+		return append(append([]byte{byte(scom.ST_STORAGE)}, addr[:]...), key...)
+	}
+
+	// storage before the stats are built is counted by init
+	db.NewBatch()
+	db.BatchPutRawKeyVal(storageKey(contract, "k1"), []byte("value"))
+	db.BatchPutRawKeyVal([]byte{byte(scom.ST_CONTRACT), 1}, []byte("code"))
+	assert.Nil(t, db.CommitTo())
+	assert.Nil(t, db.initStorageStats())
+	stats, err := db.GetStorageStats(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, &scom.StorageStats{KeyCount: 1, Bytes: 7}, stats)
+
+	applyBlock := func(writes map[string][]byte) {
+		writeSet := overlaydb.NewMemDB(0, 0)
+		for key, value := range writes {
+			if len(value) == 0 {
+				writeSet.Delete([]byte(key))
+			} else {
+				writeSet.Put([]byte(key), value)
+			}
+		}
+		db.NewBatch()
+		assert.Nil(t, db.batchUpdateStorageStats(writeSet))
+		writeSet.ForEach(func(key, val []byte) {
+			if len(val) == 0 {
+				db.BatchDeleteRawKey(key)
+			} else {
+				db.BatchPutRawKeyVal(key, val)
+			}
+		})
+		assert.Nil(t, db.CommitTo())
+	}
+
+	applyBlock(map[string][]byte{
+		string(storageKey(contract, "k1")):  []byte("v"),
+		string(storageKey(contract, "k22")): []byte("value2"),
+		string(storageKey(other, "k")):      []byte("v"),
+		string(storageKey(other, "gone")):   nil,
+	})
+	stats, err = db.GetStorageStats(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, &scom.StorageStats{KeyCount: 2, Bytes: 3 + 9}, stats)
+	stats, err = db.GetStorageStats(other)
+	assert.Nil(t, err)
+	assert.Equal(t, &scom.StorageStats{KeyCount: 1, Bytes: 2}, stats)
+
+	applyBlock(map[string][]byte{
+		string(storageKey(contract, "k1")):  nil,
+		string(storageKey(contract, "k22")): nil,
+	})
+	stats, err = db.GetStorageStats(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, &scom.StorageStats{}, stats)
+	_, err = db.store.Get(genStorageStatsKey(contract))
+	assert.Equal(t, scom.ErrNotFound, err)
+
+	// the stats are built only once
+	db.NewBatch()
+	db.BatchPutRawKeyVal(storageKey(contract, "k3"), []byte("v"))
+	assert.Nil(t, db.CommitTo())
+	assert.Nil(t, db.initStorageStats())
+	stats, err = db.GetStorageStats(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, &scom.StorageStats{}, stats)
+}
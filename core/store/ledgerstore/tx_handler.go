@@ -27,6 +27,7 @@ import (
 	"github.com/ontio/ontology/common"
 	sysconfig "github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/store"
 	scommon "github.com/ontio/ontology/core/store/common"
@@ -42,6 +43,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/service/neovm"
 	"github.com/ontio/ontology/smartcontract/service/wasmvm"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 func tuneGasFeeByHeight(height uint32, gas uint64, gasRound uint64, curBalance uint64) uint64 {
@@ -74,8 +76,15 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, overlay
 		err         error
 	)
 
+	// reject the block with invalid manifest, or with manifest before the manifest height as the nodes before it do
+	if err = manifest.Verify(deploy.GetManifest(), block.Header.Height, sysconfig.GetContractManifestHeight()); err != nil {
+		overlay.SetError(err)
+		return nil
+	}
+
 	if deploy.VmType() == payload.WASMVM_TYPE {
-		_, err = wasmvm.ReadWasmModule(deploy.GetRawCode(), sysconfig.DefConfig.Common.WasmVerifyMethod)
+		_, err = wasmvm.ReadWasmModule(deploy.GetRawCode(), sysconfig.DefConfig.Common.WasmVerifyMethod,
+			block.Header.Height)
 		if err != nil {
 			return err
 		}
@@ -101,7 +110,7 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, overlay
 			return nil
 		}
 
-		gasLimit := createGasPrice + calcGasByCodeLen(len(deploy.GetRawCode()), uintCodePrice)
+		gasLimit := createGasPrice + calcGasByCodeLen(deploy.DeployCodeLen(), uintCodePrice)
 		balance, err := isBalanceSufficient(tx.Payer, cache, config, store, gasLimit*tx.GasPrice)
 		if err != nil {
 			if err := costInvalidGas(tx.Payer, balance, config, overlay, store, notify); err != nil {
@@ -142,9 +151,9 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, overlay
 	return nil
 }
 
-//HandleInvokeTransaction deal with smart contract invoke transaction
+//HandleInvokeTransaction deal with smart contract invoke transaction, tracer is nil if the execution is not traced
 func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay *overlaydb.OverlayDB, gasTable map[string]uint64, cache *storage.CacheDB,
-	tx *types.Transaction, block *types.Block, notify *event.ExecuteNotify) ([]common.Uint256, error) {
+	tx *types.Transaction, block *types.Block, notify *event.ExecuteNotify, tracer trace.Tracer) ([]common.Uint256, error) {
 	invoke := tx.Payload.(*payload.InvokeCode)
 	code := invoke.Code
 	sysTransFlag := bytes.Compare(code, ninit.COMMIT_DPOS_BYTES) == 0 || block.Header.Height == 0
@@ -224,6 +233,11 @@ func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay
 		Gas:          availableGasLimit - codeLenGasLimit,
 		WasmExecStep: sysconfig.DEFAULT_WASM_MAX_STEPCOUNT,
 		PreExec:      false,
+		Tracer:       tracer,
+	}
+	if tracer != nil {
+		cache.SetTracer(tracer)
+		defer cache.SetTracer(nil)
 	}
 
 	//start the smart contract executive function
@@ -330,6 +344,18 @@ func chargeCostGas(payer common.Address, gas uint64, config *smartcontract.Confi
 }
 
 func refreshGlobalParam(config *smartcontract.Config, cache *storage.CacheDB, store store.LedgerStore) error {
+	gasTable, err := loadGasTable(config, cache, store)
+	if err != nil {
+		return err
+	}
+	for key, value := range gasTable {
+		neovm.GAS_TABLE.Store(key, value)
+	}
+	return nil
+}
+
+//loadGasTable return the gas table with the global params in cache, the global gas table is not changed
+func loadGasTable(config *smartcontract.Config, cache *storage.CacheDB, store store.LedgerStore) (map[string]uint64, error) {
 	sink := common.NewZeroCopySink(nil)
 	utils.EncodeVarUint(sink, uint64(len(neovm.GAS_TABLE_KEYS)))
 	for _, value := range neovm.GAS_TABLE_KEYS {
@@ -346,25 +372,25 @@ func refreshGlobalParam(config *smartcontract.Config, cache *storage.CacheDB, st
 	service, _ := sc.NewNativeService()
 	result, err := service.NativeCall(utils.ParamContractAddress, "getGlobalParam", sink.Bytes())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	params := new(global_params.Params)
 	if err := params.Deserialization(common.NewZeroCopySource(result)); err != nil {
-		return fmt.Errorf("deserialize global params error:%s", err)
+		return nil, fmt.Errorf("deserialize global params error:%s", err)
 	}
-	neovm.GAS_TABLE.Range(func(key, value interface{}) bool {
-		n, ps := params.GetParam(key.(string))
+	gasTable := getGasTable()
+	for key := range gasTable {
+		n, ps := params.GetParam(key)
 		if n != -1 && ps.Value != "" {
 			pu, err := strconv.ParseUint(ps.Value, 10, 64)
 			if err != nil {
 				log.Errorf("[refreshGlobalParam] failed to parse uint %v\n", ps.Value)
 			} else {
-				neovm.GAS_TABLE.Store(key, pu)
+				gasTable[key] = pu
 			}
 		}
-		return true
-	})
-	return nil
+	}
+	return gasTable, nil
 }
 
 func getBalanceFromNative(config *smartcontract.Config, cache *storage.CacheDB, store store.LedgerStore, address common.Address) (uint64, error) {
@@ -23,6 +23,16 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract"
+	"github.com/ontio/ontology/smartcontract/service/neovm"
+	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSyncMapRange(t *testing.T) {
@@ -75,3 +85,24 @@ func TestSyncMapRW(t *testing.T) {
 func addsync(m *sync.Map, va int) {
 	m.Store("key", va)
 }
+
+func TestLoadGasTable(t *testing.T) {
+	bookkeepers := []keypair.PublicKey{account.NewAccount("").PublicKey}
+	block, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	store, err := NewLedgerStore("test/gastable", 0)
+	assert.Nil(t, err)
+	defer store.Close()
+	assert.Nil(t, store.InitLedgerStoreWithGenesisBlock(block, bookkeepers))
+
+	old, _ := neovm.GAS_TABLE.Load(neovm.STORAGE_PUT_NAME)
+	defer neovm.GAS_TABLE.Store(neovm.STORAGE_PUT_NAME, old)
+	neovm.GAS_TABLE.Store(neovm.STORAGE_PUT_NAME, uint64(12345))
+
+	conf := &smartcontract.Config{Time: block.Header.Timestamp + 1, Height: 1, Tx: &types.Transaction{}}
+	gasTable, err := loadGasTable(conf, storage.NewCacheDB(store.stateStore.NewOverlayDB()), store)
+	assert.Nil(t, err)
+	assert.Equal(t, neovm.INIT_GAS_TABLE[neovm.STORAGE_PUT_NAME], gasTable[neovm.STORAGE_PUT_NAME])
+	gas, _ := neovm.GAS_TABLE.Load(neovm.STORAGE_PUT_NAME)
+	assert.Equal(t, uint64(12345), gas)
+}
@@ -39,6 +39,15 @@ type LevelDBStore struct {
 // too small will lead to high false positive rate.
 const BITSPERKEY = 10
 
+//BACKEND_NAME is the name of leveldb in persist store backends
+const BACKEND_NAME = "leveldb"
+
+func init() {
+	common.RegisterPersistStore(BACKEND_NAME, func(dir string) (common.PersistStore, error) {
+		return NewLevelDBStore(dir)
+	})
+}
+
 //NewLevelDBStore return LevelDBStore instance
 func NewLevelDBStore(file string) (*LevelDBStore, error) {
 	openFileCache := opt.DefaultOpenFilesCacheCapacity
@@ -68,10 +77,12 @@ func NewLevelDBStore(file string) (*LevelDBStore, error) {
 		return nil, err
 	}
 
-	return &LevelDBStore{
+	store := &LevelDBStore{
 		db:    db,
 		batch: nil,
-	}, nil
+	}
+	dbStats.add(store, file)
+	return store, nil
 }
 
 func NewMemLevelDBStore() (*LevelDBStore, error) {
@@ -146,6 +157,7 @@ func (self *LevelDBStore) BatchCommit() error {
 
 //Close leveldb
 func (self *LevelDBStore) Close() error {
+	dbStats.remove(self)
 	err := self.db.Close()
 	return err
 }
@@ -157,3 +169,8 @@ func (self *LevelDBStore) NewIterator(prefix []byte) common.StoreIterator {
 
 	return iter
 }
+
+//NewRangeIterator return a iterator of leveldb with the keys in range [start, end)
+func (self *LevelDBStore) NewRangeIterator(start, end []byte) common.StoreIterator {
+	return self.db.NewIterator(&util.Range{Start: start, Limit: end}, nil)
+}
@@ -19,8 +19,14 @@ package leveldbstore
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/storetest"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
 )
 
 var testLevelDB *LevelDBStore
@@ -140,3 +146,49 @@ func TestIterator(t *testing.T) {
 	}
 
 }
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformanceTests(t, func(t *testing.T) (common.PersistStore, func()) {
+		dir, err := ioutil.TempDir("", "leveldbstore")
+		require.Nil(t, err)
+		store, err := common.NewPersistStore(BACKEND_NAME, dir)
+		require.Nil(t, err)
+		return store, func() {
+			store.Close()
+			os.RemoveAll(dir)
+		}
+	})
+}
+
+func TestStatsCollector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leveldbstore")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewLevelDBStore(dir)
+	require.Nil(t, err)
+
+	registry := prom.NewRegistry()
+	require.Nil(t, registry.Register(dbStats))
+	hasStats := func() bool {
+		families, err := registry.Gather()
+		require.Nil(t, err)
+		for _, family := range families {
+			if family.GetName() != "ontology_leveldb_io_bytes_total" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "db" && label.GetValue() == dir {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+
+	require.Nil(t, store.Put([]byte("foo"), []byte("bar")))
+	require.True(t, hasStats())
+	require.Nil(t, store.Close())
+	require.False(t, hasStats())
+}
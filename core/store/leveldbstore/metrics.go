@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leveldbstore
+
+import (
+	"strconv"
+	"sync"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+//statsCollector exports the DBStats of the opened leveldb stores to prometheus
+type statsCollector struct {
+	lock   sync.Mutex
+	stores map[*LevelDBStore]string // store to its path
+
+	ioBytes         *prom.Desc
+	writeDelays     *prom.Desc
+	writeDelayTime  *prom.Desc
+	writePaused     *prom.Desc
+	aliveIterators  *prom.Desc
+	aliveSnapshots  *prom.Desc
+	blockCacheBytes *prom.Desc
+	openedTables    *prom.Desc
+	levelBytes      *prom.Desc
+	levelTables     *prom.Desc
+	compactions     *prom.Desc
+}
+
+var dbStats = newStatsCollector()
+
+func init() {
+	prom.MustRegister(dbStats)
+}
+
+func newStatsCollector() *statsCollector {
+	desc := func(name, help string, labels ...string) *prom.Desc {
+		return prom.NewDesc("ontology_leveldb_"+name, help, append([]string{"db"}, labels...), nil)
+	}
+	return &statsCollector{
+		stores:          make(map[*LevelDBStore]string),
+		ioBytes:         desc("io_bytes_total", "leveldb bytes read from and written to the storage", "op"),
+		writeDelays:     desc("write_delays_total", "leveldb count of the writes delayed by compaction"),
+		writeDelayTime:  desc("write_delay_seconds_total", "leveldb duration of the writes delayed by compaction"),
+		writePaused:     desc("write_paused", "leveldb whether the writes are paused by compaction"),
+		aliveIterators:  desc("alive_iterators", "leveldb count of the alive iterators"),
+		aliveSnapshots:  desc("alive_snapshots", "leveldb count of the alive snapshots"),
+		blockCacheBytes: desc("block_cache_bytes", "leveldb size of the block cache"),
+		openedTables:    desc("opened_tables", "leveldb count of the opened tables"),
+		levelBytes:      desc("level_bytes", "leveldb size of the tables per level", "level"),
+		levelTables:     desc("level_tables", "leveldb count of the tables per level", "level"),
+		compactions:     desc("compactions_total", "leveldb count of the compactions per type", "type"),
+	}
+}
+
+func (self *statsCollector) add(store *LevelDBStore, path string) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.stores[store] = path
+}
+
+func (self *statsCollector) remove(store *LevelDBStore) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	delete(self.stores, store)
+}
+
+//Describe implements prometheus.Collector
+func (self *statsCollector) Describe(ch chan<- *prom.Desc) {
+	ch <- self.ioBytes
+	ch <- self.writeDelays
+	ch <- self.writeDelayTime
+	ch <- self.writePaused
+	ch <- self.aliveIterators
+	ch <- self.aliveSnapshots
+	ch <- self.blockCacheBytes
+	ch <- self.openedTables
+	ch <- self.levelBytes
+	ch <- self.levelTables
+	ch <- self.compactions
+}
+
+//Collect implements prometheus.Collector
+func (self *statsCollector) Collect(ch chan<- prom.Metric) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	for store, path := range self.stores {
+		stats := &leveldb.DBStats{}
+		if err := store.db.Stats(stats); err != nil {
+			continue
+		}
+		self.collectStats(ch, path, stats)
+	}
+}
+
+func (self *statsCollector) collectStats(ch chan<- prom.Metric, db string, stats *leveldb.DBStats) {
+	paused := 0.0
+	if stats.WritePaused {
+		paused = 1
+	}
+	ch <- prom.MustNewConstMetric(self.ioBytes, prom.CounterValue, float64(stats.IORead), db, "read")
+	ch <- prom.MustNewConstMetric(self.ioBytes, prom.CounterValue, float64(stats.IOWrite), db, "write")
+	ch <- prom.MustNewConstMetric(self.writeDelays, prom.CounterValue, float64(stats.WriteDelayCount), db)
+	ch <- prom.MustNewConstMetric(self.writeDelayTime, prom.CounterValue, stats.WriteDelayDuration.Seconds(), db)
+	ch <- prom.MustNewConstMetric(self.writePaused, prom.GaugeValue, paused, db)
+	ch <- prom.MustNewConstMetric(self.aliveIterators, prom.GaugeValue, float64(stats.AliveIterators), db)
+	ch <- prom.MustNewConstMetric(self.aliveSnapshots, prom.GaugeValue, float64(stats.AliveSnapshots), db)
+	ch <- prom.MustNewConstMetric(self.blockCacheBytes, prom.GaugeValue, float64(stats.BlockCacheSize), db)
+	ch <- prom.MustNewConstMetric(self.openedTables, prom.GaugeValue, float64(stats.OpenedTablesCount), db)
+	for level, size := range stats.LevelSizes {
+		ch <- prom.MustNewConstMetric(self.levelBytes, prom.GaugeValue, float64(size), db, strconv.Itoa(level))
+	}
+	for level, count := range stats.LevelTablesCounts {
+		ch <- prom.MustNewConstMetric(self.levelTables, prom.GaugeValue, float64(count), db, strconv.Itoa(level))
+	}
+	ch <- prom.MustNewConstMetric(self.compactions, prom.CounterValue, float64(stats.MemComp), db, "memory")
+	ch <- prom.MustNewConstMetric(self.compactions, prom.CounterValue, float64(stats.Level0Comp), db, "level0")
+	ch <- prom.MustNewConstMetric(self.compactions, prom.CounterValue, float64(stats.NonLevel0Comp), db, "non_level0")
+	ch <- prom.MustNewConstMetric(self.compactions, prom.CounterValue, float64(stats.SeekComp), db, "seek")
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package memstore is a persist store backend keeping all data in memory, used by tests and ephemeral networks
+package memstore
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+//BACKEND_NAME is the name of memory store in persist store backends
+const BACKEND_NAME = "memory"
+
+//COMPACT_THRESHOLD is the buffer size over which the space of deleted and overwritten data is reclaimed
+const COMPACT_THRESHOLD = 64 * 1024 * 1024
+
+func init() {
+	common.RegisterPersistStore(BACKEND_NAME, func(dir string) (common.PersistStore, error) {
+		return NewMemStore(), nil
+	})
+}
+
+//MemStore keep key-value pairs in a sorted in-memory table. Data is lost when the process exits
+type MemStore struct {
+	lock  sync.RWMutex
+	db    *memdb.DB
+	batch *leveldb.Batch
+}
+
+//NewMemStore return an empty MemStore
+func NewMemStore() *MemStore {
+	return &MemStore{
+		db: memdb.New(comparer.DefaultComparer, 0),
+	}
+}
+
+//Put a key-value pair to store
+func (self *MemStore) Put(key []byte, value []byte) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	err := self.db.Put(key, value)
+	self.compact()
+	return err
+}
+
+//Get the value of a key from store
+func (self *MemStore) Get(key []byte) ([]byte, error) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	value, err := self.db.Get(key)
+	if err != nil {
+		if err == memdb.ErrNotFound {
+			return nil, common.ErrNotFound
+		}
+		return nil, err
+	}
+	return append([]byte{}, value...), nil
+}
+
+//Has return whether the key is exist in store
+func (self *MemStore) Has(key []byte) (bool, error) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.db.Contains(key), nil
+}
+
+//Delete the key in store
+func (self *MemStore) Delete(key []byte) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.db.Delete(key)
+	self.compact()
+	return nil
+}
+
+//NewBatch start commit batch
+func (self *MemStore) NewBatch() {
+	self.batch = new(leveldb.Batch)
+}
+
+//BatchPut put a key-value pair to batch
+func (self *MemStore) BatchPut(key []byte, value []byte) {
+	self.batch.Put(key, value)
+}
+
+//BatchDelete delete a key to batch
+func (self *MemStore) BatchDelete(key []byte) {
+	self.batch.Delete(key)
+}
+
+//BatchCommit commit batch to store
+func (self *MemStore) BatchCommit() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	err := self.batch.Replay(replayer{self.db})
+	if err != nil {
+		return err
+	}
+	self.batch = nil
+	self.compact()
+	return nil
+}
+
+//Close store
+func (self *MemStore) Close() error {
+	return nil
+}
+
+//NewIterator return a iterator of store with the key prefix. The iterator is not a snapshot, writes after
+//it is created may or may not be seen
+func (self *MemStore) NewIterator(prefix []byte) common.StoreIterator {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.db.NewIterator(util.BytesPrefix(prefix))
+}
+
+//NewRangeIterator return a iterator of store with the keys in range [start, end)
+func (self *MemStore) NewRangeIterator(start, end []byte) common.StoreIterator {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.db.NewIterator(&util.Range{Start: start, Limit: end})
+}
+
+//compact rebuild the table when most of the append only buffer is taken by deleted or overwritten data.
+//Iterators already created keep reading the old table
+func (self *MemStore) compact() {
+	used := self.db.Capacity() - self.db.Free()
+	if used < COMPACT_THRESHOLD || used < 2*self.db.Size() {
+		return
+	}
+	db := memdb.New(comparer.DefaultComparer, self.db.Size())
+	iter := self.db.NewIterator(nil)
+	for iter.Next() {
+		db.Put(iter.Key(), iter.Value())
+	}
+	iter.Release()
+	self.db = db
+}
+
+type replayer struct {
+	db *memdb.DB
+}
+
+func (self replayer) Put(key, value []byte) {
+	self.db.Put(key, value)
+}
+
+func (self replayer) Delete(key []byte) {
+	self.db.Delete(key)
+}
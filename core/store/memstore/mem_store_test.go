@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package memstore
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformanceTests(t, func(t *testing.T) (common.PersistStore, func()) {
+		store := NewMemStore()
+		return store, func() { store.Close() }
+	})
+}
+
+func TestCompact(t *testing.T) {
+	store := NewMemStore()
+	value := make([]byte, 1024*1024)
+	for i := 0; i < 2*COMPACT_THRESHOLD/len(value); i++ {
+		value[0] = byte(i)
+		require.Nil(t, store.Put([]byte("key"), value))
+	}
+	require.True(t, store.db.Capacity() < 2*COMPACT_THRESHOLD)
+
+	got, err := store.Get([]byte("key"))
+	require.Nil(t, err)
+	require.Equal(t, value, got)
+}
+
+func TestRegistered(t *testing.T) {
+	store, err := common.NewPersistStore(BACKEND_NAME, "")
+	require.Nil(t, err)
+	require.IsType(t, &MemStore{}, store)
+}
@@ -30,81 +30,51 @@ const (
 	FromBoth           = iota
 )
 
+const (
+	dirBeforeFirst = iota //Iterator is before the first item
+	dirAfterLast          //Iterator is after the last item
+	dirForward            //Iterator is moving forward
+	dirBackward           //Iterator is moving backward
+)
+
+//JoinIter merge the iterator of memdb over the iterator of backend. The item of memdb hides the item of
+//backend with the same key, and the items with empty value are deleted ones which are skipped
 type JoinIter struct {
-	backend     common.StoreIterator
-	memdb       common.StoreIterator
-	key, value  []byte
-	keyOrigin   KeyOrigin
-	nextMemEnd  bool
-	nextBackEnd bool
-	cmp         comparer.BasicComparer
+	backend    common.StoreIterator
+	memdb      common.StoreIterator
+	key, value []byte
+	keyOrigin  KeyOrigin
+	dir        int
+	memOk      bool //Whether memdb is at a valid item
+	backOk     bool //Whether backend is at a valid item
+	cmp        comparer.BasicComparer
 }
 
 func NewJoinIter(memIter, backendIter common.StoreIterator) *JoinIter {
 	return &JoinIter{
 		backend: backendIter,
 		memdb:   memIter,
+		dir:     dirBeforeFirst,
 		cmp:     comparer.DefaultComparer,
 	}
 }
 
 func (iter *JoinIter) First() bool {
-	f := iter.first()
-	if !f {
-		return false
-	}
-	for len(iter.value) == 0 {
-		if !iter.next() {
-			return false
-		}
-	}
+	iter.memOk = iter.memdb.First()
+	iter.backOk = iter.backend.First()
+	return iter.forward()
+}
 
-	return true
+func (iter *JoinIter) Last() bool {
+	iter.memOk = iter.memdb.Last()
+	iter.backOk = iter.backend.Last()
+	return iter.backward()
 }
 
-func (iter *JoinIter) first() bool {
-	var bkey, bval, mkey, mval []byte
-	back := iter.backend.First()
-	mem := iter.memdb.First()
-	// check error
-	if iter.Error() != nil {
-		return false
-	}
-	if back {
-		bkey = iter.backend.Key()
-		bval = iter.backend.Value()
-		if !mem {
-			iter.key = bkey
-			iter.value = bval
-			iter.keyOrigin = FromBack
-			return true
-		}
-		mkey = iter.memdb.Key()
-		mval = iter.memdb.Value()
-		cmp := iter.cmp.Compare(mkey, bkey)
-		if cmp < 1 {
-			iter.key = mkey
-			iter.value = mval
-			if cmp == 0 {
-				iter.keyOrigin = FromBoth
-			} else {
-				iter.keyOrigin = FromMem
-			}
-		} else {
-			iter.key = bkey
-			iter.value = bval
-			iter.keyOrigin = FromBack
-		}
-		return true
-	} else {
-		if mem {
-			iter.key = iter.memdb.Key()
-			iter.value = iter.memdb.Value()
-			iter.keyOrigin = FromMem
-			return true
-		}
-		return false
-	}
+func (iter *JoinIter) Seek(key []byte) bool {
+	iter.memOk = iter.memdb.Seek(key)
+	iter.backOk = iter.backend.Seek(key)
+	return iter.forward()
 }
 
 func (iter *JoinIter) Key() []byte {
@@ -116,74 +86,133 @@ func (iter *JoinIter) Value() []byte {
 }
 
 func (iter *JoinIter) Next() bool {
-	f := iter.next()
-	if !f {
+	switch iter.dir {
+	case dirBeforeFirst:
+		return iter.First()
+	case dirAfterLast:
 		return false
+	case dirBackward:
+		key := append([]byte{}, iter.key...)
+		iter.memOk = seekAfter(iter.memdb, key)
+		iter.backOk = seekAfter(iter.backend, key)
+	default:
+		iter.advance()
 	}
+	return iter.forward()
+}
 
-	for len(iter.value) == 0 {
-		if !iter.next() {
-			return false
-		}
+func (iter *JoinIter) Prev() bool {
+	switch iter.dir {
+	case dirBeforeFirst:
+		return false
+	case dirAfterLast:
+		return iter.Last()
+	case dirForward:
+		key := append([]byte{}, iter.key...)
+		iter.memOk = seekBefore(iter.memdb, key)
+		iter.backOk = seekBefore(iter.backend, key)
+	default:
+		iter.retreat()
 	}
+	return iter.backward()
+}
 
-	return true
+//advance move the iterators at current key forward
+func (iter *JoinIter) advance() {
+	if iter.keyOrigin != FromBack {
+		iter.memOk = iter.memdb.Next()
+	}
+	if iter.keyOrigin != FromMem {
+		iter.backOk = iter.backend.Next()
+	}
 }
 
-func (iter *JoinIter) next() bool {
-	if (iter.keyOrigin == FromMem || iter.keyOrigin == FromBoth) && !iter.nextMemEnd {
-		iter.nextMemEnd = !iter.memdb.Next()
+//retreat move the iterators at current key backward
+func (iter *JoinIter) retreat() {
+	if iter.keyOrigin != FromBack {
+		iter.memOk = iter.memdb.Prev()
 	}
-	if (iter.keyOrigin == FromBack || iter.keyOrigin == FromBoth) && !iter.nextBackEnd {
-		iter.nextBackEnd = !iter.backend.Next()
+	if iter.keyOrigin != FromMem {
+		iter.backOk = iter.backend.Prev()
+	}
+}
+
+//forward stop at the smallest not deleted item of memdb and backend
+func (iter *JoinIter) forward() bool {
+	iter.dir = dirForward
+	for iter.pick(-1) {
+		if len(iter.value) != 0 {
+			return true
+		}
+		iter.advance()
 	}
+	iter.dir = dirAfterLast
+	return false
+}
+
+//backward stop at the largest not deleted item of memdb and backend
+func (iter *JoinIter) backward() bool {
+	iter.dir = dirBackward
+	for iter.pick(1) {
+		if len(iter.value) != 0 {
+			return true
+		}
+		iter.retreat()
+	}
+	iter.dir = dirBeforeFirst
+	return false
+}
 
-	// check error
+//pick the current item from memdb and backend, the one whose key compares as order is preferred
+func (iter *JoinIter) pick(order int) bool {
+	iter.key, iter.value = nil, nil
 	if iter.Error() != nil {
 		return false
 	}
-
-	if iter.nextBackEnd {
-		if iter.nextMemEnd {
-			iter.key = nil
-			iter.value = nil
-			return false
-		} else {
-			iter.key = iter.memdb.Key()
-			iter.value = iter.memdb.Value()
-			iter.keyOrigin = FromMem
+	origin := FromMem
+	switch {
+	case iter.memOk && iter.backOk:
+		cmp := iter.cmp.Compare(iter.memdb.Key(), iter.backend.Key())
+		if cmp == 0 {
+			origin = FromBoth
+		} else if cmp*order < 0 {
+			origin = FromBack
 		}
+	case iter.backOk:
+		origin = FromBack
+	case !iter.memOk:
+		return false
+	}
+	iter.keyOrigin = origin
+	if origin == FromBack {
+		iter.key = iter.backend.Key()
+		iter.value = iter.backend.Value()
 	} else {
-		if iter.nextMemEnd {
-			iter.key = iter.backend.Key()
-			iter.value = iter.backend.Value()
-			iter.keyOrigin = FromBack
-		} else {
-			bkey := iter.backend.Key()
-			mkey := iter.memdb.Key()
-			cmp := iter.cmp.Compare(mkey, bkey)
-			switch cmp {
-			case -1:
-				iter.key = mkey
-				iter.value = iter.memdb.Value()
-				iter.keyOrigin = FromMem
-			case 0:
-				iter.key = mkey
-				iter.value = iter.memdb.Value()
-				iter.keyOrigin = FromBoth
-			case 1:
-				iter.key = bkey
-				iter.value = iter.backend.Value()
-				iter.keyOrigin = FromBack
-			default:
-				panic("unreachable")
-			}
-		}
+		iter.key = iter.memdb.Key()
+		iter.value = iter.memdb.Value()
 	}
+	return true
+}
 
+//seekAfter move iter to the first item greater than key
+func seekAfter(iter common.StoreIterator, key []byte) bool {
+	if !iter.Seek(key) {
+		return false
+	}
+	if comparer.DefaultComparer.Compare(iter.Key(), key) == 0 {
+		return iter.Next()
+	}
 	return true
 }
 
+//seekBefore move iter to the last item less than key
+func seekBefore(iter common.StoreIterator, key []byte) bool {
+	if iter.Seek(key) {
+		return iter.Prev()
+	}
+	return iter.Last()
+}
+
 func (iter *JoinIter) Release() {
 	iter.memdb.Release()
 	iter.backend.Release()
@@ -116,3 +116,11 @@ func (self *OverlayDB) NewIterator(key []byte) common.StoreIterator {
 
 	return NewJoinIter(memIter, backIter)
 }
+
+// NewRangeIterator return the iterator of keys in range [start, end), nil start or end means unbounded
+func (self *OverlayDB) NewRangeIterator(start, end []byte) common.StoreIterator {
+	backIter := self.store.NewRangeIterator(start, end)
+	memIter := self.memdb.NewIterator(&util.Range{Start: start, Limit: end})
+
+	return NewJoinIter(memIter, backIter)
+}
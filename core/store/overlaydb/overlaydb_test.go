@@ -20,6 +20,7 @@ package overlaydb
 import (
 	"encoding/binary"
 	"math/rand"
+	"sort"
 	"strconv"
 	"testing"
 
@@ -68,6 +69,78 @@ func TestNewOverlayDB(t *testing.T) {
 	}
 }
 
+func TestJoinIterDirection(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+
+	N := 200
+	expect := make(map[int]string)
+	for i := 0; i < N; i += 2 {
+		store.Put(makeKey(i), []byte("back"+strconv.Itoa(i)))
+		expect[i] = "back" + strconv.Itoa(i)
+	}
+	overlay := NewOverlayDB(store)
+	for i := 0; i < N; i += 3 {
+		overlay.Put(makeKey(i), []byte("mem"+strconv.Itoa(i)))
+		expect[i] = "mem" + strconv.Itoa(i)
+	}
+	for i := 0; i < N; i += 5 {
+		overlay.Delete(makeKey(i))
+		delete(expect, i)
+	}
+	var keys []int
+	for k := range expect {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	iter := overlay.NewIterator([]byte("key"))
+	defer iter.Release()
+	pos := -1
+	check := func(has bool) {
+		if pos < 0 || pos >= len(keys) {
+			assert.False(t, has)
+			return
+		}
+		assert.True(t, has)
+		assert.Equal(t, makeKey(keys[pos]), iter.Key())
+		assert.Equal(t, []byte(expect[keys[pos]]), iter.Value())
+	}
+	r := rand.New(rand.NewSource(1))
+	for step := 0; step < 2000; step++ {
+		switch r.Intn(10) {
+		case 0:
+			pos = 0
+			check(iter.First())
+		case 1:
+			pos = len(keys) - 1
+			check(iter.Last())
+		case 2:
+			target := r.Intn(N + 10)
+			pos = sort.SearchInts(keys, target)
+			check(iter.Seek(makeKey(target)))
+		case 3, 4, 5:
+			if pos < len(keys) {
+				pos++
+			}
+			check(iter.Next())
+		default:
+			if pos >= 0 {
+				pos--
+			}
+			check(iter.Prev())
+		}
+	}
+
+	rangeIter := overlay.NewRangeIterator(makeKey(10), makeKey(20))
+	defer rangeIter.Release()
+	var got []int
+	for has := rangeIter.Last(); has; has = rangeIter.Prev() {
+		got = append(got, int(binary.BigEndian.Uint64(rangeIter.Key()[3:])))
+	}
+	assert.Equal(t, []int{18, 16, 14, 12}, got)
+}
+
 func BenchmarkOverlayDBSerialPut(b *testing.B) {
 	store, _ := leveldbstore.NewMemLevelDBStore()
 
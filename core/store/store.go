@@ -19,14 +19,18 @@
 package store
 
 import (
+	"io"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/states"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/store/overlaydb"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/smartcontract/event"
 	cstates "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 type ExecuteResult struct {
@@ -38,6 +42,15 @@ type ExecuteResult struct {
 	Notify          []*event.ExecuteNotify
 }
 
+//SnapshotInfo describes the chain state kept in a snapshot
+type SnapshotInfo struct {
+	Version         byte
+	Height          uint32         //Block height of the state
+	BlockHash       common.Uint256 //Block hash at Height
+	StateMerkleRoot common.Uint256 //State merkle root at Height, empty before the state hash check height
+	StateDigest     common.Uint256 //Digest of the exported state key-values, which the state merkle root does not cover
+}
+
 // LedgerStore provides func with store package.
 type LedgerStore interface {
 	InitLedgerStoreWithGenesisBlock(genesisblock *types.Block, defaultBookkeeper []keypair.PublicKey) error
@@ -65,14 +78,27 @@ type LedgerStore interface {
 	GetContractState(contractHash common.Address) (*payload.DeployCode, error)
 	GetBookkeeperState() (*states.BookkeeperState, error)
 	GetStorageItem(key *states.StorageKey) (*states.StorageItem, error)
+	GetStorageItemAt(height uint32, key *states.StorageKey) (*states.StorageItem, error)
+	FindStorageItems(contract common.Address, prefix, start []byte, limit int) ([]*scom.KeyValue, []byte, error)
+	GetContractStorageStats(contract common.Address) (*scom.StorageStats, error)
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
 	PreExecuteContractBatch(txes []*types.Transaction, atomic bool) ([]*cstates.PreExecResult, uint32, error)
+	PreExecuteContractAt(height uint32, tx *types.Transaction) (*cstates.PreExecResult, error)
+	PreExecuteContractBatchAt(height uint32, txes []*types.Transaction) ([]*cstates.PreExecResult, error)
+	PreExecuteContractWithProfile(height *uint32, tx *types.Transaction) (*cstates.PreExecResult, error)
+	TraceTransaction(txHash common.Uint256, tracer trace.Tracer) (*event.ExecuteNotify, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	GetAddressHistory(addr common.Address, fromHeight uint32, limit int) ([]*scom.AddressHistory, uint32, bool, error)
 
 	//cross chain states root
 	GetCrossStatesRoot(height uint32) (common.Uint256, error)
 	GetCrossChainMsg(height uint32) (*types.CrossChainMsg, error)
 	GetCrossStatesProof(height uint32, key []byte) ([]byte, error)
 	EnableBlockPrune(numBeforeCurr uint32)
+	EnableStateArchive() error
+	EnableAddressHistory() error
+	ExportSnapshot(w io.Writer) (*SnapshotInfo, error)
+	ImportSnapshot(r io.Reader, genesisBlock *types.Block, blockHash, stateRoot,
+		stateDigest common.Uint256) (*SnapshotInfo, error)
 }
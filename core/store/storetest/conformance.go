@@ -0,0 +1,354 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package storetest is the conformance test suite shared by every persist store backend
+package storetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/stretchr/testify/require"
+)
+
+//StoreOpener open an empty store for a test case, the returned function releases it
+type StoreOpener func(t *testing.T) (common.PersistStore, func())
+
+//RunConformanceTests run the conformance test suite against the backend opened by opener
+func RunConformanceTests(t *testing.T, opener StoreOpener) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, store common.PersistStore)
+	}{
+		{"PutGet", testPutGet},
+		{"Delete", testDelete},
+		{"Overwrite", testOverwrite},
+		{"EmptyValue", testEmptyValue},
+		{"ValueCopied", testValueCopied},
+		{"Batch", testBatch},
+		{"BatchOrder", testBatchOrder},
+		{"NewBatchDiscard", testNewBatchDiscard},
+		{"Iterator", testIterator},
+		{"IteratorFirst", testIteratorFirst},
+		{"IteratorEmpty", testIteratorEmpty},
+		{"IteratorSeek", testIteratorSeek},
+		{"IteratorReverse", testIteratorReverse},
+		{"IteratorDirection", testIteratorDirection},
+		{"RangeIterator", testRangeIterator},
+	}
+	for _, c := range cases {
+		test := c.test
+		t.Run(c.name, func(t *testing.T) {
+			store, release := opener(t)
+			defer release()
+			test(t, store)
+		})
+	}
+}
+
+func testPutGet(t *testing.T, store common.PersistStore) {
+	_, err := store.Get([]byte("foo"))
+	require.Equal(t, common.ErrNotFound, err)
+	has, err := store.Has([]byte("foo"))
+	require.Nil(t, err)
+	require.False(t, has)
+
+	require.Nil(t, store.Put([]byte("foo"), []byte("bar")))
+	value, err := store.Get([]byte("foo"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("bar"), value)
+	has, err = store.Has([]byte("foo"))
+	require.Nil(t, err)
+	require.True(t, has)
+}
+
+func testDelete(t *testing.T, store common.PersistStore) {
+	require.Nil(t, store.Put([]byte("foo"), []byte("bar")))
+	require.Nil(t, store.Delete([]byte("foo")))
+	_, err := store.Get([]byte("foo"))
+	require.Equal(t, common.ErrNotFound, err)
+	has, err := store.Has([]byte("foo"))
+	require.Nil(t, err)
+	require.False(t, has)
+
+	//delete a missing key is not an error
+	require.Nil(t, store.Delete([]byte("missing")))
+}
+
+func testOverwrite(t *testing.T, store common.PersistStore) {
+	require.Nil(t, store.Put([]byte("foo"), []byte("bar")))
+	require.Nil(t, store.Put([]byte("foo"), []byte("baz")))
+	value, err := store.Get([]byte("foo"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("baz"), value)
+}
+
+func testEmptyValue(t *testing.T, store common.PersistStore) {
+	require.Nil(t, store.Put([]byte("foo"), []byte{}))
+	value, err := store.Get([]byte("foo"))
+	require.Nil(t, err)
+	require.Len(t, value, 0)
+	has, err := store.Has([]byte("foo"))
+	require.Nil(t, err)
+	require.True(t, has)
+}
+
+func testValueCopied(t *testing.T, store common.PersistStore) {
+	key := []byte("foo")
+	value := []byte("bar")
+	require.Nil(t, store.Put(key, value))
+	key[0], value[0] = 'x', 'x'
+
+	got, err := store.Get([]byte("foo"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("bar"), got)
+
+	store.NewBatch()
+	store.BatchPut(key, value)
+	key[0], value[0] = 'y', 'y'
+	require.Nil(t, store.BatchCommit())
+	got, err = store.Get([]byte("xoo"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("xar"), got)
+}
+
+func testBatch(t *testing.T, store common.PersistStore) {
+	require.Nil(t, store.Put([]byte("old"), []byte("value")))
+	store.NewBatch()
+	for i := 0; i < 100; i++ {
+		store.BatchPut([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	store.BatchDelete([]byte("old"))
+
+	//nothing is visible before commit
+	_, err := store.Get([]byte("key000"))
+	require.Equal(t, common.ErrNotFound, err)
+	_, err = store.Get([]byte("old"))
+	require.Nil(t, err)
+
+	require.Nil(t, store.BatchCommit())
+	for i := 0; i < 100; i++ {
+		value, err := store.Get([]byte(fmt.Sprintf("key%03d", i)))
+		require.Nil(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), value)
+	}
+	_, err = store.Get([]byte("old"))
+	require.Equal(t, common.ErrNotFound, err)
+}
+
+func testBatchOrder(t *testing.T, store common.PersistStore) {
+	store.NewBatch()
+	store.BatchPut([]byte("a"), []byte("1"))
+	store.BatchDelete([]byte("a"))
+	store.BatchDelete([]byte("b"))
+	store.BatchPut([]byte("b"), []byte("2"))
+	store.BatchPut([]byte("c"), []byte("3"))
+	store.BatchPut([]byte("c"), []byte("4"))
+	require.Nil(t, store.BatchCommit())
+
+	_, err := store.Get([]byte("a"))
+	require.Equal(t, common.ErrNotFound, err)
+	value, err := store.Get([]byte("b"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("2"), value)
+	value, err = store.Get([]byte("c"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("4"), value)
+}
+
+func testNewBatchDiscard(t *testing.T, store common.PersistStore) {
+	store.NewBatch()
+	store.BatchPut([]byte("discarded"), []byte("value"))
+	store.NewBatch()
+	store.BatchPut([]byte("kept"), []byte("value"))
+	require.Nil(t, store.BatchCommit())
+
+	_, err := store.Get([]byte("discarded"))
+	require.Equal(t, common.ErrNotFound, err)
+	_, err = store.Get([]byte("kept"))
+	require.Nil(t, err)
+}
+
+func putAll(t *testing.T, store common.PersistStore, kvs map[string]string) {
+	store.NewBatch()
+	for k, v := range kvs {
+		store.BatchPut([]byte(k), []byte(v))
+	}
+	require.Nil(t, store.BatchCommit())
+}
+
+func collect(t *testing.T, iter common.StoreIterator) []string {
+	var kvs []string
+	for iter.Next() {
+		kvs = append(kvs, string(iter.Key())+"="+string(iter.Value()))
+	}
+	require.Nil(t, iter.Error())
+	return kvs
+}
+
+func testIterator(t *testing.T, store common.PersistStore) {
+	putAll(t, store, map[string]string{
+		"a":         "0",
+		"b\x00":     "1",
+		"b\x01\xff": "2",
+		"b\x01":     "3",
+		"b\xff":     "4",
+		"b\xff\xff": "5",
+		"c":         "6",
+	})
+
+	iter := store.NewIterator([]byte("b"))
+	require.Equal(t, []string{"b\x00=1", "b\x01=3", "b\x01\xff=2", "b\xff=4", "b\xff\xff=5"}, collect(t, iter))
+	iter.Release()
+
+	iter = store.NewIterator([]byte("b\xff"))
+	require.Equal(t, []string{"b\xff=4", "b\xff\xff=5"}, collect(t, iter))
+	iter.Release()
+
+	iter = store.NewIterator(nil)
+	require.Len(t, collect(t, iter), 7)
+	iter.Release()
+}
+
+func testIteratorFirst(t *testing.T, store common.PersistStore) {
+	putAll(t, store, map[string]string{"k1": "1", "k2": "2", "k3": "3"})
+
+	iter := store.NewIterator([]byte("k"))
+	defer iter.Release()
+	require.True(t, iter.Next())
+	require.True(t, iter.Next())
+	require.Equal(t, []byte("k2"), iter.Key())
+	require.True(t, iter.First())
+	require.Equal(t, []byte("k1"), iter.Key())
+	require.Equal(t, []byte("1"), iter.Value())
+	require.Equal(t, []string{"k2=2", "k3=3"}, collect(t, iter))
+}
+
+func testIteratorEmpty(t *testing.T, store common.PersistStore) {
+	require.Nil(t, store.Put([]byte("other"), []byte("value")))
+	iter := store.NewIterator([]byte("prefix"))
+	defer iter.Release()
+	require.False(t, iter.Next())
+	require.False(t, iter.First())
+	require.False(t, iter.Last())
+	require.False(t, iter.Prev())
+	require.False(t, iter.Seek([]byte("prefix")))
+	require.Nil(t, iter.Error())
+}
+
+func collectReverse(t *testing.T, iter common.StoreIterator) []string {
+	var kvs []string
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		kvs = append(kvs, string(iter.Key())+"="+string(iter.Value()))
+	}
+	require.Nil(t, iter.Error())
+	return kvs
+}
+
+func testIteratorSeek(t *testing.T, store common.PersistStore) {
+	putAll(t, store, map[string]string{"a": "0", "k1": "1", "k3": "3", "k5": "5", "z": "6"})
+
+	iter := store.NewIterator([]byte("k"))
+	defer iter.Release()
+	require.True(t, iter.Seek([]byte("k3")))
+	require.Equal(t, []byte("k3"), iter.Key())
+	require.Equal(t, []string{"k5=5"}, collect(t, iter))
+
+	require.True(t, iter.Seek([]byte("k2")))
+	require.Equal(t, []byte("k3"), iter.Key())
+	require.Equal(t, []byte("3"), iter.Value())
+
+	//seek before the prefix stops at the first item, after the prefix finds nothing
+	require.True(t, iter.Seek([]byte("a")))
+	require.Equal(t, []byte("k1"), iter.Key())
+	require.False(t, iter.Seek([]byte("k6")))
+	require.False(t, iter.Seek([]byte("z")))
+}
+
+func testIteratorReverse(t *testing.T, store common.PersistStore) {
+	putAll(t, store, map[string]string{
+		"a":     "0",
+		"b\x00": "1",
+		"b\x01": "2",
+		"b\xff": "3",
+		"c":     "4",
+	})
+
+	iter := store.NewIterator([]byte("b"))
+	require.Equal(t, []string{"b\xff=3", "b\x01=2", "b\x00=1"}, collectReverse(t, iter))
+	iter.Release()
+
+	iter = store.NewIterator(nil)
+	require.Equal(t, []string{"c=4", "b\xff=3", "b\x01=2", "b\x00=1", "a=0"}, collectReverse(t, iter))
+	iter.Release()
+}
+
+func testIteratorDirection(t *testing.T, store common.PersistStore) {
+	putAll(t, store, map[string]string{"k1": "1", "k2": "2", "k3": "3", "k4": "4"})
+
+	iter := store.NewIterator([]byte("k"))
+	defer iter.Release()
+	require.True(t, iter.Next())
+	require.True(t, iter.Next())
+	require.Equal(t, []byte("k2"), iter.Key())
+	require.True(t, iter.Prev())
+	require.Equal(t, []byte("k1"), iter.Key())
+	require.False(t, iter.Prev())
+	require.True(t, iter.Next())
+	require.Equal(t, []byte("k1"), iter.Key())
+
+	require.True(t, iter.Last())
+	require.Equal(t, []byte("k4"), iter.Key())
+	require.True(t, iter.Prev())
+	require.Equal(t, []byte("k3"), iter.Key())
+	require.True(t, iter.Next())
+	require.Equal(t, []byte("k4"), iter.Key())
+	require.Equal(t, []byte("4"), iter.Value())
+	require.False(t, iter.Next())
+	require.True(t, iter.Prev())
+	require.Equal(t, []byte("k4"), iter.Key())
+
+	require.True(t, iter.Seek([]byte("k3")))
+	require.True(t, iter.Prev())
+	require.Equal(t, []byte("k2"), iter.Key())
+}
+
+func testRangeIterator(t *testing.T, store common.PersistStore) {
+	putAll(t, store, map[string]string{"a": "0", "b1": "1", "b2": "2", "b3": "3", "c": "4"})
+
+	iter := store.NewRangeIterator([]byte("b1"), []byte("b3"))
+	require.Equal(t, []string{"b1=1", "b2=2"}, collect(t, iter))
+	require.Equal(t, []string{"b2=2", "b1=1"}, collectReverse(t, iter))
+	require.True(t, iter.Seek([]byte("a")))
+	require.Equal(t, []byte("b1"), iter.Key())
+	require.False(t, iter.Seek([]byte("b3")))
+	iter.Release()
+
+	iter = store.NewRangeIterator([]byte("b"), nil)
+	require.Equal(t, []string{"b1=1", "b2=2", "b3=3", "c=4"}, collect(t, iter))
+	iter.Release()
+
+	iter = store.NewRangeIterator(nil, []byte("b2"))
+	require.Equal(t, []string{"b1=1", "a=0"}, collectReverse(t, iter))
+	iter.Release()
+
+	iter = store.NewRangeIterator(nil, nil)
+	require.Len(t, collect(t, iter), 5)
+	iter.Release()
+}
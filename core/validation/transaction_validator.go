@@ -21,12 +21,14 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/constants"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/types"
@@ -117,8 +119,13 @@ func checkTransactionPayload(tx *types.Transaction) error {
 	switch pld := tx.Payload.(type) {
 	case *payload.DeployCode:
 		deploy := tx.Payload.(*payload.DeployCode)
+		// the features enabled by block height are checked by the stateful validator
+		if err := manifest.Verify(deploy.GetManifest(), math.MaxUint32, 0); err != nil {
+			return err
+		}
 		if deploy.VmType() == payload.WASMVM_TYPE {
-			_, err := wasmvm.ReadWasmModule(deploy.GetRawCode(), config.DefConfig.Common.WasmVerifyMethod)
+			_, err := wasmvm.ReadWasmModule(deploy.GetRawCode(), config.DefConfig.Common.WasmVerifyMethod,
+				math.MaxUint32)
 			if err != nil {
 				return err
 			}
@@ -25,6 +25,15 @@ import (
 const (
 	TOPIC_SAVE_BLOCK_COMPLETE = "svblkcmp"
 	TOPIC_SMART_CODE_EVENT    = "scevt"
+	TOPIC_TX_STATUS           = "txstatus"
+	TOPIC_CONSENSUS_EVENT     = "csevt"
+)
+
+//tx status in the tx pool
+const (
+	TX_STATUS_POOLED   = "pooled"
+	TX_STATUS_VERIFIED = "verified"
+	TX_STATUS_FAILED   = "failed"
 )
 
 type SaveBlockCompleteMsg struct {
@@ -35,6 +44,18 @@ type SmartCodeEventMsg struct {
 	Event *types.SmartCodeEvent
 }
 
+//TxStatusMsg notify tx status change in the tx pool
+type TxStatusMsg struct {
+	Tx     *types.Transaction
+	Status string
+	Desc   string
+}
+
+//ConsensusEventMsg notify a consensus event recorded in the block timeline
+type ConsensusEventMsg struct {
+	Event interface{}
+}
+
 type BlockConsensusComplete struct {
 	Block *types.Block
 }
@@ -19,11 +19,15 @@
 package actor
 
 import (
+	"errors"
+
 	"github.com/ontio/ontology-eventbus/actor"
 	cactor "github.com/ontio/ontology/consensus/actor"
 )
 
 var consensusSrvPid *actor.PID
+var consensusStateGetter func() interface{}
+var consensusHistoryGetter func(blockNum uint32) (interface{}, error)
 
 func SetConsensusPid(actr *actor.PID) {
 	consensusSrvPid = actr
@@ -44,3 +48,25 @@ func ConsensusSrvHalt() error {
 	}
 	return nil
 }
+
+//SetConsensusMonitor set the getters of consensus state and block timeline history
+func SetConsensusMonitor(state func() interface{}, history func(blockNum uint32) (interface{}, error)) {
+	consensusStateGetter = state
+	consensusHistoryGetter = history
+}
+
+//GetConsensusState from consensus service
+func GetConsensusState() (interface{}, error) {
+	if consensusStateGetter == nil {
+		return nil, errors.New("consensus state not available")
+	}
+	return consensusStateGetter(), nil
+}
+
+//GetConsensusHistory return the consensus timeline of the block
+func GetConsensusHistory(blockNum uint32) (interface{}, error) {
+	if consensusHistoryGetter == nil {
+		return nil, errors.New("consensus history not available")
+	}
+	return consensusHistoryGetter(blockNum)
+}
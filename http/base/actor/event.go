@@ -27,6 +27,8 @@ import (
 type EventActor struct {
 	blockPersistCompleted func(v interface{})
 	smartCodeEvt          func(v interface{})
+	txStatus              func(v interface{})
+	consensusEvt          func(v interface{})
 }
 
 //receive from subscribed actor
@@ -36,17 +38,25 @@ func (t *EventActor) Receive(c actor.Context) {
 		t.blockPersistCompleted(*msg.Block)
 	case *message.SmartCodeEventMsg:
 		t.smartCodeEvt(*msg.Event)
+	case *message.TxStatusMsg:
+		t.txStatus(*msg)
+	case *message.ConsensusEventMsg:
+		t.consensusEvt(msg.Event)
 	default:
 	}
 }
 
-//Subscribe save block complete and smartcontract Event
+//Subscribe save block complete, smartcontract Event, tx status and consensus event
 func SubscribeEvent(topic string, handler func(v interface{})) {
 	var props = actor.FromProducer(func() actor.Actor {
 		if topic == message.TOPIC_SAVE_BLOCK_COMPLETE {
 			return &EventActor{blockPersistCompleted: handler}
 		} else if topic == message.TOPIC_SMART_CODE_EVENT {
 			return &EventActor{smartCodeEvt: handler}
+		} else if topic == message.TOPIC_TX_STATUS {
+			return &EventActor{txStatus: handler}
+		} else if topic == message.TOPIC_CONSENSUS_EVENT {
+			return &EventActor{consensusEvt: handler}
 		} else {
 			return &EventActor{}
 		}
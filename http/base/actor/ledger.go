@@ -22,9 +22,11 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/ledger"
 	"github.com/ontio/ontology/core/payload"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/smartcontract/event"
 	cstate "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 const (
@@ -62,6 +64,11 @@ func GetCurrentBlockHeight() uint32 {
 	return ledger.DefLedger.GetCurrentBlockHeight()
 }
 
+//GetCurrentHeaderHeight from ledger
+func GetCurrentHeaderHeight() uint32 {
+	return ledger.DefLedger.GetCurrentHeaderHeight()
+}
+
 //GetTransaction from ledger
 func GetTransaction(hash common.Uint256) (*types.Transaction, error) {
 	return ledger.DefLedger.GetTransaction(hash)
@@ -72,6 +79,21 @@ func GetStorageItem(address common.Address, key []byte) ([]byte, error) {
 	return ledger.DefLedger.GetStorageItem(address, key)
 }
 
+//GetStorageItemAt from ledger at the end of block height
+func GetStorageItemAt(height uint32, address common.Address, key []byte) ([]byte, error) {
+	return ledger.DefLedger.GetStorageItemAt(height, address, key)
+}
+
+//GetContractStorageStats from ledger, return the key count and bytes of the contract storage
+func GetContractStorageStats(address common.Address) (*scom.StorageStats, error) {
+	return ledger.DefLedger.GetContractStorageStats(address)
+}
+
+//FindStorageItems from ledger, return a page of storage items with the key prefix
+func FindStorageItems(address common.Address, prefix, start []byte, limit int) ([]*scom.KeyValue, []byte, error) {
+	return ledger.DefLedger.FindStorageItems(address, prefix, start, limit)
+}
+
 //GetContractStateFromStore from ledger
 func GetContractStateFromStore(hash common.Address) (*payload.DeployCode, error) {
 	hash = updateNativeSCAddr(hash)
@@ -93,6 +115,25 @@ func PreExecuteContractBatch(tx []*types.Transaction, atomic bool) ([]*cstate.Pr
 	return ledger.DefLedger.PreExecuteContractBatch(tx, atomic)
 }
 
+//PreExecuteContractAt from ledger on the states at the end of block height
+func PreExecuteContractAt(height uint32, tx *types.Transaction) (*cstate.PreExecResult, error) {
+	return ledger.DefLedger.PreExecuteContractAt(height, tx)
+}
+
+func PreExecuteContractBatchAt(height uint32, tx []*types.Transaction) ([]*cstate.PreExecResult, error) {
+	return ledger.DefLedger.PreExecuteContractBatchAt(height, tx)
+}
+
+//PreExecuteContractWithProfile from ledger with the gas profile, on the current states if height is nil
+func PreExecuteContractWithProfile(height *uint32, tx *types.Transaction) (*cstate.PreExecResult, error) {
+	return ledger.DefLedger.PreExecuteContractWithProfile(height, tx)
+}
+
+//TraceTransaction re-execute the committed transaction with tracer
+func TraceTransaction(txHash common.Uint256, tracer trace.Tracer) (*event.ExecuteNotify, error) {
+	return ledger.DefLedger.TraceTransaction(txHash, tracer)
+}
+
 //GetEventNotifyByTxHash from ledger
 func GetEventNotifyByTxHash(txHash common.Uint256) (*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByTx(txHash)
@@ -103,6 +144,11 @@ func GetEventNotifyByHeight(height uint32) ([]*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByBlock(height)
 }
 
+//GetAddressHistory from ledger
+func GetAddressHistory(addr common.Address, fromHeight uint32, limit int) ([]*scom.AddressHistory, uint32, bool, error) {
+	return ledger.DefLedger.GetAddressHistory(addr, fromHeight, limit)
+}
+
 //GetMerkleProof from ledger
 func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]common.Uint256, error) {
 	return ledger.DefLedger.GetMerkleProof(proofHeight, rootHeight)
@@ -19,16 +19,63 @@
 package actor
 
 import (
+	"errors"
+	"time"
+
 	"github.com/ontio/ontology/p2pserver/common"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/protocols/block_sync"
+	"github.com/ontio/ontology/p2pserver/protocols/reputation"
 )
 
 var netServer p2p.P2P
+var syncStatusGetter func() *block_sync.SyncStatus
+var peerReputation *reputation.ReputationService
 
 func SetNetServer(p2p p2p.P2P) {
 	netServer = p2p
 }
 
+func SetSyncStatusGetter(getter func() *block_sync.SyncStatus) {
+	syncStatusGetter = getter
+}
+
+//GetBlockSyncStatus from block sync manager
+func GetBlockSyncStatus() *block_sync.SyncStatus {
+	if syncStatusGetter == nil {
+		return nil
+	}
+	return syncStatusGetter()
+}
+
+func SetReputationService(service *reputation.ReputationService) {
+	peerReputation = service
+}
+
+//GetBanList from peer reputation service
+func GetBanList() []reputation.BanEntry {
+	if peerReputation == nil {
+		return []reputation.BanEntry{}
+	}
+	return peerReputation.GetBanList()
+}
+
+//BanPeer ban the ip, duration 0 means banned persistently
+func BanPeer(ip string, duration time.Duration, reason string) error {
+	if peerReputation == nil {
+		return errors.New("peer reputation service not started")
+	}
+	return peerReputation.Ban(ip, duration, reason)
+}
+
+//UnbanPeer remove the ban of ip
+func UnbanPeer(ip string) error {
+	if peerReputation == nil {
+		return errors.New("peer reputation service not started")
+	}
+	return peerReputation.Unban(ip)
+}
+
 //GetConnectionCnt from netSever actor
 func GetConnectionCnt() uint32 {
 	if netServer == nil {
@@ -35,6 +35,9 @@ var txnPid *actor.PID
 var txnPoolPid *actor.PID
 var DisableSyncVerifyTx = false
 
+//ErrTxnNotInPool is returned by GetTxFromPool if the transaction is not in txpool
+var ErrTxnNotInPool = errors.New("transaction not in pool")
+
 func SetTxPid(actr *actor.PID) {
 	txnPid = actr
 }
@@ -97,7 +100,7 @@ func GetTxFromPool(hash common.Uint256) (tcomn.TXEntry, error) {
 		return tcomn.TXEntry{}, errors.New("fail")
 	}
 	if rsp.Txn == nil {
-		return tcomn.TXEntry{}, errors.New("fail")
+		return tcomn.TXEntry{}, ErrTxnNotInPool
 	}
 
 	future = txnPid.RequestFuture(&tcomn.GetTxnStatusReq{hash}, REQ_TIMEOUT*time.Second)
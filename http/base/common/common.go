@@ -21,6 +21,7 @@ package common
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -32,21 +33,31 @@ import (
 	"github.com/ontio/ontology/common/constants"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/types"
 	cutils "github.com/ontio/ontology/core/utils"
 	ontErrors "github.com/ontio/ontology/errors"
 	bactor "github.com/ontio/ontology/http/base/actor"
 	common2 "github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/protocols/block_sync"
 	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/service/native/ont"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	sneovm "github.com/ontio/ontology/smartcontract/service/neovm"
 	cstate "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/trace"
+	"github.com/ontio/ontology/vm/crossvm_codec"
 	"github.com/ontio/ontology/vm/neovm"
 )
 
 const MAX_SEARCH_HEIGHT uint32 = 100
 const MAX_REQUEST_BODY_SIZE = 1 << 20
+const DEFAULT_STORAGE_LIST_LIMIT = 100
+const MAX_STORAGE_LIST_LIMIT = 1000
+const DEFAULT_ADDRESS_HISTORY_LIMIT = 100
+const MAX_ADDRESS_HISTORY_LIMIT = 1000
 
 type BalanceOfRsp struct {
 	Ont    string `json:"ont"`
@@ -64,6 +75,42 @@ type Oep4Balance struct {
 	Balance string `json:"balance"`
 }
 
+type StorageItem struct {
+	Key   string
+	Value string
+}
+
+type StorageList struct {
+	Items []StorageItem
+	Next  string
+}
+
+type ContractStorageStats struct {
+	KeyCount   uint64
+	Bytes      uint64
+	FeePerByte uint64 //the Storage.Byte.Gas global param
+	Fee        uint64 //the storage fee of the contract at FeePerByte
+}
+
+type AddressTransfer struct {
+	Contract string
+	From     string
+	To       string
+	Amount   string
+}
+
+type AddressTxHistory struct {
+	TxHash    string
+	Height    uint32
+	Transfers []AddressTransfer
+}
+
+type AddressHistoryList struct {
+	History    []AddressTxHistory
+	NextHeight uint32
+	HasMore    bool
+}
+
 type MerkleProof struct {
 	Type             string
 	TransactionsRoot string
@@ -86,16 +133,23 @@ type ExecuteNotify struct {
 	Notify      []NotifyEventInfo
 }
 
+type TransactionTrace struct {
+	ExecuteNotify
+	Trace interface{}
+}
+
 type PreExecuteResult struct {
-	State  byte
-	Gas    uint64
-	Result interface{}
-	Notify []NotifyEventInfo
+	State   byte
+	Gas     uint64
+	Result  interface{}
+	Notify  []NotifyEventInfo
+	Profile *trace.GasProfile `json:",omitempty"`
 }
 
 type NotifyEventInfo struct {
 	ContractAddress string
 	States          interface{}
+	Event           *manifest.DecodedEvent `json:",omitempty"` //typed event of wasm contract or decoded by the manifest of the contract
 }
 
 type TxAttributeInfo struct {
@@ -198,20 +252,106 @@ func GetLogEvent(obj *event.LogEventArgs) (map[string]bool, LogEventArgs) {
 func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify) {
 	evts := []NotifyEventInfo{}
 	var contractAddrs = make(map[string]bool)
+	decoder := NewNotifyDecoder()
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{
+			ContractAddress: v.ContractAddress.ToHexString(),
+			States:          v.States,
+			Event:           decoder.Decode(v),
+		})
 		contractAddrs[v.ContractAddress.ToHexString()] = true
 	}
 	txhash := obj.TxHash.ToHexString()
 	return contractAddrs, ExecuteNotify{txhash, obj.State, obj.GasConsumed, evts}
 }
 
+//notifyContract is the contract info needed to decode its notifies
+type notifyContract struct {
+	wasm     bool
+	manifest *manifest.Manifest //nil if the contract is deployed without manifest
+}
+
+//NotifyDecoder decode notifies by the contracts notifying them, each contract is loaded once
+type NotifyDecoder struct {
+	contracts map[common.Address]*notifyContract
+}
+
+func NewNotifyDecoder() *NotifyDecoder {
+	return &NotifyDecoder{contracts: make(map[common.Address]*notifyContract)}
+}
+
+//Decode return the typed event of wasm contract or the event decoded by the manifest of neovm contract, nil if the
+//notify is not an event. The states of notify are not changed
+func (self *NotifyDecoder) Decode(notify *event.NotifyEventInfo) *manifest.DecodedEvent {
+	contract, ok := self.contracts[notify.ContractAddress]
+	if !ok {
+		contract = getNotifyContract(notify.ContractAddress)
+		self.contracts[notify.ContractAddress] = contract
+	}
+	if contract == nil {
+		return nil
+	}
+	if contract.wasm {
+		data, ok := wasmNotifyData(notify.States)
+		if !ok {
+			return nil
+		}
+		return manifest.DecodeWasmEvent(data)
+	}
+	if contract.manifest == nil {
+		return nil
+	}
+	return contract.manifest.DecodeNeoVMEvent(notify.States)
+}
+
+//wasmNotifyData return the data of wasm notify if it is a typed event. The data is kept as bytes in notify
+//of execution, and as base64 string in notify loaded from event store
+func wasmNotifyData(states interface{}) ([]byte, bool) {
+	var data []byte
+	switch val := states.(type) {
+	case []byte:
+		data = val
+	case string:
+		var err error
+		data, err = base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	return data, crossvm_codec.IsTypedEvent(data)
+}
+
+func getNotifyContract(contract common.Address) *notifyContract {
+	if ledger.DefLedger == nil {
+		return nil
+	}
+	dep, err := bactor.GetContractStateFromStore(contract)
+	if err != nil || dep == nil {
+		return nil
+	}
+	if dep.VmType() == payload.WASMVM_TYPE {
+		return &notifyContract{wasm: true}
+	}
+	info := &notifyContract{}
+	if len(dep.GetManifest()) != 0 {
+		info.manifest, _ = manifest.Parse(dep.GetManifest())
+	}
+	return info
+}
+
 func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	evts := []NotifyEventInfo{}
+	decoder := NewNotifyDecoder()
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{
+			ContractAddress: v.ContractAddress.ToHexString(),
+			States:          v.States,
+			Event:           decoder.Decode(v),
+		})
 	}
-	return PreExecuteResult{obj.State, obj.Gas, obj.Result, evts}
+	return PreExecuteResult{obj.State, obj.Gas, obj.Result, evts, obj.Profile}
 }
 
 func TransArryByteToHexString(ptx *types.Transaction) *Transactions {
@@ -265,6 +405,31 @@ func SendTxToPool(txn *types.Transaction) (ontErrors.ErrCode, string) {
 	return ontErrors.ErrNoError, ""
 }
 
+func GetAddressHistoryList(histories []*scom.AddressHistory, next uint32, more bool) AddressHistoryList {
+	list := AddressHistoryList{
+		History:    make([]AddressTxHistory, 0, len(histories)),
+		NextHeight: next,
+		HasMore:    more,
+	}
+	for _, history := range histories {
+		item := AddressTxHistory{
+			TxHash:    history.TxHash.ToHexString(),
+			Height:    history.Height,
+			Transfers: make([]AddressTransfer, 0, len(history.Transfers)),
+		}
+		for _, transfer := range history.Transfers {
+			item.Transfers = append(item.Transfers, AddressTransfer{
+				Contract: transfer.Contract.ToHexString(),
+				From:     transfer.From.ToBase58(),
+				To:       transfer.To.ToBase58(),
+				Amount:   transfer.Amount.String(),
+			})
+		}
+		list.History = append(list.History, item)
+	}
+	return list
+}
+
 func GetBlockInfo(block *types.Block) BlockInfo {
 	hash := block.Hash()
 	var bookkeepers = []string{}
@@ -311,7 +476,16 @@ func GetBlockInfo(block *types.Block) BlockInfo {
 }
 
 func GetBalance(address common.Address) (*BalanceOfRsp, error) {
-	balances, height, err := GetContractBalance(0, []common.Address{utils.OntContractAddress, utils.OngContractAddress}, address, true)
+	return getBalance(address, nil)
+}
+
+//GetBalanceAt return the ont and ong balance of address at the end of block height
+func GetBalanceAt(address common.Address, height uint32) (*BalanceOfRsp, error) {
+	return getBalance(address, &height)
+}
+
+func getBalance(address common.Address, atHeight *uint32) (*BalanceOfRsp, error) {
+	balances, height, err := getContractBalance(0, []common.Address{utils.OntContractAddress, utils.OngContractAddress}, address, true, atHeight)
 	if err != nil {
 		return nil, fmt.Errorf("get ont balance error:%s", err)
 	}
@@ -323,7 +497,16 @@ func GetBalance(address common.Address) (*BalanceOfRsp, error) {
 }
 
 func GetOep4Balance(contractAddress common.Address, addrs []common.Address) (*Oep4BalanceOfRsp, error) {
-	balances, height, err := GetOep4ContractBalance(contractAddress, addrs, true)
+	return getOep4Balance(contractAddress, addrs, nil)
+}
+
+//GetOep4BalanceAt return the oep4 balances of addrs at the end of block height
+func GetOep4BalanceAt(contractAddress common.Address, addrs []common.Address, height uint32) (*Oep4BalanceOfRsp, error) {
+	return getOep4Balance(contractAddress, addrs, &height)
+}
+
+func getOep4Balance(contractAddress common.Address, addrs []common.Address, atHeight *uint32) (*Oep4BalanceOfRsp, error) {
+	balances, height, err := getOep4ContractBalance(contractAddress, addrs, true, atHeight)
 	if err != nil {
 		return nil, fmt.Errorf("get ont balance error:%s", err)
 	}
@@ -377,6 +560,20 @@ func GetAllowance(asset string, from, to common.Address) (string, error) {
 }
 
 func GetContractBalance(cVersion byte, contractAddres []common.Address, accAddr common.Address, atomic bool) ([]uint64, uint32, error) {
+	return getContractBalance(cVersion, contractAddres, accAddr, atomic, nil)
+}
+
+// preExecuteBatch pre-execute txes on the latest states, or on the states at the end of block atHeight if it is not nil
+func preExecuteBatch(txes []*types.Transaction, atomic bool, atHeight *uint32) ([]*cstate.PreExecResult, uint32, error) {
+	if atHeight == nil {
+		return bactor.PreExecuteContractBatch(txes, atomic)
+	}
+	results, err := bactor.PreExecuteContractBatchAt(*atHeight, txes)
+	return results, *atHeight, err
+}
+
+func getContractBalance(cVersion byte, contractAddres []common.Address, accAddr common.Address, atomic bool,
+	atHeight *uint32) ([]uint64, uint32, error) {
 	txes := make([]*types.Transaction, 0, len(contractAddres))
 	for _, contractAddr := range contractAddres {
 		mutable, err := NewNativeInvokeTransaction(0, 0, contractAddr, cVersion, "balanceOf", []interface{}{accAddr[:]})
@@ -392,7 +589,7 @@ func GetContractBalance(cVersion byte, contractAddres []common.Address, accAddr
 		txes = append(txes, tx)
 	}
 
-	results, height, err := bactor.PreExecuteContractBatch(txes, atomic)
+	results, height, err := preExecuteBatch(txes, atomic, atHeight)
 	if err != nil {
 		return nil, 0, fmt.Errorf("PrepareInvokeContract error:%s", err)
 	}
@@ -414,6 +611,11 @@ func GetContractBalance(cVersion byte, contractAddres []common.Address, accAddr
 }
 
 func GetOep4ContractBalance(contractAddr common.Address, accAddr []common.Address, atomic bool) ([]string, uint32, error) {
+	return getOep4ContractBalance(contractAddr, accAddr, atomic, nil)
+}
+
+func getOep4ContractBalance(contractAddr common.Address, accAddr []common.Address, atomic bool,
+	atHeight *uint32) ([]string, uint32, error) {
 	txes := make([]*types.Transaction, 0, len(accAddr))
 	var mutable *types.MutableTransaction
 	var err error
@@ -431,7 +633,7 @@ func GetOep4ContractBalance(contractAddr common.Address, accAddr []common.Addres
 		txes = append(txes, tx)
 	}
 
-	results, height, err := bactor.PreExecuteContractBatch(txes, atomic)
+	results, height, err := preExecuteBatch(txes, atomic, atHeight)
 	if err != nil {
 		return nil, 0, fmt.Errorf("PrepareInvokeContract error:%s", err)
 	}
@@ -486,6 +688,24 @@ func GetContractAllowance(cVersion byte, contractAddr, fromAddr, toAddr common.A
 	return allowance.Uint64(), nil
 }
 
+//GetContractStorageStats return the storage stats of contract with its storage fee at the current fee per byte
+func GetContractStorageStats(address common.Address) (*ContractStorageStats, error) {
+	stats, err := bactor.GetContractStorageStats(address)
+	if err != nil {
+		return nil, err
+	}
+	var feePerByte uint64
+	if value, ok := sneovm.GAS_TABLE.Load(sneovm.STORAGE_BYTE_NAME); ok {
+		feePerByte = value.(uint64)
+	}
+	return &ContractStorageStats{
+		KeyCount:   stats.KeyCount,
+		Bytes:      stats.Bytes,
+		FeePerByte: feePerByte,
+		Fee:        stats.Fee(feePerByte),
+	}, nil
+}
+
 func GetGasPrice() (map[string]interface{}, error) {
 	start := bactor.GetCurrentBlockHeight()
 	var gasPrice uint64 = 0
@@ -590,9 +810,11 @@ func GetAddress(str string) (common.Address, error) {
 }
 
 type SyncStatus struct {
-	CurrentBlockHeight uint32
-	ConnectCount       uint32
-	MaxPeerBlockHeight uint64
+	CurrentBlockHeight  uint32
+	CurrentHeaderHeight uint32
+	ConnectCount        uint32
+	MaxPeerBlockHeight  uint64
+	BlockSync           *block_sync.SyncStatus
 }
 
 func GetSyncStatus() (SyncStatus, error) {
@@ -601,8 +823,10 @@ func GetSyncStatus() (SyncStatus, error) {
 	curBlockHeight := bactor.GetCurrentBlockHeight()
 
 	return SyncStatus{
-		CurrentBlockHeight: curBlockHeight,
-		ConnectCount:       cnt,
-		MaxPeerBlockHeight: height,
+		CurrentBlockHeight:  curBlockHeight,
+		CurrentHeaderHeight: bactor.GetCurrentHeaderHeight(),
+		ConnectCount:        cnt,
+		MaxPeerBlockHeight:  height,
+		BlockSync:           bactor.GetBlockSyncStatus(),
 	}, nil
 }
@@ -18,6 +18,8 @@
 package common
 
 import (
+	"encoding/json"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/payload"
@@ -37,6 +39,7 @@ type DeployCodeInfo struct {
 	Author      string
 	Email       string
 	Description string
+	Manifest    json.RawMessage `json:",omitempty"`
 }
 
 type BookkeeperInfo struct {
@@ -77,6 +80,7 @@ func TransPayloadToHex(p types.Payload) PayloadInfo {
 		obj.Author = object.Author
 		obj.Email = object.Email
 		obj.Description = object.Description
+		obj.Manifest = object.GetManifest()
 		return obj
 	}
 	return nil
@@ -31,6 +31,7 @@ import (
 	bcomn "github.com/ontio/ontology/http/base/common"
 	berr "github.com/ontio/ontology/http/base/error"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstates "github.com/ontio/ontology/smartcontract/states"
 )
 
 const TLS_PORT int = 443
@@ -269,7 +270,18 @@ func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("SendRawTransaction recv %s", hash.ToHexString())
 	if txn.TxType == types.InvokeNeo || txn.TxType == types.InvokeWasm || txn.TxType == types.Deploy {
 		if preExec, ok := cmd["PreExec"].(string); ok && preExec == "1" {
-			rst, err := bactor.PreExecuteContract(txn)
+			height, err := parseHeightParam(cmd)
+			if err != nil {
+				return ResponsePack(berr.INVALID_PARAMS)
+			}
+			var rst *cstates.PreExecResult
+			if profile, ok := cmd["Profile"].(string); ok && profile == "1" {
+				rst, err = bactor.PreExecuteContractWithProfile(height, txn)
+			} else if height != nil {
+				rst, err = bactor.PreExecuteContractAt(*height, txn)
+			} else {
+				rst, err = bactor.PreExecuteContract(txn)
+			}
 			if err != nil {
 				log.Infof("PreExec: ", err)
 				resp = ResponsePack(berr.SMARTCODE_ERROR)
@@ -399,7 +411,16 @@ func GetStorage(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	value, err := bactor.GetStorageItem(address, item)
+	height, err := parseHeightParam(cmd)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var value []byte
+	if height != nil {
+		value, err = bactor.GetStorageItemAt(*height, address, item)
+	} else {
+		value, err = bactor.GetStorageItem(address, item)
+	}
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return ResponsePack(berr.SUCCESS)
@@ -421,7 +442,16 @@ func GetBalance(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	balance, err := bcomn.GetBalance(address)
+	height, err := parseHeightParam(cmd)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var balance *bcomn.BalanceOfRsp
+	if height != nil {
+		balance, err = bcomn.GetBalanceAt(address, *height)
+	} else {
+		balance, err = bcomn.GetBalance(address)
+	}
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
@@ -429,6 +459,43 @@ func GetBalance(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+//get transfer history of address
+func GetAddressHistory(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	addrBase58, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := common.AddressFromBase58(addrBase58)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	fromHeight := uint32(0)
+	height, err := parseHeightParam(cmd)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	if height != nil {
+		fromHeight = *height
+	}
+	limit := bcomn.DEFAULT_ADDRESS_HISTORY_LIMIT
+	if param, ok := cmd["Limit"].(string); ok && len(param) > 0 {
+		l, err := strconv.Atoi(param)
+		if err != nil || l < 1 || l > bcomn.MAX_ADDRESS_HISTORY_LIMIT {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		limit = l
+	}
+	histories, next, more, err := bactor.GetAddressHistory(address, fromHeight, limit)
+	if err != nil {
+		resp = ResponsePack(berr.INTERNAL_ERROR)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	resp["Result"] = bcomn.GetAddressHistoryList(histories, next, more)
+	return resp
+}
+
 //get merkle proof by transaction hash
 func GetMerkleProof(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -596,3 +663,17 @@ func GetMemPoolTxState(cmd map[string]interface{}) map[string]interface{} {
 	resp["Result"] = bcomn.TXNEntryInfo{attrs}
 	return resp
 }
+
+//parseHeightParam parse the optional block height of query, return nil height if absent
+func parseHeightParam(cmd map[string]interface{}) (*uint32, error) {
+	param, ok := cmd["Height"].(string)
+	if !ok || len(param) == 0 {
+		return nil, nil
+	}
+	h, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	height := uint32(h)
+	return &height, nil
+}
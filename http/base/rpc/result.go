@@ -22,6 +22,41 @@ import (
 	Err "github.com/ontio/ontology/http/base/error"
 )
 
+// error codes defined by json rpc 2.0
+const (
+	JSONRPC_PARSE_ERROR      int64 = -32700
+	JSONRPC_INVALID_REQUEST  int64 = -32600
+	JSONRPC_METHOD_NOT_FOUND int64 = -32601
+	JSONRPC_INVALID_PARAMS   int64 = -32602
+	JSONRPC_INTERNAL_ERROR   int64 = -32603
+	JSONRPC_SERVER_ERROR     int64 = -32000
+)
+
+var jsonRpcErrMsg = map[int64]string{
+	JSONRPC_PARSE_ERROR:      "Parse error",
+	JSONRPC_INVALID_REQUEST:  "Invalid Request",
+	JSONRPC_METHOD_NOT_FOUND: "Method not found",
+	JSONRPC_INVALID_PARAMS:   "Invalid params",
+	JSONRPC_INTERNAL_ERROR:   "Internal error",
+	JSONRPC_SERVER_ERROR:     "Server error",
+}
+
+// JsonRpcErrCode maps the ontology http error code onto a json rpc 2.0 error code
+func JsonRpcErrCode(errcode int64) int64 {
+	switch errcode {
+	case Err.INVALID_METHOD:
+		return JSONRPC_METHOD_NOT_FOUND
+	case Err.INVALID_PARAMS:
+		return JSONRPC_INVALID_PARAMS
+	case Err.ILLEGAL_DATAFORMAT:
+		return JSONRPC_INVALID_REQUEST
+	case Err.INTERNAL_ERROR:
+		return JSONRPC_INTERNAL_ERROR
+	default:
+		return JSONRPC_SERVER_ERROR
+	}
+}
+
 func ResponseSuccess(result interface{}) map[string]interface{} {
 	return ResponsePack(Err.SUCCESS, result)
 }
@@ -33,3 +68,32 @@ func ResponsePack(errcode int64, result interface{}) map[string]interface{} {
 	}
 	return resp
 }
+
+func errorResponse(id interface{}, code int64, data interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": jsonRpcErrMsg[code],
+			"data":    data,
+		},
+		"id": id,
+	}
+}
+
+// standardError builds a json rpc 2.0 error object, the ontology error code and desc are kept in data
+func standardError(id interface{}, errcode int64, result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    JsonRpcErrCode(errcode),
+			"message": Err.ErrMap[errcode],
+			"data": map[string]interface{}{
+				"code":   errcode,
+				"desc":   Err.ErrMap[errcode],
+				"result": result,
+			},
+		},
+		"id": id,
+	}
+}
@@ -20,6 +20,7 @@
 package rpc
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -30,6 +31,7 @@ import (
 
 	// fast json marshal/unmarshal
 	jsoniter "github.com/json-iterator/go"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/http/base/common"
 	berr "github.com/ontio/ontology/http/base/error"
@@ -39,11 +41,24 @@ var (
 	json = jsoniter.ConfigCompatibleWithStandardLibrary
 )
 
+// max requests in a json rpc 2.0 batch
+const MAX_BATCH_SIZE = 100
+
 type JReq struct {
-	JSONRPC string        `json:"jsonrpc"`
-	Method  string        `json:"method"`
-	Params  []interface{} `json:"params"`
-	ID      interface{}   `json:"id"`
+	JSONRPC string              `json:"jsonrpc"`
+	Method  string              `json:"method"`
+	Params  []interface{}       `json:"params"`
+	ID      jsoniter.RawMessage `json:"id"` // nil if the request is a notification
+}
+
+func (self *JReq) id() interface{} {
+	var id interface{}
+	if len(self.ID) != 0 {
+		if err := json.Unmarshal(self.ID, &id); err != nil {
+			return nil
+		}
+	}
+	return id
 }
 
 func init() {
@@ -103,60 +118,137 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		mainMux.RUnlock()
 		return
 	}
-	var request JReq
 	defer r.Body.Close()
-	decoder := json.NewDecoder(io.LimitReader(r.Body, common.MAX_REQUEST_BODY_SIZE))
-	err := decoder.Decode(&request)
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, common.MAX_REQUEST_BODY_SIZE))
 	if err != nil {
+		log.Error("HTTP JSON RPC Handle - read body: ", err)
+		return
+	}
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) > 0 && body[0] == '[' {
+		handleBatch(w, body)
+		return
+	}
+
+	var request JReq
+	if err := json.Unmarshal(body, &request); err != nil {
 		log.Error("HTTP JSON RPC Handle - json.Unmarshal: ", err)
+		if config.DefConfig.Rpc.StandardResponse {
+			writeResponse(w, errorResponse(nil, JSONRPC_PARSE_ERROR, err.Error()))
+		}
+		return
+	}
+	if response := handleRequest(&request); response != nil {
+		writeResponse(w, response)
+	}
+}
+
+// handleBatch answers a json rpc 2.0 batch, notifications in the batch get no response
+func handleBatch(w http.ResponseWriter, body []byte) {
+	var requests []jsoniter.RawMessage
+	if err := json.Unmarshal(body, &requests); err != nil {
+		log.Error("HTTP JSON RPC Handle - json.Unmarshal batch: ", err)
+		writeResponse(w, errorResponse(nil, JSONRPC_PARSE_ERROR, err.Error()))
+		return
+	}
+	if len(requests) == 0 {
+		writeResponse(w, errorResponse(nil, JSONRPC_INVALID_REQUEST, "empty batch"))
+		return
+	}
+	if len(requests) > MAX_BATCH_SIZE {
+		writeResponse(w, errorResponse(nil, JSONRPC_INVALID_REQUEST,
+			fmt.Sprintf("batch size %d exceeds limit %d", len(requests), MAX_BATCH_SIZE)))
 		return
 	}
+
+	responses := make([]map[string]interface{}, 0, len(requests))
+	for _, raw := range requests {
+		var request JReq
+		if err := json.Unmarshal(raw, &request); err != nil {
+			responses = append(responses, errorResponse(nil, JSONRPC_INVALID_REQUEST, err.Error()))
+			continue
+		}
+		if response := handleRequest(&request); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeResponse(w, responses)
+}
+
+// handleRequest calls the registered function of the request method.
+// It returns nil for a notification, which the server must not reply to.
+func handleRequest(request *JReq) map[string]interface{} {
+	standard := config.DefConfig.Rpc.StandardResponse
+	notification := standard && request.ID == nil
+	id := request.id()
 	if request.Method == "" {
 		log.Error("HTTP JSON RPC Handle - method is not string: ")
-		return
+		if !standard {
+			return nil
+		}
+		return errorResponse(id, JSONRPC_INVALID_REQUEST, "method is not string")
 	}
 	//get the corresponding function
 	mainMux.RLock()
 	function, ok := mainMux.m[request.Method]
 	mainMux.RUnlock()
-	if ok {
-		response := function(request.Params)
-		data, err := json.Marshal(map[string]interface{}{
-			"jsonrpc": "2.0",
-			"error":   response["error"],
-			"desc":    response["desc"],
-			"result":  response["result"],
-			"id":      request.ID,
-		})
-		if err != nil {
-			log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
-			return
-		}
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Write(data)
-	} else {
+	if !ok {
 		//if the function does not exist
 		log.Warn("HTTP JSON RPC Handle - No function to call for ", request.Method)
-		data, err := json.Marshal(map[string]interface{}{
+		if notification {
+			return nil
+		}
+		if standard {
+			return errorResponse(id, JSONRPC_METHOD_NOT_FOUND, "The called method was not found on the server")
+		}
+		return map[string]interface{}{
 			"error": berr.INVALID_METHOD,
 			"result": map[string]interface{}{
-				"code":    -32601,
+				"code":    JSONRPC_METHOD_NOT_FOUND,
 				"message": "Method not found",
 				"data":    "The called method was not found on the server",
 			},
-			"id": request.ID,
-		})
-		if err != nil {
-			log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
-			return
+			"id": id,
 		}
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Write(data)
 	}
+
+	response := function(request.Params)
+	if notification {
+		return nil
+	}
+	if !standard {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"error":   response["error"],
+			"desc":    response["desc"],
+			"result":  response["result"],
+			"id":      id,
+		}
+	}
+	if code, _ := response["error"].(int64); code != berr.SUCCESS {
+		return standardError(id, code, response["result"])
+	}
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"result":  response["result"],
+		"id":      id,
+	}
+}
+
+func writeResponse(w http.ResponseWriter, response interface{}) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
+		return
+	}
+	w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(data)
 }
 
 // Call sends RPC request to server
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ontio/ontology/common/config"
+	berr "github.com/ontio/ontology/http/base/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	HandleFunc("echo", func(params []interface{}) map[string]interface{} {
+		if len(params) == 0 {
+			return ResponsePack(berr.INVALID_PARAMS, "")
+		}
+		return ResponseSuccess(params[0])
+	})
+}
+
+func post(body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	Handle(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	err := json.Unmarshal(rec.Body.Bytes(), v)
+	assert.Nil(t, err)
+}
+
+func TestLegacyResponse(t *testing.T) {
+	config.DefConfig.Rpc.StandardResponse = false
+
+	var resp map[string]interface{}
+	decode(t, post(`{"jsonrpc":"2.0","method":"echo","params":["a"],"id":1}`), &resp)
+	assert.Equal(t, float64(berr.SUCCESS), resp["error"])
+	assert.Equal(t, "a", resp["result"])
+	assert.Equal(t, float64(1), resp["id"])
+
+	decode(t, post(`{"jsonrpc":"2.0","method":"echo","params":[]}`), &resp)
+	assert.Equal(t, float64(berr.INVALID_PARAMS), resp["error"])
+	assert.Nil(t, resp["id"])
+}
+
+func TestStandardResponse(t *testing.T) {
+	config.DefConfig.Rpc.StandardResponse = true
+	defer func() { config.DefConfig.Rpc.StandardResponse = false }()
+
+	var resp map[string]interface{}
+	decode(t, post(`{"jsonrpc":"2.0","method":"echo","params":["a"],"id":"x"}`), &resp)
+	assert.Equal(t, "a", resp["result"])
+	assert.Equal(t, "x", resp["id"])
+	assert.NotContains(t, resp, "error")
+
+	resp = nil
+	decode(t, post(`{"jsonrpc":"2.0","method":"echo","params":[],"id":null}`), &resp)
+	errObj := resp["error"].(map[string]interface{})
+	assert.Equal(t, float64(JSONRPC_INVALID_PARAMS), errObj["code"])
+	assert.Equal(t, float64(berr.INVALID_PARAMS), errObj["data"].(map[string]interface{})["code"])
+	assert.Contains(t, resp, "id")
+
+	resp = nil
+	decode(t, post(`{"jsonrpc":"2.0","method":"unknown","id":2}`), &resp)
+	assert.Equal(t, float64(JSONRPC_METHOD_NOT_FOUND), resp["error"].(map[string]interface{})["code"])
+
+	resp = nil
+	decode(t, post(`{"jsonrpc":`), &resp)
+	assert.Equal(t, float64(JSONRPC_PARSE_ERROR), resp["error"].(map[string]interface{})["code"])
+
+	rec := post(`{"jsonrpc":"2.0","method":"echo","params":["a"]}`)
+	assert.Equal(t, 0, rec.Body.Len())
+}
+
+func TestBatch(t *testing.T) {
+	config.DefConfig.Rpc.StandardResponse = true
+	defer func() { config.DefConfig.Rpc.StandardResponse = false }()
+
+	var resps []map[string]interface{}
+	decode(t, post(`[
+		{"jsonrpc":"2.0","method":"echo","params":["a"],"id":1},
+		{"jsonrpc":"2.0","method":"echo","params":["b"]},
+		1,
+		{"jsonrpc":"2.0","method":"echo","params":["c"],"id":3}
+	]`), &resps)
+	assert.Equal(t, 3, len(resps))
+	assert.Equal(t, "a", resps[0]["result"])
+	assert.Equal(t, float64(JSONRPC_INVALID_REQUEST), resps[1]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, "c", resps[2]["result"])
+	assert.Equal(t, float64(3), resps[2]["id"])
+
+	rec := post(`[{"jsonrpc":"2.0","method":"echo","params":["b"]}]`)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	var resp map[string]interface{}
+	decode(t, post(`[]`), &resp)
+	assert.Equal(t, float64(JSONRPC_INVALID_REQUEST), resp["error"].(map[string]interface{})["code"])
+}
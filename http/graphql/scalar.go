@@ -169,3 +169,20 @@ func (key *PubKey) UnmarshalGraphQL(input interface{}) error {
 func (key PubKey) MarshalJSON() ([]byte, error) {
 	return json.Marshal(string(key))
 }
+
+type JSON struct {
+	Value interface{}
+}
+
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (t *JSON) UnmarshalGraphQL(input interface{}) error {
+	t.Value = input
+	return nil
+}
+
+func (t JSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Value)
+}
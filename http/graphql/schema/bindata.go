@@ -96,7 +96,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _schemaGraphql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x55\x4d\x6f\xdb\x30\x0c\xbd\xfb\x57\xb0\xc8\xa5\xbb\xf4\xd0\xb5\xc5\xe0\x5b\xb3\x16\x48\xd1\xaf\x6c\xc9\x36\x0c\x45\x31\x30\x32\x6b\x0b\xb1\x25\x4f\x94\xd3\x18\x45\xff\xfb\x20\xcb\x76\xe5\xc4\x1d\xd0\x53\x22\xea\xf1\xe9\x3d\x92\x92\x27\xb0\x42\xa6\xd3\x2f\xa0\x0d\x64\xb4\x05\xb6\x46\xaa\x14\x30\x49\x0c\x31\x47\x2c\x30\x47\x03\xe7\xed\x72\x12\x62\xf4\x13\x64\xc8\xd9\xf1\xe9\x59\x07\x9b\xb9\xff\xbb\x98\xb2\x5a\xe5\x52\xc0\x9a\xea\x0e\x36\xaf\x56\xd7\x54\x47\xdd\xf2\x87\x54\xf6\xf3\x71\x34\x81\x4a\x2a\x7b\x76\x02\xa4\x84\x4e\x28\x01\xe4\x96\x25\x04\x9e\x9d\x44\x11\xa9\xaa\x80\xe5\x76\x59\x97\x04\x2f\x11\x00\xc0\xd5\xdd\xcf\xfb\xeb\xcb\x3f\x77\x97\xf7\xe1\xf2\xd7\xf9\xe2\xb6\x59\x5f\x5c\xce\x6f\xee\x7f\xf7\xdb\xed\xb2\xd9\x7e\x8d\x22\xa9\x2c\x99\x27\x14\x04\x73\xac\x73\x8d\x49\x4b\xea\x54\x40\x0c\x8b\x46\xc3\x81\x43\x5a\x77\xe2\x95\xda\xe8\x35\x7d\x75\x9b\xb2\x28\x73\x2a\x48\x59\x1e\x49\xdd\xcf\xbc\xa0\x32\xd7\xf5\x47\x32\x5d\x64\x53\x38\xa3\xc3\x98\xc2\x62\x17\x45\x86\xa5\x56\xc3\x20\x56\x36\xd3\x66\x18\xa3\x02\x65\x3e\x0c\x25\xc4\x62\xa0\x76\x02\xd6\xa0\x62\x14\x56\x6a\xe5\x9a\x50\x09\x5b\x19\x72\xdd\xd4\xca\xea\x5c\xa7\xb5\x77\xb4\x0c\x60\x2f\x43\x1d\xbe\xab\xfe\x00\x37\x26\x71\x33\x1d\xad\x7c\xad\x04\x0d\x21\x76\xeb\x5d\xfa\xb6\xfa\x58\x8a\x3c\x37\x72\x17\x99\x22\xdf\xc8\x42\xda\x61\xb4\xc4\x9a\x4c\xdc\x0d\x6a\x1f\x73\x95\x8d\xbb\x12\xfb\x28\xcb\x94\x63\x78\x58\xc8\xf4\xe0\xf1\x20\xf2\xfa\x48\xa6\x59\x40\xd8\x35\x6c\x21\xd3\xd6\x16\xcb\xf4\x02\x2d\xba\x3c\x5f\xa6\xc7\xf6\x88\x66\x94\x1d\x9f\x1f\xea\x2e\x7e\x3b\x20\x9b\xc0\x34\xd7\x62\xfd\xbf\x4a\x7a\x80\x3f\x6c\x02\xcb\x8c\x20\x23\x4c\xc8\x38\xa4\xcd\x24\xc3\xca\x01\x8e\x5a\xb9\x6e\x27\x86\x59\xf3\xdb\x7a\xf0\x49\x41\xdf\x38\xc8\x03\xa9\x44\x5e\x25\x94\x78\x82\x10\x15\xc3\x43\xd0\x45\xa7\xbf\x11\xec\xb9\x41\x3a\x96\x50\x0b\x7a\x42\x2f\xba\x05\x85\xaa\xdb\xfe\xf7\xb2\x7d\xe6\xd1\xf8\x6c\x84\x6e\x91\xb3\xd1\xa4\x70\x76\x02\x7c\x69\x68\x23\x75\xd5\xf9\x73\x28\x8f\x77\x1b\xb3\xf1\x1c\x51\x19\x43\xca\x76\x29\x4d\xd3\x8f\x46\x07\x20\xac\xa8\x2c\x88\x2d\x16\x65\x58\xce\x94\x14\x19\xb4\x7d\x3d\x3b\xcc\x28\x43\x41\x66\x9d\xbb\xd6\x10\x81\xd1\xda\xc2\xb3\xb4\x19\xe4\x84\x1b\x62\x78\x32\xba\x68\xe8\xb8\x27\xb7\x7a\xaf\xe3\xcd\xdf\xef\x5a\xdb\x11\x57\x83\x96\x37\xfc\x23\x23\x63\xb7\xfc\x4e\xba\xd0\x8a\x49\x71\xc5\x90\xa0\xc5\xb1\xdc\x1e\xe1\x6f\x80\x7f\x85\x87\x0e\xab\xdc\xca\xee\x9b\xe1\x28\x84\x56\xba\x65\x55\x3a\x21\x86\xe7\x4c\x83\x40\xd5\x17\x0e\x14\x6d\x6d\x78\x88\x5b\x4f\xb5\x5e\xaf\x89\xca\xc1\x45\x1e\x4a\xdd\x67\xed\x19\xf7\x6a\xd6\xb3\x0d\xaf\x67\x40\xc8\x32\x55\xd8\xdd\xc7\x8f\xb1\x8f\x3d\x08\xdd\xb3\x31\xc5\x1c\x95\xe8\xbe\x4d\x5a\xd9\xb7\xa2\xf9\x40\x3a\x0c\xbc\xf7\xfc\x7c\xab\xc8\xd4\x2d\x4b\x4a\xb6\x79\x22\xa6\xf5\xac\x41\x1f\xee\x24\x7d\x8a\xfd\x13\xb2\x0b\x46\xce\x0e\x83\x2b\x34\x0a\xf3\xa0\x3d\xbe\xb7\xf7\x3a\x25\xbb\xdc\xee\xd0\x04\x8f\x46\x4f\xe6\x7d\x1f\xba\x41\x78\xeb\xa0\x3b\xd2\x6f\x34\xce\x58\x64\x54\x60\xeb\xea\xaf\x73\x18\x7b\xa3\xd1\x6b\xf4\x2f\x00\x00\xff\xff\x28\x4d\x93\xb3\x8d\x08\x00\x00")
+var _schemaGraphql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xa5\x58\x6d\x6f\xdb\x36\x10\xfe\xee\x5f\x41\xd7\x5f\x52\x20\x28\xb6\xae\x2d\x06\x7f\x6b\xd2\x60\xed\xda\x26\xee\xe2\x76\x18\x8a\x60\xa0\xa5\xb3\xcd\x45\x12\x35\x92\x8a\x2d\x04\xfb\xef\xbb\xe3\x9b\x49\x4b\x2e\x56\xac\x5f\x6a\x51\x77\x0f\xef\xf5\xb9\x53\x66\x6c\xc5\x35\xbc\xfc\x99\x49\xc5\xb6\xb0\x67\xda\x28\xd1\x6c\x18\x2f\x4b\x05\x5a\x4f\x74\xc1\x2b\xae\xd8\x6b\xff\x38\x4b\x65\xe4\x9a\x6d\xb9\xde\x3e\x7f\xf9\x2a\x88\xbd\xa5\xdf\xc7\x32\x6d\xb7\xaa\x44\xc1\xee\xa1\x0f\x62\x8b\x6e\xf5\x1e\x9f\xc2\xe3\x67\xd1\x98\x9f\x9e\xa3\x5e\x87\x3f\x5e\xbd\x60\xd0\x14\xb2\x84\x92\x71\xed\x51\x52\xc1\x57\x2f\x50\x90\xab\x95\x30\x8a\xab\x9e\xfd\xa5\x65\xc3\x1e\x78\xd5\x41\x10\xfa\xf5\xf6\xe6\x7a\x32\x81\xa6\xab\xd9\x72\xbf\xec\x5b\x60\x8f\x13\x86\xff\xde\x5d\x7f\xb9\x79\x7f\xf5\xe7\xf5\xd5\x4d\xfa\xf8\xfb\xeb\xdb\x8f\xf6\xf9\xcd\xd5\xe2\xc3\xcd\x1f\xf1\xb5\x7f\xb4\xaf\xff\x99\x4c\xf0\x62\x50\x6b\x5e\x00\x5b\xf0\xbe\x92\xbc\xf4\xa0\x64\x28\x9b\xb3\x5b\x6b\xe6\x94\x24\x0d\xdd\xf8\xae\x79\x90\xf7\x70\x49\x2f\x45\xdd\x56\x50\x43\x63\xf4\x88\xea\x50\xf3\x0d\xb4\x95\xec\xbf\x47\x93\x4e\x1e\x6a\x72\x34\x3f\x6b\x78\x7d\x2c\x05\x4a\x0b\xd9\xe4\x87\xbc\x33\x5b\xa9\xf2\x33\xa8\xb9\xa8\xf2\xa3\x12\x74\x91\x59\x3b\x63\x98\x80\x46\xf3\xc2\x20\x24\xe5\xa9\x2b\x4c\xa7\x80\x12\x2e\x1b\x23\x2b\xb9\xe9\x9d\x47\xcb\x44\xec\x31\xb7\xc3\x25\xde\x5d\x40\x95\x34\xb7\x05\xe4\xcd\x97\x4d\x01\xb9\x88\xd9\x3b\x2f\x5d\x5a\xdd\xd9\x86\xeb\x85\x12\xc7\x92\x78\xfa\x41\xd4\xc2\xe4\xa7\x2d\xef\x01\x3d\xf5\xb5\x1c\xcf\x28\xb2\xf3\x10\x62\x77\xaa\xc5\x46\xcf\xd9\xd7\x5b\xb1\x99\xde\x4d\x27\xce\x3e\x10\x9b\x6d\x02\x18\x12\x86\x32\xde\x2d\x54\x7a\xc3\x0d\x27\x3d\x17\xa6\x3b\x7f\x85\xad\x76\xc2\x73\x75\x1f\xce\x3f\x66\x60\x33\x76\x51\xc9\xe2\xfe\x5b\x91\x74\x02\xee\xb2\x19\x5b\x6e\x01\x8d\xe2\x25\x28\x92\x34\x5b\xa1\xd9\x8a\x04\x9e\x79\x73\xe9\x0d\x06\xd4\xfe\xef\x7d\x70\x4a\x49\xde\x74\xa2\xc7\x44\x53\x54\x1d\xf6\x9d\x03\x48\xa5\xd0\xf4\x24\x8b\x64\xbf\x35\xd8\x61\x33\x41\x28\xa9\x2d\xdc\x01\x3a\xa3\xbd\x50\x6a\xb5\xcf\x7f\x34\xdb\x69\x3e\x1b\xaf\x8d\xd4\x5b\x2c\x91\x51\xa5\xb4\x76\x12\xf9\x56\xc1\x83\x90\x5d\xf0\x8f\xa4\x9c\x3c\xbd\x78\x3b\xae\x53\x74\x4a\x61\xcb\x05\x15\x9b\xf4\x67\xa3\x05\x90\x46\x54\xd4\xa0\x0d\xaf\xdb\x34\x9c\x1b\x68\x40\x71\x13\xe3\x19\x64\x46\x11\x6a\x50\xf7\x15\xa5\x06\x80\x29\x29\x0d\xdb\x09\xb3\x65\x15\xf0\x07\xd0\x6c\xad\x64\x6d\xe1\x74\x04\x37\x72\x90\x71\xfb\xf3\x37\xd4\x1d\xf1\x2a\x4b\xb9\xc5\x1f\x29\x19\xb3\xd7\x27\xd4\x0b\x54\x83\x46\x63\x24\x4b\x2c\xf0\x31\xdd\x28\xe1\x3a\xc0\x11\x75\xee\x61\x57\x19\x11\xc6\x0a\x41\xa0\x8a\xf4\xa8\x0d\x12\x9a\x66\xbb\xad\x64\x05\x6f\x62\xe0\x58\x03\x7b\x93\x5e\x42\xcf\x17\x52\xde\xdf\x03\xb4\x59\x23\xe7\xa6\x0e\x51\x23\xe2\x20\x66\x11\x2d\x6f\xcf\x04\x10\xbb\xba\xe1\xa1\x1f\xbf\x0f\x7d\x8c\x10\x02\x6d\x5c\xe0\xac\x42\x82\xf3\x7d\x81\x7d\x7e\x08\x9a\x3b\xd8\xe4\x07\xa7\xe8\xe7\x06\xda\x17\x39\x16\x85\xf8\x88\xe4\x56\x4e\x60\x38\x6d\x12\x6d\xed\xd5\xbd\x2c\xc5\x23\x79\x1b\x38\x6b\xc4\x8c\x19\xce\x2c\x3b\xaf\x17\x5c\xf1\x9a\xd8\x80\xb3\xb5\x80\xaa\x74\x75\x02\x38\x3a\xdc\x38\x87\x07\x6c\xac\x30\xe5\x12\x8d\xc7\x13\xe3\xca\x0c\x46\x9a\x9d\xf3\x73\x3b\xe1\xf3\xab\xaf\x08\x3a\x10\x11\xe9\xf9\xdb\xc8\x84\x1d\xd7\x35\x25\x0e\x7b\xa0\x30\xe7\xb4\xe6\x90\x50\x23\x8d\x58\x8b\x82\xdb\xb1\x14\x2c\x5c\xf5\xf6\x5d\x10\x66\x35\x6f\xc4\x1a\xbb\x36\x33\xda\xdd\x75\xca\xe8\x96\x5c\xa2\xe0\xa5\x2e\x46\xc6\xbc\xa6\x5b\xfb\x68\x2d\xcf\xcd\x00\x9c\x58\xc6\x99\xc1\x0f\x46\x94\x9d\x5d\xa4\x60\x0f\x45\x47\x62\xce\x98\x14\xe9\x31\xaf\x7f\xa7\x16\xc0\x6c\x51\xa6\xd7\xc4\x86\xb5\x72\xbe\x4e\xc6\x9b\x29\xb3\xce\x4f\xca\xf3\x6c\xbb\x53\xd8\x94\x9a\xae\xa9\x84\xb6\xd1\x3e\xbc\xd4\xbe\x09\x0c\xb6\x86\x0e\x49\x4b\xc0\xb3\xb2\x38\x67\x4d\x57\x55\x4c\xac\x87\xc9\x21\x4a\xc0\x03\xb6\x8a\x2a\x0e\xd8\xea\xcd\xb3\xac\xb8\x20\x5f\xd9\x48\x81\x8b\x50\x28\x8a\x18\x3e\x86\x5e\x22\x15\xb9\x39\x95\xf0\xa2\x0b\x6b\xae\xfb\xe8\x79\x71\x64\x56\xfc\xc8\xd6\xe8\xbc\xee\x0a\x6c\x15\x7d\xce\x7e\xb0\x8f\x6b\x5c\x9b\x90\x29\x12\xc7\x07\x6b\xc9\x25\x12\x65\x57\x43\x99\x77\xb7\x75\xb8\xc7\xaa\x49\xd2\x9a\xb2\xc5\x47\x3b\x1e\x16\xc8\xdc\xeb\x2c\xdb\x27\x78\x1d\xfc\x94\xd8\x6d\x45\xb1\x8d\x73\xdd\x35\xc7\x41\x63\x38\xe7\x4f\xd0\xbf\x6b\xfa\xef\x87\xb6\x92\x6f\x4f\x8f\x4d\x87\x14\xcc\xce\x26\xaf\x2f\xd2\x4e\x5d\x7c\x63\xaa\x9d\x9e\xd5\x41\xf1\x1b\x97\xf3\xae\x14\x06\x8b\x1a\xc7\xac\x9d\xaf\x07\xd7\xec\x6c\x3d\x46\x27\x2b\x7d\xc0\xb8\xda\x80\xa1\x92\xb0\x24\x69\xad\xca\x52\x55\x2f\xa4\xac\x96\xfb\x4b\xd9\x0d\x7a\xb3\x73\x94\x84\x4b\x0e\xd6\x37\x85\x2d\xcd\x9f\x68\xec\xbd\x2d\x6a\xc7\x5d\xc8\x8a\x8d\xda\x1f\xc1\x32\x8c\x1d\x17\x86\x1a\x93\xaa\xd1\xa9\xa7\x4d\xdf\x42\x53\x8a\x30\x59\x22\x7d\x2f\xf7\x5f\x48\xb2\xbf\xa5\x82\x0d\xfd\x92\x2a\xbb\x52\x76\x1d\x43\xd6\xe5\x86\xbb\xf0\xc9\x06\x88\x9e\x05\x2e\x08\x52\xf9\xc5\x3f\xc3\x7d\x1c\x9d\x1f\x07\x9e\x4f\x4f\x40\xa9\x4b\xfb\x8d\x73\x3c\xed\x3e\x75\xa0\x42\x5b\x62\x12\x6c\x8e\x2f\x7a\x97\xe5\xb3\x23\xec\xa7\x73\xb7\x30\x1f\x0b\x63\xe2\xce\x92\x85\x71\x54\xcc\x09\x0d\xf0\x0e\x5f\x27\x28\xb8\xdc\x1f\xc1\x24\x2b\x72\x04\x73\xd3\xf3\x2c\x9f\xc9\x74\xa5\x1f\xab\x21\xa3\x96\xcc\xf4\x80\x94\x0e\xb4\x48\x0c\xb8\xc6\x94\x7b\xf2\x43\x6c\x4b\x12\xfa\xa2\x1f\xd8\x91\x71\xd8\x08\xbe\x2d\x68\x1c\x84\x2d\xdd\xc0\xab\xaa\xc7\xe4\x55\xfd\x80\x78\xad\xf4\x46\xa0\x62\x1c\x16\x83\xbb\xc7\x23\x7f\x1e\x15\xa2\xcf\x68\xd6\xd7\xcc\xae\xb8\x65\x21\xda\xa5\x97\x1e\x86\xe9\xf0\x35\xec\xfd\xd0\x58\x5d\x7c\x03\x6e\x13\xa0\x8e\x39\xcc\x9b\xb1\x58\xd1\x1f\x1d\x98\xfd\x58\x08\x52\xd1\x85\x5b\x87\x74\x36\x30\x15\xcd\x47\xad\x38\xd5\x9f\x86\x5f\x41\x31\xd9\x8a\x46\x95\xc9\x09\x22\x86\x70\x72\x47\x10\xe9\xa2\x35\xf5\xbe\x70\xad\xc1\xae\x01\x4f\x10\xe5\x09\xcd\x52\xfc\xb1\x79\x12\x2d\x7c\x5d\x55\x72\xe7\xca\x87\x24\xa3\x45\xe7\xb6\xe3\xd2\x2b\x8d\xcc\xa2\x96\x4e\x16\xc4\xf9\xdc\xac\x28\x18\x37\xcd\x66\x18\xe0\x23\xd1\x5f\xb0\xf4\xcc\x7f\x11\x4c\x06\xd2\x51\xf5\x25\x6f\x26\x07\xe9\x94\x13\xe7\x47\x1c\x39\x1d\x88\x51\xfb\x7d\xc0\x85\x22\xa1\x57\x17\xb2\x21\x2b\xe9\x53\xb4\x94\x97\x03\x56\xca\x81\x57\xd3\xab\x2c\x39\x1d\xb9\xf0\x35\x63\xae\xe9\xdd\xe1\x5b\xbf\x5b\xe9\x42\x89\x36\xf9\x5b\xc6\x0c\x3f\xef\x71\x12\x94\x8c\xaf\x0d\x7e\xe6\xe2\x46\x07\xbb\xf0\x3d\xad\x99\xc6\xef\xb7\x32\x7c\xbc\xec\x2c\xb5\x78\xba\x99\x8e\x6b\xa7\xbc\x8a\xfa\x6e\x6b\x81\xf2\x7f\x74\x6b\x78\xb2\x2d\x7b\x36\xd6\x98\x79\x5f\x92\xb3\xba\xd8\x42\xcd\xbd\x87\x7f\x13\xe7\xce\x1d\xf5\xba\xc5\x26\x89\xc2\x3c\x8b\x09\xea\xfe\x0b\xed\x8b\x6e\xbc\x4f\x14\x00\x00")
 
 func schemaGraphqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -111,7 +111,7 @@ func schemaGraphql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema.graphql", size: 2189, mode: os.FileMode(438), modTime: time.Unix(1598341445, 0)}
+	info := bindataFileInfo{name: "schema.graphql", size: 5199, mode: os.FileMode(438), modTime: time.Unix(1792206896, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
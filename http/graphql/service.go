@@ -19,26 +19,35 @@
 package graphql
 
 import (
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"strconv"
 
+	"github.com/gorilla/websocket"
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/manifest"
 	"github.com/ontio/ontology/core/payload"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/events/message"
 	"github.com/ontio/ontology/http/base/actor"
 	comm "github.com/ontio/ontology/http/base/common"
 	"github.com/ontio/ontology/http/graphql/schema"
+	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 	"golang.org/x/net/netutil"
 )
 
 var ontSchema *graphql.Schema
 
+var errEventLogDisabled = errors.New("event log is disabled")
+
 func init() {
 	resolver := &resolver{}
 	s, err := schema.Asset("schema.graphql")
@@ -306,17 +315,328 @@ func (self *resolver) GetBalance(args struct{ Addr Addr }) (*balance, error) {
 	}, nil
 }
 
+type decodedParam struct {
+	Name  string
+	Type  string
+	Value JSON
+}
+
+type decodedEvent struct {
+	Name   string
+	Params []*decodedParam
+}
+
+func newDecodedEvent(evt *manifest.DecodedEvent) *decodedEvent {
+	if evt == nil {
+		return nil
+	}
+	params := make([]*decodedParam, 0, len(evt.Params))
+	for _, p := range evt.Params {
+		params = append(params, &decodedParam{Name: p.Name, Type: p.Type, Value: JSON{p.Value}})
+	}
+	return &decodedEvent{Name: evt.Name, Params: params}
+}
+
+type notifyEvent struct {
+	ContractAddress Addr
+	States          JSON
+	Event           *decodedEvent
+}
+
+type executeNotify struct {
+	TxHash      H256
+	State       Uint32
+	GasConsumed Uint64
+	Notify      []*notifyEvent
+}
+
+// NewExecuteNotify converts the execute notify, only notifications of contract are kept if it is not nil.
+// It returns nil when none of the notifications match the contract.
+func NewExecuteNotify(evt *event.ExecuteNotify, contract *common.Address) *executeNotify {
+	notifies := make([]*notifyEvent, 0, len(evt.Notify))
+	decoder := comm.NewNotifyDecoder()
+	for _, n := range evt.Notify {
+		if contract != nil && n.ContractAddress != *contract {
+			continue
+		}
+		notifies = append(notifies, &notifyEvent{
+			ContractAddress: Addr{n.ContractAddress},
+			States:          JSON{n.States},
+			Event:           newDecodedEvent(decoder.Decode(n)),
+		})
+	}
+	if contract != nil && len(notifies) == 0 {
+		return nil
+	}
+
+	return &executeNotify{
+		TxHash:      H256(evt.TxHash),
+		State:       Uint32(evt.State),
+		GasConsumed: Uint64(evt.GasConsumed),
+		Notify:      notifies,
+	}
+}
+
+func (self *resolver) GetEventsByTx(args struct{ Hash H256 }) (*executeNotify, error) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return nil, errEventLogDisabled
+	}
+	evt, err := actor.GetEventNotifyByTxHash(common.Uint256(args.Hash))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return NewExecuteNotify(evt, nil), nil
+}
+
+func (self *resolver) GetEventsByHeight(args struct {
+	Height   Uint32
+	Contract *Addr
+}) ([]*executeNotify, error) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return nil, errEventLogDisabled
+	}
+	evts, err := actor.GetEventNotifyByHeight(uint32(args.Height))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return []*executeNotify{}, nil
+		}
+		return nil, err
+	}
+	var contract *common.Address
+	if args.Contract != nil {
+		contract = &args.Contract.Address
+	}
+	result := make([]*executeNotify, 0, len(evts))
+	for _, evt := range evts {
+		if notify := NewExecuteNotify(evt, contract); notify != nil {
+			result = append(result, notify)
+		}
+	}
+
+	return result, nil
+}
+
+func (self *resolver) GetContract(args struct{ Addr Addr }) (*deployCodePayload, error) {
+	contract, err := actor.GetContractStateFromStore(args.Addr.Address)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, nil
+	}
+
+	return NewTxPayload(contract).pl.(*deployCodePayload), nil
+}
+
+func (self *resolver) GetStorage(args struct {
+	Contract Addr
+	Key      string
+}) (*string, error) {
+	key, err := common.HexToBytes(args.Key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := actor.GetStorageItem(args.Contract.Address, key)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	val := common.ToHexString(value)
+
+	return &val, nil
+}
+
+type oep4Balance struct {
+	Addr    Addr
+	Balance string
+}
+
+type oep4Balances struct {
+	Balances []*oep4Balance
+	Height   Uint32
+}
+
+func (self *resolver) GetOep4Balance(args struct {
+	Contract Addr
+	Addrs    []Addr
+}) (*oep4Balances, error) {
+	addrs := make([]common.Address, 0, len(args.Addrs))
+	for _, addr := range args.Addrs {
+		addrs = append(addrs, addr.Address)
+	}
+	balances, height, err := comm.GetOep4ContractBalance(args.Contract.Address, addrs, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &oep4Balances{Height: Uint32(height)}
+	for i, addr := range args.Addrs {
+		result.Balances = append(result.Balances, &oep4Balance{Addr: addr, Balance: balances[i]})
+	}
+
+	return result, nil
+}
+
+func (self *resolver) GetAllowance(args struct {
+	Asset string
+	From  Addr
+	To    Addr
+}) (Uint64, error) {
+	allowance, err := comm.GetAllowance(args.Asset, args.From.Address, args.To.Address)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseUint64(allowance)
+}
+
+func (self *resolver) GetUnboundOng(args struct{ Addr Addr }) (Uint64, error) {
+	unbound, err := comm.GetAllowance("ong", utils.OntContractAddress, args.Addr.Address)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseUint64(unbound)
+}
+
+func (self *resolver) GetGrantOng(args struct{ Addr Addr }) (Uint64, error) {
+	grant, err := comm.GetGrantOng(args.Addr.Address)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseUint64(grant)
+}
+
+func parseUint64(val string) (Uint64, error) {
+	v, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return Uint64(v), nil
+}
+
+type merkleProof struct {
+	TransactionsRoot H256
+	BlockHeight      Uint32
+	CurBlockRoot     H256
+	CurBlockHeight   Uint32
+	TargetHashes     []H256
+}
+
+func (self *resolver) GetMerkleProof(args struct{ Hash H256 }) (*merkleProof, error) {
+	height, _, err := actor.GetTxnWithHeightByTxHash(common.Uint256(args.Hash))
+	if err != nil {
+		return nil, err
+	}
+	header, err := actor.GetHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	curHeight := actor.GetCurrentBlockHeight()
+	curHeader, err := actor.GetHeaderByHeight(curHeight)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := actor.GetMerkleProof(height, curHeight)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]H256, 0, len(proof))
+	for _, hash := range proof {
+		hashes = append(hashes, H256(hash))
+	}
+
+	return &merkleProof{
+		TransactionsRoot: H256(header.TransactionsRoot),
+		BlockHeight:      Uint32(height),
+		CurBlockRoot:     H256(curHeader.BlockRoot),
+		CurBlockHeight:   Uint32(curHeight),
+		TargetHashes:     hashes,
+	}, nil
+}
+
+type memPoolTxCount struct {
+	Verified Uint32
+	Pending  Uint32
+}
+
+func (self *resolver) GetMemPoolTxCount() (*memPoolTxCount, error) {
+	count, err := actor.GetTxnCount()
+	if err != nil {
+		return nil, err
+	}
+	if len(count) < 2 {
+		return nil, fmt.Errorf("invalid txn count response")
+	}
+
+	return &memPoolTxCount{Verified: Uint32(count[0]), Pending: Uint32(count[1])}, nil
+}
+
+func (self *resolver) GetMemPoolTxHashList() ([]H256, error) {
+	hashes, err := actor.GetTxnHashList()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]H256, 0, len(hashes))
+	for _, hash := range hashes {
+		result = append(result, H256(hash))
+	}
+
+	return result, nil
+}
+
+type txVerifyState struct {
+	Height  Uint32
+	Type    Uint32
+	ErrCode Uint32
+}
+
+func (self *resolver) GetMemPoolTxState(args struct{ Hash H256 }) (*[]*txVerifyState, error) {
+	entry, err := actor.GetTxFromPool(common.Uint256(args.Hash))
+	if err != nil {
+		if err == actor.ErrTxnNotInPool {
+			return nil, nil
+		}
+		return nil, err
+	}
+	states := make([]*txVerifyState, 0, len(entry.Attrs))
+	for _, attr := range entry.Attrs {
+		states = append(states, &txVerifyState{
+			Height:  Uint32(attr.Height),
+			Type:    Uint32(attr.Type),
+			ErrCode: Uint32(attr.ErrCode),
+		})
+	}
+
+	return &states, nil
+}
+
 func StartServer(cfg *config.GraphQLConfig) {
 	if !cfg.EnableGraphQL || cfg.GraphQLPort == 0 {
 		return
 	}
+	actor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, hub.publish)
+	actor.SubscribeEvent(message.TOPIC_SMART_CODE_EVENT, hub.publish)
 
 	serverMut := http.NewServeMux()
 	serverMut.HandleFunc("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write(page)
 	}))
 
-	serverMut.Handle("/query", &relay.Handler{Schema: ontSchema})
+	queryHandler := &relay.Handler{Schema: ontSchema}
+	serverMut.Handle("/query", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			serveSubscription(w, r)
+			return
+		}
+		queryHandler.ServeHTTP(w, r)
+	}))
 
 	server := &http.Server{Handler: serverMut}
 	listener, err := net.Listen("tcp", ":"+strconv.Itoa(int(cfg.GraphQLPort)))
@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/types"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/smartcontract/event"
+	tcomn "github.com/ontio/ontology/txnpool/common"
+	vt "github.com/ontio/ontology/validator/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDataDir = "./test_graphql"
+
+var (
+	pooledTxHash = common.Uint256{1}
+	failedTxHash = common.Uint256{2}
+)
+
+func TestMain(m *testing.M) {
+	log.InitLog(log.InfoLog, log.Stdout)
+	var err error
+	ledger.DefLedger, err = ledger.NewLedger(testDataDir, 0)
+	if err != nil {
+		panic(err)
+	}
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		panic(err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		panic(err)
+	}
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		panic(err)
+	}
+	bactor.SetTxPid(actor.Spawn(actor.FromFunc(testTxPoolReceive)))
+
+	code := m.Run()
+
+	ledger.DefLedger.Close()
+	os.RemoveAll(testDataDir)
+	os.Exit(code)
+}
+
+//testTxPoolReceive serves pooledTxHash, and fails the request of failedTxHash
+func testTxPoolReceive(ctx actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case *tcomn.GetTxnReq:
+		switch msg.Hash {
+		case pooledTxHash:
+			ctx.Sender().Request(&tcomn.GetTxnRsp{Txn: &types.Transaction{}}, ctx.Self())
+		case failedTxHash:
+			ctx.Sender().Request(&tcomn.GetTxnStatusRsp{}, ctx.Self())
+		default:
+			ctx.Sender().Request(&tcomn.GetTxnRsp{}, ctx.Self())
+		}
+	case *tcomn.GetTxnStatusReq:
+		attrs := []*tcomn.TXAttr{{Height: 10, Type: vt.Stateful}}
+		ctx.Sender().Request(&tcomn.GetTxnStatusRsp{Hash: msg.Hash, TxStatus: attrs}, ctx.Self())
+	}
+}
+
+func execQuery(t *testing.T, query string) (map[string]interface{}, []string) {
+	resp := ontSchema.Exec(context.Background(), query, "", nil)
+	var errs []string
+	for _, err := range resp.Errors {
+		errs = append(errs, err.Message)
+	}
+	data := make(map[string]interface{})
+	if resp.Data != nil {
+		assert.Nil(t, json.Unmarshal(resp.Data, &data))
+	}
+	return data, errs
+}
+
+func TestQueryBlock(t *testing.T) {
+	genesisBlock, err := ledger.DefLedger.GetBlockByHeight(0)
+	assert.Nil(t, err)
+	hash := genesisBlock.Hash()
+
+	data, errs := execQuery(t, `{getBlockByHeight(height: 0) {header {height hash} transactions {hash}}}`)
+	assert.Nil(t, errs)
+	blk := data["getBlockByHeight"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"height": float64(0), "hash": hash.ToHexString()}, blk["header"])
+	assert.Len(t, blk["transactions"], len(genesisBlock.Transactions))
+
+	data, errs = execQuery(t, fmt.Sprintf(`{getBlockByHash(hash: "%s") {header {height}}}`, hash.ToHexString()))
+	assert.Nil(t, errs)
+	assert.Equal(t, map[string]interface{}{"header": map[string]interface{}{"height": float64(0)}},
+		data["getBlockByHash"])
+
+	data, errs = execQuery(t, `{getBlockHash(height: 0)}`)
+	assert.Nil(t, errs)
+	assert.Equal(t, hash.ToHexString(), data["getBlockHash"])
+
+	txHash := genesisBlock.Transactions[0].Hash()
+	data, errs = execQuery(t, fmt.Sprintf(`{getTx(hash: "%s") {hash height}}`, txHash.ToHexString()))
+	assert.Nil(t, errs)
+	assert.Equal(t, map[string]interface{}{"hash": txHash.ToHexString(), "height": float64(0)}, data["getTx"])
+
+	_, errs = execQuery(t, `{getBlockByHeight(height: 100) {header {height}}}`)
+	assert.NotNil(t, errs)
+}
+
+func TestQueryBalance(t *testing.T) {
+	addr := common.AddressFromVmCode([]byte("graphql"))
+	data, errs := execQuery(t, fmt.Sprintf(`{getBalance(addr: "%s") {ont ong height}}`, addr.ToBase58()))
+	assert.Nil(t, errs)
+	assert.Equal(t, map[string]interface{}{"ont": "0", "ong": "0", "height": float64(0)}, data["getBalance"])
+}
+
+func TestGetMemPoolTxState(t *testing.T) {
+	query := `{getMemPoolTxState(hash: "%s") {height type errCode}}`
+	data, errs := execQuery(t, fmt.Sprintf(query, pooledTxHash.ToHexString()))
+	assert.Nil(t, errs)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"height": float64(10), "type": float64(vt.Stateful), "errCode": float64(0)},
+	}, data["getMemPoolTxState"])
+
+	//not found is null without error
+	data, errs = execQuery(t, fmt.Sprintf(query, common.UINT256_EMPTY.ToHexString()))
+	assert.Nil(t, errs)
+	assert.Nil(t, data["getMemPoolTxState"])
+
+	_, errs = execQuery(t, fmt.Sprintf(query, failedTxHash.ToHexString()))
+	assert.NotNil(t, errs)
+}
+
+func subscribe(t *testing.T, ctx context.Context, query string) <-chan interface{} {
+	hub.RLock()
+	count := len(hub.subscribers)
+	hub.RUnlock()
+	responses, err := ontSchema.Subscribe(ctx, query, "", nil)
+	assert.Nil(t, err)
+	//wait for the resolver to subscribe the hub, or the published events are missed
+	for i := 0; i < 100; i++ {
+		hub.RLock()
+		subscribed := len(hub.subscribers) > count
+		hub.RUnlock()
+		if subscribed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return responses
+}
+
+func receive(t *testing.T, responses <-chan interface{}) map[string]interface{} {
+	select {
+	case resp := <-responses:
+		buf, err := json.Marshal(resp)
+		assert.Nil(t, err)
+		result := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(buf, &result))
+		assert.Nil(t, result["errors"])
+		return result["data"].(map[string]interface{})
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription response timeout")
+	}
+	return nil
+}
+
+func TestSubscribeNewBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	responses := subscribe(t, ctx, `subscription {newBlock {header {height}}}`)
+
+	genesisBlock, err := ledger.DefLedger.GetBlockByHeight(0)
+	assert.Nil(t, err)
+	hub.publish(types.SmartCodeEvent{})
+	hub.publish(*genesisBlock)
+	data := receive(t, responses)
+	assert.Equal(t, map[string]interface{}{"header": map[string]interface{}{"height": float64(0)}}, data["newBlock"])
+}
+
+func TestSubscribeContractEvent(t *testing.T) {
+	contract1 := common.AddressFromVmCode([]byte("contract1"))
+	contract2 := common.AddressFromVmCode([]byte("contract2"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	query := fmt.Sprintf(`subscription {contractEvent(contract: "%s") {txHash notify {contractAddress states}}}`,
+		contract2.ToBase58())
+	responses := subscribe(t, ctx, query)
+
+	txHash := common.Uint256{2}
+	hub.publish(types.SmartCodeEvent{Result: &event.ExecuteNotify{
+		TxHash: common.Uint256{1},
+		Notify: []*event.NotifyEventInfo{{ContractAddress: contract1, States: "skipped"}},
+	}})
+	hub.publish(types.SmartCodeEvent{Result: &event.ExecuteNotify{
+		TxHash: txHash,
+		Notify: []*event.NotifyEventInfo{
+			{ContractAddress: contract1, States: "filtered"},
+			{ContractAddress: contract2, States: []interface{}{"transfer"}},
+		},
+	}})
+	data := receive(t, responses)
+	assert.Equal(t, map[string]interface{}{
+		"txHash": txHash.ToHexString(),
+		"notify": []interface{}{
+			map[string]interface{}{"contractAddress": contract2.ToBase58(), "states": []interface{}{"transfer"}},
+		},
+	}, data["contractEvent"])
+
+	cancel()
+	select {
+	case _, ok := <-responses:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription is not closed after cancel")
+	}
+}
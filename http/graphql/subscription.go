@@ -0,0 +1,269 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/event"
+)
+
+// message types of the graphql-ws protocol
+const (
+	GQL_CONNECTION_INIT      = "connection_init"
+	GQL_CONNECTION_ACK       = "connection_ack"
+	GQL_CONNECTION_ERROR     = "connection_error"
+	GQL_CONNECTION_TERMINATE = "connection_terminate"
+	GQL_START                = "start"
+	GQL_DATA                 = "data"
+	GQL_ERROR                = "error"
+	GQL_COMPLETE             = "complete"
+	GQL_STOP                 = "stop"
+)
+
+// buffered notifications per subscriber, new ones are dropped when the buffer is full
+const SUBSCRIBER_BUFFER_SIZE = 64
+
+var hub = newEventHub()
+
+// eventHub fans out ledger events received from the actor subscriber to subscription resolvers
+type eventHub struct {
+	sync.RWMutex
+	nextId      uint64
+	subscribers map[uint64]chan interface{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[uint64]chan interface{})}
+}
+
+func (self *eventHub) subscribe() (uint64, <-chan interface{}) {
+	self.Lock()
+	defer self.Unlock()
+	self.nextId += 1
+	ch := make(chan interface{}, SUBSCRIBER_BUFFER_SIZE)
+	self.subscribers[self.nextId] = ch
+	return self.nextId, ch
+}
+
+func (self *eventHub) unsubscribe(id uint64) {
+	self.Lock()
+	defer self.Unlock()
+	delete(self.subscribers, id)
+}
+
+func (self *eventHub) publish(v interface{}) {
+	self.RLock()
+	defer self.RUnlock()
+	for id, ch := range self.subscribers {
+		select {
+		case ch <- v:
+		default:
+			log.Warnf("graphql subscriber %d is too slow, drop event", id)
+		}
+	}
+}
+
+func (self *resolver) NewBlock(ctx context.Context) <-chan *block {
+	out := make(chan *block)
+	id, in := hub.subscribe()
+	go func() {
+		defer close(out)
+		defer hub.unsubscribe(id)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v := <-in:
+				blk, ok := v.(types.Block)
+				if !ok {
+					continue
+				}
+				select {
+				case out <- NewBlock(&blk):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (self *resolver) ContractEvent(ctx context.Context, args struct{ Contract *Addr }) <-chan *executeNotify {
+	var contract *common.Address
+	if args.Contract != nil {
+		contract = &args.Contract.Address
+	}
+	out := make(chan *executeNotify)
+	id, in := hub.subscribe()
+	go func() {
+		defer close(out)
+		defer hub.unsubscribe(id)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v := <-in:
+				evt, ok := v.(types.SmartCodeEvent)
+				if !ok {
+					continue
+				}
+				notify, ok := evt.Result.(*event.ExecuteNotify)
+				if !ok {
+					continue
+				}
+				result := NewExecuteNotify(notify, contract)
+				if result == nil {
+					continue
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+type wsMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type wsStartPayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{"graphql-ws"},
+}
+
+// wsConn is a graphql-ws connection, each started operation runs until stopped or the connection closes
+type wsConn struct {
+	sync.Mutex
+	conn    *websocket.Conn
+	ctx     context.Context
+	cancels map[string]context.CancelFunc
+}
+
+func serveSubscription(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Errorf("graphql websocket upgrade error: %s", err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &wsConn{conn: conn, ctx: ctx, cancels: make(map[string]context.CancelFunc)}
+	defer conn.Close()
+
+	for {
+		msg := &wsMessage{}
+		if err := conn.ReadJSON(msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case GQL_CONNECTION_INIT:
+			c.send(&wsMessage{Type: GQL_CONNECTION_ACK})
+		case GQL_START:
+			c.start(msg)
+		case GQL_STOP:
+			c.stop(msg.Id)
+		case GQL_CONNECTION_TERMINATE:
+			return
+		default:
+			c.sendError(msg.Id, GQL_CONNECTION_ERROR, "unknown message type: "+msg.Type)
+		}
+	}
+}
+
+func (self *wsConn) send(msg *wsMessage) {
+	self.Lock()
+	defer self.Unlock()
+	if err := self.conn.WriteJSON(msg); err != nil {
+		log.Debugf("graphql websocket write error: %s", err)
+	}
+}
+
+func (self *wsConn) sendError(id string, ty string, desc string) {
+	payload, _ := json.Marshal(map[string]string{"message": desc})
+	self.send(&wsMessage{Id: id, Type: ty, Payload: payload})
+}
+
+func (self *wsConn) start(msg *wsMessage) {
+	param := &wsStartPayload{}
+	if err := json.Unmarshal(msg.Payload, param); err != nil {
+		self.sendError(msg.Id, GQL_ERROR, err.Error())
+		return
+	}
+	ctx, cancel := context.WithCancel(self.ctx)
+	self.Lock()
+	if _, ok := self.cancels[msg.Id]; ok {
+		self.Unlock()
+		cancel()
+		self.sendError(msg.Id, GQL_ERROR, "duplicated operation id")
+		return
+	}
+	self.cancels[msg.Id] = cancel
+	self.Unlock()
+
+	responses, err := ontSchema.Subscribe(ctx, param.Query, param.OperationName, param.Variables)
+	if err != nil {
+		self.stop(msg.Id)
+		self.sendError(msg.Id, GQL_ERROR, err.Error())
+		return
+	}
+	go func() {
+		for resp := range responses {
+			payload, err := json.Marshal(resp)
+			if err != nil {
+				log.Errorf("graphql marshal subscription response error: %s", err)
+				continue
+			}
+			self.send(&wsMessage{Id: msg.Id, Type: GQL_DATA, Payload: payload})
+		}
+		self.stop(msg.Id)
+		self.send(&wsMessage{Id: msg.Id, Type: GQL_COMPLETE})
+	}()
+}
+
+func (self *wsConn) stop(id string) {
+	self.Lock()
+	defer self.Unlock()
+	if cancel, ok := self.cancels[id]; ok {
+		cancel()
+		delete(self.cancels, id)
+	}
+}
@@ -20,6 +20,8 @@ package jsonrpc
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"math"
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
@@ -33,6 +35,8 @@ import (
 	berr "github.com/ontio/ontology/http/base/error"
 	"github.com/ontio/ontology/http/base/rpc"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	cstates "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 //get best block hash
@@ -230,8 +234,8 @@ func GetRawTransaction(params []interface{}) map[string]interface{} {
 	return rpc.ResponseSuccess(common.ToHexString(common.SerializeToBytes(tx)))
 }
 
-//get storage from contract
-//   {"jsonrpc": "2.0", "method": "getstorage", "params": ["code hash", "key"], "id": 0}
+//get storage from contract, the optional height queries the storage at the end of the block
+//   {"jsonrpc": "2.0", "method": "getstorage", "params": ["code hash", "key", height], "id": 0}
 func GetStorage(params []interface{}) map[string]interface{} {
 	if len(params) < 2 {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
@@ -262,19 +266,109 @@ func GetStorage(params []interface{}) map[string]interface{} {
 	default:
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
-	value, err := bactor.GetStorageItem(address, key)
+	height, ok := parseHeightParam(params, 2)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var value []byte
+	var err error
+	if height != nil {
+		value, err = bactor.GetStorageItemAt(*height, address, key)
+	} else {
+		value, err = bactor.GetStorageItem(address, key)
+	}
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return rpc.ResponseSuccess(nil)
 		}
+		if height != nil {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, err.Error())
+		}
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
 	return rpc.ResponseSuccess(common.ToHexString(value))
 }
 
+//get a page of the storage items of contract whose keys have the prefix, from the key not less than start.
+//Next of the result is the start of the following page, empty if no item left
+//   {"jsonrpc": "2.0", "method": "getstoragelist", "params": ["code hash", "prefix", "start", limit], "id": 0}
+func GetStorageList(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	prefix, ok := parseHexParam(params, 1)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	start, ok := parseHexParam(params, 2)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	if len(start) == 0 {
+		start = nil
+	}
+	limit := bcomn.DEFAULT_STORAGE_LIST_LIMIT
+	if len(params) > 3 && params[3] != nil {
+		l, ok := params[3].(float64)
+		if !ok || l < 1 || l > bcomn.MAX_STORAGE_LIST_LIMIT {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+		limit = int(l)
+	}
+
+	items, next, err := bactor.FindStorageItems(address, prefix, start, limit)
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, "")
+	}
+	list := bcomn.StorageList{
+		Items: make([]bcomn.StorageItem, 0, len(items)),
+		Next:  common.ToHexString(next),
+	}
+	for _, item := range items {
+		list.Items = append(list.Items, bcomn.StorageItem{
+			Key:   common.ToHexString(item.Key),
+			Value: common.ToHexString(item.Value),
+		})
+	}
+	return rpc.ResponseSuccess(list)
+}
+
+//get the key count and bytes of the contract storage, with the storage fee at the Storage.Byte.Gas global param
+//   {"jsonrpc": "2.0", "method": "getcontractstoragestats", "params": ["code hash"], "id": 0}
+func GetContractStorageStats(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	stats, err := bcomn.GetContractStorageStats(address)
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, "")
+	}
+	return rpc.ResponseSuccess(stats)
+}
+
 //send raw transaction
 // A JSON example for sendrawtransaction method as following:
 //   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex"], "id": 0}
+// pre-execute on the states at the end of a block height:
+//   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex", 1, height], "id": 0}
+// pre-execute with the gas profile, height can be null for the current states:
+//   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex", 1, height, 1], "id": 0}
 func SendRawTransaction(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
@@ -297,7 +391,22 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 			if len(params) > 1 {
 				preExec, ok := params[1].(float64)
 				if ok && preExec == 1 {
-					result, err := bactor.PreExecuteContract(txn)
+					height, ok := parseHeightParam(params, 2)
+					if !ok {
+						return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+					}
+					profile, ok := parseFlagParam(params, 3)
+					if !ok {
+						return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+					}
+					var result *cstates.PreExecResult
+					if profile {
+						result, err = bactor.PreExecuteContractWithProfile(height, txn)
+					} else if height != nil {
+						result, err = bactor.PreExecuteContractAt(*height, txn)
+					} else {
+						result, err = bactor.PreExecuteContract(txn)
+					}
 					if err != nil {
 						log.Infof("PreExec: ", err)
 						return rpc.ResponsePack(berr.SMARTCODE_ERROR, err.Error())
@@ -366,6 +475,29 @@ func GetContractState(params []interface{}) map[string]interface{} {
 	return rpc.ResponseSuccess(common.ToHexString(sink.Bytes()))
 }
 
+//get the manifest of contract, null if the contract was deployed without manifest
+func GetContractManifest(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	contract, err := bactor.GetContractStateFromStore(address)
+	if err != nil || contract == nil {
+		return rpc.ResponsePack(berr.UNKNOWN_CONTRACT, berr.ErrMap[berr.UNKNOWN_CONTRACT])
+	}
+	if len(contract.GetManifest()) == 0 {
+		return rpc.ResponseSuccess(nil)
+	}
+	return rpc.ResponseSuccess(json.RawMessage(contract.GetManifest()))
+}
+
 //get smartconstract event
 func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if !config.DefConfig.Common.EnableEventLog {
@@ -414,6 +546,42 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 }
 
+//re-execute the committed transaction with tracer "call"(default), "opcode" or "storage"
+//   {"jsonrpc": "2.0", "method": "tracetransaction", "params": ["tx hash", "call"], "id": 0}
+func TraceTransaction(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	hash, err := common.Uint256FromHexString(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	tracerName := ""
+	if len(params) > 1 {
+		tracerName, ok = params[1].(string)
+		if !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	tracer, err := trace.NewTracer(tracerName)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	eventInfo, err := bactor.TraceTransaction(hash, tracer)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return rpc.ResponsePack(berr.UNKNOWN_TRANSACTION, "")
+		}
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	_, notify := bcomn.GetExecuteNotify(eventInfo)
+	return rpc.ResponseSuccess(bcomn.TransactionTrace{ExecuteNotify: notify, Trace: tracer.Result()})
+}
+
 //get block height by transaction hash
 func GetBlockHeightByTxHash(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -452,13 +620,61 @@ func GetBalance(params []interface{}) map[string]interface{} {
 	if err != nil {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
-	rsp, err := bcomn.GetBalance(address)
+	height, ok := parseHeightParam(params, 1)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var rsp *bcomn.BalanceOfRsp
+	if height != nil {
+		rsp, err = bcomn.GetBalanceAt(address, *height)
+	} else {
+		rsp, err = bcomn.GetBalance(address)
+	}
 	if err != nil {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
 	return rpc.ResponseSuccess(rsp)
 }
 
+//get a page of the transactions which transfers touched the address, from the block height in ascending order.
+//NextHeight of the result is the height of the following page if HasMore
+//   {"jsonrpc": "2.0", "method": "getaddresshistory", "params": ["address", fromHeight, limit], "id": 0}
+func GetAddressHistory(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	addrBase58, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := common.AddressFromBase58(addrBase58)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	fromHeight := uint32(0)
+	height, ok := parseHeightParam(params, 1)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	if height != nil {
+		fromHeight = *height
+	}
+	limit := bcomn.DEFAULT_ADDRESS_HISTORY_LIMIT
+	if len(params) > 2 && params[2] != nil {
+		l, ok := params[2].(float64)
+		if !ok || l < 1 || l > bcomn.MAX_ADDRESS_HISTORY_LIMIT {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+		limit = int(l)
+	}
+
+	histories, next, more, err := bactor.GetAddressHistory(address, fromHeight, limit)
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return rpc.ResponseSuccess(bcomn.GetAddressHistoryList(histories, next, more))
+}
+
 //get balance of address
 func GetOep4Balance(params []interface{}) map[string]interface{} {
 	if len(params) < 2 {
@@ -477,13 +693,65 @@ func GetOep4Balance(params []interface{}) map[string]interface{} {
 	if err != nil {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
-	rsp, err := bcomn.GetOep4Balance(contractAddr, addrs)
+	height, ok := parseHeightParam(params, 2)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var rsp *bcomn.Oep4BalanceOfRsp
+	if height != nil {
+		rsp, err = bcomn.GetOep4BalanceAt(contractAddr, addrs, *height)
+	} else {
+		rsp, err = bcomn.GetOep4Balance(contractAddr, addrs)
+	}
 	if err != nil {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
 	return rpc.ResponseSuccess(rsp)
 }
 
+//parseHeightParam parse the optional block height at index of params, return nil height if absent
+func parseHeightParam(params []interface{}, index int) (*uint32, bool) {
+	if len(params) <= index || params[index] == nil {
+		return nil, true
+	}
+	h, ok := params[index].(float64)
+	if !ok || h < 0 || h > math.MaxUint32 {
+		return nil, false
+	}
+	height := uint32(h)
+	return &height, true
+}
+
+//parseFlagParam parse the optional flag which is 1 or true
+func parseFlagParam(params []interface{}, index int) (bool, bool) {
+	if len(params) <= index || params[index] == nil {
+		return false, true
+	}
+	switch flag := params[index].(type) {
+	case float64:
+		return flag == 1, true
+	case bool:
+		return flag, true
+	default:
+		return false, false
+	}
+}
+
+func parseHexParam(params []interface{}, index int) ([]byte, bool) {
+	if len(params) <= index || params[index] == nil {
+		return nil, true
+	}
+	str, ok := params[index].(string)
+	if !ok {
+		return nil, false
+	}
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
 func parseAddressParam(params []interface{}) ([]common.Address, error) {
 	res := make([]common.Address, len(params))
 	var err error
@@ -685,3 +953,28 @@ func GetCrossStatesProof(params []interface{}) map[string]interface{} {
 	}
 	return rpc.ResponseSuccess(bcomn.CrossStatesProof{"CrossStatesProof", hex.EncodeToString(proof)})
 }
+
+//get consensus state, chain config, peers and timeline of current block
+func GetConsensusState(params []interface{}) map[string]interface{} {
+	state, err := bactor.GetConsensusState()
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return rpc.ResponseSuccess(state)
+}
+
+//get consensus timeline of the block
+func GetConsensusHistory(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	height, ok := params[0].(float64)
+	if !ok || height < 0 || height > math.MaxUint32 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	history, err := bactor.GetConsensusHistory(uint32(height))
+	if err != nil {
+		return rpc.ResponsePack(berr.UNKNOWN_BLOCK, err.Error())
+	}
+	return rpc.ResponseSuccess(history)
+}
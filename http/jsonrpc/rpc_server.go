@@ -43,17 +43,22 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getrawtransaction", GetRawTransaction)
 	rpc.HandleFunc("sendrawtransaction", SendRawTransaction)
 	rpc.HandleFunc("getstorage", GetStorage)
+	rpc.HandleFunc("getstoragelist", GetStorageList)
+	rpc.HandleFunc("getcontractstoragestats", GetContractStorageStats)
 	rpc.HandleFunc("getversion", GetNodeVersion)
 	rpc.HandleFunc("getnetworkid", GetNetworkId)
 
 	rpc.HandleFunc("getcontractstate", GetContractState)
+	rpc.HandleFunc("getcontractmanifest", GetContractManifest)
 	rpc.HandleFunc("getmempooltxcount", GetMemPoolTxCount)
 	rpc.HandleFunc("getmempooltxstate", GetMemPoolTxState)
 	rpc.HandleFunc("getmempooltxhashlist", GetMemPoolTxHashList)
 	rpc.HandleFunc("getsmartcodeevent", GetSmartCodeEvent)
 	rpc.HandleFunc("getblockheightbytxhash", GetBlockHeightByTxHash)
+	rpc.HandleFunc("tracetransaction", TraceTransaction)
 
 	rpc.HandleFunc("getbalance", GetBalance)
+	rpc.HandleFunc("getaddresshistory", GetAddressHistory)
 	rpc.HandleFunc("getoep4balance", GetOep4Balance)
 	rpc.HandleFunc("getallowance", GetAllowance)
 	rpc.HandleFunc("getmerkleproof", GetMerkleProof)
@@ -64,6 +69,8 @@ func StartRPCServer() error {
 
 	rpc.HandleFunc("getcrosschainmsg", GetCrossChainMsg)
 	rpc.HandleFunc("getcrossstatesproof", GetCrossStatesProof)
+	rpc.HandleFunc("getconsensusstate", GetConsensusState)
+	rpc.HandleFunc("getconsensushistory", GetConsensusHistory)
 
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
 	if err != nil {
@@ -19,6 +19,7 @@
 package localrpc
 
 import (
+	"math"
 	"time"
 
 	"github.com/ontio/ontology/common/log"
@@ -87,3 +88,81 @@ func SetDebugInfo(params []interface{}) map[string]interface{} {
 	}
 	return rpc.ResponsePack(berr.SUCCESS, true)
 }
+
+// curl http://localhost:20337/local -d '{"method":"getbanlist", "params":[]}'
+func GetBanList(params []interface{}) map[string]interface{} {
+	return rpc.ResponseSuccess(bactor.GetBanList())
+}
+
+//BanPeer params: ip, ban duration in second(optional, 0 means persistently), reason(optional)
+// curl http://localhost:20337/local -d '{"method":"banpeer", "params":["1.2.3.4", 3600, "spam"]}'
+func BanPeer(params []interface{}) map[string]interface{} {
+	if len(params) < 1 || len(params) > 3 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	ip, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var duration float64
+	if len(params) > 1 {
+		duration, ok = params[1].(float64)
+		if !ok || duration < 0 {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	var reason string
+	if len(params) > 2 {
+		reason, ok = params[2].(string)
+		if !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if err := bactor.BanPeer(ip, time.Duration(duration)*time.Second, reason); err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return rpc.ResponsePack(berr.SUCCESS, true)
+}
+
+//UnbanPeer params: ip
+// curl http://localhost:20337/local -d '{"method":"unbanpeer", "params":["1.2.3.4"]}'
+func UnbanPeer(params []interface{}) map[string]interface{} {
+	if len(params) != 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	ip, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	if err := bactor.UnbanPeer(ip); err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return rpc.ResponsePack(berr.SUCCESS, true)
+}
+
+//GetConsensusState return the consensus state, chain config, peers and timeline of current block
+// curl http://localhost:20337/local -d '{"method":"getconsensusstate", "params":[]}'
+func GetConsensusState(params []interface{}) map[string]interface{} {
+	state, err := bactor.GetConsensusState()
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return rpc.ResponseSuccess(state)
+}
+
+//GetConsensusHistory params: block height
+// curl http://localhost:20337/local -d '{"method":"getconsensushistory", "params":[100]}'
+func GetConsensusHistory(params []interface{}) map[string]interface{} {
+	if len(params) != 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	height, ok := params[0].(float64)
+	if !ok || height < 0 || height > math.MaxUint32 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	history, err := bactor.GetConsensusHistory(uint32(height))
+	if err != nil {
+		return rpc.ResponsePack(berr.UNKNOWN_BLOCK, err.Error())
+	}
+	return rpc.ResponseSuccess(history)
+}
@@ -43,6 +43,11 @@ func StartLocalServer() error {
 	rpc.HandleFunc("startconsensus", StartConsensus)
 	rpc.HandleFunc("stopconsensus", StopConsensus)
 	rpc.HandleFunc("setdebuginfo", SetDebugInfo)
+	rpc.HandleFunc("getbanlist", GetBanList)
+	rpc.HandleFunc("banpeer", BanPeer)
+	rpc.HandleFunc("unbanpeer", UnbanPeer)
+	rpc.HandleFunc("getconsensusstate", GetConsensusState)
+	rpc.HandleFunc("getconsensushistory", GetConsensusHistory)
 
 	// TODO: only listen to local host
 	err := http.ListenAndServe(LOCAL_HOST+":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpLocalPort)), nil)
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package metrics provides the prometheus metrics server
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+//newHandler serves the metrics of the default prometheus registry on the configured path
+func newHandler(cfg *config.MetricsConfig) http.Handler {
+	path := cfg.MetricsPath
+	if path == "" {
+		path = config.DEFAULT_METRICS_PATH
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	serverMut := http.NewServeMux()
+	serverMut.Handle(path, promhttp.Handler())
+	return serverMut
+}
+
+func StartServer(cfg *config.MetricsConfig) {
+	if !cfg.EnableMetrics || cfg.MetricsPort == 0 {
+		return
+	}
+	server := &http.Server{Handler: newHandler(cfg)}
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(int(cfg.MetricsPort)))
+	if err != nil {
+		log.Errorf("start metrics server error: %s", err)
+		return
+	}
+
+	log.Infof("start metrics service on %d", cfg.MetricsPort)
+	log.Error(server.Serve(listener))
+}
@@ -74,6 +74,7 @@ const (
 	GET_ALLOWANCE         = "/api/v1/allowance/:asset/:from/:to"
 	GET_UNBOUNDONG        = "/api/v1/unboundong/:addr"
 	GET_GRANTONG          = "/api/v1/grantong/:addr"
+	GET_ADDRESS_HISTORY   = "/api/v1/addresshistory/:addr"
 	GET_MEMPOOL_TXCOUNT   = "/api/v1/mempool/txcount"
 	GET_MEMPOOL_TXSTATE   = "/api/v1/mempool/txstate/:hash"
 	GET_MEMPOOL_TXHASHS   = "/api/v1/mempool/txhashlist"
@@ -169,6 +170,7 @@ func (this *restServer) registryMethod() {
 		GET_GAS_PRICE:         {name: "getgasprice", handler: rest.GetGasPrice},
 		GET_UNBOUNDONG:        {name: "getunboundong", handler: rest.GetUnboundOng},
 		GET_GRANTONG:          {name: "getgrantong", handler: rest.GetGrantOng},
+		GET_ADDRESS_HISTORY:   {name: "getaddresshistory", handler: rest.GetAddressHistory},
 		GET_MEMPOOL_TXCOUNT:   {name: "getmempooltxcount", handler: rest.GetMemPoolTxCount},
 		GET_MEMPOOL_TXSTATE:   {name: "getmempooltxstate", handler: rest.GetMemPoolTxState},
 		GET_MEMPOOL_TXHASHS:   {name: "getmempooltxhashlist", handler: rest.GetMemPoolTxHashList},
@@ -215,6 +217,8 @@ func (this *restServer) getPath(url string) string {
 		return GET_UNBOUNDONG
 	} else if strings.Contains(url, strings.TrimRight(GET_GRANTONG, ":addr")) {
 		return GET_GRANTONG
+	} else if strings.Contains(url, strings.TrimRight(GET_ADDRESS_HISTORY, ":addr")) {
+		return GET_ADDRESS_HISTORY
 	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXSTATE, ":hash")) {
 		return GET_MEMPOOL_TXSTATE
 	}
@@ -239,9 +243,11 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	case GET_CONTRACT_STATE:
 		req["Hash"], req["Raw"] = getParam(r, "hash"), r.FormValue("raw")
 	case POST_RAW_TX:
-		req["PreExec"] = r.FormValue("preExec")
+		req["PreExec"], req["Height"] = r.FormValue("preExec"), r.FormValue("height")
+		req["Profile"] = r.FormValue("profile")
 	case GET_STORAGE:
 		req["Hash"], req["Key"] = getParam(r, "hash"), getParam(r, "key")
+		req["Height"] = r.FormValue("height")
 	case GET_SMTCOCE_EVT_TXS:
 		req["Height"] = getParam(r, "height")
 	case GET_SMTCOCE_EVTS:
@@ -249,7 +255,7 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	case GET_BLK_HGT_BY_TXHASH:
 		req["Hash"] = getParam(r, "hash")
 	case GET_BALANCE:
-		req["Addr"] = getParam(r, "addr")
+		req["Addr"], req["Height"] = getParam(r, "addr"), r.FormValue("height")
 	case GET_MERKLE_PROOF:
 		req["Hash"] = getParam(r, "hash")
 	case GET_ALLOWANCE:
@@ -259,6 +265,9 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Addr"] = getParam(r, "addr")
 	case GET_GRANTONG:
 		req["Addr"] = getParam(r, "addr")
+	case GET_ADDRESS_HISTORY:
+		req["Addr"], req["Height"] = getParam(r, "addr"), r.FormValue("height")
+		req["Limit"] = r.FormValue("limit")
 	case GET_MEMPOOL_TXSTATE:
 		req["Hash"] = getParam(r, "hash")
 	default:
@@ -38,6 +38,8 @@ var ws *websocket.WsServer
 func StartServer() {
 	bactor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, sendBlock2WSclient)
 	bactor.SubscribeEvent(message.TOPIC_SMART_CODE_EVENT, pushSmartCodeEvent)
+	bactor.SubscribeEvent(message.TOPIC_TX_STATUS, pushTxStatus)
+	bactor.SubscribeEvent(message.TOPIC_CONSENSUS_EVENT, pushConsensusEvent)
 	go func() {
 		ws = websocket.InitWsServer()
 		ws.Start()
@@ -45,12 +47,31 @@ func StartServer() {
 }
 func sendBlock2WSclient(v interface{}) {
 	if cfg.DefConfig.Ws.HttpWsPort != 0 {
+		if block, ok := v.(types.Block); ok && ws != nil {
+			ws.NotifyBlock(&block)
+		}
 		go func() {
 			pushBlock(v)
 			pushBlockTransactions(v)
 		}()
 	}
 }
+
+func pushTxStatus(v interface{}) {
+	if ws == nil {
+		return
+	}
+	if msg, ok := v.(message.TxStatusMsg); ok {
+		ws.NotifyTxStatus(&msg)
+	}
+}
+
+func pushConsensusEvent(v interface{}) {
+	if ws == nil {
+		return
+	}
+	ws.NotifyConsensusEvent(v)
+}
 func Stop() {
 	if ws == nil {
 		return
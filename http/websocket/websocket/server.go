@@ -68,6 +68,9 @@ type WsServer struct {
 	ActionMap    map[string]Handler   //handler functions
 	TxHashMap    map[string]string    //key: txHash   value:sessionid
 	SubscribeMap map[string]subscribe //key: sessionId   value:subscribeInfo
+	//key: sessionId   value: named subscriptions of the session
+	Subscriptions map[string]map[string]*Subscription
+	notifyCh      chan interface{}
 }
 
 //init websocket server
@@ -77,7 +80,11 @@ func InitWsServer() *WsServer {
 		SessionList:  session.NewSessionList(),
 		TxHashMap:    make(map[string]string),
 		SubscribeMap: make(map[string]subscribe),
+
+		Subscriptions: make(map[string]map[string]*Subscription),
+		notifyCh:      make(chan interface{}, SUB_NOTIFY_CHAN_SIZE),
 	}
+	go ws.dispatchLoop()
 	return ws
 }
 
@@ -207,6 +214,7 @@ func (self *WsServer) registryMethod() {
 
 		"getsessioncount": {handler: getsessioncount},
 	}
+	self.registrySubscriptionMethod(actionMap)
 	self.ActionMap = actionMap
 }
 
@@ -268,6 +276,7 @@ func (self *WsServer) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		self.deleteTxHashes(nsSession.GetSessionId())
 		self.deleteSubscribe(nsSession.GetSessionId())
+		self.deleteSubscriptions(nsSession.GetSessionId())
 		self.SessionList.CloseSession(nsSession)
 		if err := recover(); err != nil {
 			log.Fatal("websocket recover:", err)
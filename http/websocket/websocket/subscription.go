@@ -0,0 +1,659 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/events/message"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	bcomn "github.com/ontio/ontology/http/base/common"
+	Err "github.com/ontio/ontology/http/base/error"
+	"github.com/ontio/ontology/http/base/rest"
+	"github.com/ontio/ontology/smartcontract/event"
+)
+
+//topics of named subscription
+const (
+	SUB_TOPIC_EVENT      = "event"
+	SUB_TOPIC_JSON_BLOCK = "jsonblock"
+	SUB_TOPIC_RAW_BLOCK  = "rawblock"
+	SUB_TOPIC_TXHASHS    = "blocktxhashs"
+	SUB_TOPIC_PENDING_TX = "pendingtx"
+	SUB_TOPIC_TX_STATUS  = "txstatus"
+	SUB_TOPIC_CONSENSUS  = "consensus"
+)
+
+const (
+	MAX_SUBSCRIPTIONS    = 16   //max named subscriptions of a session
+	MAX_SUB_FILTER_ITEMS = 64   //max items of a filter list
+	MAX_RESUME_BLOCKS    = 1024 //max blocks a subscription can resume from
+	SUB_NOTIFY_CHAN_SIZE = 1024
+	SUB_ACTION_PUSH      = "subscription"
+	TX_STATUS_COMMITTED  = "committed"
+)
+
+//Subscription is a named subscription of a session
+type Subscription struct {
+	Name       string   `json:"Name"`
+	Topic      string   `json:"Topic"`
+	Contracts  []string `json:"Contracts,omitempty"`
+	EventNames []string `json:"EventNames,omitempty"`
+	Addresses  []string `json:"Addresses,omitempty"`
+	Payers     []string `json:"Payers,omitempty"`
+	TxHash     string   `json:"TxHash,omitempty"`
+	FromHeight uint32   `json:"FromHeight,omitempty"`
+
+	sessionId  string
+	nextHeight uint32 //next block height to push, only used by block and event topics
+	contracts  map[common.Address]bool
+	eventNames map[string]bool
+	addresses  map[common.Address]bool
+	payers     map[common.Address]bool
+	txHash     common.Uint256
+}
+
+//TxStatus is pushed to txstatus subscriptions
+type TxStatus struct {
+	TxHash string
+	Status string
+	Height uint32 `json:",omitempty"`
+	Desc   string `json:",omitempty"`
+}
+
+//resumeReq triggers pushing the history blocks of new subscriptions
+type resumeReq struct{}
+
+//consensusEvent wraps the consensus timeline event
+type consensusEvent struct {
+	event interface{}
+}
+
+func (self *Subscription) isBlockTopic() bool {
+	switch self.Topic {
+	case SUB_TOPIC_EVENT, SUB_TOPIC_JSON_BLOCK, SUB_TOPIC_RAW_BLOCK, SUB_TOPIC_TXHASHS:
+		return true
+	}
+	return false
+}
+
+//parseSubscription parse subscription from the websocket request
+func parseSubscription(cmd map[string]interface{}) (*Subscription, error) {
+	sub := &Subscription{}
+	sub.Name, _ = cmd["Name"].(string)
+	sub.Topic, _ = cmd["Topic"].(string)
+	if sub.Name == "" {
+		return nil, fmt.Errorf("empty subscription name")
+	}
+	var err error
+	if sub.Contracts, err = parseStringList(cmd, "Contracts"); err != nil {
+		return nil, err
+	}
+	if sub.EventNames, err = parseStringList(cmd, "EventNames"); err != nil {
+		return nil, err
+	}
+	if sub.Addresses, err = parseStringList(cmd, "Addresses"); err != nil {
+		return nil, err
+	}
+	if sub.Payers, err = parseStringList(cmd, "Payers"); err != nil {
+		return nil, err
+	}
+	if sub.contracts, err = parseAddressSet(sub.Contracts); err != nil {
+		return nil, err
+	}
+	if sub.addresses, err = parseAddressSet(sub.Addresses); err != nil {
+		return nil, err
+	}
+	if sub.payers, err = parseAddressSet(sub.Payers); err != nil {
+		return nil, err
+	}
+	sub.eventNames = make(map[string]bool)
+	for _, name := range sub.EventNames {
+		sub.eventNames[name] = true
+		sub.eventNames[hex.EncodeToString([]byte(name))] = true
+	}
+	if height, ok := cmd["FromHeight"].(float64); ok {
+		if height < 0 || height > float64(^uint32(0)) {
+			return nil, fmt.Errorf("invalid FromHeight")
+		}
+		sub.FromHeight = uint32(height)
+	}
+	sub.TxHash, _ = cmd["TxHash"].(string)
+
+	hasFilter := len(sub.Contracts) != 0 || len(sub.EventNames) != 0 || len(sub.Addresses) != 0 ||
+		len(sub.Payers) != 0
+	switch sub.Topic {
+	case SUB_TOPIC_EVENT:
+	case SUB_TOPIC_JSON_BLOCK, SUB_TOPIC_RAW_BLOCK, SUB_TOPIC_TXHASHS:
+		if hasFilter {
+			return nil, fmt.Errorf("topic %s does not support filters", sub.Topic)
+		}
+	case SUB_TOPIC_PENDING_TX:
+		if len(sub.Contracts) != 0 || len(sub.EventNames) != 0 || sub.FromHeight != 0 {
+			return nil, fmt.Errorf("topic %s only supports Addresses and Payers filters", sub.Topic)
+		}
+	case SUB_TOPIC_TX_STATUS:
+		if hasFilter || sub.FromHeight != 0 {
+			return nil, fmt.Errorf("topic %s only supports TxHash", sub.Topic)
+		}
+		if sub.txHash, err = common.Uint256FromHexString(sub.TxHash); err != nil {
+			return nil, fmt.Errorf("invalid TxHash: %s", err)
+		}
+	case SUB_TOPIC_CONSENSUS:
+		if hasFilter || sub.FromHeight != 0 {
+			return nil, fmt.Errorf("topic %s does not support filters", sub.Topic)
+		}
+	default:
+		return nil, fmt.Errorf("unknown topic: %s", sub.Topic)
+	}
+	if sub.Topic != SUB_TOPIC_TX_STATUS && sub.TxHash != "" {
+		return nil, fmt.Errorf("topic %s does not support TxHash", sub.Topic)
+	}
+	return sub, nil
+}
+
+func parseStringList(cmd map[string]interface{}, key string) ([]string, error) {
+	if cmd[key] == nil {
+		return nil, nil
+	}
+	items, ok := cmd[key].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s should be a list", key)
+	}
+	if len(items) > MAX_SUB_FILTER_ITEMS {
+		return nil, fmt.Errorf("too many items in %s", key)
+	}
+	var list []string
+	for _, v := range items {
+		s, ok := v.(string)
+		if !ok || s == "" {
+			return nil, fmt.Errorf("invalid item in %s", key)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+//parseAddressSet parse addresses in base58 or hex format
+func parseAddressSet(list []string) (map[common.Address]bool, error) {
+	set := make(map[common.Address]bool)
+	for _, s := range list {
+		addr, err := common.AddressFromBase58(s)
+		if err != nil {
+			addr, err = common.AddressFromHexString(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address: %s", s)
+			}
+		}
+		set[addr] = true
+	}
+	return set, nil
+}
+
+//matchPayer check the tx payer filter
+func (self *Subscription) matchPayer(payer common.Address) bool {
+	return len(self.payers) == 0 || self.payers[payer]
+}
+
+//matchNotify check whether a notify event pass the contract, event name and address filters
+func (self *Subscription) matchNotify(notify *event.NotifyEventInfo, payerInvolved bool) bool {
+	if len(self.contracts) != 0 && !self.contracts[notify.ContractAddress] {
+		return false
+	}
+	if len(self.eventNames) != 0 {
+		states, ok := notify.States.([]interface{})
+		if !ok || len(states) == 0 {
+			return false
+		}
+		name, ok := states[0].(string)
+		if !ok || !self.eventNames[name] {
+			return false
+		}
+	}
+	if len(self.addresses) != 0 && !payerInvolved && !self.statesInvolve(notify.States) {
+		return false
+	}
+	return true
+}
+
+//statesInvolve check whether the states contain any of the subscribed addresses
+func (self *Subscription) statesInvolve(states interface{}) bool {
+	switch v := states.(type) {
+	case string:
+		if addr, err := common.AddressFromBase58(v); err == nil {
+			return self.addresses[addr]
+		}
+		buf, err := hex.DecodeString(v)
+		if err != nil || len(buf) != common.ADDR_LEN {
+			return false
+		}
+		addr, _ := common.AddressParseFromBytes(buf)
+		if self.addresses[addr] {
+			return true
+		}
+		addr, _ = common.AddressParseFromBytes(common.ToArrayReverse(buf))
+		return self.addresses[addr]
+	case []interface{}:
+		for _, s := range v {
+			if self.statesInvolve(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//filterExecuteNotify return the notify with the matched events, or nil if the tx does not match
+func (self *Subscription) filterExecuteNotify(notify *event.ExecuteNotify, payer common.Address) *event.ExecuteNotify {
+	if !self.matchPayer(payer) {
+		return nil
+	}
+	payerInvolved := self.addresses[payer]
+	if len(self.contracts) == 0 && len(self.eventNames) == 0 && (len(self.addresses) == 0 || payerInvolved) {
+		return notify
+	}
+	var matched []*event.NotifyEventInfo
+	for _, n := range notify.Notify {
+		if self.matchNotify(n, payerInvolved) {
+			matched = append(matched, n)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	return &event.ExecuteNotify{
+		TxHash:      notify.TxHash,
+		State:       notify.State,
+		GasConsumed: notify.GasConsumed,
+		Notify:      matched,
+	}
+}
+
+//matchPendingTx check the payer and address filters of a pending tx
+func (self *Subscription) matchPendingTx(tx *types.Transaction) bool {
+	if !self.matchPayer(tx.Payer) {
+		return false
+	}
+	return len(self.addresses) == 0 || self.addresses[tx.Payer]
+}
+
+//registry named subscription handler methods
+func (self *WsServer) registrySubscriptionMethod(actionMap map[string]Handler) {
+	actionMap["addsubscription"] = Handler{handler: self.addSubscription}
+	actionMap["removesubscription"] = Handler{handler: self.removeSubscription}
+	actionMap["listsubscriptions"] = Handler{handler: self.listSubscriptions}
+}
+
+func (self *WsServer) addSubscription(cmd map[string]interface{}) map[string]interface{} {
+	sub, err := parseSubscription(cmd)
+	if err != nil {
+		resp := rest.ResponsePack(Err.INVALID_PARAMS)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	sub.sessionId, _ = cmd["SessionId"].(string)
+	if sub.isBlockTopic() {
+		current := bactor.GetCurrentBlockHeight()
+		sub.nextHeight = current + 1
+		if sub.FromHeight != 0 {
+			if sub.FromHeight > current+1 || current+1-sub.FromHeight > MAX_RESUME_BLOCKS {
+				resp := rest.ResponsePack(Err.INVALID_PARAMS)
+				resp["Result"] = fmt.Sprintf("FromHeight should be in [%d, %d]",
+					resumeLowerBound(current), current+1)
+				return resp
+			}
+			sub.nextHeight = sub.FromHeight
+		}
+	}
+
+	self.Lock()
+	subs := self.Subscriptions[sub.sessionId]
+	if subs == nil {
+		subs = make(map[string]*Subscription)
+		self.Subscriptions[sub.sessionId] = subs
+	}
+	if _, present := subs[sub.Name]; !present && len(subs) >= MAX_SUBSCRIPTIONS {
+		self.Unlock()
+		resp := rest.ResponsePack(Err.SERVICE_CEILING)
+		resp["Result"] = "too many subscriptions"
+		return resp
+	}
+	subs[sub.Name] = sub
+	self.Unlock()
+
+	if sub.Topic == SUB_TOPIC_TX_STATUS {
+		go self.pushCurrentTxStatus(sub)
+	} else if sub.isBlockTopic() && sub.FromHeight != 0 {
+		self.notify(resumeReq{})
+	}
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Result"] = sub
+	return resp
+}
+
+func resumeLowerBound(current uint32) uint32 {
+	if current+1 <= MAX_RESUME_BLOCKS {
+		return 1
+	}
+	return current + 1 - MAX_RESUME_BLOCKS
+}
+
+func (self *WsServer) removeSubscription(cmd map[string]interface{}) map[string]interface{} {
+	sessionId, _ := cmd["SessionId"].(string)
+	name, _ := cmd["Name"].(string)
+	self.Lock()
+	defer self.Unlock()
+	subs := self.Subscriptions[sessionId]
+	if _, ok := subs[name]; !ok {
+		return rest.ResponsePack(Err.INVALID_PARAMS)
+	}
+	delete(subs, name)
+	if len(subs) == 0 {
+		delete(self.Subscriptions, sessionId)
+	}
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Result"] = name
+	return resp
+}
+
+func (self *WsServer) listSubscriptions(cmd map[string]interface{}) map[string]interface{} {
+	sessionId, _ := cmd["SessionId"].(string)
+	self.RLock()
+	list := make([]*Subscription, 0, len(self.Subscriptions[sessionId]))
+	for _, sub := range self.Subscriptions[sessionId] {
+		list = append(list, sub)
+	}
+	self.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Result"] = list
+	return resp
+}
+
+func (self *WsServer) deleteSubscriptions(sessionId string) {
+	self.Lock()
+	defer self.Unlock()
+	delete(self.Subscriptions, sessionId)
+}
+
+//NotifyBlock notify the named subscriptions a new block is saved
+func (self *WsServer) NotifyBlock(block *types.Block) {
+	self.notify(block)
+}
+
+//NotifyTxStatus notify the named subscriptions the tx status changed in the tx pool
+func (self *WsServer) NotifyTxStatus(msg *message.TxStatusMsg) {
+	self.notify(msg)
+}
+
+//NotifyConsensusEvent notify the named subscriptions a consensus event is recorded
+func (self *WsServer) NotifyConsensusEvent(event interface{}) {
+	self.notify(consensusEvent{event: event})
+}
+
+func (self *WsServer) notify(msg interface{}) {
+	select {
+	case self.notifyCh <- msg:
+	default:
+		//missed blocks will be pushed with the next block from ledger, and the txs committed in them are rechecked
+		log.Warnf("websocket subscription notify channel is full, drop %T", msg)
+	}
+}
+
+//dispatchLoop push the notifications to named subscriptions in order
+func (self *WsServer) dispatchLoop() {
+	var lastHeight uint32
+	for msg := range self.notifyCh {
+		switch m := msg.(type) {
+		case *types.Block:
+			self.pushBlocks(m.Header.Height, m)
+			if m.Header.Height > lastHeight {
+				//the notifications of blocks in between are dropped, or not received yet after start
+				if m.Header.Height > lastHeight+1 {
+					self.recheckTxCommitted()
+				}
+				self.pushTxCommitted(m)
+				lastHeight = m.Header.Height
+			}
+		case resumeReq:
+			self.pushBlocks(bactor.GetCurrentBlockHeight(), nil)
+		case *message.TxStatusMsg:
+			self.pushTxStatus(m)
+		case consensusEvent:
+			self.pushConsensusEvent(m.event)
+		}
+	}
+}
+
+//collectSubs return the subscriptions matched by filter
+func (self *WsServer) collectSubs(filter func(sub *Subscription) bool) []*Subscription {
+	self.RLock()
+	defer self.RUnlock()
+	var list []*Subscription
+	for _, subs := range self.Subscriptions {
+		for _, sub := range subs {
+			if filter(sub) {
+				list = append(list, sub)
+			}
+		}
+	}
+	return list
+}
+
+//pushBlocks push blocks and events up to height to the block and event subscriptions
+func (self *WsServer) pushBlocks(height uint32, latest *types.Block) {
+	subs := self.collectSubs(func(sub *Subscription) bool {
+		return sub.isBlockTopic() && sub.nextHeight <= height
+	})
+	if len(subs) == 0 {
+		return
+	}
+	from := height
+	for _, sub := range subs {
+		if sub.nextHeight < from {
+			from = sub.nextHeight
+		}
+	}
+	for h := from; h <= height; h++ {
+		block := latest
+		if block == nil || block.Header.Height != h {
+			var err error
+			block, err = bactor.GetBlockByHeight(h)
+			if err != nil || block == nil {
+				log.Warnf("websocket subscription get block %d error: %v", h, err)
+				return
+			}
+		}
+		var notifies []*event.ExecuteNotify
+		for _, sub := range subs {
+			if sub.nextHeight != h {
+				continue
+			}
+			if sub.Topic == SUB_TOPIC_EVENT && notifies == nil {
+				var err error
+				notifies, err = bactor.GetEventNotifyByHeight(h)
+				if err != nil {
+					log.Warnf("websocket subscription get events at %d error: %s", h, err)
+					return
+				}
+				if notifies == nil {
+					notifies = []*event.ExecuteNotify{}
+				}
+			}
+			self.pushBlock(sub, block, notifies)
+			sub.nextHeight = h + 1
+		}
+	}
+}
+
+func (self *WsServer) pushBlock(sub *Subscription, block *types.Block, notifies []*event.ExecuteNotify) {
+	height := block.Header.Height
+	switch sub.Topic {
+	case SUB_TOPIC_JSON_BLOCK:
+		self.pushToSub(sub, height, bcomn.GetBlockInfo(block))
+	case SUB_TOPIC_RAW_BLOCK:
+		self.pushToSub(sub, height, common.ToHexString(block.ToArray()))
+	case SUB_TOPIC_TXHASHS:
+		self.pushToSub(sub, height, bcomn.GetBlockTransactions(block))
+	case SUB_TOPIC_EVENT:
+		payers := make(map[common.Uint256]common.Address, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			payers[tx.Hash()] = tx.Payer
+		}
+		for _, notify := range notifies {
+			if matched := sub.filterExecuteNotify(notify, payers[notify.TxHash]); matched != nil {
+				_, result := bcomn.GetExecuteNotify(matched)
+				self.pushToSub(sub, height, result)
+			}
+		}
+	}
+}
+
+//pushTxCommitted push the final status of the txs in block to the txstatus subscriptions
+func (self *WsServer) pushTxCommitted(block *types.Block) {
+	subs := self.collectSubs(func(sub *Subscription) bool {
+		return sub.Topic == SUB_TOPIC_TX_STATUS
+	})
+	if len(subs) == 0 {
+		return
+	}
+	txs := make(map[common.Uint256]bool, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		txs[tx.Hash()] = true
+	}
+	for _, sub := range subs {
+		if txs[sub.txHash] {
+			self.finishTxSub(sub, block.Header.Height)
+		}
+	}
+}
+
+//recheckTxCommitted look up the txs of txstatus subscriptions in ledger, since the notifications of the blocks
+//committing them may be dropped
+func (self *WsServer) recheckTxCommitted() {
+	subs := self.collectSubs(func(sub *Subscription) bool {
+		return sub.Topic == SUB_TOPIC_TX_STATUS
+	})
+	for _, sub := range subs {
+		height, tx, err := bactor.GetTxnWithHeightByTxHash(sub.txHash)
+		if err == nil && tx != nil {
+			self.finishTxSub(sub, height)
+		}
+	}
+}
+
+//pushTxStatus push the tx status in tx pool to txstatus and pendingtx subscriptions
+func (self *WsServer) pushTxStatus(msg *message.TxStatusMsg) {
+	hash := msg.Tx.Hash()
+	subs := self.collectSubs(func(sub *Subscription) bool {
+		return (sub.Topic == SUB_TOPIC_TX_STATUS && sub.txHash == hash) ||
+			(sub.Topic == SUB_TOPIC_PENDING_TX && msg.Status == message.TX_STATUS_VERIFIED)
+	})
+	for _, sub := range subs {
+		if sub.Topic == SUB_TOPIC_PENDING_TX {
+			if sub.matchPendingTx(msg.Tx) {
+				self.pushToSub(sub, 0, bcomn.TransArryByteToHexString(msg.Tx))
+			}
+			continue
+		}
+		status := &TxStatus{TxHash: hash.ToHexString(), Status: msg.Status, Desc: msg.Desc}
+		if msg.Status == message.TX_STATUS_FAILED {
+			if self.deleteSub(sub) {
+				self.pushToSub(sub, 0, status)
+			}
+			continue
+		}
+		self.pushToSub(sub, 0, status)
+	}
+}
+
+//pushConsensusEvent push the consensus timeline event to consensus subscriptions
+func (self *WsServer) pushConsensusEvent(event interface{}) {
+	subs := self.collectSubs(func(sub *Subscription) bool {
+		return sub.Topic == SUB_TOPIC_CONSENSUS
+	})
+	for _, sub := range subs {
+		self.pushToSub(sub, 0, event)
+	}
+}
+
+//pushCurrentTxStatus push the status of the tx when the txstatus subscription is added
+func (self *WsServer) pushCurrentTxStatus(sub *Subscription) {
+	height, tx, err := bactor.GetTxnWithHeightByTxHash(sub.txHash)
+	if err == nil && tx != nil {
+		self.finishTxSub(sub, height)
+		return
+	}
+	if _, err := bactor.GetTxFromPool(sub.txHash); err == nil {
+		self.pushToSub(sub, 0, &TxStatus{TxHash: sub.TxHash, Status: message.TX_STATUS_VERIFIED})
+	}
+}
+
+//finishTxSub push the committed or failed status and remove the txstatus subscription
+func (self *WsServer) finishTxSub(sub *Subscription, height uint32) {
+	if !self.deleteSub(sub) {
+		return
+	}
+	hash := sub.txHash.ToHexString()
+	status := &TxStatus{TxHash: hash, Status: TX_STATUS_COMMITTED, Height: height}
+	notify, err := bactor.GetEventNotifyByTxHash(sub.txHash)
+	if err == nil && notify != nil && notify.State == event.CONTRACT_STATE_FAIL {
+		status.Status = message.TX_STATUS_FAILED
+		status.Desc = "execution failed"
+	}
+	self.pushToSub(sub, height, status)
+}
+
+//deleteSub remove the subscription, return false if it has been removed
+func (self *WsServer) deleteSub(sub *Subscription) bool {
+	self.Lock()
+	defer self.Unlock()
+	subs := self.Subscriptions[sub.sessionId]
+	if subs[sub.Name] != sub {
+		return false
+	}
+	delete(subs, sub.Name)
+	if len(subs) == 0 {
+		delete(self.Subscriptions, sub.sessionId)
+	}
+	return true
+}
+
+func (self *WsServer) pushToSub(sub *Subscription, height uint32, result interface{}) {
+	s := self.SessionList.GetSessionById(sub.sessionId)
+	if s == nil {
+		return
+	}
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Action"] = SUB_ACTION_PUSH
+	resp["Name"] = sub.Name
+	resp["Topic"] = sub.Topic
+	if height != 0 {
+		resp["Height"] = height
+	}
+	resp["Result"] = result
+	s.Send(marshalResp(resp))
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSubscription(t *testing.T) {
+	addr := common.AddressFromVmCode([]byte("contract"))
+	sub, err := parseSubscription(map[string]interface{}{
+		"Name":       "ev",
+		"Topic":      SUB_TOPIC_EVENT,
+		"Contracts":  []interface{}{addr.ToHexString()},
+		"Addresses":  []interface{}{addr.ToBase58()},
+		"EventNames": []interface{}{"transfer"},
+		"FromHeight": float64(10),
+	})
+	assert.Nil(t, err)
+	assert.True(t, sub.contracts[addr])
+	assert.True(t, sub.addresses[addr])
+	assert.True(t, sub.eventNames["transfer"])
+	assert.True(t, sub.eventNames[hex.EncodeToString([]byte("transfer"))])
+	assert.Equal(t, uint32(10), sub.FromHeight)
+
+	invalid := []map[string]interface{}{
+		{"Topic": SUB_TOPIC_EVENT},
+		{"Name": "a", "Topic": "unknown"},
+		{"Name": "a", "Topic": SUB_TOPIC_JSON_BLOCK, "Payers": []interface{}{addr.ToBase58()}},
+		{"Name": "a", "Topic": SUB_TOPIC_EVENT, "Contracts": []interface{}{"invalid"}},
+		{"Name": "a", "Topic": SUB_TOPIC_EVENT, "Contracts": "invalid"},
+		{"Name": "a", "Topic": SUB_TOPIC_TX_STATUS},
+		{"Name": "a", "Topic": SUB_TOPIC_PENDING_TX, "FromHeight": float64(1)},
+		{"Name": "a", "Topic": SUB_TOPIC_CONSENSUS, "FromHeight": float64(1)},
+	}
+	for _, cmd := range invalid {
+		_, err := parseSubscription(cmd)
+		assert.NotNil(t, err, "%v", cmd)
+	}
+
+	hash := common.UINT256_EMPTY
+	sub, err = parseSubscription(map[string]interface{}{
+		"Name":   "tx",
+		"Topic":  SUB_TOPIC_TX_STATUS,
+		"TxHash": hash.ToHexString(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, hash, sub.txHash)
+}
+
+func TestFilterExecuteNotify(t *testing.T) {
+	contract1 := common.AddressFromVmCode([]byte("contract1"))
+	contract2 := common.AddressFromVmCode([]byte("contract2"))
+	user := common.AddressFromVmCode([]byte("user"))
+	payer := common.AddressFromVmCode([]byte("payer"))
+
+	notify := &event.ExecuteNotify{
+		State: event.CONTRACT_STATE_SUCCESS,
+		Notify: []*event.NotifyEventInfo{
+			{ContractAddress: contract1, States: []interface{}{"transfer", user.ToBase58(), payer.ToBase58(), 1}},
+			{ContractAddress: contract2, States: []interface{}{hex.EncodeToString([]byte("approve")),
+				hex.EncodeToString(user[:])}},
+			{ContractAddress: contract2, States: "log"},
+		},
+	}
+	parse := func(cmd map[string]interface{}) *Subscription {
+		cmd["Name"] = "test"
+		cmd["Topic"] = SUB_TOPIC_EVENT
+		sub, err := parseSubscription(cmd)
+		assert.Nil(t, err)
+		return sub
+	}
+
+	sub := parse(map[string]interface{}{})
+	assert.Equal(t, notify, sub.filterExecuteNotify(notify, payer))
+
+	sub = parse(map[string]interface{}{"Contracts": []interface{}{contract2.ToHexString()}})
+	assert.Len(t, sub.filterExecuteNotify(notify, payer).Notify, 2)
+
+	sub = parse(map[string]interface{}{"EventNames": []interface{}{"approve"}})
+	res := sub.filterExecuteNotify(notify, payer)
+	assert.Len(t, res.Notify, 1)
+	assert.Equal(t, contract2, res.Notify[0].ContractAddress)
+
+	sub = parse(map[string]interface{}{"Addresses": []interface{}{user.ToBase58()}})
+	assert.Len(t, sub.filterExecuteNotify(notify, payer).Notify, 2)
+
+	//payer is involved in all the events of the tx
+	sub = parse(map[string]interface{}{"Addresses": []interface{}{payer.ToBase58()}})
+	assert.Len(t, sub.filterExecuteNotify(notify, payer).Notify, 3)
+
+	sub = parse(map[string]interface{}{"Payers": []interface{}{payer.ToBase58()}})
+	assert.NotNil(t, sub.filterExecuteNotify(notify, payer))
+	assert.Nil(t, sub.filterExecuteNotify(notify, user))
+
+	sub = parse(map[string]interface{}{
+		"Contracts":  []interface{}{contract1.ToHexString()},
+		"EventNames": []interface{}{"approve"},
+	})
+	assert.Nil(t, sub.filterExecuteNotify(notify, payer))
+}
+
+func TestMatchPendingTx(t *testing.T) {
+	payer := common.AddressFromVmCode([]byte("payer"))
+	sub, err := parseSubscription(map[string]interface{}{
+		"Name":   "pending",
+		"Topic":  SUB_TOPIC_PENDING_TX,
+		"Payers": []interface{}{payer.ToBase58()},
+	})
+	assert.Nil(t, err)
+	assert.True(t, sub.matchPendingTx(&types.Transaction{Payer: payer}))
+	assert.False(t, sub.matchPendingTx(&types.Transaction{}))
+}
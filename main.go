@@ -19,8 +19,10 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -32,7 +34,6 @@ import (
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ontio/ontology-crypto/keypair"
 	alog "github.com/ontio/ontology-eventbus/log"
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/cmd"
 	cmdcom "github.com/ontio/ontology/cmd/common"
 	"github.com/ontio/ontology/cmd/utils"
@@ -40,13 +41,17 @@ import (
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/consensus"
+	"github.com/ontio/ontology/consensus/signer"
+	"github.com/ontio/ontology/consensus/vbft"
 	"github.com/ontio/ontology/core/genesis"
 	"github.com/ontio/ontology/core/ledger"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/events"
 	bactor "github.com/ontio/ontology/http/base/actor"
 	"github.com/ontio/ontology/http/graphql"
 	"github.com/ontio/ontology/http/jsonrpc"
 	"github.com/ontio/ontology/http/localrpc"
+	"github.com/ontio/ontology/http/metrics"
 	"github.com/ontio/ontology/http/nodeinfo"
 	"github.com/ontio/ontology/http/restful"
 	"github.com/ontio/ontology/http/websocket"
@@ -72,8 +77,11 @@ func setupAPP() *cli.App {
 		cmd.InfoCommand,
 		cmd.AssetCommand,
 		cmd.ContractCommand,
+		cmd.GovernanceCommand,
+		cmd.OntIdCommand,
 		cmd.ImportCommand,
 		cmd.ExportCommand,
+		cmd.SnapshotCommand,
 		cmd.TxCommond,
 		cmd.SigTxCommand,
 		cmd.MultiSigAddrCommand,
@@ -88,7 +96,14 @@ func setupAPP() *cli.App {
 		utils.LogDirFlag,
 		utils.DisableLogFileFlag,
 		utils.DisableEventLogFlag,
+		utils.EnableStateArchiveFlag,
+		utils.EnableAddressHistoryFlag,
+		utils.SnapshotFileFlag,
+		utils.SnapshotBlockHashFlag,
+		utils.SnapshotStateRootFlag,
+		utils.SnapshotStateDigestFlag,
 		utils.DataDirFlag,
+		utils.StoreBackendFlag,
 		utils.WasmVerifyMethodFlag,
 		//account setting
 		utils.WalletFileFlag,
@@ -97,10 +112,14 @@ func setupAPP() *cli.App {
 		//consensus setting
 		utils.EnableConsensusFlag,
 		utils.MaxTxInBlockFlag,
+		utils.RemoteSignerFlag,
+		utils.RemoteSignerTokenFlag,
 		//txpool setting
 		utils.GasPriceFlag,
 		utils.GasLimitFlag,
 		utils.TxpoolPreExecDisableFlag,
+		utils.TxpoolMaxTxInPoolFlag,
+		utils.TxpoolMaxTxPerPayerFlag,
 		utils.DisableSyncVerifyTxFlag,
 		utils.DisableBroadcastNetTxFlag,
 		//p2p setting
@@ -112,6 +131,14 @@ func setupAPP() *cli.App {
 		utils.MaxConnInBoundFlag,
 		utils.MaxConnOutBoundFlag,
 		utils.MaxConnInBoundForSingleIPFlag,
+		utils.EnableNoiseFlag,
+		utils.NodeKeyFileFlag,
+		utils.DisableCompressionFlag,
+		utils.MaxPeerRecvRateFlag,
+		utils.MaxPeerSendRateFlag,
+		utils.SyncMaxFlightHeadersFlag,
+		utils.SyncMaxFlightBlocksFlag,
+		utils.SyncMaxBlockCacheFlag,
 		//test mode setting
 		utils.EnableTestModeFlag,
 		utils.TestModeGenBlockTimeFlag,
@@ -120,6 +147,7 @@ func setupAPP() *cli.App {
 		utils.RPCPortFlag,
 		utils.RPCLocalEnableFlag,
 		utils.RPCLocalProtFlag,
+		utils.RPCStandardResponseFlag,
 		//rest setting
 		utils.RestfulEnableFlag,
 		utils.RestfulPortFlag,
@@ -131,6 +159,10 @@ func setupAPP() *cli.App {
 		//ws setting
 		utils.WsEnabledFlag,
 		utils.WsPortFlag,
+		//metrics setting
+		utils.MetricsEnableFlag,
+		utils.MetricsPortFlag,
+		utils.MetricsPathFlag,
 	}
 	app.Before = func(context *cli.Context) error {
 		runtime.GOMAXPROCS(runtime.NumCPU())
@@ -158,7 +190,7 @@ func startOntology(ctx *cli.Context) {
 		log.Errorf("initConfig error: %s", err)
 		return
 	}
-	acc, err := initAccount(ctx)
+	sig, err := initAccount(ctx)
 	if err != nil {
 		log.Errorf("initWallet error: %s", err)
 		return
@@ -174,12 +206,12 @@ func startOntology(ctx *cli.Context) {
 		log.Errorf("initTxPool error: %s", err)
 		return
 	}
-	p2pSvr, p2p, err := initP2PNode(ctx, txpool, acc)
+	p2pSvr, p2p, err := initP2PNode(ctx, txpool, sig)
 	if err != nil {
 		log.Errorf("initP2PNode error: %s", err)
 		return
 	}
-	_, err = initConsensus(ctx, p2p, txpool, acc)
+	_, err = initConsensus(ctx, p2p, txpool, sig)
 	if err != nil {
 		log.Errorf("initConsensus error: %s", err)
 		return
@@ -198,6 +230,7 @@ func startOntology(ctx *cli.Context) {
 	initRestful(ctx)
 	initWs(ctx)
 	initNodeInfo(ctx, p2pSvr)
+	initMetrics(ctx)
 
 	go logCurrBlockHeight()
 	waitToExit(ldg)
@@ -228,10 +261,17 @@ func initConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 	return cfg, nil
 }
 
-func initAccount(ctx *cli.Context) (*account.Account, error) {
+func initAccount(ctx *cli.Context) (signer.Signer, error) {
 	if !config.DefConfig.Consensus.EnableConsensus {
 		return nil, nil
 	}
+	if remoteSigner := ctx.GlobalString(utils.GetFlagName(utils.RemoteSignerFlag)); remoteSigner != "" {
+		sig, err := initRemoteSigner(ctx, remoteSigner)
+		if err != nil {
+			return nil, err
+		}
+		return sig, nil
+	}
 	walletFile := ctx.GlobalString(utils.GetFlagName(utils.WalletFileFlag))
 	if walletFile == "" {
 		return nil, fmt.Errorf("please config wallet file using --wallet flag")
@@ -252,7 +292,28 @@ func initAccount(ctx *cli.Context) (*account.Account, error) {
 	}
 
 	log.Infof("Account init success")
-	return acc, nil
+	return signer.NewLocalSigner(acc), nil
+}
+
+func initRemoteSigner(ctx *cli.Context, address string) (signer.Signer, error) {
+	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
+	if consensusType != config.CONSENSUS_TYPE_VBFT && consensusType != config.CONSENSUS_TYPE_SBFT {
+		return nil, fmt.Errorf("remote signer only supports vbft and sbft consensus")
+	}
+	tokenFile := ctx.GlobalString(utils.GetFlagName(utils.RemoteSignerTokenFlag))
+	if tokenFile == "" {
+		return nil, fmt.Errorf("please config remote signer token file using --%s flag", utils.GetFlagName(utils.RemoteSignerTokenFlag))
+	}
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("read remote signer token file error: %s", err)
+	}
+	sig, err := signer.NewRemoteSigner(address, bytes.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("init remote signer error: %s", err)
+	}
+	log.Infof("Using remote signer: %s, pubkey: %s", address, hex.EncodeToString(keypair.SerializePublicKey(sig.PubKey())))
+	return sig, nil
 }
 
 func initLedger(ctx *cli.Context, stateHashHeight uint32) (*ledger.Ledger, error) {
@@ -273,10 +334,37 @@ func initLedger(ctx *cli.Context, stateHashHeight uint32) (*ledger.Ledger, error
 	if err != nil {
 		return nil, fmt.Errorf("genesisBlock error %s", err)
 	}
+	snapshotFile := ctx.GlobalString(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile != "" {
+		blockHash := ctx.GlobalString(utils.GetFlagName(utils.SnapshotBlockHashFlag))
+		stateRoot := ctx.GlobalString(utils.GetFlagName(utils.SnapshotStateRootFlag))
+		stateDigest := ctx.GlobalString(utils.GetFlagName(utils.SnapshotStateDigestFlag))
+		info, err := cmd.ImportSnapshotFile(ledger.DefLedger, snapshotFile, genesisBlock, blockHash, stateRoot,
+			stateDigest)
+		if err == scom.ErrAlreadyInitialized {
+			log.Infof("Ledger has already been initialized, snapshot %s is ignored", snapshotFile)
+		} else if err != nil {
+			return nil, fmt.Errorf("import snapshot error: %s", err)
+		} else {
+			log.Infof("Ledger starts from snapshot at height %d", info.Height)
+		}
+	}
 	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
 	if err != nil {
 		return nil, fmt.Errorf("init ledger error: %s", err)
 	}
+	if config.DefConfig.Common.StateArchive {
+		err = ledger.DefLedger.EnableStateArchive()
+		if err != nil {
+			return nil, fmt.Errorf("enable state archive error: %s", err)
+		}
+	}
+	if config.DefConfig.Common.AddressHistory {
+		err = ledger.DefLedger.EnableAddressHistory()
+		if err != nil {
+			return nil, fmt.Errorf("enable address history error: %s", err)
+		}
+	}
 
 	log.Infof("Ledger init success")
 	return ledger.DefLedger, nil
@@ -304,11 +392,11 @@ func initTxPool(ctx *cli.Context) (*proc.TXPoolServer, error) {
 	return txPoolServer, nil
 }
 
-func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, acct *account.Account) (*p2pserver.P2PServer, p2p.P2P, error) {
+func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, sig signer.Signer) (*p2pserver.P2PServer, p2p.P2P, error) {
 	if config.DefConfig.Genesis.ConsensusType == config.CONSENSUS_TYPE_SOLO {
 		return nil, nil, nil
 	}
-	p2p, err := p2pserver.NewServer(acct)
+	p2p, err := p2pserver.NewServer(sig)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -320,19 +408,21 @@ func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, acct *account.A
 	netreqactor.SetTxnPoolPid(txpoolSvr.GetPID(tc.TxActor))
 	txpoolSvr.Net = p2p.GetNetwork()
 	bactor.SetNetServer(p2p.GetNetwork())
+	bactor.SetSyncStatusGetter(p2p.GetSyncStatus)
+	bactor.SetReputationService(p2p.GetReputation())
 	p2p.WaitForPeersStart()
 	log.Infof("P2P init success")
 	return p2p, p2p.GetNetwork(), nil
 }
 
-func initConsensus(ctx *cli.Context, net p2p.P2P, txpoolSvr *proc.TXPoolServer, acc *account.Account) (consensus.ConsensusService, error) {
+func initConsensus(ctx *cli.Context, net p2p.P2P, txpoolSvr *proc.TXPoolServer, sig signer.Signer) (consensus.ConsensusService, error) {
 	if !config.DefConfig.Consensus.EnableConsensus {
 		return nil, nil
 	}
 	pool := txpoolSvr.GetPID(tc.TxPoolActor)
 
 	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
-	consensusService, err := consensus.NewConsensusService(consensusType, acc, pool, nil, net)
+	consensusService, err := consensus.NewConsensusService(consensusType, sig, pool, nil, net)
 	if err != nil {
 		return nil, fmt.Errorf("NewConsensusService %s error: %s", consensusType, err)
 	}
@@ -340,6 +430,13 @@ func initConsensus(ctx *cli.Context, net p2p.P2P, txpoolSvr *proc.TXPoolServer,
 
 	netreqactor.SetConsensusPid(consensusService.GetPID())
 	bactor.SetConsensusPid(consensusService.GetPID())
+	if server, ok := consensusService.(*vbft.Server); ok {
+		bactor.SetConsensusMonitor(func() interface{} {
+			return server.GetConsensusState()
+		}, func(blockNum uint32) (interface{}, error) {
+			return server.GetConsensusHistory(blockNum)
+		})
+	}
 
 	log.Infof("Consensus init success")
 	return consensusService, nil
@@ -421,6 +518,15 @@ func initWs(ctx *cli.Context) {
 	log.Infof("Ws init success")
 }
 
+func initMetrics(ctx *cli.Context) {
+	if !config.DefConfig.Metrics.EnableMetrics {
+		return
+	}
+	go metrics.StartServer(config.DefConfig.Metrics)
+
+	log.Infof("Metrics init success")
+}
+
 func initNodeInfo(ctx *cli.Context, p2pSvr *p2pserver.P2PServer) {
 	// testmode has no p2pserver(see function initP2PNode for detail), simply ignore httpInfoPort in testmode
 	if ctx.Bool(utils.GetFlagName(utils.EnableTestModeFlag)) || config.DefConfig.P2PNode.HttpInfoPort == 0 {
@@ -23,15 +23,22 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"math/big"
 	"math/bits"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/types"
 )
 
@@ -67,10 +74,29 @@ func (self *PeerId) ToHexString() string {
 	return self.val.ToHexString()
 }
 
+//PeerIdFromHexString parse peer id from the output of ToHexString
+func PeerIdFromHexString(s string) (PeerId, error) {
+	val, err := common.AddressFromHexString(s)
+	if err != nil {
+		return PeerId{}, err
+	}
+	return PeerId{val: val}, nil
+}
+
 type PeerKeyId struct {
 	PublicKey keypair.PublicKey
 
 	Id PeerId
+
+	account *account.Account // node key, only available for local node
+}
+
+//Sign data with node key, used to authenticate the node in noise handshake
+func (this *PeerKeyId) Sign(data []byte) ([]byte, error) {
+	if this.account == nil {
+		return nil, errors.New("node key is not available")
+	}
+	return signature.Sign(this.account, data)
 }
 
 func (self PeerId) GenRandPeerId(prefix uint) PeerId {
@@ -167,6 +193,22 @@ func peerIdFromPubkey(pubKey keypair.PublicKey) PeerId {
 	return PeerId{val: types.AddressFromPubKey(pubKey)}
 }
 
+//ParsePeerId return the peer id of node public key in hex
+func ParsePeerId(pubKeyHex string) (PeerId, error) {
+	data, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return PeerId{}, err
+	}
+	pub, err := keypair.DeserializePublicKey(data)
+	if err != nil {
+		return PeerId{}, err
+	}
+	if !validatePublicKey(pub) {
+		return PeerId{}, errors.New("invalid kad public key")
+	}
+	return peerIdFromPubkey(pub), nil
+}
+
 func RandPeerKeyId() *PeerKeyId {
 	var acc *account.Account
 	for {
@@ -175,11 +217,55 @@ func RandPeerKeyId() *PeerKeyId {
 			break
 		}
 	}
-	kid := peerIdFromPubkey(acc.PublicKey)
+	return newPeerKeyId(acc)
+}
+
+func newPeerKeyId(acc *account.Account) *PeerKeyId {
 	return &PeerKeyId{
 		PublicKey: acc.PublicKey,
-		Id:        kid,
+		Id:        peerIdFromPubkey(acc.PublicKey),
+		account:   acc,
+	}
+}
+
+//LoadOrCreatePeerKeyId load node key from file, or create a new one and save it if file not exist.
+//The node key is saved in hex, peer id keeps the same after restart.
+func LoadOrCreatePeerKeyId(path string) (*PeerKeyId, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		keyId := RandPeerKeyId()
+		pri := keypair.SerializePrivateKey(keyId.account.PrivateKey)
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(hex.EncodeToString(pri)), 0600)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("save node key error: %s", err)
+		}
+		return keyId, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	buf, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid node key file %s: %s", path, err)
+	}
+	pri, err := keypair.DeserializePrivateKey(buf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node key file %s: %s", path, err)
+	}
+	pub := pri.Public()
+	if !validatePublicKey(pub) {
+		return nil, errors.New("invalid kad public key")
+	}
+	acc := &account.Account{
+		PrivateKey: pri,
+		PublicKey:  pub,
+		Address:    types.AddressFromPubKey(pub),
+		SigScheme:  account.DefaultSigScheme(keypair.GetKeyType(pub)),
 	}
+	return newPeerKeyId(acc), nil
 }
 
 func validatePublicKey(pubKey keypair.PublicKey) bool {
@@ -19,11 +19,18 @@
 package common
 
 import (
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
+	s "github.com/ontio/ontology-crypto/signature"
+	"github.com/ontio/ontology/core/signature"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,3 +59,48 @@ func TestKadId_IsEmpty(t *testing.T) {
 	kid := RandPeerKeyId()
 	assert.False(t, kid.Id.IsEmpty())
 }
+
+func TestLoadOrCreatePeerKeyId(t *testing.T) {
+	defer func(difficulty int) { Difficulty = difficulty }(Difficulty)
+	Difficulty = 1
+	dir, err := ioutil.TempDir("", "nodekey")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	// the directory is created if not exist
+	path := filepath.Join(dir, "testnet", "nodekey")
+
+	created, err := LoadOrCreatePeerKeyId(path)
+	assert.Nil(t, err)
+	loaded, err := LoadOrCreatePeerKeyId(path)
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, loaded.Id)
+
+	sig, err := loaded.Sign([]byte("data"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, sig)
+
+	id, err := ParsePeerId(hex.EncodeToString(keypair.SerializePublicKey(created.PublicKey)))
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, id)
+	_, err = ParsePeerId("zz")
+	assert.NotNil(t, err)
+
+	// the signature scheme follows the key type
+	var pri keypair.PrivateKey
+	for {
+		var pub keypair.PublicKey
+		pri, pub, err = keypair.GenerateKeyPair(keypair.PK_SM2, keypair.SM2P256V1)
+		assert.Nil(t, err)
+		if validatePublicKey(pub) {
+			break
+		}
+	}
+	sm2Path := filepath.Join(dir, "sm2key")
+	assert.Nil(t, ioutil.WriteFile(sm2Path, []byte(hex.EncodeToString(keypair.SerializePrivateKey(pri))), 0600))
+	sm2, err := LoadOrCreatePeerKeyId(sm2Path)
+	assert.Nil(t, err)
+	assert.Equal(t, s.SM3withSM2, sm2.account.SigScheme)
+	sig, err = sm2.Sign([]byte("data"))
+	assert.Nil(t, err)
+	assert.Nil(t, signature.Verify(sm2.PublicKey, []byte("data"), sig))
+}
@@ -29,6 +29,7 @@ const (
 )
 
 const MIN_VERSION_FOR_DHT = "1.9.1-beta"
+const MIN_VERSION_FOR_SKELETON = "2.2.0-0"
 
 //link and concurrent const
 const (
@@ -39,16 +40,38 @@ const (
 	MAX_REQ_RECORD_SIZE = 1000       //the maximum request record size
 	MAX_RESP_CACHE_SIZE = 50         //the maximum response cache
 	MAX_TX_CACHE_SIZE   = 100000     //the maximum txHash cache size
+	COMPRESS_THRESHOLD  = 1024       //min payload len in byte to compress
 )
 
+//rate limit const
+const (
+	RATE_LIMIT_BURST          = 2    //burst of bandwidth limit in second
+	RATE_LIMIT_MAX_DELAY      = 30   //max throttle delay in second, peer exceeds it is disconnected
+	SEND_QUEUE_SIZE           = 1024 //max msgs waiting to be sent to a peer, msgs exceeding it are dropped
+	RATE_LIMIT_MAX_VIOLATIONS = 100  //max dropped messages per minute of a peer, peer exceeds it is disconnected
+)
+
+//MSG_RATE_LIMITS is the max messages per second of each request type from a peer, exceeded messages are dropped
+var MSG_RATE_LIMITS = map[string]float64{
+	GetADDR_TYPE:            1,
+	PING_TYPE:               10,
+	GET_HEADERS_TYPE:        20,
+	GET_DATA_TYPE:           1000,
+	GET_BLOCKS_TYPE:         20,
+	FINDNODE_TYPE:           20,
+	GET_SUBNET_MEMBERS_TYPE: 2,
+	GET_SKELETON_TYPE:       5,
+}
+
 //msg cmd const
 const (
-	MSG_CMD_LEN     = 12               //msg type length in byte
-	CHECKSUM_LEN    = 4                //checksum length in byte
-	MSG_HDR_LEN     = 24               //msg hdr length in byte
-	MAX_BLK_HDR_CNT = 500              //hdr count once when sync header
-	MAX_MSG_LEN     = 30 * 1024 * 1024 //the maximum message length
-	MAX_PAYLOAD_LEN = MAX_MSG_LEN - MSG_HDR_LEN
+	MSG_CMD_LEN      = 12               //msg type length in byte
+	CHECKSUM_LEN     = 4                //checksum length in byte
+	MSG_HDR_LEN      = 24               //msg hdr length in byte
+	MAX_BLK_HDR_CNT  = 500              //hdr count once when sync header
+	MAX_SKELETON_CNT = 64               //skeleton hdr count once when sync header
+	MAX_MSG_LEN      = 30 * 1024 * 1024 //the maximum message length
+	MAX_PAYLOAD_LEN  = MAX_MSG_LEN - MSG_HDR_LEN
 )
 
 //msg type const
@@ -76,6 +99,7 @@ const (
 
 //cap flag
 const HTTP_INFO_FLAG = 0 //peer`s http info bit in cap field
+const COMPRESS_FLAG = 1  //peer`s snappy compression bit in cap field
 
 //recent contact const
 const (
@@ -83,6 +107,17 @@ const (
 	RECENT_FILE_NAME = "peers.recent"
 )
 
+//peer reputation const
+const (
+	BAN_FILE_NAME        = "peers.ban"
+	BAN_SCORE            = 100   //peer reaches the misbehavior score is banned
+	BAN_SCORE_HALF_LIFE  = 600   //misbehavior score halves every 10 minutes in second
+	BAN_DURATION         = 3600  //duration of the first ban in second, doubles for every later ban of the same ip
+	MAX_TEMP_BAN_TIMES   = 3     //peer banned more times is banned persistently
+	BAN_FORGET_TIME      = 86400 //expired ban is forgotten after 1 day in second, later ban starts from the first
+	BAN_CLEAN_UP_TIMEOUT = 60    //interval to clean up expired bans in second
+)
+
 //PeerAddr represent peer`s net information
 type PeerAddr struct {
 	Time     int64    //latest timestamp
@@ -118,6 +153,9 @@ const (
 	GET_SUBNET_MEMBERS_TYPE = "getmembers" // request subnet members
 	SUBNET_MEMBERS_TYPE     = "members"    // response subnet members
 	SUBNET_OFFLINE_TYPE     = "offline"    // offline witness message
+
+	GET_SKELETON_TYPE = "getskeleton" // req skeleton blk hdrs at fixed interval
+	COMPRESSED_TYPE   = "compressed"  // snappy compressed msg
 )
 
 //ParseIPAddr return ip address
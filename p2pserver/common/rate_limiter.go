@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+//RateLimiter is a token bucket, tokens can be borrowed and the debt is paid by waiting
+type RateLimiter struct {
+	lock   sync.Mutex
+	rate   float64 //tokens per second
+	burst  float64 //max tokens
+	tokens float64
+	last   time.Time
+}
+
+//NewRateLimiter return a full token bucket
+func NewRateLimiter(rate, burst float64) *RateLimiter {
+	return &RateLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+func (this *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(this.last).Seconds()
+	if elapsed > 0 {
+		this.tokens += elapsed * this.rate
+		if this.tokens > this.burst {
+			this.tokens = this.burst
+		}
+	}
+	this.last = now
+}
+
+//Allow take n tokens if available
+func (this *RateLimiter) Allow(n float64) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.refill(time.Now())
+	if this.tokens < n {
+		return false
+	}
+	this.tokens -= n
+	return true
+}
+
+//Reserve take n tokens and return the time to wait until the debt is paid. If the wait exceeds maxWait, no token
+//is taken and false is returned
+func (this *RateLimiter) Reserve(n float64, maxWait time.Duration) (time.Duration, bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.refill(time.Now())
+	left := this.tokens - n
+	var wait time.Duration
+	if left < 0 {
+		wait = time.Duration(-left / this.rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+	this.tokens = left
+	return wait, true
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100, 200)
+	assert.True(t, limiter.Allow(150))
+	assert.False(t, limiter.Allow(100))
+	wait, ok := limiter.Reserve(40, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = limiter.Reserve(200, time.Second)
+	assert.False(t, ok)
+	wait, ok = limiter.Reserve(100, time.Second)
+	assert.True(t, ok)
+	assert.True(t, wait > 800*time.Millisecond && wait <= time.Second, wait)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, limiter.Allow(1))
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/p2pserver/handshake"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
 	"github.com/ontio/ontology/p2pserver/peer"
 	"github.com/scylladb/go-set/strset"
 )
@@ -182,8 +183,9 @@ func (self *ConnectController) AcceptConnect(conn net.Conn) (*peer.PeerInfo, net
 		return nil, nil, err
 	}
 
-	peerInfo, err := handshake.HandshakeServer(self.peerInfo, self.selfId, conn)
+	peerInfo, conn, err := self.handshakeServer(conn)
 	if err != nil {
+		self.reportHandshakeFailure(addr, err)
 		return nil, nil, err
 	}
 
@@ -216,11 +218,12 @@ func (self *ConnectController) Connect(addr string) (*peer.PeerInfo, net.Conn, e
 		return nil, nil, err
 	}
 
-	peerInfo, err := handshake.HandshakeClient(self.peerInfo, self.selfId, conn)
+	peerInfo, secure, err := self.handshakeClient(conn)
 	if err != nil {
 		_ = conn.Close()
 		return nil, nil, err
 	}
+	conn = secure
 
 	err = self.afterHandshakeCheck(peerInfo, conn.RemoteAddr().String())
 	if err != nil {
@@ -234,19 +237,82 @@ func (self *ConnectController) Connect(addr string) (*peer.PeerInfo, net.Conn, e
 	return peerInfo, wrapped, nil
 }
 
+func (self *ConnectController) handshakeClient(conn net.Conn) (*peer.PeerInfo, net.Conn, error) {
+	if self.Noise {
+		return handshake.HandshakeNoiseClient(self.peerInfo, self.selfId, conn)
+	}
+	peerInfo, err := handshake.HandshakeClient(self.peerInfo, self.selfId, conn)
+	return peerInfo, conn, err
+}
+
+func (self *ConnectController) handshakeServer(conn net.Conn) (*peer.PeerInfo, net.Conn, error) {
+	if self.Noise {
+		return handshake.HandshakeNoiseServer(self.peerInfo, self.selfId, conn)
+	}
+	peerInfo, err := handshake.HandshakeServer(self.peerInfo, self.selfId, conn)
+	return peerInfo, conn, err
+}
+
+//reportHandshakeFailure score the inbound handshake failure proving the remote peer misbehaved, timeouts and
+//mismatched versions are not counted since honest peers fail by them too
+func (self *ConnectController) reportHandshakeFailure(addr string, err error) {
+	if _, ok := err.(*handshake.ViolationError); !ok || self.Reputation == nil {
+		return
+	}
+	self.Reputation.Report(common.PeerId{}, addr, p2p.HandshakeFailure, err.Error())
+}
+
+//ReportMisbehavior feed the misbehavior of peer to reputation
+func (self *ConnectController) ReportMisbehavior(id common.PeerId, kind p2p.Misbehavior, detail string) {
+	if self.Reputation == nil {
+		return
+	}
+	p := self.getPeer(id)
+	if p == nil {
+		self.logger.Debugf("[p2p]report misbehavior %s of disconnected peer %d", kind, id)
+		return
+	}
+	self.Reputation.Report(id, p.addr, kind, detail)
+}
+
+func (self *ConnectController) checkBanned(id common.PeerId, addr string) error {
+	if self.Reputation != nil && self.Reputation.IsBanned(id, addr) {
+		return fmt.Errorf("the remote peer: %s is banned", addr)
+	}
+
+	return nil
+}
+
 func (self *ConnectController) afterHandshakeCheck(remotePeer *peer.PeerInfo, remoteAddr string) error {
 	if err := self.isHandWithSelf(remotePeer, remoteAddr); err != nil {
 		return err
 	}
+	if err := self.checkBanned(remotePeer.Id, remoteAddr); err != nil {
+		return err
+	}
+	if err := self.checkReservedKeys(remotePeer); err != nil {
+		return err
+	}
 
 	return self.checkPeerIdAndIP(remotePeer, remoteAddr)
 }
 
+func (self *ConnectController) checkReservedKeys(remotePeer *peer.PeerInfo) error {
+	if len(self.ReservedKeys) == 0 || self.ReservedKeys[remotePeer.Id] {
+		return nil
+	}
+
+	return fmt.Errorf("the remote peer: %s not in reserved keys", remotePeer.Id.ToHexString())
+}
+
 func (self *ConnectController) beforeHandshakeCheck(addr string, index int) error {
 	err := self.checkReservedPeers(addr)
 	if err != nil {
 		return err
 	}
+	if err := self.checkBanned(common.PeerId{}, addr); err != nil {
+		return err
+	}
 
 	if self.hasBoundAddr(addr) {
 		return fmt.Errorf("peer %s already in connection records", addr)
@@ -302,6 +368,7 @@ func (self *ConnectController) savePeer(conn net.Conn, p *peer.PeerInfo, index i
 
 	addr := conn.RemoteAddr().String()
 	self.inoutbounds[index].Add(addr)
+	peersMetric.WithLabelValues(boundName(index)).Set(float64(self.inoutbounds[index].Size()))
 	listen := p.RemoteListenAddress()
 	if index == INBOUND_INDEX {
 		self.inboundListenAddress.Add(listen)
@@ -330,6 +397,7 @@ func (self *ConnectController) removePeer(conn *Conn) {
 	defer self.mutex.Unlock()
 
 	self.inoutbounds[conn.boundIndex].Remove(conn.addr)
+	peersMetric.WithLabelValues(boundName(conn.boundIndex)).Set(float64(self.inoutbounds[conn.boundIndex].Size()))
 	if conn.boundIndex == INBOUND_INDEX {
 		self.inboundListenAddress.Remove(conn.listenAddr)
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/p2pserver/handshake"
 	"github.com/ontio/ontology/p2pserver/peer"
+	"github.com/ontio/ontology/p2pserver/protocols/reputation"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -229,6 +230,46 @@ func checkServer(t *testing.T, client, server *Node, clientConns chan<- net.Conn
 	clientConns <- conn
 }
 
+func TestConnCtrlOption_Reputation(t *testing.T) {
+	trans := NewTransport(t)
+	rep := reputation.NewReputationService("", true, nil)
+	server := NewNode(NewConnCtrlOption().WithReputation(rep))
+	client := NewNode(NewConnCtrlOption())
+
+	assert.Nil(t, rep.Ban("127.0.0.1", time.Hour, "test"))
+	conn1, conn2 := trans.Pipe()
+	go func() {
+		_, _ = handshake.HandshakeClient(client.peerInfo, client.Key, conn1)
+	}()
+	_, _, err := server.AcceptConnect(conn2)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "banned")
+	_ = conn1.Close()
+
+	assert.Nil(t, rep.Unban("127.0.0.1"))
+	conn1, conn2 = trans.Pipe()
+	done := make(chan error)
+	go func() {
+		_, err := handshake.HandshakeClient(client.peerInfo, client.Key, conn1)
+		done <- err
+	}()
+	_, conn, err := server.AcceptConnect(conn2)
+	assert.Nil(t, err)
+	assert.Nil(t, <-done)
+	_ = conn.Close()
+	_ = conn1.Close()
+
+	// only the handshake failures proving misbehavior are scored
+	for i := 0; i < 20; i++ {
+		server.reportHandshakeFailure("127.0.0.2:20338", fmt.Errorf("i/o timeout"))
+	}
+	assert.False(t, rep.IsBanned(common.PeerId{}, "127.0.0.2:20338"))
+	for i := 0; i < 20; i++ {
+		server.reportHandshakeFailure("127.0.0.2:20338", &handshake.ViolationError{Reason: "impersonation"})
+	}
+	assert.True(t, rep.IsBanned(common.PeerId{}, "127.0.0.2:20338"))
+}
+
 func TestCheckReserveWithDomain(t *testing.T) {
 	a := assert.New(t)
 	// this domain only have one A record, so we can assure two lookup below return the same IP
@@ -18,7 +18,11 @@
 package connect_controller
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/p2pserver/common"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
 )
 
@@ -26,7 +30,10 @@ type ConnCtrlOption struct {
 	MaxConnOutBound     uint
 	MaxConnInBound      uint
 	MaxConnInBoundPerIP uint
-	ReservedPeers       p2p.AddressFilter // enabled if not empty
+	ReservedPeers       p2p.AddressFilter      // enabled if not empty
+	Noise               bool                   // encrypt connection and authenticate peer by node key
+	ReservedKeys        map[common.PeerId]bool // peer ids of node keys allowed to connect, enabled if not empty, requires Noise
+	Reputation          p2p.PeerReputation     // reject banned peers and report handshake failures, disabled if nil
 	dialer              Dialer
 }
 
@@ -60,6 +67,24 @@ func (self ConnCtrlOption) ReservedOnly(peers []string) ConnCtrlOption {
 	return self
 }
 
+func (self ConnCtrlOption) WithNoise() ConnCtrlOption {
+	self.Noise = true
+	return self
+}
+
+func (self ConnCtrlOption) ReservedKeysOnly(ids []common.PeerId) ConnCtrlOption {
+	self.ReservedKeys = make(map[common.PeerId]bool, len(ids))
+	for _, id := range ids {
+		self.ReservedKeys[id] = true
+	}
+	return self
+}
+
+func (self ConnCtrlOption) WithReputation(reputation p2p.PeerReputation) ConnCtrlOption {
+	self.Reputation = reputation
+	return self
+}
+
 func (self ConnCtrlOption) WithDialer(dialer Dialer) ConnCtrlOption {
 	self.dialer = dialer
 	return self
@@ -71,12 +96,37 @@ func ConnCtrlOptionFromConfig(config *config.P2PNodeConfig, reserveFilter p2p.Ad
 		err = e
 		return
 	}
+	reservedKeys, e := reservedKeysFromConfig(config)
+	if e != nil {
+		err = e
+		return
+	}
 	return ConnCtrlOption{
 		MaxConnOutBound:     config.MaxConnOutBound,
 		MaxConnInBound:      config.MaxConnInBound,
 		MaxConnInBoundPerIP: config.MaxConnInBoundForSingleIP,
 		ReservedPeers:       reserveFilter,
+		Noise:               config.EnableNoise,
+		ReservedKeys:        reservedKeys,
 
 		dialer: dialer,
 	}, nil
 }
+
+func reservedKeysFromConfig(config *config.P2PNodeConfig) (map[common.PeerId]bool, error) {
+	if !config.ReservedPeersOnly || config.ReservedCfg == nil || len(config.ReservedCfg.ReservedPeerKeys) == 0 {
+		return nil, nil
+	}
+	if !config.EnableNoise {
+		return nil, errors.New("[p2p]reserved peer keys require noise handshake enabled")
+	}
+	keys := make(map[common.PeerId]bool, len(config.ReservedCfg.ReservedPeerKeys))
+	for _, pubKey := range config.ReservedCfg.ReservedPeerKeys {
+		id, err := common.ParsePeerId(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("[p2p]invalid reserved peer key %s: %s", pubKey, err)
+		}
+		keys[id] = true
+	}
+	return keys, nil
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connect_controller
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var peersMetric = prom.NewGaugeVec(prom.GaugeOpts{
+	Name: "ontology_p2p_peers",
+	Help: "ontology p2p count of the connected peers per direction",
+}, []string{"direction"})
+
+func init() {
+	prom.MustRegister(peersMetric)
+}
+
+func boundName(index int) string {
+	if index == INBOUND_INDEX {
+		return "inbound"
+	}
+	return "outbound"
+}
@@ -92,7 +92,7 @@ func HandshakeClient(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Con
 		return nil, fmt.Errorf("handshake failed, expect verack message, got %s", msg.CmdType())
 	}
 
-	return createPeerInfo(receivedVersion, kid, conn.RemoteAddr().String()), nil
+	return createPeerInfo(info, receivedVersion, kid, conn.RemoteAddr().String()), nil
 }
 
 func HandshakeServer(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Conn) (*peer.PeerInfo, error) {
@@ -154,7 +154,7 @@ func HandshakeServer(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Con
 		return nil, err
 	}
 
-	return createPeerInfo(version, kid, conn.RemoteAddr().String()), nil
+	return createPeerInfo(info, version, kid, conn.RemoteAddr().String()), nil
 }
 
 func sendMsg(conn net.Conn, msg types.Message) error {
@@ -168,9 +168,12 @@ func sendMsg(conn net.Conn, msg types.Message) error {
 	return nil
 }
 
-func createPeerInfo(version *types.Version, kid common.PeerId, addr string) *peer.PeerInfo {
-	return peer.NewPeerInfo(kid, version.P.Version, version.P.Services, version.P.Relay != 0, version.P.HttpInfoPort,
+func createPeerInfo(local *peer.PeerInfo, version *types.Version, kid common.PeerId, addr string) *peer.PeerInfo {
+	info := peer.NewPeerInfo(kid, version.P.Version, version.P.Services, version.P.Relay != 0, version.P.HttpInfoPort,
 		version.P.SyncPort, version.P.StartHeight, version.P.SoftVersion, addr)
+	// compress only if both sides support it
+	info.Compress = local.Compress && version.P.Cap[common.COMPRESS_FLAG] == 0x01
+	return info
 }
 
 func newVersion(peerInfo *peer.PeerInfo) *types.Version {
@@ -197,6 +200,9 @@ func newVersion(peerInfo *peer.PeerInfo) *types.Version {
 	} else {
 		version.P.Cap[common.HTTP_INFO_FLAG] = 0x00
 	}
+	if peerInfo.Compress {
+		version.P.Cap[common.COMPRESS_FLAG] = 0x01
+	}
 
 	return &version
 }
@@ -132,3 +132,19 @@ func TestVersion(t *testing.T) {
 	assert.False(t, supportDHT("1.8.0-beta-9-geeaeewwf"))
 	assert.False(t, supportDHT("1.8.0"))
 }
+
+func TestCompressNegotiation(t *testing.T) {
+	local := &peer.PeerInfo{Compress: true}
+	remote := &peer.PeerInfo{Compress: true}
+	info := createPeerInfo(local, newVersion(remote), remote.Id, "")
+	assert.True(t, info.Compress)
+
+	remote.Compress = false
+	info = createPeerInfo(local, newVersion(remote), remote.Id, "")
+	assert.False(t, info.Compress)
+
+	local.Compress = false
+	remote.Compress = true
+	info = createPeerInfo(local, newVersion(remote), remote.Id, "")
+	assert.False(t, info.Compress)
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handshake
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	common2 "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/peer"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// noise-style handshake: initiator and responder exchange ephemeral x25519 keys in order, derive directional
+// keys from DH(e_i, e_r) and switch to encrypted frames. Then initiator and responder send node public key and
+// sign(h | role) in order, h is the hash of protocol name and both ephemeral keys, so the signature binds the
+// node key to this session.
+const NOISE_PROTOCOL_NAME = "ontology-p2p-noise-x25519-chachapoly-sha256"
+
+const (
+	NOISE_KEY_SIZE      = 32
+	NOISE_TAG_SIZE      = 16                               // poly1305 authentication tag
+	NOISE_MAX_FRAME     = 65535                            // max cipher text in a frame
+	NOISE_MAX_PLAINTEXT = NOISE_MAX_FRAME - NOISE_TAG_SIZE // max plain text in a frame
+
+	noiseInitiator = "initiator"
+	noiseResponder = "responder"
+)
+
+//NoiseClient run noise handshake as initiator, return the encrypted connection and authenticated remote node key
+func NoiseClient(selfId *common.PeerKeyId, conn net.Conn) (net.Conn, *common.PeerKeyId, error) {
+	return noiseHandshake(selfId, conn, true)
+}
+
+//NoiseServer run noise handshake as responder, return the encrypted connection and authenticated remote node key
+func NoiseServer(selfId *common.PeerKeyId, conn net.Conn) (net.Conn, *common.PeerKeyId, error) {
+	return noiseHandshake(selfId, conn, false)
+}
+
+//HandshakeNoiseClient run noise handshake, then version handshake over the encrypted connection. Peer id is bound
+//to the authenticated node key
+func HandshakeNoiseClient(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Conn) (*peer.PeerInfo, net.Conn, error) {
+	secure, remoteId, err := NoiseClient(selfId, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	peerInfo, err := HandshakeClient(info, selfId, secure)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = bindPeerId(peerInfo, remoteId); err != nil {
+		return nil, nil, err
+	}
+	return peerInfo, secure, nil
+}
+
+//HandshakeNoiseServer run noise handshake, then version handshake over the encrypted connection. Peer id is bound
+//to the authenticated node key
+func HandshakeNoiseServer(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Conn) (*peer.PeerInfo, net.Conn, error) {
+	secure, remoteId, err := NoiseServer(selfId, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	peerInfo, err := HandshakeServer(info, selfId, secure)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = bindPeerId(peerInfo, remoteId); err != nil {
+		return nil, nil, err
+	}
+	return peerInfo, secure, nil
+}
+
+//ViolationError is a handshake failure proving the remote peer misbehaved, rather than a network failure or an
+//incompatible version or protocol
+type ViolationError struct {
+	Reason string
+}
+
+func (self *ViolationError) Error() string {
+	return self.Reason
+}
+
+//bindPeerId replace peer id with the id of authenticated node key, a different kad id means impersonation
+func bindPeerId(peerInfo *peer.PeerInfo, remoteId *common.PeerKeyId) error {
+	if !peerInfo.Id.IsPseudoPeerId() && peerInfo.Id != remoteId.Id {
+		return &ViolationError{Reason: fmt.Sprintf("[noise] peer id %s does not match node key %s",
+			peerInfo.Id.ToHexString(), remoteId.Id.ToHexString())}
+	}
+	peerInfo.Id = remoteId.Id
+	return nil
+}
+
+func noiseHandshake(selfId *common.PeerKeyId, conn net.Conn, initiator bool) (net.Conn, *common.PeerKeyId, error) {
+	if err := conn.SetDeadline(time.Now().Add(HANDSHAKE_DURATION)); err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = conn.SetDeadline(time.Time{}) //reset back
+	}()
+
+	var ePri, ePub, eRemote [NOISE_KEY_SIZE]byte
+	if _, err := io.ReadFull(rand.Reader, ePri[:]); err != nil {
+		return nil, nil, err
+	}
+	curve25519.ScalarBaseMult(&ePub, &ePri)
+
+	// 1, 2. exchange ephemeral keys
+	if initiator {
+		if _, err := conn.Write(ePub[:]); err != nil {
+			return nil, nil, fmt.Errorf("[noise] send ephemeral key error: %s", err)
+		}
+	}
+	if _, err := io.ReadFull(conn, eRemote[:]); err != nil {
+		return nil, nil, fmt.Errorf("[noise] read ephemeral key error: %s", err)
+	}
+	if !initiator {
+		if _, err := conn.Write(ePub[:]); err != nil {
+			return nil, nil, fmt.Errorf("[noise] send ephemeral key error: %s", err)
+		}
+	}
+
+	var shared [NOISE_KEY_SIZE]byte
+	curve25519.ScalarMult(&shared, &ePri, &eRemote)
+	if shared == [NOISE_KEY_SIZE]byte{} {
+		// a low order point never generated by an honest peer
+		return nil, nil, &ViolationError{Reason: "[noise] invalid ephemeral key"}
+	}
+	eInit, eResp := ePub, eRemote
+	if !initiator {
+		eInit, eResp = eRemote, ePub
+	}
+	h := noiseTranscriptHash(eInit[:], eResp[:])
+	secure, err := newSecureConn(conn, shared[:], h, initiator)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 3, 4. exchange node keys
+	localRole, remoteRole := noiseInitiator, noiseResponder
+	if !initiator {
+		localRole, remoteRole = noiseResponder, noiseInitiator
+	}
+	if initiator {
+		if err := sendNoiseIdentity(secure, selfId, h, localRole); err != nil {
+			return nil, nil, err
+		}
+	}
+	remoteId, err := readNoiseIdentity(secure, h, remoteRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !initiator {
+		if err := sendNoiseIdentity(secure, selfId, h, localRole); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return secure, remoteId, nil
+}
+
+func noiseTranscriptHash(eInit, eResp []byte) []byte {
+	hasher := sha256.New()
+	hasher.Write([]byte(NOISE_PROTOCOL_NAME))
+	hasher.Write(eInit)
+	hasher.Write(eResp)
+	return hasher.Sum(nil)
+}
+
+func noiseSignData(h []byte, role string) []byte {
+	return append(append([]byte{}, h...), role...)
+}
+
+func sendNoiseIdentity(conn net.Conn, selfId *common.PeerKeyId, h []byte, role string) error {
+	sig, err := selfId.Sign(noiseSignData(h, role))
+	if err != nil {
+		return err
+	}
+	sink := common2.NewZeroCopySink(nil)
+	selfId.Serialization(sink)
+	sink.WriteVarBytes(sig)
+	if _, err := conn.Write(sink.Bytes()); err != nil {
+		return fmt.Errorf("[noise] send node key error: %s", err)
+	}
+	return nil
+}
+
+func readNoiseIdentity(conn *secureConn, h []byte, role string) (*common.PeerKeyId, error) {
+	frame, err := conn.readFrame()
+	if err != nil {
+		return nil, fmt.Errorf("[noise] read node key error: %s", err)
+	}
+	source := common2.NewZeroCopySource(frame)
+	remoteId := &common.PeerKeyId{}
+	if err := remoteId.Deserialization(source); err != nil {
+		return nil, fmt.Errorf("[noise] invalid node key: %s", err)
+	}
+	sig, _, irregular, eof := source.NextVarBytes()
+	if irregular || eof {
+		return nil, errors.New("[noise] invalid node key signature")
+	}
+	if err := signature.Verify(remoteId.PublicKey, noiseSignData(h, role), sig); err != nil {
+		return nil, fmt.Errorf("[noise] authenticate node %s failed: %s", remoteId.Id.ToHexString(), err)
+	}
+	return remoteId, nil
+}
+
+//secureConn encrypt each frame with chacha20-poly1305, frame format: uint16 cipher text length | cipher text
+type secureConn struct {
+	net.Conn
+	sendCipher cipher.AEAD
+	recvCipher cipher.AEAD
+	sendNonce  uint64
+	recvNonce  uint64
+	readBuf    []byte
+	readLock   sync.Mutex
+	writeLock  sync.Mutex
+}
+
+func newSecureConn(conn net.Conn, shared, h []byte, initiator bool) (*secureConn, error) {
+	keys := make([]byte, 2*chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, h, []byte(NOISE_PROTOCOL_NAME)), keys); err != nil {
+		return nil, err
+	}
+	i2r, err := chacha20poly1305.New(keys[:chacha20poly1305.KeySize])
+	if err != nil {
+		return nil, err
+	}
+	r2i, err := chacha20poly1305.New(keys[chacha20poly1305.KeySize:])
+	if err != nil {
+		return nil, err
+	}
+	if initiator {
+		return &secureConn{Conn: conn, sendCipher: i2r, recvCipher: r2i}, nil
+	}
+	return &secureConn{Conn: conn, sendCipher: r2i, recvCipher: i2r}, nil
+}
+
+func noiseNonce(n uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[4:], n)
+	return nonce
+}
+
+func (this *secureConn) Write(b []byte) (int, error) {
+	this.writeLock.Lock()
+	defer this.writeLock.Unlock()
+	written := 0
+	for {
+		end := written + NOISE_MAX_PLAINTEXT
+		if end > len(b) {
+			end = len(b)
+		}
+		frame := make([]byte, 2, 2+end-written+NOISE_TAG_SIZE)
+		frame = this.sendCipher.Seal(frame, noiseNonce(this.sendNonce), b[written:end], nil)
+		binary.BigEndian.PutUint16(frame, uint16(len(frame)-2))
+		this.sendNonce++
+		if _, err := this.Conn.Write(frame); err != nil {
+			return written, err
+		}
+		written = end
+		if written >= len(b) {
+			return written, nil
+		}
+	}
+}
+
+func (this *secureConn) Read(b []byte) (int, error) {
+	this.readLock.Lock()
+	defer this.readLock.Unlock()
+	for len(this.readBuf) == 0 {
+		frame, err := this.readFrameLocked()
+		if err != nil {
+			return 0, err
+		}
+		this.readBuf = frame
+	}
+	n := copy(b, this.readBuf)
+	this.readBuf = this.readBuf[n:]
+	return n, nil
+}
+
+//readFrame read a whole frame, used in handshake before any Read
+func (this *secureConn) readFrame() ([]byte, error) {
+	this.readLock.Lock()
+	defer this.readLock.Unlock()
+	return this.readFrameLocked()
+}
+
+func (this *secureConn) readFrameLocked() ([]byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(this.Conn, header[:]); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, binary.BigEndian.Uint16(header[:]))
+	if _, err := io.ReadFull(this.Conn, frame); err != nil {
+		return nil, err
+	}
+	plain, err := this.recvCipher.Open(frame[:0], noiseNonce(this.recvNonce), frame, nil)
+	if err != nil {
+		return nil, errors.New("[noise] decrypt frame failed")
+	}
+	this.recvNonce++
+	return plain, nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handshake
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	common2 "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/peer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestHandshakeNoise(t *testing.T) {
+	versions := []string{"v1.8.0", "v1.20"}
+	for _, version := range versions {
+		client, server := NewPair()
+		client.Info.SoftVersion = version
+		server.Info.SoftVersion = version
+
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		var clientInfo, serverInfo *peer.PeerInfo
+		var clientConn, serverConn net.Conn
+		var clientErr, serverErr error
+		go func() {
+			clientInfo, clientConn, clientErr = HandshakeNoiseClient(client.Info, client.Id, client.Conn)
+			wg.Done()
+		}()
+		go func() {
+			serverInfo, serverConn, serverErr = HandshakeNoiseServer(server.Info, server.Id, server.Conn)
+			wg.Done()
+		}()
+		wg.Wait()
+
+		assert.Nil(t, clientErr)
+		assert.Nil(t, serverErr)
+		assert.Equal(t, server.Id.Id, clientInfo.Id)
+		assert.Equal(t, client.Id.Id, serverInfo.Id)
+
+		//large payload is split into frames
+		data := bytes.Repeat([]byte{0x5a}, 3*NOISE_MAX_PLAINTEXT+10)
+		go func() {
+			_, err := clientConn.Write(data)
+			assert.Nil(t, err)
+		}()
+		buf := make([]byte, len(data))
+		_, err := io.ReadFull(serverConn, buf)
+		assert.Nil(t, err)
+		assert.Equal(t, data, buf)
+	}
+}
+
+func TestNoiseImpersonation(t *testing.T) {
+	client, server := NewPair()
+	victim := common.RandPeerKeyId()
+	go func() {
+		//attacker claims node key of victim, but can only sign with its own key
+		var ePri, ePub, eRemote, shared [NOISE_KEY_SIZE]byte
+		_, _ = rand.Read(ePri[:])
+		curve25519.ScalarBaseMult(&ePub, &ePri)
+		_, _ = client.Conn.Write(ePub[:])
+		_, _ = io.ReadFull(client.Conn, eRemote[:])
+		curve25519.ScalarMult(&shared, &ePri, &eRemote)
+		h := noiseTranscriptHash(ePub[:], eRemote[:])
+		secure, _ := newSecureConn(client.Conn, shared[:], h, true)
+		sig, _ := client.Id.Sign(noiseSignData(h, noiseInitiator))
+		sink := common2.NewZeroCopySink(nil)
+		victim.Serialization(sink)
+		sink.WriteVarBytes(sig)
+		_, _ = secure.Write(sink.Bytes())
+	}()
+	_, _, err := NoiseServer(server.Id, server.Conn)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "authenticate node")
+}
+
+func TestNoiseTamperedFrame(t *testing.T) {
+	client, server := NewPair()
+	go func() {
+		_, _, _ = NoiseClient(client.Id, &tamperConn{Conn: client.Conn})
+	}()
+	_, _, err := NoiseServer(server.Id, server.Conn)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "decrypt frame failed")
+}
+
+//tamperConn flip the last byte of encrypted frames
+type tamperConn struct {
+	net.Conn
+	writes int
+}
+
+func (this *tamperConn) Write(b []byte) (int, error) {
+	this.writes++
+	if this.writes > 1 {
+		b = append([]byte{}, b...)
+		b[len(b)-1] ^= 0xff
+	}
+	return this.Conn.Write(b)
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/p2pserver/message/types"
@@ -43,21 +44,100 @@ type Link struct {
 	time      int64                  // The latest time the node activity
 	recvChan  chan *types.MsgPayload //msgpayload channel
 	reqRecord map[string]int64       //Map RequestId to Timestamp, using for rejecting duplicate request in specific time
+
+	compress    bool                //compress msg sent, negotiated in handshake
+	recvLimiter *common.RateLimiter //bandwidth limit of received msg, nil if unlimited
+	sendLimiter *common.RateLimiter //bandwidth limit of sent msg, nil if unlimited
+	sendQueue   chan *outPacket     //msgs waiting to be sent, the full queue drops new msgs
+	closed      chan struct{}       //closed when the link is closed, stops the send loop
+	closeOnce   sync.Once
+	statsLock   sync.Mutex
+	stats       BandwidthStats
+}
+
+//outPacket is a msg packet waiting in send queue
+type outPacket struct {
+	cmdType string
+	data    []byte
+}
+
+//BandwidthStats is the traffic of a link in byte, including msg header
+type BandwidthStats struct {
+	RecvBytes  uint64
+	SendBytes  uint64
+	RecvByType map[string]uint64
+	SendByType map[string]uint64
 }
 
 func NewLink(id common.PeerId, c net.Conn, msgChan chan *types.MsgPayload) *Link {
+	conf := config.DefConfig.P2PNode
 	link := &Link{
-		id:        id,
-		addr:      c.RemoteAddr().String(),
-		conn:      c,
-		time:      time.Now().UnixNano(),
-		recvChan:  msgChan,
-		reqRecord: make(map[string]int64),
+		id:          id,
+		addr:        c.RemoteAddr().String(),
+		conn:        c,
+		time:        time.Now().UnixNano(),
+		recvChan:    msgChan,
+		reqRecord:   make(map[string]int64),
+		recvLimiter: newBandwidthLimiter(conf.MaxPeerRecvRate),
+		sendLimiter: newBandwidthLimiter(conf.MaxPeerSendRate),
+		sendQueue:   make(chan *outPacket, common.SEND_QUEUE_SIZE),
+		closed:      make(chan struct{}),
+		stats: BandwidthStats{
+			RecvByType: make(map[string]uint64),
+			SendByType: make(map[string]uint64),
+		},
 	}
+	go link.sendLoop()
 
 	return link
 }
 
+//newBandwidthLimiter return limiter of rate kB/s, nil if rate is 0
+func newBandwidthLimiter(rate uint) *common.RateLimiter {
+	if rate == 0 {
+		return nil
+	}
+	bytesPerSecond := float64(rate) * 1024
+	return common.NewRateLimiter(bytesPerSecond, bytesPerSecond*common.RATE_LIMIT_BURST)
+}
+
+//SetCompress enable compression of msg sent
+func (this *Link) SetCompress(compress bool) {
+	this.compress = compress
+}
+
+//GetBandwidthStats return the traffic of link
+func (this *Link) GetBandwidthStats() BandwidthStats {
+	this.statsLock.Lock()
+	defer this.statsLock.Unlock()
+	stats := BandwidthStats{
+		RecvBytes:  this.stats.RecvBytes,
+		SendBytes:  this.stats.SendBytes,
+		RecvByType: make(map[string]uint64, len(this.stats.RecvByType)),
+		SendByType: make(map[string]uint64, len(this.stats.SendByType)),
+	}
+	for cmd, size := range this.stats.RecvByType {
+		stats.RecvByType[cmd] = size
+	}
+	for cmd, size := range this.stats.SendByType {
+		stats.SendByType[cmd] = size
+	}
+	return stats
+}
+
+func (this *Link) addStats(direction, cmdType string, size int) {
+	observeMessage(direction, cmdType, size)
+	this.statsLock.Lock()
+	defer this.statsLock.Unlock()
+	if direction == "in" {
+		this.stats.RecvBytes += uint64(size)
+		this.stats.RecvByType[cmdType] += uint64(size)
+	} else {
+		this.stats.SendBytes += uint64(size)
+		this.stats.SendByType[cmdType] += uint64(size)
+	}
+}
+
 //get address
 func (this *Link) GetAddr() string {
 	return this.addr
@@ -102,11 +182,18 @@ func (this *Link) Rx() {
 			break
 		}
 
+		size := int(payloadSize) + common.MSG_HDR_LEN
+		if !this.throttleRecv(size) {
+			log.Warnf("[p2p]peer %s exceeds receive bandwidth limit, disconnect", this.GetAddr())
+			break
+		}
 		if unknown, ok := msg.(*types.UnknownMessage); ok {
+			this.addStats("in", "unknown", size)
 			log.Infof("skip handle unknown msg type:%s from:%d", unknown.CmdType(), this.id)
 			continue
 		}
 
+		this.addStats("in", msg.CmdType(), size)
 		t := time.Now()
 		this.UpdateRXTime(t)
 
@@ -137,6 +224,9 @@ func (this *Link) CloseConn() {
 	if conn != nil {
 		_ = conn.Close()
 	}
+	this.closeOnce.Do(func() {
+		close(this.closed)
+	})
 }
 
 func (this *Link) Send(msg types.Message) error {
@@ -146,7 +236,83 @@ func (this *Link) Send(msg types.Message) error {
 	return this.SendRaw(sink.Bytes())
 }
 
+//throttleRecv wait until received bytes are within bandwidth limit, the peer reading slower is throttled by tcp
+//flow control. Return false if the peer should be disconnected
+func (this *Link) throttleRecv(size int) bool {
+	if this.recvLimiter == nil {
+		return true
+	}
+	wait, ok := this.recvLimiter.Reserve(float64(size), common.RATE_LIMIT_MAX_DELAY*time.Second)
+	if !ok {
+		observeRateLimit("in", "disconnect")
+		return false
+	}
+	if wait > 0 {
+		observeRateLimit("in", "throttle")
+		time.Sleep(wait)
+	}
+	return true
+}
+
+//throttleSend wait until sent bytes are within bandwidth limit. Return false if the msg should be dropped or the
+//link is closed
+func (this *Link) throttleSend(size int) bool {
+	if this.sendLimiter == nil {
+		return true
+	}
+	wait, ok := this.sendLimiter.Reserve(float64(size), common.RATE_LIMIT_MAX_DELAY*time.Second)
+	if !ok {
+		observeRateLimit("out", "drop")
+		return false
+	}
+	if wait > 0 {
+		observeRateLimit("out", "throttle")
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-this.closed:
+			return false
+		}
+	}
+	return true
+}
+
+//SendRaw queue the msg to send, the msg is dropped if the send queue is full
 func (this *Link) SendRaw(rawPacket []byte) error {
+	if this.GetConn() == nil {
+		return errors.New("[p2p]tx link invalid")
+	}
+	cmdType := packetCmdType(rawPacket)
+	if this.compress {
+		rawPacket = types.CompressPacket(rawPacket)
+	}
+	select {
+	case this.sendQueue <- &outPacket{cmdType: cmdType, data: rawPacket}:
+		return nil
+	default:
+		observeRateLimit("out", "drop")
+		return fmt.Errorf("[p2p]send queue of %s is full, drop %s", this.GetAddr(), cmdType)
+	}
+}
+
+//sendLoop write the queued msgs to connection within bandwidth limit until the link is closed
+func (this *Link) sendLoop() {
+	for {
+		select {
+		case packet := <-this.sendQueue:
+			if !this.throttleSend(len(packet.data)) {
+				log.Debugf("[p2p]send %s to %s exceeds bandwidth limit", packet.cmdType, this.GetAddr())
+				continue
+			}
+			_ = this.write(packet.cmdType, packet.data)
+		case <-this.closed:
+			return
+		}
+	}
+}
+
+func (this *Link) write(cmdType string, rawPacket []byte) error {
 	conn := this.GetConn()
 	if conn == nil {
 		return errors.New("[p2p]tx link invalid")
@@ -165,6 +331,7 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 		this.CloseConn()
 		return err
 	}
+	this.addStats("out", cmdType, nByteCnt)
 
 	return nil
 }
@@ -20,6 +20,8 @@ package link
 
 import (
 	"math/rand"
+	"net"
+	"reflect"
 	"testing"
 	"time"
 
@@ -117,3 +119,67 @@ func TestUnpackBufNode(t *testing.T) {
 	sink := comm.NewZeroCopySink(nil)
 	mt.WriteMessage(sink, msg)
 }
+
+func TestPacketCmdType(t *testing.T) {
+	sink := comm.NewZeroCopySink(nil)
+	mt.WriteMessage(sink, &mt.VerACK{})
+	if cmd := packetCmdType(sink.Bytes()); cmd != common.VERACK_TYPE {
+		t.Errorf("packetCmdType error %s != %s", cmd, common.VERACK_TYPE)
+	}
+	if cmd := packetCmdType([]byte{1, 2}); cmd != "unknown" {
+		t.Errorf("packetCmdType error %s != unknown", cmd)
+	}
+}
+
+func TestLinkCompress(t *testing.T) {
+	c, s := net.Pipe()
+	recvChan := make(chan *mt.MsgPayload, 1)
+	sender := NewLink(common.PseudoPeerIdFromUint64(1), c, nil)
+	receiver := NewLink(common.PseudoPeerIdFromUint64(2), s, recvChan)
+	sender.SetCompress(true)
+	go receiver.Rx()
+
+	addr := &mt.Addr{}
+	for i := 0; i < 64; i++ {
+		addr.NodeAddrs = append(addr.NodeAddrs, common.PeerAddr{Time: 12345678, Port: 20338})
+	}
+	sink := comm.NewZeroCopySink(nil)
+	mt.WriteMessage(sink, addr)
+	if err := sender.Send(addr); err != nil {
+		t.Fatalf("send error %s", err)
+	}
+	payload := <-recvChan
+	if !reflect.DeepEqual(payload.Payload, addr) {
+		t.Errorf("received msg mismatch")
+	}
+
+	// the stats of sender are added by the send loop after the msg written
+	sent := sender.GetBandwidthStats()
+	for i := 0; i < 100 && sent.SendBytes == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		sent = sender.GetBandwidthStats()
+	}
+	recv := receiver.GetBandwidthStats()
+	if sent.SendBytes >= uint64(len(sink.Bytes())) {
+		t.Errorf("msg not compressed, sent %d bytes", sent.SendBytes)
+	}
+	if sent.SendByType[common.ADDR_TYPE] != sent.SendBytes || recv.RecvByType[common.ADDR_TYPE] != sent.SendBytes {
+		t.Errorf("bandwidth stats mismatch, sent %v, recv %v", sent, recv)
+	}
+	sender.CloseConn()
+}
+
+func TestLinkSendLimit(t *testing.T) {
+	c, _ := net.Pipe()
+	link := NewLink(common.PseudoPeerIdFromUint64(1), c, nil)
+	link.sendLimiter = common.NewRateLimiter(0.1, 1)
+	// the msgs wait in the queue for bandwidth, the ones exceeding the queue are dropped without blocking
+	var err error
+	for i := 0; i <= common.SEND_QUEUE_SIZE+1 && err == nil; i++ {
+		err = link.Send(&mt.VerACK{})
+	}
+	if err == nil {
+		t.Errorf("msg exceeds send queue should be dropped")
+	}
+	link.CloseConn()
+}
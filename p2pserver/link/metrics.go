@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"bytes"
+
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/p2pserver/common"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	msgBytesMetric = prom.NewCounterVec(prom.CounterOpts{
+		Name: "ontology_p2p_message_bytes_total",
+		Help: "ontology p2p bytes of the messages received and sent per message type",
+	}, []string{"direction", "type"})
+
+	msgCountMetric = prom.NewCounterVec(prom.CounterOpts{
+		Name: "ontology_p2p_messages_total",
+		Help: "ontology p2p count of the messages received and sent per message type",
+	}, []string{"direction", "type"})
+
+	rateLimitMetric = prom.NewCounterVec(prom.CounterOpts{
+		Name: "ontology_p2p_rate_limit_total",
+		Help: "ontology p2p count of the messages throttled, dropped or peers disconnected by bandwidth limit",
+	}, []string{"direction", "action"})
+)
+
+func init() {
+	prom.MustRegister(msgBytesMetric, msgCountMetric, rateLimitMetric)
+}
+
+func observeRateLimit(direction, action string) {
+	rateLimitMetric.WithLabelValues(direction, action).Inc()
+}
+
+func observeMessage(direction, cmdType string, size int) {
+	msgBytesMetric.WithLabelValues(direction, cmdType).Add(float64(size))
+	msgCountMetric.WithLabelValues(direction, cmdType).Inc()
+}
+
+//packetCmdType returns the message type in the header of a raw packet
+func packetCmdType(rawPacket []byte) string {
+	if len(rawPacket) < common.MSG_HDR_LEN {
+		return "unknown"
+	}
+	cmd := rawPacket[comm.UINT32_SIZE : comm.UINT32_SIZE+common.MSG_CMD_LEN]
+	return string(bytes.TrimRight(cmd, "\x00"))
+}
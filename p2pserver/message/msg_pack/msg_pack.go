@@ -72,6 +72,27 @@ func NewHeadersReq(curHdrHash common.Uint256) mt.Message {
 	return &h
 }
 
+//blk hdr req between stopHash(exclusive) and startHash(inclusive)
+func NewHeadersRangeReq(startHash, stopHash common.Uint256) mt.Message {
+	log.Trace()
+	var h mt.HeadersReq
+	h.Len = 1
+	h.HashStart = startHash
+	h.HashEnd = stopHash
+
+	return &h
+}
+
+//skeleton blk hdr req package
+func NewSkeletonReq(startHeight, interval, count uint32) mt.Message {
+	log.Trace()
+	return &mt.SkeletonReq{
+		StartHeight: startHeight,
+		Interval:    interval,
+		Count:       count,
+	}
+}
+
 ////Consensus info package
 func NewConsensus(cp *mt.ConsensusPayload) mt.Message {
 	log.Trace()
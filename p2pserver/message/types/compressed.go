@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/p2pserver/common"
+)
+
+//compressed msg payload: cmd of original msg | snappy compressed payload of original msg
+
+//CompressPacket compress the payload of raw packet, return the original packet if it is too small or not smaller
+//after compression
+func CompressPacket(rawPacket []byte) []byte {
+	if len(rawPacket) < common.MSG_HDR_LEN+common.COMPRESS_THRESHOLD {
+		return rawPacket
+	}
+	cmd := rawPacket[comm.UINT32_SIZE : comm.UINT32_SIZE+common.MSG_CMD_LEN]
+	payload := rawPacket[common.MSG_HDR_LEN:]
+	compressed := make([]byte, common.MSG_CMD_LEN+snappy.MaxEncodedLen(len(payload)))
+	copy(compressed, cmd)
+	encoded := snappy.Encode(compressed[common.MSG_CMD_LEN:], payload)
+	compressed = compressed[:common.MSG_CMD_LEN+len(encoded)]
+	if len(compressed) >= len(payload) {
+		return rawPacket
+	}
+
+	sink := comm.NewZeroCopySink(make([]byte, 0, common.MSG_HDR_LEN+len(compressed)))
+	hdr := newMessageHeader(common.COMPRESSED_TYPE, uint32(len(compressed)), common.Checksum(compressed))
+	writeMessageHeaderInto(sink, hdr)
+	sink.WriteBytes(compressed)
+	return sink.Bytes()
+}
+
+//decompressPayload return the cmd and payload of original msg
+func decompressPayload(buf []byte) (string, []byte, error) {
+	if len(buf) < common.MSG_CMD_LEN {
+		return "", nil, errors.New("compressed msg too short")
+	}
+	cmd := string(bytes.TrimRight(buf[:common.MSG_CMD_LEN], "\x00"))
+	if cmd == common.COMPRESSED_TYPE {
+		return "", nil, errors.New("nested compressed msg")
+	}
+	data := buf[common.MSG_CMD_LEN:]
+	length, err := snappy.DecodedLen(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if length > common.MAX_PAYLOAD_LEN {
+		return "", nil, fmt.Errorf("decompressed msg payload length:%d exceed max payload size: %d",
+			length, common.MAX_PAYLOAD_LEN)
+	}
+	payload, err := snappy.Decode(nil, data)
+	if err != nil {
+		return "", nil, err
+	}
+	return cmd, payload, nil
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	comm "github.com/ontio/ontology/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressPacket(t *testing.T) {
+	msg := &Addr{}
+	for i := 0; i < 64; i++ {
+		msg.NodeAddrs = append(msg.NodeAddrs, comm.PeerAddr{
+			Time:     12345678,
+			Services: 100,
+			Port:     20338,
+			ID:       comm.PseudoPeerIdFromUint64(uint64(i)),
+		})
+	}
+	sink := common.NewZeroCopySink(nil)
+	WriteMessage(sink, msg)
+	raw := sink.Bytes()
+
+	packet := CompressPacket(raw)
+	assert.True(t, len(packet) < len(raw))
+	demsg, length, err := ReadMessage(bytes.NewBuffer(packet))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(len(packet)-comm.MSG_HDR_LEN), length)
+	assert.Equal(t, msg, demsg)
+}
+
+func TestCompressSmallPacket(t *testing.T) {
+	sink := common.NewZeroCopySink(nil)
+	WriteMessage(sink, &Ping{Height: 100})
+	raw := sink.Bytes()
+
+	assert.Equal(t, raw, CompressPacket(raw))
+}
+
+func TestDecompressNested(t *testing.T) {
+	payload := make([]byte, comm.MSG_CMD_LEN+10)
+	copy(payload, comm.COMPRESSED_TYPE)
+	_, _, err := decompressPayload(payload)
+	assert.NotNil(t, err)
+}
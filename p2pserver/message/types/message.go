@@ -147,6 +147,12 @@ func ReadMessage(reader io.Reader) (Message, uint32, error) {
 	}
 
 	cmdType := string(bytes.TrimRight(hdr.CMD[:], string(0)))
+	if cmdType == common.COMPRESSED_TYPE {
+		cmdType, buf, err = decompressPayload(buf)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
 	msg := makeEmptyMessage(cmdType)
 
 	// the buf is referenced by msg to avoid reallocation, so can not reused
@@ -203,6 +209,8 @@ func makeEmptyMessage(cmdType string) Message {
 		return &SubnetMembers{}
 	case common.SUBNET_OFFLINE_TYPE:
 		return &OfflineWitnessMsg{}
+	case common.GET_SKELETON_TYPE:
+		return &SkeletonReq{}
 	default:
 		return &UnknownMessage{Cmd: cmdType}
 	}
@@ -23,8 +23,8 @@ import (
 	"errors"
 	"math"
 
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/core/signature"
 	common2 "github.com/ontio/ontology/p2pserver/common"
@@ -140,9 +140,10 @@ func (self *OfflineWitnessMsg) Hash() common.Uint256 {
 	return hash
 }
 
-func (self *OfflineWitnessMsg) AddProposeSig(acct *account.Account) error {
-	hash := self.Hash()
-	sig, err := signature.Sign(acct, hash[:])
+func (self *OfflineWitnessMsg) AddProposeSig(consSigner signer.Signer) error {
+	sink := common.NewZeroCopySink(nil)
+	self.serializeUnsigned(sink)
+	sig, err := consSigner.Sign(&signer.SignRequest{Type: signer.SIGN_WITNESS, Data: sink.Bytes()})
 	if err != nil {
 		return err
 	}
@@ -151,16 +152,15 @@ func (self *OfflineWitnessMsg) AddProposeSig(acct *account.Account) error {
 	return nil
 }
 
-func (self *OfflineWitnessMsg) VoteFor(acct *account.Account, index []uint8) error {
+func (self *OfflineWitnessMsg) VoteFor(consSigner signer.Signer, index []uint8) error {
 	sink := common.NewZeroCopySink(nil)
 	self.serializeUnsigned(sink)
 	sink.WriteVarBytes(index)
-	hash := common.Uint256(sha256.Sum256(sink.Bytes()))
-	sig, err := signature.Sign(acct, hash[:])
+	sig, err := consSigner.Sign(&signer.SignRequest{Type: signer.SIGN_WITNESS, Data: sink.Bytes()})
 	if err != nil {
 		return err
 	}
-	pubkey := vconfig.PubkeyID(acct.PublicKey)
+	pubkey := vconfig.PubkeyID(consSigner.PubKey())
 	self.Voters = append(self.Voters, VoterMsg{OfflineIndex: index, PubKey: pubkey, Sig: sig})
 
 	return nil
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"io"
+
+	"github.com/ontio/ontology/common"
+	comm "github.com/ontio/ontology/p2pserver/common"
+)
+
+//SkeletonReq request headers at height StartHeight + k*Interval, k = 1..Count
+type SkeletonReq struct {
+	StartHeight uint32
+	Interval    uint32
+	Count       uint32
+}
+
+//Serialize message payload
+func (this *SkeletonReq) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.StartHeight)
+	sink.WriteUint32(this.Interval)
+	sink.WriteUint32(this.Count)
+}
+
+func (this *SkeletonReq) CmdType() string {
+	return comm.GET_SKELETON_TYPE
+}
+
+//Deserialize message payload
+func (this *SkeletonReq) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.StartHeight, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Interval, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Count, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
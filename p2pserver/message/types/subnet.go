@@ -24,8 +24,8 @@ import (
 	"time"
 
 	"github.com/ontio/ontology-crypto/keypair"
-	"github.com/ontio/ontology/account"
 	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/consensus/signer"
 	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/p2pserver/common"
 )
@@ -69,15 +69,15 @@ func (self *SubnetMembersRequest) Role() string {
 	return "gov"
 }
 
-func NewMembersRequest(from, to common.PeerId, acc *account.Account) (*SubnetMembersRequest, error) {
+func NewMembersRequest(from, to common.PeerId, consSigner signer.Signer) (*SubnetMembersRequest, error) {
 	request := &SubnetMembersRequest{
 		From:      from,
 		To:        to,
 		Timestamp: uint32(time.Now().Unix()),
-		PubKey:    acc.PublicKey,
+		PubKey:    consSigner.PubKey(),
 	}
 
-	sig, err := signature.Sign(acc, request.sigdata())
+	sig, err := consSigner.Sign(&signer.SignRequest{Type: signer.SIGN_MESSAGE, Data: request.sigdata()})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mock
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/connect_controller"
+	"github.com/ontio/ontology/p2pserver/net/netserver"
+	"github.com/ontio/ontology/p2pserver/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoiseReservedKeys(t *testing.T) {
+	//topo
+	/**
+	trusted —————— seed —x— untrusted
+	*/
+	net := NewNetwork()
+	trustedId := common.RandPeerKeyId()
+	seedNode := NewNoiseNode(common.RandPeerKeyId(), nil, net, []common.PeerId{trustedId.Id}, "seed")
+	go seedNode.Start()
+	seedAddr := seedNode.GetHostInfo().Addr
+
+	trusted := NewNoiseNode(trustedId, []string{seedAddr}, net, nil, "trusted")
+	untrusted := NewNoiseNode(common.RandPeerKeyId(), []string{seedAddr}, net, nil, "untrusted")
+	for _, node := range []*netserver.NetServer{trusted, untrusted} {
+		net.AllowConnect(seedNode.GetHostInfo().Id, node.GetHostInfo().Id)
+		go node.Start()
+	}
+	net.AllowConnect(trusted.GetHostInfo().Id, untrusted.GetHostInfo().Id)
+
+	time.Sleep(time.Second * 5)
+	assert.Equal(t, uint32(1), seedNode.GetConnectionCnt())
+	assert.NotNil(t, seedNode.GetPeer(trustedId.Id))
+	assert.Equal(t, uint32(0), untrusted.GetConnectionCnt())
+}
+
+func NewNoiseNode(keyId *common.PeerKeyId, seeds []string, nw Network, reservedKeys []common.PeerId,
+	logPrefix string) *netserver.NetServer {
+	info := peer.NewPeerInfo(keyId.Id, 0, 0, true, 0,
+		0, 0, "1.10", "")
+	dis := NewDiscoveryProtocol(seeds, nil)
+	dis.RefleshInterval = time.Millisecond * 1000
+	context := fmt.Sprintf("peer %s-%s:, ", logPrefix, keyId.Id.ToHexString()[:6])
+	logger := common.LoggerWithContext(common.NewGlobalLoggerWrapper(), context)
+
+	listenAddr, listener := nw.NewListener(keyId.Id)
+	host, port, _ := net.SplitHostPort(listenAddr)
+	info.Addr = listenAddr
+	iport, _ := strconv.Atoi(port)
+	info.Port = uint16(iport)
+	opt := connect_controller.NewConnCtrlOption().MaxInBoundPerIp(10).
+		MaxInBound(20).MaxOutBound(20).WithDialer(nw.NewDialerWithHost(keyId.Id, host)).
+		WithNoise().ReservedKeysOnly(reservedKeys)
+	return netserver.NewCustomNetServer(keyId, info, dis, listener, opt, logger)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/p2pserver/common"
 	msgTypes "github.com/ontio/ontology/p2pserver/message/types"
@@ -194,7 +195,7 @@ func NewTestSubnetProtocalHandler(acct *account.Account, seedList, govs []string
 	if invalid != nil {
 		panic(fmt.Errorf("invalid seed list； %v", invalid))
 	}
-	subNet := subnet.NewSubNet(acct, seeds, gov, logger)
+	subNet := subnet.NewSubNet(signer.NewLocalSigner(acct), seeds, gov, logger)
 	return &TestSubnetProtocalHandler{seeds: seeds, subnet: subNet, acct: acct}
 }
 
@@ -19,9 +19,11 @@
 package netserver
 
 import (
+	"encoding/hex"
 	"errors"
 	"net"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/p2pserver/common"
@@ -33,20 +35,30 @@ import (
 )
 
 //NewNetServer return the net object in p2p
-func NewNetServer(protocol p2p.Protocol, conf *config.P2PNodeConfig, reserveAddrFilter p2p.AddressFilter) (*NetServer, error) {
+func NewNetServer(protocol p2p.Protocol, conf *config.P2PNodeConfig, reserveAddrFilter p2p.AddressFilter,
+	reputation p2p.PeerReputation) (*NetServer, error) {
 	nodePort := conf.NodePort
 	if nodePort == 0 {
 		nodePort = config.DEFAULT_NODE_PORT
 	}
 
 	keyId := common.RandPeerKeyId()
+	if conf.NodeKeyPath != "" {
+		var err error
+		keyId, err = common.LoadOrCreatePeerKeyId(conf.NodeKeyPath)
+		if err != nil {
+			return nil, err
+		}
+	}
 	info := peer.NewPeerInfo(keyId.Id, common.PROTOCOL_VERSION, common.SERVICE_NODE, true,
 		conf.HttpInfoPort, nodePort, 0, config.Version, "")
+	info.Compress = conf.EnableCompression
 
 	option, err := connect_controller.ConnCtrlOptionFromConfig(conf, reserveAddrFilter)
 	if err != nil {
 		return nil, err
 	}
+	option = option.WithReputation(reputation)
 
 	listener, err := connect_controller.NewListener(nodePort, conf)
 	if err != nil {
@@ -55,6 +67,10 @@ func NewNetServer(protocol p2p.Protocol, conf *config.P2PNodeConfig, reserveAddr
 	}
 
 	log.Infof("[p2p] init peer ID to %s", info.Id.ToHexString())
+	if conf.EnableNoise {
+		log.Infof("[p2p] noise handshake enabled, node public key %s",
+			hex.EncodeToString(keypair.SerializePublicKey(keyId.PublicKey)))
+	}
 
 	return NewCustomNetServer(keyId, info, protocol, listener, option, nil), nil
 }
@@ -280,6 +296,11 @@ func (this *NetServer) IsOwnAddress(addr string) bool {
 	return addr == this.connCtrl.OwnAddress()
 }
 
+//ReportMisbehavior lower the reputation of peer, peer with bad reputation is banned
+func (this *NetServer) ReportMisbehavior(id common.PeerId, kind p2p.Misbehavior, detail string) {
+	this.connCtrl.ReportMisbehavior(id, kind, detail)
+}
+
 func (ns *NetServer) ConnectController() *connect_controller.ConnectController {
 	return ns.connCtrl
 }
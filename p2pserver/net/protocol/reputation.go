@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/p2pserver/common"
+)
+
+//Misbehavior is the kind of misbehavior reported against a peer
+type Misbehavior uint8
+
+const (
+	InvalidBlock Misbehavior = iota
+	InvalidTx
+	BadHeader
+	RequestTimeout
+	Spam
+	ProtocolViolation
+	HandshakeFailure
+)
+
+func (self Misbehavior) String() string {
+	switch self {
+	case InvalidBlock:
+		return "invalid block"
+	case InvalidTx:
+		return "invalid tx"
+	case BadHeader:
+		return "bad header"
+	case RequestTimeout:
+		return "request timeout"
+	case Spam:
+		return "spam"
+	case ProtocolViolation:
+		return "protocol violation"
+	case HandshakeFailure:
+		return "handshake failure"
+	default:
+		return fmt.Sprintf("misbehavior(%d)", uint8(self))
+	}
+}
+
+//PeerReputation score peers by their misbehavior and ban the bad ones
+type PeerReputation interface {
+	// addr format : ip:port, id is empty if peer is not identified, such as handshake failure
+	Report(id common.PeerId, addr string, kind Misbehavior, detail string)
+	IsBanned(id common.PeerId, addr string) bool
+}
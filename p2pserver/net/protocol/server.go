@@ -41,4 +41,5 @@ type P2P interface {
 	GetOutConnRecordLen() uint
 	Broadcast(msg types.Message)
 	IsOwnAddress(addr string) bool
+	ReportMisbehavior(id common.PeerId, kind Misbehavior, detail string)
 }
@@ -22,26 +22,29 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/consensus/signer"
 	"github.com/ontio/ontology/core/ledger"
 	"github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/p2pserver/connect_controller"
 	"github.com/ontio/ontology/p2pserver/net/netserver"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
 	"github.com/ontio/ontology/p2pserver/protocols"
+	"github.com/ontio/ontology/p2pserver/protocols/block_sync"
+	"github.com/ontio/ontology/p2pserver/protocols/reputation"
 	"github.com/ontio/ontology/p2pserver/protocols/utils"
 )
 
 //P2PServer control all network activities
 type P2PServer struct {
-	network *netserver.NetServer
-	db      *ledger.Ledger
+	network  *netserver.NetServer
+	protocol *protocols.MsgHandler
+	db       *ledger.Ledger
 }
 
 //NewServer return a new p2pserver according to the pubkey
-func NewServer(acct *account.Account) (*P2PServer, error) {
+func NewServer(consSigner signer.Signer) (*P2PServer, error) {
 	db := ledger.DefLedger
 	var rsv []string
 	var recRsv []string
@@ -54,17 +57,18 @@ func NewServer(acct *account.Account) (*P2PServer, error) {
 	}
 
 	staticFilter := connect_controller.NewStaticReserveFilter(rsv)
-	protocol := protocols.NewMsgHandler(acct, connect_controller.NewStaticReserveFilter(recRsv), db, common.NewGlobalLoggerWrapper())
+	protocol := protocols.NewMsgHandler(consSigner, connect_controller.NewStaticReserveFilter(recRsv), db, common.NewGlobalLoggerWrapper())
 	reserved := protocol.GetReservedAddrFilter(len(rsv) != 0)
 	reservedPeers := p2p.CombineAddrFilter(staticFilter, reserved)
-	n, err := netserver.NewNetServer(protocol, conf, reservedPeers)
+	n, err := netserver.NewNetServer(protocol, conf, reservedPeers, protocol.GetReputation())
 	if err != nil {
 		return nil, err
 	}
 
 	p := &P2PServer{
-		db:      db,
-		network: n,
+		db:       db,
+		network:  n,
+		protocol: protocol,
 	}
 
 	return p, nil
@@ -85,6 +89,16 @@ func (self *P2PServer) GetNetwork() p2p.P2P {
 	return self.network
 }
 
+//GetReputation return the peer reputation service
+func (self *P2PServer) GetReputation() *reputation.ReputationService {
+	return self.protocol.GetReputation()
+}
+
+//GetSyncStatus return the progress of block sync
+func (self *P2PServer) GetSyncStatus() *block_sync.SyncStatus {
+	return self.protocol.GetSyncStatus()
+}
+
 //WaitForPeersStart check whether enough peer linked in loop
 func (self *P2PServer) WaitForPeersStart() {
 	periodTime := config.DEFAULT_GEN_BLOCK_TIME / common.UPDATE_RATE_PER_BLOCK
@@ -45,6 +45,7 @@ type PeerInfo struct {
 	Port         uint16
 	SoftVersion  string
 	Addr         string
+	Compress     bool // snappy compression, for local node means supported, for remote peer means negotiated
 
 	height uint64
 }
@@ -96,9 +97,11 @@ type Peer struct {
 
 //NewPeer return new peer without publickey initial
 func NewPeer(info *PeerInfo, c net.Conn, msgChan chan *types.MsgPayload) *Peer {
+	link := conn.NewLink(info.Id, c, msgChan)
+	link.SetCompress(info.Compress)
 	return &Peer{
 		Info: info,
-		Link: conn.NewLink(info.Id, c, msgChan),
+		Link: link,
 	}
 }
 
@@ -26,8 +26,10 @@ import (
 	"time"
 
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/ledger"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/types"
 	p2pComm "github.com/ontio/ontology/p2pserver/common"
 	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
@@ -37,9 +39,6 @@ import (
 
 const (
 	SYNC_MAX_HEADER_FORWARD_SIZE = 5000            //keep CurrentHeaderHeight - CurrentBlockHeight <= SYNC_MAX_HEADER_FORWARD_SIZE
-	SYNC_MAX_FLIGHT_HEADER_SIZE  = 1               //Number of headers on flight
-	SYNC_MAX_FLIGHT_BLOCK_SIZE   = 50              //Number of blocks on flight
-	SYNC_MAX_BLOCK_CACHE_SIZE    = 500             //Cache size of block wait to commit to ledger
 	SYNC_HEADER_REQUEST_TIMEOUT  = 2 * time.Second //s, Request header timeout time. If header haven't receive after SYNC_HEADER_REQUEST_TIMEOUT second, retry
 	SYNC_BLOCK_REQUEST_TIMEOUT   = 2 * time.Second //s, Request block timeout time. If block haven't received after SYNC_BLOCK_REQUEST_TIMEOUT second, retry
 	SYNC_NEXT_BLOCK_TIMES        = 3               //Request times of next height block
@@ -49,6 +48,14 @@ const (
 	SYNC_NODE_SPEED_INIT         = 100 * 1024      //Init a big speed (100MB/s) for every node in first round
 	SYNC_MAX_ERROR_RESP_TIMES    = 5               //Max error headers/blocks response times, if reaches, delete it
 	SYNC_MAX_HEIGHT_OFFSET       = 5               //Offset of the max height and current height
+
+	SYNC_SKELETON_INTERVAL         = p2pComm.MAX_BLK_HDR_CNT                               //Headers of a skeleton segment
+	SYNC_SKELETON_SIZE             = SYNC_MAX_HEADER_FORWARD_SIZE / SYNC_SKELETON_INTERVAL //Max skeleton headers of a request
+	SYNC_MAX_SEGMENT_FAILED_TIMES  = 5                                                     //Max timeout times of a segment, if reaches, drop the skeleton
+	SYNC_BLOCK_FLIGHT_PER_NODE     = 32                                                    //Blocks on flight of a node with average speed
+	SYNC_MIN_BLOCK_FLIGHT_PER_NODE = 4                                                     //Min blocks on flight of a node
+	SYNC_MAX_BLOCK_FLIGHT_PER_NODE = 128                                                   //Max blocks on flight of a node
+	SYNC_PROGRESS_SAMPLE_SIZE      = 10                                                    //Record block height of recent seconds for sync speed
 )
 
 //NodeWeight record some params of node, using for sort
@@ -113,16 +120,27 @@ func (this *NodeWeight) AppendNewSpeed(s float32) {
 	this.speed[SYNC_NODE_RECORD_SPEED_CNT-1] = s
 }
 
-//Weight calculate node's weight for sort. Highest weight node will be accessed first for next request.
-func (this *NodeWeight) Weight() float32 {
+//AvgSpeed return the average speed of recent requests, unit kB/s
+func (this *NodeWeight) AvgSpeed() float32 {
 	this.lock.Lock()
 	defer this.lock.Unlock()
+	return this.avgSpeed()
+}
 
+func (this *NodeWeight) avgSpeed() float32 {
 	avgSpeed := float32(0.0)
 	for _, s := range this.speed {
 		avgSpeed += s
 	}
-	avgSpeed = avgSpeed / float32(len(this.speed))
+	return avgSpeed / float32(len(this.speed))
+}
+
+//Weight calculate node's weight for sort. Highest weight node will be accessed first for next request.
+func (this *NodeWeight) Weight() float32 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	avgSpeed := this.avgSpeed()
 
 	avgInterval := float32(0.0)
 	now := time.Now().UnixNano() / int64(time.Millisecond)
@@ -236,32 +254,64 @@ type BlockInfo struct {
 	merkleRoot    common.Uint256
 }
 
+//skeletonRequest record the skeleton request on flight
+type skeletonRequest struct {
+	flight      *SyncFlightInfo
+	startHeight uint32
+	startHash   common.Uint256
+}
+
+//progressSample record block height at time, using for calc sync speed
+type progressSample struct {
+	time   time.Time
+	height uint32
+}
+
 //BlockSyncMgr is the manager class to deal with block sync
 type BlockSyncMgr struct {
-	flightBlocks   map[common.Uint256][]*SyncFlightInfo //Map BlockHash => []SyncFlightInfo, using for manager all of those block flights
-	flightHeaders  map[uint32]*SyncFlightInfo           //Map HeaderHeight => SyncFlightInfo, using for manager all of those header flights
-	blocksCache    *BlockCache                          //Map BlockHash => BlockInfo, using for cache the blocks receive from net, and waiting for commit to ledger
-	server         p2p.P2P                              //Pointer to the local node
-	syncBlockLock  bool                                 //Help to avoid send block sync request duplicate
-	syncHeaderLock bool                                 //Help to avoid send header sync request duplicate
-	saveBlockLock  bool                                 //Help to avoid saving block concurrently
-	exitCh         chan interface{}                     //ExitCh to receive exit signal
-	ledger         *ledger.Ledger                       //ledger
-	lock           sync.RWMutex                         //lock
-	nodeWeights    map[p2pComm.PeerId]*NodeWeight       //Map NodeID => NodeStatus, using for getNextNode
+	flightBlocks     map[common.Uint256][]*SyncFlightInfo //Map BlockHash => []SyncFlightInfo, using for manager all of those block flights
+	flightHeaders    map[uint32]*SyncFlightInfo           //Map HeaderHeight => SyncFlightInfo, using for manager all of those header flights
+	blocksCache      *BlockCache                          //Map BlockHash => BlockInfo, using for cache the blocks receive from net, and waiting for commit to ledger
+	server           p2p.P2P                              //Pointer to the local node
+	syncBlockLock    bool                                 //Help to avoid send block sync request duplicate
+	syncHeaderLock   bool                                 //Help to avoid send header sync request duplicate
+	saveBlockCh      chan struct{}                        //Notify to save blocks in cache to ledger
+	exitCh           chan interface{}                     //ExitCh to receive exit signal
+	ledger           *ledger.Ledger                       //ledger
+	lock             sync.RWMutex                         //lock
+	nodeWeights      map[p2pComm.PeerId]*NodeWeight       //Map NodeID => NodeStatus, using for getNextNode
+	maxFlightHeaders int                                  //Max header segments on flight
+	maxFlightBlocks  int                                  //Max blocks on flight
+	maxBlockCache    int                                  //Max non-empty blocks in cache
+	skeletonLock     sync.Mutex                           //lock of skeleton, should not be acquired while holding lock
+	skeletonReq      *skeletonRequest                     //Skeleton request on flight
+	skeleton         *headerSkeleton                      //Skeleton headers and segments to fill in
+	progress         []progressSample                     //Block height of recent seconds
 }
 
 //NewBlockSyncMgr return a BlockSyncMgr instance
 func NewBlockSyncMgr(server p2p.P2P, ld *ledger.Ledger) *BlockSyncMgr {
+	conf := config.DefConfig.P2PNode
 	return &BlockSyncMgr{
-		flightBlocks:  make(map[common.Uint256][]*SyncFlightInfo),
-		flightHeaders: make(map[uint32]*SyncFlightInfo),
-		blocksCache:   NewBlockCache(),
-		server:        server,
-		ledger:        ld,
-		exitCh:        make(chan interface{}, 1),
-		nodeWeights:   make(map[p2pComm.PeerId]*NodeWeight),
+		flightBlocks:     make(map[common.Uint256][]*SyncFlightInfo),
+		flightHeaders:    make(map[uint32]*SyncFlightInfo),
+		blocksCache:      NewBlockCache(),
+		server:           server,
+		ledger:           ld,
+		saveBlockCh:      make(chan struct{}, 1),
+		exitCh:           make(chan interface{}, 1),
+		nodeWeights:      make(map[p2pComm.PeerId]*NodeWeight),
+		maxFlightHeaders: configOrDefault(conf.SyncMaxFlightHeaders, config.DEFAULT_SYNC_MAX_FLIGHT_HEADERS),
+		maxFlightBlocks:  configOrDefault(conf.SyncMaxFlightBlocks, config.DEFAULT_SYNC_MAX_FLIGHT_BLOCKS),
+		maxBlockCache:    configOrDefault(conf.SyncMaxBlockCache, config.DEFAULT_SYNC_MAX_BLOCK_CACHE),
+	}
+}
+
+func configOrDefault(value uint, def int) int {
+	if value == 0 {
+		return def
 	}
+	return int(value)
 }
 
 type BlockCache struct {
@@ -342,6 +392,7 @@ func (this *BlockSyncMgr) getNonEmptyBlockCount() int {
 //Start to sync
 func (this *BlockSyncMgr) Start() {
 	go this.sync()
+	go this.saveBlockLoop()
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 	for {
@@ -351,11 +402,32 @@ func (this *BlockSyncMgr) Start() {
 		case <-ticker.C:
 			go this.checkTimeout()
 			go this.sync()
-			go this.saveBlock()
+			this.notifySaveBlock()
+			this.recordProgress()
 		}
 	}
 }
 
+//saveBlockLoop save blocks in cache to ledger, so that block execution is pipelined with download
+func (this *BlockSyncMgr) saveBlockLoop() {
+	for {
+		select {
+		case <-this.exitCh:
+			return
+		case <-this.saveBlockCh:
+			this.saveBlock()
+		}
+	}
+}
+
+//notifySaveBlock wake up saveBlockLoop without blocking
+func (this *BlockSyncMgr) notifySaveBlock() {
+	select {
+	case this.saveBlockCh <- struct{}{}:
+	default:
+	}
+}
+
 // now prev is nanosecond
 func timeDiff(now, prev int64) time.Duration {
 	return time.Duration((now - prev) * time.Nanosecond.Nanoseconds())
@@ -434,6 +506,7 @@ func (this *BlockSyncMgr) checkTimeout() {
 			}
 		}
 	}
+	this.checkSkeletonTimeout(now)
 }
 
 func (this *BlockSyncMgr) sync() {
@@ -450,9 +523,6 @@ func (this *BlockSyncMgr) syncHeader() {
 	}
 	defer this.releaseSyncHeaderLock()
 
-	if this.getFlightHeaderCount() >= SYNC_MAX_FLIGHT_HEADER_SIZE {
-		return
-	}
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
 
 	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
@@ -460,6 +530,13 @@ func (this *BlockSyncMgr) syncHeader() {
 	if curHeaderHeight-curBlockHeight >= SYNC_MAX_HEADER_FORWARD_SIZE {
 		return
 	}
+	//Only one serial header request on flight, headers are requested after current header
+	if this.getFlightHeaderCount() > 0 {
+		return
+	}
+	if this.syncSkeleton(curHeaderHeight) {
+		return
+	}
 	NextHeaderId := curHeaderHeight + 1
 	reqNode := this.getNextNode(NextHeaderId)
 	if reqNode == nil {
@@ -485,10 +562,12 @@ func (this *BlockSyncMgr) syncBlock() {
 	}
 	defer this.releaseSyncBlockLock()
 
-	availCount := SYNC_MAX_FLIGHT_BLOCK_SIZE - this.getFlightBlockCount()
+	windows, maxFlight := this.getBlockWindows()
+	availCount := maxFlight - this.getFlightBlockCount()
 	if availCount <= 0 {
 		return
 	}
+	nodeFlights := this.getNodeFlightBlockCount()
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
 	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
 	count := int(curHeaderHeight - curBlockHeight)
@@ -498,7 +577,7 @@ func (this *BlockSyncMgr) syncBlock() {
 	if count > availCount {
 		count = availCount
 	}
-	cacheCap := SYNC_MAX_BLOCK_CACHE_SIZE - this.getNonEmptyBlockCount()
+	cacheCap := this.maxBlockCache - this.getNonEmptyBlockCount()
 	if count > cacheCap {
 		count = cacheCap
 	}
@@ -531,7 +610,7 @@ func (this *BlockSyncMgr) syncBlock() {
 			reqTimes = SYNC_NEXT_BLOCK_TIMES
 		}
 		for t := 0; t < reqTimes; t++ {
-			reqNode := this.getNextNode(nextBlockHeight)
+			reqNode := this.getNextBlockNode(nextBlockHeight, windows, nodeFlights)
 			if reqNode == nil {
 				return
 			}
@@ -556,6 +635,11 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID p2pComm.PeerId, headers []*type
 		return
 	}
 	log.Infof("Header receive height:%d - %d", headers[0].Height, headers[len(headers)-1].Height)
+	if this.onSkeletonReceive(fromID, headers) || this.onSegmentReceive(fromID, headers) {
+		this.notifySaveBlock()
+		this.syncHeader()
+		return
+	}
 	height := headers[0].Height
 	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
 
@@ -569,7 +653,7 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID p2pComm.PeerId, headers []*type
 	err := this.ledger.AddHeaders(headers)
 	this.delFlightHeader(height)
 	if err != nil {
-		this.addErrorRespCnt(fromID)
+		this.addErrorRespCnt(fromID, p2p.BadHeader, err.Error())
 		n := this.getNodeWeight(fromID)
 		if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
 			this.delNode(fromID)
@@ -580,7 +664,14 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID p2pComm.PeerId, headers []*type
 	sort.Slice(headers, func(i, j int) bool {
 		return headers[i].Height < headers[j].Height
 	})
-	curHeaderHeight = this.ledger.GetCurrentHeaderHeight()
+	this.addEmptyBlocks(fromID, headers)
+	this.notifySaveBlock()
+	this.syncHeader()
+}
+
+//addEmptyBlocks add empty blocks to cache, which are not requested since header is enough
+func (this *BlockSyncMgr) addEmptyBlocks(fromID p2pComm.PeerId, headers []*types.Header) {
+	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
 	for _, header := range headers {
 		prevHeader, err := this.ledger.GetHeaderByHeight(header.Height - 1)
@@ -608,8 +699,6 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID p2pComm.PeerId, headers []*type
 			this.addBlockCache(fromID, block, nil, common.UINT256_EMPTY)
 		}
 	}
-	go this.saveBlock()
-	this.syncHeader()
 }
 
 // OnBlockReceive receive block from net
@@ -637,7 +726,7 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID p2pComm.PeerId, blockSize uint32
 	}
 
 	this.addBlockCache(fromID, block, ccMsg, merkleRoot)
-	go this.saveBlock()
+	this.notifySaveBlock()
 	this.syncBlock()
 }
 
@@ -718,27 +807,8 @@ func (this *BlockSyncMgr) delBlockCache(blockHeight uint32) {
 	this.blocksCache.delBlockLocked(blockHeight)
 }
 
-func (this *BlockSyncMgr) tryGetSaveBlockLock() bool {
-	this.lock.Lock()
-	defer this.lock.Unlock()
-	if this.saveBlockLock {
-		return true
-	}
-	this.saveBlockLock = true
-	return false
-}
-
-func (this *BlockSyncMgr) releaseSaveBlockLock() {
-	this.lock.Lock()
-	defer this.lock.Unlock()
-	this.saveBlockLock = false
-}
-
+//saveBlock save continuous blocks in cache to ledger, should only be called by saveBlockLoop
 func (this *BlockSyncMgr) saveBlock() {
-	if this.tryGetSaveBlockLock() {
-		return
-	}
-	defer this.releaseSaveBlockLock()
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
 	nextBlockHeight := curBlockHeight + 1
 	this.clearBlocks(curBlockHeight)
@@ -750,10 +820,13 @@ func (this *BlockSyncMgr) saveBlock() {
 		err := this.ledger.AddBlock(nextBlock, ccMsg, merkleRoot)
 		this.delBlockCache(nextBlockHeight)
 		if err != nil {
-			this.addErrorRespCnt(fromID)
-			n := this.getNodeWeight(fromID)
-			if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
-				this.delNode(fromID)
+			//local failures of saving block are not the fault of the peer
+			if scom.IsInvalidBlock(err) {
+				this.addErrorRespCnt(fromID, p2p.InvalidBlock, err.Error())
+				n := this.getNodeWeight(fromID)
+				if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
+					this.delNode(fromID)
+				}
 			}
 			log.Warnf("[block-sync] saveBlock Height:%d AddBlock error:%s", nextBlockHeight, err)
 			reqNode := this.getNextNode(nextBlockHeight)
@@ -773,6 +846,10 @@ func (this *BlockSyncMgr) saveBlock() {
 		}
 		nextBlockHeight++
 		this.pingOutsyncNodes(nextBlockHeight - 1)
+		//refill the download window released by saved blocks
+		if (nextBlockHeight-curBlockHeight)%SYNC_BLOCK_FLIGHT_PER_NODE == 0 {
+			go this.syncBlock()
+		}
 	}
 }
 
@@ -908,6 +985,80 @@ func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
 	}
 }
 
+//getNextBlockNode return the best node with free window whose height is not less than nextBlockHeight,
+//and count the request in nodeFlights
+func (this *BlockSyncMgr) getNextBlockNode(nextBlockHeight uint32, windows map[p2pComm.PeerId]int,
+	nodeFlights map[p2pComm.PeerId]int) *peer.Peer {
+	weights := this.getAllNodeWeights()
+	sort.Sort(sort.Reverse(weights))
+	for _, w := range weights {
+		if nodeFlights[w.id] >= windows[w.id] {
+			continue
+		}
+		n := this.server.GetPeer(w.id)
+		if n == nil || nextBlockHeight > uint32(n.GetHeight()) {
+			continue
+		}
+		nodeFlights[w.id]++
+		return n
+	}
+	return nil
+}
+
+//getNodeFlightBlockCount return blocks on flight of each node
+func (this *BlockSyncMgr) getNodeFlightBlockCount() map[p2pComm.PeerId]int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	counts := make(map[p2pComm.PeerId]int)
+	for _, flightInfos := range this.flightBlocks {
+		for _, flightInfo := range flightInfos {
+			counts[flightInfo.GetNodeId()]++
+		}
+	}
+	return counts
+}
+
+//getBlockWindows return max blocks on flight of each node adapted to the measured speed, and the total window
+//limited by maxFlightBlocks
+func (this *BlockSyncMgr) getBlockWindows() (map[p2pComm.PeerId]int, int) {
+	weights := this.getAllNodeWeights()
+	windows := make(map[p2pComm.PeerId]int, len(weights))
+	if len(weights) == 0 {
+		return windows, 0
+	}
+	speeds := make([]float32, len(weights))
+	meanSpeed := float32(0.0)
+	for i, w := range weights {
+		speeds[i] = w.AvgSpeed()
+		meanSpeed += speeds[i]
+	}
+	meanSpeed = meanSpeed / float32(len(weights))
+	total := 0
+	for i, w := range weights {
+		windows[w.id] = calcBlockWindow(speeds[i], meanSpeed)
+		total += windows[w.id]
+	}
+	if total > this.maxFlightBlocks {
+		total = this.maxFlightBlocks
+	}
+	return windows, total
+}
+
+//calcBlockWindow scale the window of node by speed, node faster than average gets more blocks on flight
+func calcBlockWindow(speed, meanSpeed float32) int {
+	if meanSpeed <= 0 {
+		return SYNC_BLOCK_FLIGHT_PER_NODE
+	}
+	window := int(SYNC_BLOCK_FLIGHT_PER_NODE * speed / meanSpeed)
+	if window < SYNC_MIN_BLOCK_FLIGHT_PER_NODE {
+		return SYNC_MIN_BLOCK_FLIGHT_PER_NODE
+	}
+	if window > SYNC_MAX_BLOCK_FLIGHT_PER_NODE {
+		return SYNC_MAX_BLOCK_FLIGHT_PER_NODE
+	}
+	return window
+}
+
 func (this *BlockSyncMgr) getNodeWithMinFailedTimes(flightInfo *SyncFlightInfo, curBlockHeight uint32) *peer.Peer {
 	var minFailedTimes = math.MaxInt64
 	var minFailedTimesNode *peer.Peer
@@ -938,6 +1089,75 @@ func (this *BlockSyncMgr) Stop() {
 	close(this.exitCh)
 }
 
+//SyncStatus is the progress of block sync
+type SyncStatus struct {
+	CurrentBlockHeight  uint32
+	CurrentHeaderHeight uint32
+	TargetHeight        uint32  //max height of sync nodes
+	SkeletonHeight      uint32  //height of last skeleton header, 0 if headers are synced serially
+	SyncNodes           int     //count of sync nodes
+	FlightHeaders       int     //header requests on flight
+	FlightBlocks        int     //block requests on flight
+	CachedBlocks        int     //non-empty blocks waiting to commit to ledger
+	MaxFlightHeaders    int     //max header segments on flight
+	BlockWindow         int     //max blocks on flight, adapted to speed of sync nodes
+	MaxBlockCache       int     //max non-empty blocks in cache
+	BlocksPerSecond     float64 //blocks committed to ledger per second recently
+}
+
+//GetSyncStatus return the progress of block sync
+func (this *BlockSyncMgr) GetSyncStatus() *SyncStatus {
+	_, window := this.getBlockWindows()
+	status := &SyncStatus{
+		CurrentBlockHeight:  this.ledger.GetCurrentBlockHeight(),
+		CurrentHeaderHeight: this.ledger.GetCurrentHeaderHeight(),
+		SkeletonHeight:      this.getSkeletonHeight(),
+		FlightHeaders:       this.getFlightHeaderCount() + this.getFlightSegmentCount(),
+		FlightBlocks:        this.getFlightBlockCount(),
+		CachedBlocks:        this.getNonEmptyBlockCount(),
+		MaxFlightHeaders:    this.maxFlightHeaders,
+		BlockWindow:         window,
+		MaxBlockCache:       this.maxBlockCache,
+		BlocksPerSecond:     this.getBlocksPerSecond(),
+	}
+	for _, w := range this.getAllNodeWeights() {
+		n := this.server.GetPeer(w.id)
+		if n == nil {
+			continue
+		}
+		status.SyncNodes++
+		if height := uint32(n.GetHeight()); height > status.TargetHeight {
+			status.TargetHeight = height
+		}
+	}
+	return status
+}
+
+//recordProgress sample current block height, keeping samples of recent SYNC_PROGRESS_SAMPLE_SIZE seconds
+func (this *BlockSyncMgr) recordProgress() {
+	sample := progressSample{time: time.Now(), height: this.ledger.GetCurrentBlockHeight()}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.progress = append(this.progress, sample)
+	if len(this.progress) > SYNC_PROGRESS_SAMPLE_SIZE {
+		this.progress = this.progress[len(this.progress)-SYNC_PROGRESS_SAMPLE_SIZE:]
+	}
+}
+
+func (this *BlockSyncMgr) getBlocksPerSecond() float64 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if len(this.progress) < 2 {
+		return 0
+	}
+	first, last := this.progress[0], this.progress[len(this.progress)-1]
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 || last.height < first.height {
+		return 0
+	}
+	return float64(last.height-first.height) / elapsed
+}
+
 //getNodeWeight get nodeweight by id
 func (this *BlockSyncMgr) getNodeWeight(nodeId p2pComm.PeerId) *NodeWeight {
 	this.lock.RLock()
@@ -962,14 +1182,16 @@ func (this *BlockSyncMgr) addTimeoutCnt(nodeId p2pComm.PeerId) {
 	if n != nil {
 		n.AddTimeoutCnt()
 	}
+	this.server.ReportMisbehavior(nodeId, p2p.RequestTimeout, "block sync")
 }
 
-//addErrorRespCnt incre a node's error resp count
-func (this *BlockSyncMgr) addErrorRespCnt(nodeId p2pComm.PeerId) {
+//addErrorRespCnt incre a node's error resp count, and report the misbehavior
+func (this *BlockSyncMgr) addErrorRespCnt(nodeId p2pComm.PeerId, kind p2p.Misbehavior, detail string) {
 	n := this.getNodeWeight(nodeId)
 	if n != nil {
 		n.AddErrorRespCnt()
 	}
+	this.server.ReportMisbehavior(nodeId, kind, detail)
 }
 
 //appendReqTime append a node's request time
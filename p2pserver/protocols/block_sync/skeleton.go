@@ -0,0 +1,451 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package block_sync
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+	p2pComm "github.com/ontio/ontology/p2pserver/common"
+	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
+	msgTypes "github.com/ontio/ontology/p2pserver/message/types"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/peer"
+)
+
+//headerSegment is the headers between two skeleton headers. Segments are filled in from different nodes in parallel
+type headerSegment struct {
+	startHeight uint32          //height of the header before segment
+	startHash   common.Uint256  //hash of the header before segment
+	endHeight   uint32          //height of the skeleton header at segment end
+	endHash     common.Uint256  //hash of the skeleton header at segment end
+	flight      *SyncFlightInfo //request on flight, nil if not requested
+	fromID      p2pComm.PeerId  //node delivered headers
+	headers     []*types.Header //headers received, waiting to add to ledger
+}
+
+//isIdle return true if segment is neither requested nor received
+func (this *headerSegment) isIdle() bool {
+	return this.flight == nil && this.headers == nil
+}
+
+//match return true if headers are the response of segment request
+func (this *headerSegment) match(headers []*types.Header) bool {
+	if len(headers) == 0 {
+		return false
+	}
+	return headers[0].Height == this.startHeight+1 && headers[len(headers)-1].Height == this.endHeight
+}
+
+//fill check headers link from start hash to end hash, and save them
+func (this *headerSegment) fill(fromID p2pComm.PeerId, headers []*types.Header) error {
+	if uint32(len(headers)) != this.endHeight-this.startHeight {
+		return fmt.Errorf("segment %d-%d expect %d headers, got %d", this.startHeight+1, this.endHeight,
+			this.endHeight-this.startHeight, len(headers))
+	}
+	prevHash := this.startHash
+	for i, header := range headers {
+		if header.Height != this.startHeight+uint32(i)+1 {
+			return fmt.Errorf("segment %d-%d unexpected header height %d", this.startHeight+1, this.endHeight, header.Height)
+		}
+		if header.PrevBlockHash != prevHash {
+			return fmt.Errorf("segment %d-%d header %d does not link to previous header", this.startHeight+1, this.endHeight, header.Height)
+		}
+		prevHash = header.Hash()
+	}
+	if prevHash != this.endHash {
+		return fmt.Errorf("segment %d-%d does not match skeleton", this.startHeight+1, this.endHeight)
+	}
+	this.flight = nil
+	this.fromID = fromID
+	this.headers = headers
+	return nil
+}
+
+//reset clear segment for request again
+func (this *headerSegment) reset() {
+	this.flight = nil
+	this.headers = nil
+}
+
+//headerSkeleton is the headers at fixed interval from one node, segments between them are filled in from other nodes
+type headerSkeleton struct {
+	nodeId   p2pComm.PeerId   //node delivered skeleton
+	segments []*headerSegment //ascending by height
+}
+
+//newHeaderSkeleton check skeleton headers are at height startHeight + k*interval, and split segments between them
+func newHeaderSkeleton(nodeId p2pComm.PeerId, startHeight uint32, startHash common.Uint256, interval uint32,
+	headers []*types.Header) (*headerSkeleton, error) {
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("empty skeleton")
+	}
+	skeleton := &headerSkeleton{nodeId: nodeId}
+	prevHeight, prevHash := startHeight, startHash
+	for i, header := range headers {
+		if header.Height != startHeight+uint32(i+1)*interval {
+			return nil, fmt.Errorf("unexpected skeleton header height %d", header.Height)
+		}
+		hash := header.Hash()
+		skeleton.segments = append(skeleton.segments, &headerSegment{
+			startHeight: prevHeight,
+			startHash:   prevHash,
+			endHeight:   header.Height,
+			endHash:     hash,
+		})
+		prevHeight, prevHash = header.Height, hash
+	}
+	return skeleton, nil
+}
+
+//findSegment return the segment of headers
+func (this *headerSkeleton) findSegment(headers []*types.Header) *headerSegment {
+	for _, seg := range this.segments {
+		if seg.match(headers) {
+			return seg
+		}
+	}
+	return nil
+}
+
+//idleSegments return segments neither requested nor received, at most max
+func (this *headerSkeleton) idleSegments(max int) []*headerSegment {
+	var segs []*headerSegment
+	for _, seg := range this.segments {
+		if len(segs) >= max {
+			break
+		}
+		if seg.isIdle() {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+//flightSegments return segments on flight
+func (this *headerSkeleton) flightSegments() []*headerSegment {
+	var segs []*headerSegment
+	for _, seg := range this.segments {
+		if seg.flight != nil {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+//popReady remove and return the first segment if it continues from height and headers are received
+func (this *headerSkeleton) popReady(height uint32) *headerSegment {
+	if len(this.segments) == 0 {
+		return nil
+	}
+	seg := this.segments[0]
+	if seg.startHeight != height || seg.headers == nil {
+		return nil
+	}
+	this.segments = this.segments[1:]
+	return seg
+}
+
+//isDone return true if all segments are added to ledger
+func (this *headerSkeleton) isDone() bool {
+	return len(this.segments) == 0
+}
+
+//endHeight return the height of last skeleton header
+func (this *headerSkeleton) endHeight() uint32 {
+	if len(this.segments) == 0 {
+		return 0
+	}
+	return this.segments[len(this.segments)-1].endHeight
+}
+
+//supportSkeleton return true if node of version can serve skeleton request
+func supportSkeleton(version string) bool {
+	if version == "" {
+		return false
+	}
+	v1, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+	min, err := semver.ParseTolerant(p2pComm.MIN_VERSION_FOR_SKELETON)
+	if err != nil {
+		panic(err) // enforced by testcase
+	}
+
+	return v1.Compare(min) >= 0
+}
+
+//headerRequest is a request to send after skeleton lock released
+type headerRequest struct {
+	node *peer.Peer
+	msg  msgTypes.Message
+}
+
+func (this *BlockSyncMgr) sendHeaderRequests(reqs []headerRequest) {
+	for _, req := range reqs {
+		err := this.server.Send(req.node, req.msg)
+		if err != nil {
+			log.Warnf("[block-sync] send header request to node:%d error:%s", req.node.GetID(), err)
+			continue
+		}
+		this.appendReqTime(req.node.GetID())
+	}
+}
+
+//getSkeletonNode return the best node which supports skeleton and whose height is not less than minHeight
+func (this *BlockSyncMgr) getSkeletonNode(minHeight uint32) *peer.Peer {
+	weights := this.getAllNodeWeights()
+	sort.Sort(sort.Reverse(weights))
+	for _, w := range weights {
+		n := this.server.GetPeer(w.id)
+		if n == nil || uint32(n.GetHeight()) < minHeight {
+			continue
+		}
+		if supportSkeleton(n.GetSoftVersion()) {
+			return n
+		}
+	}
+	return nil
+}
+
+//getSegmentNode return the best node not busy, whose height is not less than segment end, and with min failed times
+func (this *BlockSyncMgr) getSegmentNode(seg *headerSegment, busy map[p2pComm.PeerId]bool) *peer.Peer {
+	weights := this.getAllNodeWeights()
+	sort.Sort(sort.Reverse(weights))
+	var best *peer.Peer
+	minFailedTimes := 0
+	for _, w := range weights {
+		if busy[w.id] {
+			continue
+		}
+		n := this.server.GetPeer(w.id)
+		if n == nil || uint32(n.GetHeight()) < seg.endHeight {
+			continue
+		}
+		failedTimes := 0
+		if seg.flight != nil {
+			failedTimes = seg.flight.GetFailedTimes(w.id)
+		}
+		if best == nil || failedTimes < minFailedTimes {
+			best, minFailedTimes = n, failedTimes
+		}
+		if failedTimes == 0 {
+			break
+		}
+	}
+	return best
+}
+
+//syncSkeleton request skeleton headers from one node, and fill in segments between them from multiple nodes
+//in parallel. Return false if no node supports skeleton or headers are close to the best height, then headers
+//are synced serially.
+func (this *BlockSyncMgr) syncSkeleton(curHeaderHeight uint32) bool {
+	this.skeletonLock.Lock()
+	if this.skeletonReq != nil {
+		this.skeletonLock.Unlock()
+		return true
+	}
+	//headers are added by other ways, such as consensus
+	if this.skeleton != nil && this.skeleton.segments[0].startHeight != curHeaderHeight {
+		log.Infof("[block-sync] drop skeleton from node:%s, header height changed to %d",
+			this.skeleton.nodeId.ToHexString(), curHeaderHeight)
+		this.skeleton = nil
+	}
+	if this.skeleton == nil {
+		this.skeletonLock.Unlock()
+		return this.requestSkeleton(curHeaderHeight)
+	}
+	reqs := this.fillSegments()
+	this.skeletonLock.Unlock()
+	this.sendHeaderRequests(reqs)
+	return true
+}
+
+func (this *BlockSyncMgr) requestSkeleton(curHeaderHeight uint32) bool {
+	reqNode := this.getSkeletonNode(curHeaderHeight + 2*SYNC_SKELETON_INTERVAL)
+	if reqNode == nil {
+		return false
+	}
+	curHeaderHash := this.ledger.GetCurrentHeaderHash()
+	count := (uint32(reqNode.GetHeight()) - curHeaderHeight) / SYNC_SKELETON_INTERVAL
+	if count > SYNC_SKELETON_SIZE {
+		count = SYNC_SKELETON_SIZE
+	}
+	this.skeletonLock.Lock()
+	this.skeletonReq = &skeletonRequest{
+		flight:      NewSyncFlightInfo(curHeaderHeight, reqNode.GetID()),
+		startHeight: curHeaderHeight,
+		startHash:   curHeaderHash,
+	}
+	this.skeletonLock.Unlock()
+	msg := msgpack.NewSkeletonReq(curHeaderHeight, SYNC_SKELETON_INTERVAL, count)
+	this.sendHeaderRequests([]headerRequest{{node: reqNode, msg: msg}})
+	log.Infof("[block-sync] request skeleton from node:%d, height:%d, count:%d",
+		reqNode.GetID(), curHeaderHeight, count)
+	return true
+}
+
+//fillSegments assign idle segments to nodes, one segment for each node. Should be called with skeletonLock held
+func (this *BlockSyncMgr) fillSegments() []headerRequest {
+	flights := this.skeleton.flightSegments()
+	busy := make(map[p2pComm.PeerId]bool, len(flights))
+	for _, seg := range flights {
+		busy[seg.flight.GetNodeId()] = true
+	}
+	var reqs []headerRequest
+	for _, seg := range this.skeleton.idleSegments(this.maxFlightHeaders - len(flights)) {
+		reqNode := this.getSegmentNode(seg, busy)
+		if reqNode == nil {
+			break
+		}
+		busy[reqNode.GetID()] = true
+		seg.flight = NewSyncFlightInfo(seg.endHeight, reqNode.GetID())
+		reqs = append(reqs, headerRequest{node: reqNode, msg: msgpack.NewHeadersRangeReq(seg.endHash, seg.startHash)})
+	}
+	return reqs
+}
+
+//onSkeletonReceive handle skeleton response, return false if headers are not skeleton
+func (this *BlockSyncMgr) onSkeletonReceive(fromID p2pComm.PeerId, headers []*types.Header) bool {
+	this.skeletonLock.Lock()
+	defer this.skeletonLock.Unlock()
+	req := this.skeletonReq
+	if req == nil || req.flight.GetNodeId() != fromID || headers[0].Height != req.startHeight+SYNC_SKELETON_INTERVAL {
+		return false
+	}
+	this.skeletonReq = nil
+	skeleton, err := newHeaderSkeleton(fromID, req.startHeight, req.startHash, SYNC_SKELETON_INTERVAL, headers)
+	if err != nil {
+		log.Warnf("[block-sync] skeleton from node:%s error:%s", fromID.ToHexString(), err)
+		this.addErrorRespCnt(fromID, p2p.BadHeader, err.Error())
+		return true
+	}
+	this.skeleton = skeleton
+	return true
+}
+
+//onSegmentReceive handle segment response, and add continuous segments to ledger. Return false if headers are not
+//in skeleton
+func (this *BlockSyncMgr) onSegmentReceive(fromID p2pComm.PeerId, headers []*types.Header) bool {
+	this.skeletonLock.Lock()
+	defer this.skeletonLock.Unlock()
+	if this.skeleton == nil {
+		return false
+	}
+	seg := this.skeleton.findSegment(headers)
+	if seg == nil {
+		return false
+	}
+	if seg.headers != nil {
+		return true
+	}
+	if err := seg.fill(fromID, headers); err != nil {
+		log.Warnf("[block-sync] segment from node:%s error:%s", fromID.ToHexString(), err)
+		this.addErrorRespCnt(fromID, p2p.BadHeader, err.Error())
+		seg.reset()
+		return true
+	}
+	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
+	for seg := this.skeleton.popReady(curHeaderHeight); seg != nil; seg = this.skeleton.popReady(curHeaderHeight) {
+		if err := this.ledger.AddHeaders(seg.headers); err != nil {
+			//segment links to skeleton, so both nodes deliver invalid headers
+			log.Warnf("[block-sync] segment %d-%d AddHeaders error:%s", seg.startHeight+1, seg.endHeight, err)
+			this.addErrorRespCnt(seg.fromID, p2p.BadHeader, err.Error())
+			this.addErrorRespCnt(this.skeleton.nodeId, p2p.BadHeader, err.Error())
+			this.skeleton = nil
+			return true
+		}
+		this.addEmptyBlocks(seg.fromID, seg.headers)
+		curHeaderHeight = seg.endHeight
+	}
+	if this.skeleton.isDone() {
+		this.skeleton = nil
+	}
+	return true
+}
+
+//checkSkeletonTimeout retry timeout segments from other nodes, drop skeleton if segment failed too many times
+func (this *BlockSyncMgr) checkSkeletonTimeout(now int64) {
+	this.skeletonLock.Lock()
+	var reqs []headerRequest
+	defer func() {
+		this.skeletonLock.Unlock()
+		this.sendHeaderRequests(reqs)
+	}()
+	if req := this.skeletonReq; req != nil && timeDiff(now, req.flight.GetStartTime()) >= SYNC_HEADER_REQUEST_TIMEOUT {
+		log.Infof("[block-sync] skeleton request to node:%d timeout", req.flight.GetNodeId())
+		this.addTimeoutCnt(req.flight.GetNodeId())
+		this.skeletonReq = nil
+	}
+	if this.skeleton == nil {
+		return
+	}
+	for _, seg := range this.skeleton.flightSegments() {
+		flight := seg.flight
+		if timeDiff(now, flight.GetStartTime()) < SYNC_HEADER_REQUEST_TIMEOUT {
+			continue
+		}
+		log.Infof("[block-sync] segment %d-%d request to node:%d timeout", seg.startHeight+1, seg.endHeight,
+			flight.GetNodeId())
+		this.addTimeoutCnt(flight.GetNodeId())
+		flight.MarkFailedNode()
+		if flight.GetTotalFailedTimes() >= SYNC_MAX_SEGMENT_FAILED_TIMES {
+			//no node can serve the segment, skeleton may be fake
+			this.addErrorRespCnt(this.skeleton.nodeId, p2p.BadHeader, "no node can serve skeleton segment")
+			this.skeleton = nil
+			return
+		}
+		reqNode := this.getSegmentNode(seg, nil)
+		if reqNode == nil {
+			continue
+		}
+		flight.SetNodeId(reqNode.GetID())
+		flight.ResetStartTime()
+		reqs = append(reqs, headerRequest{node: reqNode, msg: msgpack.NewHeadersRangeReq(seg.endHash, seg.startHash)})
+	}
+}
+
+//getSkeletonHeight return the height of last skeleton header, 0 if not syncing by skeleton
+func (this *BlockSyncMgr) getSkeletonHeight() uint32 {
+	this.skeletonLock.Lock()
+	defer this.skeletonLock.Unlock()
+	if this.skeleton == nil {
+		return 0
+	}
+	return this.skeleton.endHeight()
+}
+
+//getFlightSegmentCount return count of segment and skeleton requests on flight
+func (this *BlockSyncMgr) getFlightSegmentCount() int {
+	this.skeletonLock.Lock()
+	defer this.skeletonLock.Unlock()
+	count := 0
+	if this.skeletonReq != nil {
+		count++
+	}
+	if this.skeleton != nil {
+		count += len(this.skeleton.flightSegments())
+	}
+	return count
+}
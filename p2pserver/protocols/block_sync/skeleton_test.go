@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package block_sync
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+	p2pComm "github.com/ontio/ontology/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildHeaders(prevHash common.Uint256, startHeight, count uint32) []*types.Header {
+	headers := make([]*types.Header, 0, count)
+	for i := uint32(0); i < count; i++ {
+		header := &types.Header{Height: startHeight + i, PrevBlockHash: prevHash}
+		prevHash = header.Hash()
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+func pickSkeleton(headers []*types.Header, interval uint32) []*types.Header {
+	var skeleton []*types.Header
+	for i := interval - 1; i < uint32(len(headers)); i += interval {
+		skeleton = append(skeleton, headers[i])
+	}
+	return skeleton
+}
+
+func TestHeaderSkeleton(t *testing.T) {
+	genesis := &types.Header{Height: 10}
+	headers := buildHeaders(genesis.Hash(), 11, 30)
+	nodeId := p2pComm.PseudoPeerIdFromUint64(1)
+
+	_, err := newHeaderSkeleton(nodeId, 10, genesis.Hash(), 10, headers[:3])
+	assert.NotNil(t, err)
+
+	skeleton, err := newHeaderSkeleton(nodeId, 10, genesis.Hash(), 10, pickSkeleton(headers, 10))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(skeleton.segments))
+	assert.Equal(t, uint32(40), skeleton.endHeight())
+	assert.Equal(t, 2, len(skeleton.idleSegments(2)))
+
+	//fill in out of order
+	seg := skeleton.findSegment(headers[10:20])
+	assert.NotNil(t, seg)
+	assert.Nil(t, seg.fill(nodeId, headers[10:20]))
+	assert.Nil(t, skeleton.popReady(10))
+
+	seg = skeleton.findSegment(headers[:10])
+	assert.Nil(t, seg.fill(nodeId, headers[:10]))
+	assert.Equal(t, uint32(20), skeleton.popReady(10).endHeight)
+	assert.Equal(t, uint32(30), skeleton.popReady(20).endHeight)
+	assert.Nil(t, skeleton.popReady(30))
+	assert.False(t, skeleton.isDone())
+}
+
+func TestSegmentFillMismatch(t *testing.T) {
+	genesis := &types.Header{Height: 0}
+	headers := buildHeaders(genesis.Hash(), 1, 20)
+	nodeId := p2pComm.PseudoPeerIdFromUint64(1)
+	skeleton, err := newHeaderSkeleton(nodeId, 0, genesis.Hash(), 10, pickSkeleton(headers, 10))
+	assert.Nil(t, err)
+
+	//headers of another chain do not link to skeleton
+	fork := buildHeaders(genesis.Hash(), 1, 10)
+	fork[9] = &types.Header{Height: 10, PrevBlockHash: fork[8].Hash(), Timestamp: 1}
+	seg := skeleton.findSegment(fork)
+	assert.NotNil(t, seg)
+	assert.NotNil(t, seg.fill(nodeId, fork))
+
+	assert.NotNil(t, seg.fill(nodeId, headers[:9]))
+	assert.NotNil(t, seg.fill(nodeId, append(headers[1:10:10], headers[0])))
+	assert.Nil(t, seg.fill(nodeId, headers[:10]))
+}
+
+func TestCalcBlockWindow(t *testing.T) {
+	assert.Equal(t, SYNC_BLOCK_FLIGHT_PER_NODE, calcBlockWindow(100, 0))
+	assert.Equal(t, SYNC_BLOCK_FLIGHT_PER_NODE, calcBlockWindow(100, 100))
+	assert.Equal(t, 2*SYNC_BLOCK_FLIGHT_PER_NODE, calcBlockWindow(200, 100))
+	assert.Equal(t, SYNC_MIN_BLOCK_FLIGHT_PER_NODE, calcBlockWindow(1, 100))
+	assert.Equal(t, SYNC_MAX_BLOCK_FLIGHT_PER_NODE, calcBlockWindow(10000, 100))
+}
+
+func TestSupportSkeleton(t *testing.T) {
+	assert.False(t, supportSkeleton(""))
+	assert.False(t, supportSkeleton("v1.10.0"))
+	assert.True(t, supportSkeleton(p2pComm.MIN_VERSION_FOR_SKELETON))
+	assert.True(t, supportSkeleton("v2.2.0"))
+}
@@ -23,10 +23,10 @@ import (
 	"fmt"
 
 	lru "github.com/hashicorp/golang-lru"
-	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/consensus/signer"
 	"github.com/ontio/ontology/core/ledger"
 	"github.com/ontio/ontology/core/types"
 	actor "github.com/ontio/ontology/p2pserver/actor/req"
@@ -40,8 +40,10 @@ import (
 	"github.com/ontio/ontology/p2pserver/protocols/heatbeat"
 	"github.com/ontio/ontology/p2pserver/protocols/recent_peers"
 	"github.com/ontio/ontology/p2pserver/protocols/reconnect"
+	"github.com/ontio/ontology/p2pserver/protocols/reputation"
 	"github.com/ontio/ontology/p2pserver/protocols/subnet"
 	"github.com/ontio/ontology/p2pserver/protocols/utils"
+	tc "github.com/ontio/ontology/txnpool/common"
 )
 
 //respCache cache for some response data
@@ -60,20 +62,26 @@ type MsgHandler struct {
 	bootstrap                *bootstrap.BootstrapService
 	persistRecentPeerService *recent_peers.PersistRecentPeerService
 	subnet                   *subnet.SubNet
+	reputation               *reputation.ReputationService
 	ledger                   *ledger.Ledger
-	acct                     *account.Account // nil if conenesus is not enabled
+	signer                   signer.Signer // nil if conenesus is not enabled
 	staticReserveFilter      p2p.AddressFilter
+	msgLimiter               *MsgRateLimiter
 }
 
-func NewMsgHandler(acct *account.Account, staticReserveFilter p2p.AddressFilter, ld *ledger.Ledger, logger msgCommon.Logger) *MsgHandler {
+func NewMsgHandler(consSigner signer.Signer, staticReserveFilter p2p.AddressFilter, ld *ledger.Ledger, logger msgCommon.Logger) *MsgHandler {
 	gov := utils.NewGovNodeResolver(ld)
 	seedsList := config.DefConfig.Genesis.SeedList
 	seeds, invalid := utils.NewHostsResolver(seedsList)
 	if invalid != nil {
 		panic(fmt.Errorf("invalid seed list； %v", invalid))
 	}
-	subNet := subnet.NewSubNet(acct, seeds, gov, logger)
-	return &MsgHandler{ledger: ld, seeds: seeds, subnet: subNet, acct: acct, staticReserveFilter: staticReserveFilter}
+	subNet := subnet.NewSubNet(consSigner, seeds, gov, logger)
+	// peer id can be banned only if it is authenticated by node key, reserved peers and consensus peers are not scored
+	exempt := p2p.CombineAddrFilter(staticReserveFilter, subNet.GetMemberAddrFilter())
+	rep := reputation.NewReputationService(msgCommon.BAN_FILE_NAME, config.DefConfig.P2PNode.EnableNoise, exempt)
+	return &MsgHandler{ledger: ld, seeds: seeds, subnet: subNet, reputation: rep, signer: consSigner,
+		staticReserveFilter: staticReserveFilter, msgLimiter: NewMsgRateLimiter()}
 }
 
 func (self *MsgHandler) GetReservedAddrFilter(staticFilterEnabled bool) p2p.AddressFilter {
@@ -84,10 +92,23 @@ func (self *MsgHandler) GetMaskAddrFilter() p2p.AddressFilter {
 	return self.subnet.GetMaskAddrFilter()
 }
 
+//GetReputation return the peer reputation service, which bans misbehaving peers
+func (self *MsgHandler) GetReputation() *reputation.ReputationService {
+	return self.reputation
+}
+
 func (self *MsgHandler) GetSubnetMembersInfo() []msgCommon.SubnetMemberInfo {
 	return self.subnet.GetMembersInfo()
 }
 
+//GetSyncStatus return the progress of block sync, nil if block sync is not started
+func (self *MsgHandler) GetSyncStatus() *block_sync.SyncStatus {
+	if self.blockSync == nil {
+		return nil
+	}
+	return self.blockSync.GetSyncStatus()
+}
+
 func (self *MsgHandler) start(net p2p.P2P) {
 	self.blockSync = block_sync.NewBlockSyncMgr(net, self.ledger)
 	self.reconnect = reconnect.NewReconectService(net, self.staticReserveFilter)
@@ -103,6 +124,7 @@ func (self *MsgHandler) start(net p2p.P2P) {
 	go self.heatBeat.Start()
 	go self.bootstrap.Start()
 	go self.subnet.Start(net)
+	self.reputation.Start(net)
 
 	RegisterProposeOfflineVote(self.subnet)
 }
@@ -115,6 +137,7 @@ func (self *MsgHandler) stop() {
 	self.heatBeat.Stop()
 	self.bootstrap.Stop()
 	self.subnet.Stop()
+	self.reputation.Stop()
 }
 
 func (self *MsgHandler) HandleSystemMessage(net p2p.P2P, msg p2p.SystemMessage) {
@@ -135,6 +158,7 @@ func (self *MsgHandler) HandleSystemMessage(net p2p.P2P, msg p2p.SystemMessage)
 		self.bootstrap.OnDelPeer(m.Info)
 		self.subnet.OnDelPeer(m.Info)
 		self.persistRecentPeerService.DelNodeAddr(m.Info.RemoteListenAddress())
+		self.msgLimiter.DelPeer(m.Info.Id)
 	case p2p.NetworkStop:
 		self.stop()
 	case p2p.HostAddrDetected:
@@ -144,6 +168,16 @@ func (self *MsgHandler) HandleSystemMessage(net p2p.P2P, msg p2p.SystemMessage)
 
 func (self *MsgHandler) HandlePeerMessage(ctx *p2p.Context, msg msgTypes.Message) {
 	log.Trace("[p2p]receive message", ctx.Sender().GetAddr(), ctx.Sender().GetID())
+	if allow, disconnect := self.msgLimiter.Check(ctx.Sender().GetID(), msg.CmdType()); !allow {
+		if disconnect {
+			log.Warnf("[p2p]peer %s sends too many %s messages, disconnect", ctx.Sender().GetAddr(), msg.CmdType())
+			ctx.Network().ReportMisbehavior(ctx.Sender().GetID(), p2p.Spam, "too many "+msg.CmdType()+" messages")
+			ctx.Sender().Close()
+		} else {
+			log.Debugf("[p2p]drop %s message from peer %s, rate limit exceeded", msg.CmdType(), ctx.Sender().GetAddr())
+		}
+		return
+	}
 	switch m := msg.(type) {
 	case *msgTypes.AddrReq:
 		self.discovery.AddrReqHandle(ctx)
@@ -153,6 +187,8 @@ func (self *MsgHandler) HandlePeerMessage(ctx *p2p.Context, msg msgTypes.Message
 		self.discovery.FindNodeHandle(ctx, m)
 	case *msgTypes.HeadersReq:
 		HeadersReqHandle(ctx, m)
+	case *msgTypes.SkeletonReq:
+		SkeletonReqHandle(ctx, m)
 	case *msgTypes.Ping:
 		self.heatBeat.PingHandle(ctx, m)
 	case *msgTypes.Pong:
@@ -208,11 +244,30 @@ func HeadersReqHandle(ctx *p2p.Context, headersReq *msgTypes.HeadersReq) {
 	}
 }
 
+// SkeletonReqHandle handles the skeleton header sync req from peer
+func SkeletonReqHandle(ctx *p2p.Context, req *msgTypes.SkeletonReq) {
+	headers, err := GetSkeletonHeaders(req.StartHeight, req.Interval, req.Count)
+	if err != nil {
+		log.Debugf("SkeletonReqHandle error: %s, start:%d, interval:%d, count:%d", err, req.StartHeight, req.Interval, req.Count)
+		return
+	}
+	if len(headers) == 0 {
+		return
+	}
+	remotePeer := ctx.Sender()
+	err = remotePeer.Send(msgpack.NewHeaders(headers))
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+}
+
 // blockHandle handles the block message from peer
 func (self *MsgHandler) blockHandle(ctx *p2p.Context, block *msgTypes.Block) {
 	stateHashHeight := config.GetStateHashCheckHeight(config.DefConfig.P2PNode.NetworkId)
 	if block.Blk.Header.Height >= stateHashHeight && block.MerkleRoot == common.UINT256_EMPTY {
 		remotePeer := ctx.Sender()
+		ctx.Network().ReportMisbehavior(remotePeer.GetID(), p2p.InvalidBlock, "block without merkle root")
 		remotePeer.Close()
 		return
 	}
@@ -226,6 +281,7 @@ func ConsensusHandle(ctx *p2p.Context, consensus *msgTypes.Consensus) {
 	if cpid != nil {
 		if err := consensus.Cons.Verify(); err != nil {
 			log.Warn(err)
+			ctx.Network().ReportMisbehavior(ctx.Sender().GetID(), p2p.ProtocolViolation, err.Error())
 			return
 		}
 		consensus.Cons.PeerId = ctx.Sender().GetID()
@@ -235,6 +291,11 @@ func ConsensusHandle(ctx *p2p.Context, consensus *msgTypes.Consensus) {
 
 // TransactionHandle handles the transaction message from peer
 func TransactionHandle(ctx *p2p.Context, trn *msgTypes.Trn) {
+	if ctx.MsgSize > tc.MAX_TX_SIZE {
+		ctx.Network().ReportMisbehavior(ctx.Sender().GetID(), p2p.InvalidTx,
+			fmt.Sprintf("tx %x size %d over limit", trn.Txn.Hash(), ctx.MsgSize))
+		return
+	}
 	if !txCache.Contains(trn.Txn.Hash()) {
 		txCache.Add(trn.Txn.Hash(), nil)
 		actor.AddTransaction(trn.Txn)
@@ -471,6 +532,30 @@ func GetHeadersFromHash(startHash common.Uint256, stopHash common.Uint256) ([]*t
 	return headers, nil
 }
 
+//GetSkeletonHeaders return headers at height startHeight + k*interval, k = 1..count, stop at current header height
+func GetSkeletonHeaders(startHeight, interval, count uint32) ([]*types.RawHeader, error) {
+	if interval == 0 || interval > msgCommon.MAX_BLK_HDR_CNT {
+		return nil, fmt.Errorf("invalid skeleton interval:%d", interval)
+	}
+	if count > msgCommon.MAX_SKELETON_CNT {
+		count = msgCommon.MAX_SKELETON_CNT
+	}
+	curHeight := ledger.DefLedger.GetCurrentHeaderHeight()
+	var headers []*types.RawHeader
+	for i := uint32(1); i <= count; i++ {
+		height := uint64(startHeight) + uint64(i)*uint64(interval)
+		if height > uint64(curHeight) {
+			break
+		}
+		header, err := ledger.DefLedger.GetRawHeaderByHash(ledger.DefLedger.GetBlockHash(uint32(height)))
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
 //getRespCacheValue get response data from cache
 func getRespCacheValue(key string) interface{} {
 	data, ok := respCache.Get(key)
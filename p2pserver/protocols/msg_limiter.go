@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package protocols
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology/p2pserver/common"
+)
+
+type peerMsgLimiter struct {
+	msgs       map[string]*common.RateLimiter
+	violations *common.RateLimiter
+}
+
+func newPeerMsgLimiter() *peerMsgLimiter {
+	limiter := &peerMsgLimiter{
+		msgs: make(map[string]*common.RateLimiter, len(common.MSG_RATE_LIMITS)),
+		violations: common.NewRateLimiter(float64(common.RATE_LIMIT_MAX_VIOLATIONS)/60,
+			common.RATE_LIMIT_MAX_VIOLATIONS),
+	}
+	for cmd, rate := range common.MSG_RATE_LIMITS {
+		limiter.msgs[cmd] = common.NewRateLimiter(rate, rate*common.RATE_LIMIT_BURST)
+	}
+	return limiter
+}
+
+//MsgRateLimiter limits the request messages of each type from every peer
+type MsgRateLimiter struct {
+	lock  sync.Mutex
+	peers map[common.PeerId]*peerMsgLimiter
+}
+
+func NewMsgRateLimiter() *MsgRateLimiter {
+	return &MsgRateLimiter{peers: make(map[common.PeerId]*peerMsgLimiter)}
+}
+
+//Check return whether the message should be handled, and whether the peer has too many dropped messages and
+//should be disconnected
+func (self *MsgRateLimiter) Check(id common.PeerId, cmdType string) (allow bool, disconnect bool) {
+	if _, limited := common.MSG_RATE_LIMITS[cmdType]; !limited {
+		return true, false
+	}
+	self.lock.Lock()
+	limiter, ok := self.peers[id]
+	if !ok {
+		limiter = newPeerMsgLimiter()
+		self.peers[id] = limiter
+	}
+	self.lock.Unlock()
+
+	if limiter.msgs[cmdType].Allow(1) {
+		return true, false
+	}
+	return false, !limiter.violations.Allow(1)
+}
+
+func (self *MsgRateLimiter) DelPeer(id common.PeerId) {
+	self.lock.Lock()
+	delete(self.peers, id)
+	self.lock.Unlock()
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package protocols
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgRateLimiter(t *testing.T) {
+	limiter := NewMsgRateLimiter()
+	id := common.PseudoPeerIdFromUint64(1)
+
+	burst := int(common.MSG_RATE_LIMITS[common.GetADDR_TYPE] * common.RATE_LIMIT_BURST)
+	for i := 0; i < burst; i++ {
+		allow, _ := limiter.Check(id, common.GetADDR_TYPE)
+		assert.True(t, allow)
+	}
+	allow, disconnect := limiter.Check(id, common.GetADDR_TYPE)
+	assert.False(t, allow)
+	assert.False(t, disconnect)
+
+	//other peers and unlimited msg types are not affected
+	allow, _ = limiter.Check(common.PseudoPeerIdFromUint64(2), common.GetADDR_TYPE)
+	assert.True(t, allow)
+	allow, _ = limiter.Check(id, common.BLOCK_TYPE)
+	assert.True(t, allow)
+
+	for i := 0; i < common.RATE_LIMIT_MAX_VIOLATIONS; i++ {
+		limiter.Check(id, common.GetADDR_TYPE)
+	}
+	allow, disconnect = limiter.Check(id, common.GetADDR_TYPE)
+	assert.False(t, allow)
+	assert.True(t, disconnect)
+
+	limiter.DelPeer(id)
+	allow, _ = limiter.Check(id, common.GetADDR_TYPE)
+	assert.True(t, allow)
+}
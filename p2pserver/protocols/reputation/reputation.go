@@ -0,0 +1,426 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reputation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	common2 "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/p2pserver/common"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+)
+
+//penalties is the score added to a peer for each kind of misbehavior
+var penalties = map[p2p.Misbehavior]float64{
+	p2p.InvalidBlock:      50,
+	p2p.InvalidTx:         20,
+	p2p.BadHeader:         30,
+	p2p.RequestTimeout:    2,
+	p2p.Spam:              20,
+	p2p.ProtocolViolation: 25,
+	p2p.HandshakeFailure:  10,
+}
+
+//BanEntry is a banned ip, together with the peer id which caused the ban
+type BanEntry struct {
+	Ip        string `json:"ip"`
+	PeerId    string `json:"peer_id,omitempty"`
+	Reason    string `json:"reason"`
+	BanTimes  uint32 `json:"ban_times"`
+	CreatedAt int64  `json:"created_at"`
+	ExpireAt  int64  `json:"expire_at"` // unix time in second, 0 means banned persistently
+}
+
+func (self *BanEntry) isActive(now int64) bool {
+	return self.ExpireAt == 0 || self.ExpireAt > now
+}
+
+type peerScore struct {
+	score  float64
+	update time.Time
+}
+
+//ReputationService score peers by misbehavior reported from protocol handlers, peer reaching BAN_SCORE is banned
+//by ip. Bans are saved to file, so banned peers can not reconnect after restart
+type ReputationService struct {
+	path      string            //ban file, bans are not saved if empty
+	banPeerId bool              //also ban by peer id, only when peer id is authenticated by node key
+	exempt    p2p.AddressFilter //reserved and consensus peers, which are not scored. nil if no peer is exempt
+	net       p2p.P2P
+	quit      chan bool
+
+	lock   sync.RWMutex
+	scores map[string]*peerScore    //ip -> misbehavior score
+	bans   map[string]*BanEntry     //ip -> ban, including expired ones not forgotten yet
+	ids    map[common.PeerId]string //banned peer id -> ip
+}
+
+func NewReputationService(path string, banPeerId bool, exempt p2p.AddressFilter) *ReputationService {
+	service := &ReputationService{
+		path:      path,
+		banPeerId: banPeerId,
+		exempt:    exempt,
+		quit:      make(chan bool),
+		scores:    make(map[string]*peerScore),
+		bans:      make(map[string]*BanEntry),
+		ids:       make(map[common.PeerId]string),
+	}
+	service.loadBans()
+
+	return service
+}
+
+func (self *ReputationService) Start(net p2p.P2P) {
+	self.lock.Lock()
+	self.net = net
+	self.lock.Unlock()
+	go self.cleanUpLoop()
+}
+
+func (self *ReputationService) Stop() {
+	close(self.quit)
+}
+
+//Report add the penalty of misbehavior to peer score, ban the peer if the score reaches BAN_SCORE.
+//Misbehavior of exempt peers is only logged
+func (self *ReputationService) Report(id common.PeerId, addr string, kind p2p.Misbehavior, detail string) {
+	ip, err := common.ParseIPAddr(addr)
+	if err != nil {
+		log.Debugf("[p2p]report misbehavior of invalid addr %s: %s", addr, err)
+		return
+	}
+	if self.exempt != nil && self.exempt.Contains(addr) {
+		log.Debugf("[p2p]exempt peer %s misbehavior: %s, %s", addr, kind, detail)
+		return
+	}
+	now := time.Now()
+	self.lock.Lock()
+	if entry := self.bans[ip]; entry != nil && entry.isActive(now.Unix()) {
+		self.lock.Unlock()
+		return
+	}
+	score := self.scores[ip]
+	if score == nil {
+		score = &peerScore{}
+		self.scores[ip] = score
+	}
+	score.score = decayScore(score.score, now.Sub(score.update)) + penalties[kind]
+	score.update = now
+	log.Debugf("[p2p]peer %s misbehavior: %s, %s, score: %.1f", addr, kind, detail, score.score)
+	if math.Round(score.score) < common.BAN_SCORE {
+		self.lock.Unlock()
+		return
+	}
+	reason := kind.String()
+	if detail != "" {
+		reason += ": " + detail
+	}
+	entry := self.banLocked(ip, id, 0, reason, now)
+	self.lock.Unlock()
+
+	log.Warnf("[p2p]ban peer %s until %d, reason: %s", addr, entry.ExpireAt, reason)
+	self.saveBans()
+	self.disconnect(ip, id)
+}
+
+//decayScore halves the score every BAN_SCORE_HALF_LIFE
+func decayScore(score float64, elapsed time.Duration) float64 {
+	if score == 0 || elapsed <= 0 {
+		return score
+	}
+	return score * math.Pow(0.5, elapsed.Seconds()/common.BAN_SCORE_HALF_LIFE)
+}
+
+//banLocked ban ip for duration, or for the default duration of the ban times if duration is 0
+func (self *ReputationService) banLocked(ip string, id common.PeerId, duration time.Duration, reason string,
+	now time.Time) *BanEntry {
+	var banTimes uint32 = 1
+	if old := self.bans[ip]; old != nil {
+		banTimes = old.BanTimes + 1
+		self.delIdsLocked(ip)
+	}
+	entry := &BanEntry{
+		Ip:        ip,
+		Reason:    reason,
+		BanTimes:  banTimes,
+		CreatedAt: now.Unix(),
+	}
+	if duration == 0 {
+		duration = banDuration(banTimes)
+	}
+	if duration > 0 {
+		entry.ExpireAt = now.Add(duration).Unix()
+	}
+	if !id.IsEmpty() {
+		entry.PeerId = id.ToHexString()
+		if self.banPeerId {
+			self.ids[id] = ip
+		}
+	}
+	self.bans[ip] = entry
+	delete(self.scores, ip)
+
+	return entry
+}
+
+//banDuration return BAN_DURATION doubled for every previous ban, or -1 for persistent ban
+func banDuration(banTimes uint32) time.Duration {
+	if banTimes > common.MAX_TEMP_BAN_TIMES {
+		return -1
+	}
+	return time.Duration(common.BAN_DURATION) * time.Second << (banTimes - 1)
+}
+
+func (self *ReputationService) delIdsLocked(ip string) {
+	for id, bannedIp := range self.ids {
+		if bannedIp == ip {
+			delete(self.ids, id)
+		}
+	}
+}
+
+//IsBanned return whether the ip of addr or the peer id is banned
+func (self *ReputationService) IsBanned(id common.PeerId, addr string) bool {
+	now := time.Now().Unix()
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	if !id.IsEmpty() {
+		if ip, ok := self.ids[id]; ok && self.bans[ip].isActive(now) {
+			return true
+		}
+	}
+	ip, err := common.ParseIPAddr(addr)
+	if err != nil {
+		return false
+	}
+	entry := self.bans[ip]
+	return entry != nil && entry.isActive(now)
+}
+
+//Ban ban the ip manually, duration 0 means banned persistently
+func (self *ReputationService) Ban(ip string, duration time.Duration, reason string) error {
+	ip, err := parseIp(ip)
+	if err != nil {
+		return err
+	}
+	if duration == 0 {
+		duration = -1
+	}
+	if reason == "" {
+		reason = "manually banned"
+	}
+	self.lock.Lock()
+	entry := self.banLocked(ip, common.PeerId{}, duration, reason, time.Now())
+	self.lock.Unlock()
+
+	log.Infof("[p2p]ban ip %s until %d, reason: %s", ip, entry.ExpireAt, reason)
+	self.saveBans()
+	self.disconnect(ip, common.PeerId{})
+	return nil
+}
+
+//Unban remove the ban of ip, and forget its ban times
+func (self *ReputationService) Unban(ip string) error {
+	ip, err := parseIp(ip)
+	if err != nil {
+		return err
+	}
+	self.lock.Lock()
+	if _, ok := self.bans[ip]; !ok {
+		self.lock.Unlock()
+		return fmt.Errorf("ip %s is not banned", ip)
+	}
+	delete(self.bans, ip)
+	delete(self.scores, ip)
+	self.delIdsLocked(ip)
+	self.lock.Unlock()
+
+	log.Infof("[p2p]unban ip %s", ip)
+	self.saveBans()
+	return nil
+}
+
+//GetBanList return the active bans, sorted by ban time and ip
+func (self *ReputationService) GetBanList() []BanEntry {
+	now := time.Now().Unix()
+	self.lock.RLock()
+	bans := make([]BanEntry, 0, len(self.bans))
+	for _, entry := range self.bans {
+		if entry.isActive(now) {
+			bans = append(bans, *entry)
+		}
+	}
+	self.lock.RUnlock()
+	sort.Slice(bans, func(i, j int) bool {
+		if bans[i].CreatedAt != bans[j].CreatedAt {
+			return bans[i].CreatedAt < bans[j].CreatedAt
+		}
+		return bans[i].Ip < bans[j].Ip
+	})
+
+	return bans
+}
+
+//parseIp accept both ip and ip:port
+func parseIp(addr string) (string, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String(), nil
+	}
+	ip, err := common.ParseIPAddr(addr)
+	if err != nil || net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("invalid ip %s", addr)
+	}
+	return ip, nil
+}
+
+//disconnect close the connections with banned ip or peer id
+func (self *ReputationService) disconnect(ip string, id common.PeerId) {
+	self.lock.RLock()
+	net := self.net
+	self.lock.RUnlock()
+	if net == nil {
+		return
+	}
+	for _, p := range net.GetNeighbors() {
+		peerIp, _ := common.ParseIPAddr(p.GetAddr())
+		if peerIp == ip || (!id.IsEmpty() && self.banPeerId && p.GetID() == id) {
+			p.Close()
+		}
+	}
+}
+
+//cleanUp forget the bans expired for BAN_FORGET_TIME and the scores decayed to zero
+func (self *ReputationService) cleanUp() {
+	now := time.Now()
+	changed := false
+	self.lock.Lock()
+	for ip, entry := range self.bans {
+		if entry.ExpireAt != 0 && entry.ExpireAt+common.BAN_FORGET_TIME < now.Unix() {
+			delete(self.bans, ip)
+			self.delIdsLocked(ip)
+			changed = true
+		}
+	}
+	for ip, score := range self.scores {
+		if decayScore(score.score, now.Sub(score.update)) < 1 {
+			delete(self.scores, ip)
+		}
+	}
+	self.lock.Unlock()
+	if changed {
+		self.saveBans()
+	}
+}
+
+func (self *ReputationService) cleanUpLoop() {
+	t := time.NewTicker(common.BAN_CLEAN_UP_TIMEOUT * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			self.cleanUp()
+		case <-self.quit:
+			return
+		}
+	}
+}
+
+func (self *ReputationService) saveBans() {
+	if self.path == "" {
+		return
+	}
+	self.lock.RLock()
+	bans := make([]*BanEntry, 0, len(self.bans))
+	for _, entry := range self.bans {
+		bans = append(bans, entry)
+	}
+	buf, err := json.Marshal(bans)
+	self.lock.RUnlock()
+	if err != nil {
+		log.Warn("[p2p]package ban list fail: ", err)
+		return
+	}
+	err = writeFileAtomic(self.path, buf)
+	if err != nil {
+		log.Warn("[p2p]write ban list fail: ", err)
+	}
+}
+
+//writeFileAtomic write to a temp file in the same directory then rename it, so that a crash or a concurrent save
+//never leaves a truncated file
+func writeFileAtomic(path string, buf []byte) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(buf)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
+
+func (self *ReputationService) loadBans() {
+	if self.path == "" || !common2.FileExisted(self.path) {
+		return
+	}
+	buf, err := ioutil.ReadFile(self.path)
+	if err != nil {
+		log.Warnf("[p2p]read %s fail: %s", self.path, err)
+		return
+	}
+	var bans []*BanEntry
+	err = json.Unmarshal(buf, &bans)
+	if err != nil {
+		log.Warn("[p2p]parse ban list fail: ", err)
+		return
+	}
+	for _, entry := range bans {
+		if _, err := parseIp(entry.Ip); err != nil {
+			log.Warn("[p2p]skip invalid ban entry: ", err)
+			continue
+		}
+		self.bans[entry.Ip] = entry
+		if self.banPeerId && entry.PeerId != "" {
+			id, err := common.PeerIdFromHexString(entry.PeerId)
+			if err == nil {
+				self.ids[id] = entry.Ip
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reputation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/connect_controller"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportAndBan(t *testing.T) {
+	service := NewReputationService("", true, nil)
+	id := common.PseudoPeerIdFromUint64(1)
+	addr := "1.2.3.4:20338"
+
+	service.Report(id, addr, p2p.InvalidBlock, "")
+	assert.False(t, service.IsBanned(id, addr))
+	service.Report(id, addr, p2p.InvalidBlock, "bad merkle root")
+	assert.True(t, service.IsBanned(id, addr))
+	assert.True(t, service.IsBanned(common.PeerId{}, "1.2.3.4:30338"))
+	assert.True(t, service.IsBanned(id, "5.6.7.8:20338"))
+	assert.False(t, service.IsBanned(common.PseudoPeerIdFromUint64(2), "5.6.7.8:20338"))
+
+	bans := service.GetBanList()
+	assert.Equal(t, 1, len(bans))
+	assert.Equal(t, "1.2.3.4", bans[0].Ip)
+	assert.Equal(t, "invalid block: bad merkle root", bans[0].Reason)
+	assert.Equal(t, uint32(1), bans[0].BanTimes)
+	assert.Equal(t, bans[0].CreatedAt+common.BAN_DURATION, bans[0].ExpireAt)
+}
+
+func TestBanPeerIdDisabled(t *testing.T) {
+	service := NewReputationService("", false, nil)
+	id := common.PseudoPeerIdFromUint64(1)
+	for i := 0; i < 2; i++ {
+		service.Report(id, "1.2.3.4:20338", p2p.InvalidBlock, "")
+	}
+	assert.True(t, service.IsBanned(id, "1.2.3.4:20338"))
+	assert.False(t, service.IsBanned(id, "5.6.7.8:20338"))
+}
+
+func TestExemptPeer(t *testing.T) {
+	exempt := connect_controller.NewStaticReserveFilter([]string{"1.2.3.4"})
+	service := NewReputationService("", true, exempt)
+	id1, id2 := common.PseudoPeerIdFromUint64(1), common.PseudoPeerIdFromUint64(2)
+	for i := 0; i < 10; i++ {
+		service.Report(id1, "1.2.3.4:20338", p2p.InvalidBlock, "")
+		service.Report(id2, "5.6.7.8:20338", p2p.InvalidBlock, "")
+	}
+	assert.False(t, service.IsBanned(id1, "1.2.3.4:20338"))
+	assert.Nil(t, service.scores["1.2.3.4"])
+	assert.True(t, service.IsBanned(common.PeerId{}, "5.6.7.8:20338"))
+
+	//exempt peers can still be banned manually
+	assert.Nil(t, service.Ban("1.2.3.4", time.Hour, ""))
+	assert.True(t, service.IsBanned(common.PeerId{}, "1.2.3.4:20338"))
+}
+
+func TestDecayScore(t *testing.T) {
+	assert.Equal(t, float64(100), decayScore(100, 0))
+	assert.InDelta(t, 50, decayScore(100, common.BAN_SCORE_HALF_LIFE*time.Second), 0.001)
+	assert.InDelta(t, 25, decayScore(100, 2*common.BAN_SCORE_HALF_LIFE*time.Second), 0.001)
+
+	service := NewReputationService("", true, nil)
+	addr := "1.2.3.4:20338"
+	service.Report(common.PeerId{}, addr, p2p.InvalidBlock, "")
+	service.scores["1.2.3.4"].update = time.Now().Add(-common.BAN_SCORE_HALF_LIFE * time.Second)
+	service.Report(common.PeerId{}, addr, p2p.InvalidBlock, "")
+	assert.False(t, service.IsBanned(common.PeerId{}, addr))
+}
+
+func TestBanDuration(t *testing.T) {
+	assert.Equal(t, common.BAN_DURATION*time.Second, banDuration(1))
+	assert.Equal(t, 2*common.BAN_DURATION*time.Second, banDuration(2))
+	assert.Equal(t, 4*common.BAN_DURATION*time.Second, banDuration(3))
+	assert.True(t, banDuration(common.MAX_TEMP_BAN_TIMES+1) < 0)
+
+	service := NewReputationService("", true, nil)
+	addr := "1.2.3.4:20338"
+	for i := 1; i <= common.MAX_TEMP_BAN_TIMES+1; i++ {
+		if entry := service.bans["1.2.3.4"]; entry != nil {
+			entry.ExpireAt = time.Now().Unix() - 1
+		}
+		service.Report(common.PeerId{}, addr, p2p.InvalidBlock, "")
+		service.Report(common.PeerId{}, addr, p2p.InvalidBlock, "")
+		assert.Equal(t, uint32(i), service.bans["1.2.3.4"].BanTimes)
+	}
+	assert.Equal(t, int64(0), service.bans["1.2.3.4"].ExpireAt)
+}
+
+func TestManualBan(t *testing.T) {
+	service := NewReputationService("", true, nil)
+	assert.NotNil(t, service.Ban("not an ip", 0, ""))
+	assert.Nil(t, service.Ban("1.2.3.4:20338", 0, ""))
+	assert.True(t, service.IsBanned(common.PeerId{}, "1.2.3.4:1"))
+	bans := service.GetBanList()
+	assert.Equal(t, 1, len(bans))
+	assert.Equal(t, int64(0), bans[0].ExpireAt)
+
+	assert.Nil(t, service.Unban("1.2.3.4"))
+	assert.False(t, service.IsBanned(common.PeerId{}, "1.2.3.4:1"))
+	assert.NotNil(t, service.Unban("1.2.3.4"))
+	assert.Equal(t, 0, len(service.GetBanList()))
+}
+
+func TestCleanUp(t *testing.T) {
+	service := NewReputationService("", true, nil)
+	assert.Nil(t, service.Ban("1.2.3.4", time.Hour, ""))
+	assert.Nil(t, service.Ban("5.6.7.8", time.Hour, ""))
+	service.bans["1.2.3.4"].ExpireAt = time.Now().Unix() - 1
+	service.bans["5.6.7.8"].ExpireAt = time.Now().Unix() - common.BAN_FORGET_TIME - 1
+	service.Report(common.PeerId{}, "9.9.9.9:1", p2p.RequestTimeout, "")
+	service.scores["9.9.9.9"].update = time.Now().Add(-10 * common.BAN_SCORE_HALF_LIFE * time.Second)
+
+	service.cleanUp()
+	assert.Equal(t, 0, len(service.GetBanList()))
+	assert.NotNil(t, service.bans["1.2.3.4"])
+	assert.Nil(t, service.bans["5.6.7.8"])
+	assert.Nil(t, service.scores["9.9.9.9"])
+}
+
+func TestPersistBans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reputation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, common.BAN_FILE_NAME)
+
+	service := NewReputationService(path, true, nil)
+	id := common.PseudoPeerIdFromUint64(1)
+	service.Report(id, "1.2.3.4:20338", p2p.InvalidBlock, "")
+	service.Report(id, "1.2.3.4:20338", p2p.InvalidBlock, "")
+	assert.Nil(t, service.Ban("5.6.7.8", 0, "manual"))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+
+	loaded := NewReputationService(path, true, nil)
+	assert.Equal(t, service.GetBanList(), loaded.GetBanList())
+	assert.True(t, loaded.IsBanned(id, "9.9.9.9:20338"))
+	assert.True(t, loaded.IsBanned(common.PeerId{}, "5.6.7.8:20338"))
+
+	assert.Nil(t, loaded.Unban("5.6.7.8"))
+	loaded = NewReputationService(path, true, nil)
+	assert.Equal(t, 1, len(loaded.GetBanList()))
+}
@@ -37,7 +37,7 @@ func (self *SubNetReservedAddrFilter) Contains(addr string) bool {
 	}
 
 	// gov node
-	if self.subnet.acct != nil && self.subnet.gov.IsGovNodePubKey(self.subnet.acct.PublicKey) {
+	if self.subnet.signer != nil && self.subnet.gov.IsGovNodePubKey(self.subnet.signer.PubKey()) {
 		return self.subnet.isSeedIp(ip) || self.subnet.IpInMembers(ip)
 	}
 
@@ -56,3 +56,17 @@ func (self *SubNetMaskAddrFilter) Contains(addr string) bool {
 
 	return ok
 }
+
+//SubNetMemberAddrFilter contains the addresses of which ip is of subnet members
+type SubNetMemberAddrFilter struct {
+	subnet *SubNet
+}
+
+func (self *SubNetMemberAddrFilter) Contains(addr string) bool {
+	ip, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	return self.subnet.IpInMembers(ip)
+}
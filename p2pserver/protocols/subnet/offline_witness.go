@@ -37,10 +37,10 @@ const ExpireOfflineMsgTime = uint32(2 * time.Hour / time.Second)
 const DelayUpdateMsgTime = 5 * time.Second
 
 func (self *SubNet) ProposeOffline(nodes []string) error {
-	if self.acct == nil {
+	if self.signer == nil {
 		return errors.New("only consensus node can propose offline witness")
 	}
-	key := vconfig.PubkeyID(self.acct.PublicKey)
+	key := vconfig.PubkeyID(self.signer.PubKey())
 	role, view := self.gov.GetNodeRoleAndView(key)
 	if role != utils.ConsensusNode {
 		return errors.New("only consensus node can propose offline witness")
@@ -72,7 +72,7 @@ func (self *SubNet) ProposeOffline(nodes []string) error {
 		NodePubKeys: leftNodes,
 		Proposer:    key,
 	}
-	err := msg.AddProposeSig(self.acct)
+	err := msg.AddProposeSig(self.signer)
 	if err != nil {
 		return err
 	}
@@ -169,9 +169,9 @@ func (self *SubNet) processOfflineWitnessMsg(ctx *p2p.Context, msg *types.Offlin
 	defer self.lock.Unlock()
 	offline := self.offlineWitness[msg.Hash()]
 	if offline == nil {
-		govNode := self.acct != nil && self.gov.IsGovNodePubKey(self.acct.PublicKey)
+		govNode := self.signer != nil && self.gov.IsGovNodePubKey(self.signer.PubKey())
 		if govNode {
-			err := msg.VoteFor(self.acct, self.collectOfflineIndexLocked(msg.NodePubKeys))
+			err := msg.VoteFor(self.signer, self.collectOfflineIndexLocked(msg.NodePubKeys))
 			if err != nil {
 				self.logger.Infof("vote for witness msg error: %s", err)
 				return UnchangedStatus
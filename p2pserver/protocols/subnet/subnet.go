@@ -25,8 +25,8 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/ontio/ontology/account"
 	common2 "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/consensus/signer"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/p2pserver/message/types"
@@ -52,7 +52,7 @@ type Offline struct {
 }
 
 type SubNet struct {
-	acct     *account.Account // nil if conenesus is not enabled
+	signer   signer.Signer // nil if conenesus is not enabled
 	seeds    *utils.HostsResolver
 	gov      utils.GovNodeResolver
 	unparker *utils.Parker
@@ -68,10 +68,10 @@ type SubNet struct {
 	logger         common.Logger
 }
 
-func NewSubNet(acc *account.Account, seeds *utils.HostsResolver,
+func NewSubNet(consSigner signer.Signer, seeds *utils.HostsResolver,
 	gov utils.GovNodeResolver, logger common.Logger) *SubNet {
 	return &SubNet{
-		acct:     acc,
+		signer:   consSigner,
 		seeds:    seeds,
 		gov:      gov,
 		unparker: utils.NewParker(),
@@ -268,9 +268,9 @@ func (self *SubNet) newMembersRequest(from, to common.PeerId) *types.SubnetMembe
 	var request *types.SubnetMembersRequest
 	// need first check is gov node, since gov node may also be seed node
 	// so the remote peer can known this node is gov node.
-	if self.acct != nil && self.gov.IsGovNodePubKey(self.acct.PublicKey) {
+	if self.signer != nil && self.gov.IsGovNodePubKey(self.signer.PubKey()) {
 		var err error
-		request, err = types.NewMembersRequest(from, to, self.acct)
+		request, err = types.NewMembersRequest(from, to, self.signer)
 		if err != nil {
 			return nil
 		}
@@ -356,7 +356,7 @@ func (self *SubNet) maintainLoop(net p2p.P2P) {
 				}
 			}
 		}
-		seedOrGov := self.IsSeedNode() || (self.acct != nil && self.gov.IsGovNodePubKey(self.acct.PublicKey))
+		seedOrGov := self.IsSeedNode() || (self.signer != nil && self.gov.IsGovNodePubKey(self.signer.PubKey()))
 		selfAddr := self.selfAddr
 		self.lock.Unlock()
 
@@ -398,6 +398,12 @@ func (self *SubNet) GetReservedAddrFilter(staticFilterEnabled bool) p2p.AddressF
 	}
 }
 
+func (self *SubNet) GetMemberAddrFilter() p2p.AddressFilter {
+	return &SubNetMemberAddrFilter{
+		subnet: self,
+	}
+}
+
 func (self *SubNet) GetMaskAddrFilter() p2p.AddressFilter {
 	return &SubNetMaskAddrFilter{
 		subnet: self,
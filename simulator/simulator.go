@@ -0,0 +1,462 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package simulator provides an in-process devnet for contract testing. It runs a solo chain on an in-memory ledger,
+//funds the configured genesis accounts, and executes transactions into blocks with controllable height and timestamp.
+//The simulator switches the global config to solo consensus, so it must not be used inside a running node, and it is
+//not safe for concurrent use.
+package simulator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/constants"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/memstore"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/ontio/ontology/smartcontract/states"
+)
+
+const (
+	DEFAULT_GAS_LIMIT = 200000000 //enough to deploy a contract of several hundred KB
+	STORAGE_PAGE_SIZE = 1024      //page size used to iterate the storage of a contract
+)
+
+//GenesisAccount is an account funded when the simulator starts
+type GenesisAccount struct {
+	Account *account.Account
+	Ont     uint64 //transferred by admin in block 1
+	Ong     uint64 //allocated by the genesis block, in the smallest unit, 1 ONG is 10^9
+}
+
+//Config is the configuration of a simulator
+type Config struct {
+	Admin         *account.Account  //the solo bookkeeper which holds all ONT at genesis, a random account if nil
+	Accounts      []*GenesisAccount //accounts funded at genesis and in block 1
+	StartTime     uint32            //timestamp of block 1
+	BlockInterval uint32            //timestamp increment between blocks
+	GasPrice      uint64            //gas price of the transactions built by the simulator, 0 means free
+	GasLimit      uint64            //gas limit of the transactions built by the simulator
+}
+
+//DefaultConfig return a config without genesis accounts and with the default gas price of the network
+func DefaultConfig() *Config {
+	return &Config{
+		StartTime:     constants.GENESIS_BLOCK_TIMESTAMP + 1,
+		BlockInterval: 1,
+		GasPrice:      config.DEFAULT_GAS_PRICE,
+		GasLimit:      DEFAULT_GAS_LIMIT,
+	}
+}
+
+//Receipt is the execution result of a transaction
+type Receipt struct {
+	TxHash      common.Uint256
+	Height      uint32
+	State       byte   //event.CONTRACT_STATE_SUCCESS or event.CONTRACT_STATE_FAIL
+	GasConsumed uint64 //ONG charged to the payer
+	Notify      []*event.NotifyEventInfo
+}
+
+//Success return whether the transaction executed successfully
+func (self *Receipt) Success() bool {
+	return self.State == event.CONTRACT_STATE_SUCCESS
+}
+
+func newReceipt(height uint32, notify *event.ExecuteNotify) *Receipt {
+	return &Receipt{
+		TxHash:      notify.TxHash,
+		Height:      height,
+		State:       notify.State,
+		GasConsumed: notify.GasConsumed,
+		Notify:      notify.Notify,
+	}
+}
+
+type snapshot struct {
+	height   uint32
+	nextTime uint32
+	nonce    uint32
+}
+
+//Simulator is an in-process solo chain
+type Simulator struct {
+	admin       *account.Account
+	bookkeepers []keypair.PublicKey
+	gasPrice    uint64
+	gasLimit    uint64
+	interval    uint32
+	genesis     *types.Block
+	blocks      []*types.Block //blocks after genesis, replayed to revert to a snapshot
+	snapshots   []*snapshot
+	nextTime    uint32
+	nonce       uint32
+	ledger      *ledger.Ledger
+	dataDir     string
+}
+
+//New start a simulator, the genesis accounts are funded in block 1
+func New(cfg *Config) (*Simulator, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.BlockInterval == 0 {
+		return nil, fmt.Errorf("block interval should not be zero")
+	}
+	if cfg.StartTime <= constants.GENESIS_BLOCK_TIMESTAMP {
+		return nil, fmt.Errorf("start time should be later than genesis time %d", constants.GENESIS_BLOCK_TIMESTAMP)
+	}
+	admin := cfg.Admin
+	if admin == nil {
+		admin = account.NewAccount("")
+	}
+	bookkeepers := []keypair.PublicKey{admin.PublicKey}
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	config.DefConfig.Genesis.SOLO.Bookkeepers = []string{hex.EncodeToString(keypair.SerializePublicKey(admin.PublicKey))}
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Common.EnableEventLog = true
+	genesisBlock, err := genesis.BuildGenesisBlockWithOng(bookkeepers, config.DefConfig.Genesis, genesisOngs(cfg.Accounts))
+	if err != nil {
+		return nil, fmt.Errorf("BuildGenesisBlock error:%s", err)
+	}
+
+	sim := &Simulator{
+		admin:       admin,
+		bookkeepers: bookkeepers,
+		gasPrice:    cfg.GasPrice,
+		gasLimit:    cfg.GasLimit,
+		interval:    cfg.BlockInterval,
+		genesis:     genesisBlock,
+		nextTime:    cfg.StartTime,
+	}
+	sim.ledger, sim.dataDir, err = sim.newLedger()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := sim.newFundTx(cfg.Accounts)
+	if err == nil && tx != nil {
+		err = sim.executeAll([]*types.Transaction{tx})
+	}
+	if err != nil {
+		sim.Close()
+		return nil, fmt.Errorf("fund genesis accounts error:%s", err)
+	}
+	return sim, nil
+}
+
+//genesisOngs return the ONG allocated to the genesis accounts. On solo ONG is never unbound to ONT holders, so the
+//genesis block takes it from the ONT contract which holds the whole supply
+func genesisOngs(accounts []*GenesisAccount) []*ont.State {
+	var ongs []*ont.State
+	for _, acc := range accounts {
+		if acc.Ong > 0 {
+			ongs = append(ongs, &ont.State{From: nutils.OntContractAddress, To: acc.Account.Address, Value: acc.Ong})
+		}
+	}
+	return ongs
+}
+
+func (self *Simulator) newLedger() (*ledger.Ledger, string, error) {
+	dir, err := ioutil.TempDir("", "ontology-simulator")
+	if err != nil {
+		return nil, "", err
+	}
+	backend := config.DefConfig.Common.StoreBackend
+	config.DefConfig.Common.StoreBackend = memstore.BACKEND_NAME
+	ldg, err := ledger.NewLedger(dir, 0)
+	config.DefConfig.Common.StoreBackend = backend
+	if err == nil {
+		err = ldg.Init(self.bookkeepers, self.genesis)
+		if err != nil {
+			ldg.Close()
+		}
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", err
+	}
+	return ldg, dir, nil
+}
+
+//newFundTx return the ONT transfer from admin to the genesis accounts, nil if no account holds ONT
+func (self *Simulator) newFundTx(accounts []*GenesisAccount) (*types.Transaction, error) {
+	var onts []*ont.State
+	for _, acc := range accounts {
+		if acc.Ont > 0 {
+			onts = append(onts, &ont.State{From: self.admin.Address, To: acc.Account.Address, Value: acc.Ont})
+		}
+	}
+	if len(onts) == 0 {
+		return nil, nil
+	}
+	return self.newNativeTx(self.admin, 0, nutils.OntContractAddress, ont.TRANSFER_NAME, []interface{}{onts})
+}
+
+//Close release the ledger of the simulator
+func (self *Simulator) Close() error {
+	err := self.ledger.Close()
+	os.RemoveAll(self.dataDir)
+	return err
+}
+
+//Admin return the solo bookkeeper
+func (self *Simulator) Admin() *account.Account {
+	return self.admin
+}
+
+//Ledger return the underlying ledger, it is replaced after Revert
+func (self *Simulator) Ledger() *ledger.Ledger {
+	return self.ledger
+}
+
+//Height return the current block height
+func (self *Simulator) Height() uint32 {
+	return self.ledger.GetCurrentBlockHeight()
+}
+
+//NextBlockTime return the timestamp of the next block
+func (self *Simulator) NextBlockTime() uint32 {
+	return self.nextTime
+}
+
+//SetNextBlockTime set the timestamp of the next block, which should be later than the current block
+func (self *Simulator) SetNextBlockTime(timestamp uint32) error {
+	header, err := self.ledger.GetHeaderByHeight(self.Height())
+	if err != nil {
+		return err
+	}
+	if timestamp <= header.Timestamp {
+		return fmt.Errorf("timestamp %d should be later than current block time %d", timestamp, header.Timestamp)
+	}
+	self.nextTime = timestamp
+	return nil
+}
+
+//AdvanceTime delay the next block by seconds
+func (self *Simulator) AdvanceTime(seconds uint32) {
+	self.nextTime += seconds
+}
+
+//AdvanceBlocks seal n empty blocks
+func (self *Simulator) AdvanceBlocks(n uint32) error {
+	for i := uint32(0); i < n; i++ {
+		if _, err := self.Execute(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Execute seal the transactions into the next block, the receipts are in the order of the transactions
+func (self *Simulator) Execute(txs ...*types.Transaction) ([]*Receipt, error) {
+	block, err := self.makeBlock(txs)
+	if err != nil {
+		return nil, err
+	}
+	result, err := submitBlock(self.ledger, block)
+	if err != nil {
+		return nil, err
+	}
+	self.blocks = append(self.blocks, block)
+	self.nextTime = block.Header.Timestamp + self.interval
+
+	receipts := make([]*Receipt, 0, len(result.Notify))
+	for _, notify := range result.Notify {
+		receipts = append(receipts, newReceipt(block.Header.Height, notify))
+	}
+	return receipts, nil
+}
+
+func submitBlock(ldg *ledger.Ledger, block *types.Block) (store.ExecuteResult, error) {
+	result, err := ldg.ExecuteBlock(block)
+	if err != nil {
+		return result, fmt.Errorf("ExecuteBlock error:%s", err)
+	}
+	if err := ldg.SubmitBlock(block, nil, result); err != nil {
+		return result, fmt.Errorf("SubmitBlock error:%s", err)
+	}
+	return result, nil
+}
+
+//executeAll execute the transactions in a block and fail if any of them fails
+func (self *Simulator) executeAll(txs []*types.Transaction) error {
+	receipts, err := self.Execute(txs...)
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		if !receipt.Success() {
+			return fmt.Errorf("tx %s failed", receipt.TxHash.ToHexString())
+		}
+	}
+	return nil
+}
+
+func (self *Simulator) makeBlock(txs []*types.Transaction) (*types.Block, error) {
+	nextBookkeeper, err := types.AddressFromBookkeepers(self.bookkeepers)
+	if err != nil {
+		return nil, fmt.Errorf("GetBookkeeperAddress error:%s", err)
+	}
+	height := self.ledger.GetCurrentBlockHeight()
+	txHash := make([]common.Uint256, 0, len(txs))
+	for _, tx := range txs {
+		txHash = append(txHash, tx.Hash())
+	}
+	txRoot := common.ComputeMerkleRoot(txHash)
+	header := &types.Header{
+		PrevBlockHash:    self.ledger.GetCurrentBlockHash(),
+		TransactionsRoot: txRoot,
+		BlockRoot:        self.ledger.GetBlockRootWithNewTxRoots(height+1, []common.Uint256{txRoot}),
+		Timestamp:        self.nextTime,
+		Height:           height + 1,
+		ConsensusData:    uint64(height + 1),
+		NextBookkeeper:   nextBookkeeper,
+	}
+	block := &types.Block{
+		Header:       header,
+		Transactions: txs,
+	}
+	blockHash := block.Hash()
+	sig, err := signature.Sign(self.admin, blockHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("signature, Sign error:%s", err)
+	}
+	block.Header.Bookkeepers = self.bookkeepers
+	block.Header.SigData = [][]byte{sig}
+	return block, nil
+}
+
+//PreExecute run the transaction on the current state without committing it
+func (self *Simulator) PreExecute(tx *types.Transaction) (*states.PreExecResult, error) {
+	return self.ledger.PreExecuteContract(tx)
+}
+
+//Profile run the transaction on the current state without committing it, with the gas profile in the result
+func (self *Simulator) Profile(tx *types.Transaction) (*states.PreExecResult, error) {
+	return self.ledger.PreExecuteContractWithProfile(nil, tx)
+}
+
+//GetReceipt return the receipt of an executed transaction
+func (self *Simulator) GetReceipt(txHash common.Uint256) (*Receipt, error) {
+	_, height, err := self.ledger.GetTransactionWithHeight(txHash)
+	if err != nil {
+		return nil, err
+	}
+	notify, err := self.ledger.GetEventNotifyByTx(txHash)
+	if err != nil {
+		return nil, err
+	}
+	return newReceipt(height, notify), nil
+}
+
+//GetEvents return the execute notifies of the block at height
+func (self *Simulator) GetEvents(height uint32) ([]*event.ExecuteNotify, error) {
+	return self.ledger.GetEventNotifyByBlock(height)
+}
+
+//GetStorage return the storage value of contract at key, nil if not exist
+func (self *Simulator) GetStorage(contract common.Address, key []byte) ([]byte, error) {
+	return self.ledger.GetStorageItem(contract, key)
+}
+
+//FindStorage return all the storage items of contract whose keys have the prefix
+func (self *Simulator) FindStorage(contract common.Address, prefix []byte) ([]*scom.KeyValue, error) {
+	var items []*scom.KeyValue
+	var start []byte
+	for {
+		page, next, err := self.ledger.FindStorageItems(contract, prefix, start, STORAGE_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if next == nil {
+			return items, nil
+		}
+		start = next
+	}
+}
+
+//StorageStats return the key count and bytes of the contract storage
+func (self *Simulator) StorageStats(contract common.Address) (*scom.StorageStats, error) {
+	return self.ledger.GetContractStorageStats(contract)
+}
+
+//BalanceOf return the balance of addr in the native asset contract, ONT or ONG
+func (self *Simulator) BalanceOf(asset common.Address, addr common.Address) (uint64, error) {
+	value, err := self.GetStorage(asset, addr[:])
+	if err != nil || value == nil {
+		return 0, err
+	}
+	balance, eof := common.NewZeroCopySource(value).NextUint64()
+	if eof {
+		return 0, fmt.Errorf("invalid balance of %s", addr.ToBase58())
+	}
+	return balance, nil
+}
+
+//Snapshot record the current chain state and return its id for Revert
+func (self *Simulator) Snapshot() int {
+	self.snapshots = append(self.snapshots, &snapshot{
+		height:   self.Height(),
+		nextTime: self.nextTime,
+		nonce:    self.nonce,
+	})
+	return len(self.snapshots) - 1
+}
+
+//Revert restore the chain state recorded by the snapshot. The snapshot is kept so the simulator can revert to it again,
+//while the snapshots taken after it are dropped
+func (self *Simulator) Revert(id int) error {
+	if id < 0 || id >= len(self.snapshots) {
+		return fmt.Errorf("unknown snapshot %d", id)
+	}
+	snap := self.snapshots[id]
+	ldg, dir, err := self.newLedger()
+	if err != nil {
+		return err
+	}
+	blocks := self.blocks[:snap.height]
+	for _, block := range blocks {
+		if _, err := submitBlock(ldg, block); err != nil {
+			ldg.Close()
+			os.RemoveAll(dir)
+			return fmt.Errorf("replay block %d error:%s", block.Header.Height, err)
+		}
+	}
+	self.Close()
+	self.ledger, self.dataDir = ldg, dir
+	self.blocks = blocks
+	self.snapshots = self.snapshots[:id+1]
+	self.nextTime = snap.nextTime
+	self.nonce = snap.nonce
+	return nil
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/constants"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/states"
+	"github.com/ontio/ontology/core/types"
+	cutils "github.com/ontio/ontology/core/utils"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	sneovm "github.com/ontio/ontology/smartcontract/service/neovm"
+	"github.com/ontio/ontology/smartcontract/trace"
+	"github.com/ontio/ontology/vm/neovm"
+	"github.com/stretchr/testify/assert"
+)
+
+//newTestContract return a neovm contract which stores its argument at "key" and notifies "stored"
+func newTestContract() []byte {
+	var code []byte
+	push := func(data []byte) {
+		builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+		builder.EmitPushByteArray(data)
+		code = append(code, builder.ToArray()...)
+	}
+	syscall := func(name string) {
+		code = append(code, byte(neovm.SYSCALL), byte(len(name)))
+		code = append(code, name...)
+	}
+	push([]byte("key"))
+	syscall("System.Storage.GetContext")
+	syscall("System.Storage.Put")
+	push([]byte("stored"))
+	syscall("System.Runtime.Notify")
+	return append(code, byte(neovm.PUSH1), byte(neovm.RET))
+}
+
+//newTestWasmContract return a wasm contract which stores its input at "key" and notifies "stored"
+func newTestWasmContract() []byte {
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+	section := func(id byte, items ...[]byte) []byte {
+		content := []byte{byte(len(items))}
+		for _, item := range items {
+			content = append(content, item...)
+		}
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	imported := func(field string, typeIdx byte) []byte {
+		return append(append(name("env"), name(field)...), 0x00, typeIdx)
+	}
+	segment := func(offset byte, data string) []byte {
+		return append([]byte{0x00, 0x41, offset, 0x0b}, name(data)...)
+	}
+	body := []byte{
+		0x01, 0x01, 0x7f, // one i32 local
+		0x10, 0x00, 0x21, 0x00, // local0 = ontio_input_length()
+		0x41, 0x10, 0x10, 0x01, // ontio_get_input(16)
+		0x41, 0x00, 0x41, 0x03, 0x41, 0x10, 0x20, 0x00, 0x10, 0x02, // ontio_storage_write(0, 3, 16, local0)
+		0x41, 0x08, 0x41, 0x06, 0x10, 0x03, // ontio_notify(8, 6)
+		0x0b,
+	}
+
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	code = append(code, section(1,
+		[]byte{0x60, 0x00, 0x01, 0x7f},
+		[]byte{0x60, 0x01, 0x7f, 0x00},
+		[]byte{0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x00},
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x00},
+		[]byte{0x60, 0x00, 0x00})...)
+	code = append(code, section(2,
+		imported("ontio_input_length", 0),
+		imported("ontio_get_input", 1),
+		imported("ontio_storage_write", 2),
+		imported("ontio_notify", 3))...)
+	code = append(code, section(3, []byte{0x04})...)
+	code = append(code, section(5, []byte{0x00, 0x01})...)
+	code = append(code, section(7, append(name("invoke"), 0x00, 0x04))...)
+	code = append(code, section(10, append([]byte{byte(len(body))}, body...))...)
+	return append(code, section(11, segment(0, "key"), segment(8, "stored"))...)
+}
+
+func newTestSimulator(t *testing.T) (*Simulator, *account.Account) {
+	alice := account.NewAccount("")
+	cfg := DefaultConfig()
+	cfg.Accounts = []*GenesisAccount{{Account: alice, Ont: 100, Ong: 1000000000000}}
+	sim, err := New(cfg)
+	assert.Nil(t, err)
+	return sim, alice
+}
+
+func TestGenesisAccounts(t *testing.T) {
+	sim, alice := newTestSimulator(t)
+	defer sim.Close()
+
+	assert.Equal(t, uint32(1), sim.Height())
+	header, err := sim.Ledger().GetHeaderByHeight(1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(constants.GENESIS_BLOCK_TIMESTAMP+1), header.Timestamp)
+
+	balance, err := sim.BalanceOf(utils.OntContractAddress, alice.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), balance)
+	balance, err = sim.BalanceOf(utils.OngContractAddress, alice.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000000000000), balance)
+
+	bob := account.NewAccount("")
+	receipt, err := sim.Transfer(utils.OntContractAddress, alice, bob.Address, 30)
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+	assert.Equal(t, uint32(2), receipt.Height)
+	balance, err = sim.BalanceOf(utils.OntContractAddress, bob.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(30), balance)
+	balance, err = sim.BalanceOf(utils.OngContractAddress, alice.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000000000000)-receipt.GasConsumed, balance)
+}
+
+func TestGenesisOng(t *testing.T) {
+	bob := account.NewAccount("")
+	cfg := DefaultConfig()
+	cfg.Accounts = []*GenesisAccount{{Account: bob, Ong: 500}}
+	sim, err := New(cfg)
+	assert.Nil(t, err)
+	defer sim.Close()
+
+	assert.Equal(t, uint32(0), sim.Height())
+	balance, err := sim.BalanceOf(utils.OngContractAddress, bob.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), balance)
+	balance, err = sim.BalanceOf(utils.OngContractAddress, utils.OntContractAddress)
+	assert.Nil(t, err)
+	assert.Equal(t, constants.ONG_TOTAL_SUPPLY-500, balance)
+}
+
+func TestContract(t *testing.T) {
+	sim, alice := newTestSimulator(t)
+	defer sim.Close()
+
+	contract, receipt, err := sim.DeployNeoContract(alice, newTestContract())
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+	assert.True(t, receipt.GasConsumed > 0)
+
+	receipt, err = sim.InvokeNeo(alice, contract, []interface{}{[]byte("value")})
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+	value, err := sim.GetStorage(contract, []byte("key"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value"), value)
+	items, err := sim.FindStorage(contract, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+	stats, err := sim.StorageStats(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), stats.KeyCount)
+	assert.Equal(t, uint64(len("key")+len(states.GenRawStorageItem([]byte("value")))), stats.Bytes)
+
+	var notified bool
+	for _, notify := range receipt.Notify {
+		if notify.ContractAddress == contract {
+			notified = true
+			assert.Equal(t, "73746f726564", notify.States)
+		}
+	}
+	assert.True(t, notified)
+
+	saved, err := sim.GetReceipt(receipt.TxHash)
+	assert.Nil(t, err)
+	assert.Equal(t, receipt.Height, saved.Height)
+	assert.Equal(t, receipt.GasConsumed, saved.GasConsumed)
+	assert.Equal(t, len(receipt.Notify), len(saved.Notify))
+	events, err := sim.GetEvents(receipt.Height)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+}
+
+func TestWasmContract(t *testing.T) {
+	sim, alice := newTestSimulator(t)
+	defer sim.Close()
+
+	contract, receipt, err := sim.DeployWasmContract(alice, newTestWasmContract())
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+
+	params := []interface{}{[]byte("value")}
+	tx, err := sim.NewWasmInvokeTx(alice, contract, params)
+	assert.Nil(t, err)
+	receipts, err := sim.Execute(tx)
+	assert.Nil(t, err)
+	receipt = receipts[0]
+	assert.True(t, receipt.Success())
+	assert.True(t, receipt.GasConsumed > 0)
+
+	input, err := cutils.BuildWasmContractParam(params)
+	assert.Nil(t, err)
+	value, err := sim.GetStorage(contract, []byte("key"))
+	assert.Nil(t, err)
+	assert.Equal(t, input, value)
+
+	var notified bool
+	for _, notify := range receipt.Notify {
+		if notify.ContractAddress == contract {
+			notified = true
+			assert.Equal(t, []byte("stored"), notify.States)
+		}
+	}
+	assert.True(t, notified)
+}
+
+func TestProfile(t *testing.T) {
+	sim, alice := newTestSimulator(t)
+	defer sim.Close()
+
+	contract, _, err := sim.DeployNeoContract(alice, newTestContract())
+	assert.Nil(t, err)
+	tx, err := sim.NewNeoInvokeTx(alice, contract, []interface{}{[]byte("value")})
+	assert.Nil(t, err)
+	result, err := sim.Profile(tx)
+	assert.Nil(t, err)
+
+	profile := result.Profile
+	assert.NotNil(t, profile)
+	assert.True(t, profile.Total > 0 && profile.Total <= result.Gas)
+	assert.True(t, profile.Syscalls["System.Storage.Put"] > 0)
+	assert.True(t, profile.Syscalls[trace.APPCALL_SYSCALL] > 0)
+	assert.True(t, profile.Opcodes[trace.OPCODE_CLASS_CONSTANT] > 0)
+	// the value is stored as serialized storage item
+	assert.True(t, profile.StorageBytesWritten > uint64(len("key")+len("value")))
+
+	callee := profile.Contracts[contract.ToHexString()]
+	assert.NotNil(t, callee)
+	assert.Equal(t, uint64(1), callee.Calls)
+	assert.Equal(t, profile.StorageBytesWritten, callee.StorageBytesWritten)
+	var total uint64
+	for _, c := range profile.Contracts {
+		total += c.Gas
+	}
+	assert.Equal(t, profile.Total, total)
+
+	result, err = sim.PreExecute(tx)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Profile)
+}
+
+func TestManifestDeploy(t *testing.T) {
+	sim, alice := newTestSimulator(t)
+	defer sim.Close()
+
+	newDeployTx := func(code, manifest []byte) *types.Transaction {
+		deploy, err := payload.NewDeployCodeWithManifest(code, payload.NEOVM_TYPE, "", "", "", "", "", manifest)
+		assert.Nil(t, err)
+		tx, err := sim.signTx(alice, sim.gasPrice, &types.MutableTransaction{TxType: types.Deploy, Payload: deploy})
+		assert.Nil(t, err)
+		return tx
+	}
+	manifest := []byte(`{"name":"` + strings.Repeat("a", 1100) + `","methods":[]}`)
+
+	// the manifest is charged as code
+	plain, err := sim.PreExecute(newDeployTx(newTestContract(), nil))
+	assert.Nil(t, err)
+	withManifest, err := sim.PreExecute(newDeployTx(newTestContract(), manifest))
+	assert.Nil(t, err)
+	assert.Equal(t, plain.Gas+sneovm.UINT_DEPLOY_CODE_LEN_GAS, withManifest.Gas)
+
+	// the block is rejected before the manifest height
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	_, err = sim.Execute(newDeployTx(newTestContract(), manifest))
+	config.DefConfig.P2PNode.NetworkId = networkId
+	assert.NotNil(t, err)
+
+	receipts, err := sim.Execute(newDeployTx(newTestContract(), manifest))
+	assert.Nil(t, err)
+	assert.True(t, receipts[0].Success())
+}
+
+func TestTimeTravel(t *testing.T) {
+	sim, _ := newTestSimulator(t)
+	defer sim.Close()
+
+	start := sim.NextBlockTime()
+	assert.Nil(t, sim.AdvanceBlocks(5))
+	assert.Equal(t, uint32(6), sim.Height())
+	assert.Equal(t, start+5, sim.NextBlockTime())
+
+	sim.AdvanceTime(3600)
+	assert.Nil(t, sim.AdvanceBlocks(1))
+	header, err := sim.Ledger().GetHeaderByHeight(7)
+	assert.Nil(t, err)
+	assert.Equal(t, start+5+3600, header.Timestamp)
+
+	assert.NotNil(t, sim.SetNextBlockTime(header.Timestamp))
+	assert.Nil(t, sim.SetNextBlockTime(header.Timestamp+100))
+	assert.Nil(t, sim.AdvanceBlocks(1))
+	header, err = sim.Ledger().GetHeaderByHeight(8)
+	assert.Nil(t, err)
+	assert.Equal(t, start+5+3600+100, header.Timestamp)
+}
+
+func TestSnapshotRevert(t *testing.T) {
+	sim, alice := newTestSimulator(t)
+	defer sim.Close()
+
+	contract, _, err := sim.DeployNeoContract(alice, newTestContract())
+	assert.Nil(t, err)
+	_, err = sim.InvokeNeo(alice, contract, []interface{}{[]byte("value")})
+	assert.Nil(t, err)
+	ong, err := sim.BalanceOf(utils.OngContractAddress, alice.Address)
+	assert.Nil(t, err)
+
+	id := sim.Snapshot()
+	for i := 0; i < 2; i++ {
+		receipt, err := sim.InvokeNeo(alice, contract, []interface{}{[]byte("changed")})
+		assert.Nil(t, err)
+		assert.Nil(t, sim.AdvanceBlocks(3))
+		value, err := sim.GetStorage(contract, []byte("key"))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("changed"), value)
+
+		assert.Nil(t, sim.Revert(id))
+		assert.Equal(t, uint32(3), sim.Height())
+		value, err = sim.GetStorage(contract, []byte("key"))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("value"), value)
+		balance, err := sim.BalanceOf(utils.OngContractAddress, alice.Address)
+		assert.Nil(t, err)
+		assert.Equal(t, ong, balance)
+		_, err = sim.GetReceipt(receipt.TxHash)
+		assert.NotNil(t, err)
+	}
+	assert.NotNil(t, sim.Revert(id+1))
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/core/utils"
+	"github.com/ontio/ontology/smartcontract/service/native/ont"
+)
+
+//signTx fill the gas and nonce of the transaction, signer signs it and pays the gas. Nonces are increased one by one
+//so that the same sequence of calls always produces the same transactions
+func (self *Simulator) signTx(signer *account.Account, gasPrice uint64, mutable *types.MutableTransaction) (*types.Transaction, error) {
+	self.nonce++
+	mutable.Nonce = self.nonce
+	mutable.GasPrice = gasPrice
+	mutable.GasLimit = self.gasLimit
+	mutable.Payer = signer.Address
+	txHash := mutable.Hash()
+	sig, err := signature.Sign(signer, txHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("signature, Sign error:%s", err)
+	}
+	mutable.Sigs = []types.Sig{{
+		PubKeys: []keypair.PublicKey{signer.PublicKey},
+		M:       1,
+		SigData: [][]byte{sig},
+	}}
+	return mutable.IntoImmutable()
+}
+
+func (self *Simulator) newNativeTx(signer *account.Account, gasPrice uint64, contract common.Address, method string,
+	params []interface{}) (*types.Transaction, error) {
+	code, err := utils.BuildNativeInvokeCode(contract, 0, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("BuildNativeInvokeCode error:%s", err)
+	}
+	return self.signTx(signer, gasPrice, utils.NewInvokeTransaction(code))
+}
+
+//NewDeployTx return a transaction deploying the contract code
+func (self *Simulator) NewDeployTx(signer *account.Account, code []byte, vmType payload.VmType) (*types.Transaction, error) {
+	mutable, err := utils.NewDeployTransaction(code, "simulator", "1.0", "simulator", "", "", vmType)
+	if err != nil {
+		return nil, err
+	}
+	return self.signTx(signer, self.gasPrice, mutable)
+}
+
+//NewNeoInvokeTx return a transaction invoking the neovm contract with params
+func (self *Simulator) NewNeoInvokeTx(signer *account.Account, contract common.Address, params []interface{}) (*types.Transaction, error) {
+	code, err := utils.BuildNeoVMInvokeCode(contract, params)
+	if err != nil {
+		return nil, fmt.Errorf("BuildNeoVMInvokeCode error:%s", err)
+	}
+	return self.signTx(signer, self.gasPrice, utils.NewInvokeTransaction(code))
+}
+
+//NewWasmInvokeTx return a transaction invoking the wasm contract with params
+func (self *Simulator) NewWasmInvokeTx(signer *account.Account, contract common.Address, params []interface{}) (*types.Transaction, error) {
+	code, err := utils.BuildWasmVMInvokeCode(contract, params)
+	if err != nil {
+		return nil, fmt.Errorf("BuildWasmVMInvokeCode error:%s", err)
+	}
+	mutable := utils.NewInvokeTransaction(code)
+	mutable.TxType = types.InvokeWasm
+	return self.signTx(signer, self.gasPrice, mutable)
+}
+
+//NewNativeInvokeTx return a transaction invoking the method of the native contract with params
+func (self *Simulator) NewNativeInvokeTx(signer *account.Account, contract common.Address, method string,
+	params []interface{}) (*types.Transaction, error) {
+	return self.newNativeTx(signer, self.gasPrice, contract, method, params)
+}
+
+//DeployNeoContract deploy the neovm contract in a new block
+func (self *Simulator) DeployNeoContract(signer *account.Account, code []byte) (common.Address, *Receipt, error) {
+	return self.deploy(signer, code, payload.NEOVM_TYPE)
+}
+
+//DeployWasmContract deploy the wasm contract in a new block
+func (self *Simulator) DeployWasmContract(signer *account.Account, code []byte) (common.Address, *Receipt, error) {
+	return self.deploy(signer, code, payload.WASMVM_TYPE)
+}
+
+func (self *Simulator) deploy(signer *account.Account, code []byte, vmType payload.VmType) (common.Address, *Receipt, error) {
+	tx, err := self.NewDeployTx(signer, code, vmType)
+	if err != nil {
+		return common.ADDRESS_EMPTY, nil, err
+	}
+	receipt, err := self.executeOne(tx)
+	if err != nil {
+		return common.ADDRESS_EMPTY, nil, err
+	}
+	return common.AddressFromVmCode(code), receipt, nil
+}
+
+//InvokeNeo invoke the neovm contract in a new block
+func (self *Simulator) InvokeNeo(signer *account.Account, contract common.Address, params []interface{}) (*Receipt, error) {
+	tx, err := self.NewNeoInvokeTx(signer, contract, params)
+	if err != nil {
+		return nil, err
+	}
+	return self.executeOne(tx)
+}
+
+//InvokeWasm invoke the wasm contract in a new block
+func (self *Simulator) InvokeWasm(signer *account.Account, contract common.Address, params []interface{}) (*Receipt, error) {
+	tx, err := self.NewWasmInvokeTx(signer, contract, params)
+	if err != nil {
+		return nil, err
+	}
+	return self.executeOne(tx)
+}
+
+//InvokeNative invoke the method of the native contract in a new block
+func (self *Simulator) InvokeNative(signer *account.Account, contract common.Address, method string,
+	params []interface{}) (*Receipt, error) {
+	tx, err := self.NewNativeInvokeTx(signer, contract, method, params)
+	if err != nil {
+		return nil, err
+	}
+	return self.executeOne(tx)
+}
+
+//Transfer transfer amount of the native asset, ONT or ONG, from the signer to addr in a new block
+func (self *Simulator) Transfer(asset common.Address, from *account.Account, to common.Address, amount uint64) (*Receipt, error) {
+	states := []*ont.State{{From: from.Address, To: to, Value: amount}}
+	return self.InvokeNative(from, asset, ont.TRANSFER_NAME, []interface{}{states})
+}
+
+func (self *Simulator) executeOne(tx *types.Transaction) (*Receipt, error) {
+	receipts, err := self.Execute(tx)
+	if err != nil {
+		return nil, err
+	}
+	return receipts[0], nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 // ContextRef is a interface of smart context
@@ -45,6 +46,7 @@ type ContextRef interface {
 	SetInternalErr()
 	IsInternalErr() bool
 	PutCrossStateHashes(hashes []common.Uint256)
+	GetTracer() trace.Tracer
 }
 
 type Engine interface {
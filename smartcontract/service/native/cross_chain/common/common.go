@@ -69,6 +69,9 @@ func CrossChainNeoVMCall(this *native.NativeService, address common.Address, met
 	if !this.ContextRef.CheckUseGas(neovm.NATIVE_INVOKE_GAS) {
 		return nil, fmt.Errorf("[CrossChainNeoVMCall], check use gaslimit insufficient！")
 	}
+	if tracer := this.ContextRef.GetTracer(); tracer != nil {
+		tracer.CaptureGas(neovm.NATIVE_INVOKE_NAME, neovm.NATIVE_INVOKE_GAS)
+	}
 	engine, err := this.ContextRef.NewExecuteEngine(dep.GetRawCode(), ctypes.InvokeNeo)
 	if err != nil {
 		return nil, err
@@ -128,6 +128,23 @@ func (this *PeerPoolListForVm) Serialization(sink *common.ZeroCopySink) {
 	}
 }
 
+func (this *PeerPoolListForVm) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint32, deserialize PeerPoolList length error: %v", io.ErrUnexpectedEOF)
+	}
+	peerPoolList := make([]*PeerPoolItemForVm, 0, n)
+	for i := uint32(0); i < n; i++ {
+		peerPoolItem := new(PeerPoolItemForVm)
+		if err := peerPoolItem.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize peerPool error: %v", err)
+		}
+		peerPoolList = append(peerPoolList, peerPoolItem)
+	}
+	this.PeerPoolList = peerPoolList
+	return nil
+}
+
 type PeerPoolItem struct {
 	Index      uint32         //peer index
 	PeerPubkey string         //peer pubkey
@@ -201,6 +218,40 @@ func (this *PeerPoolItemForVm) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteUint64(this.TotalPos)
 }
 
+func (this *PeerPoolItemForVm) Deserialization(source *common.ZeroCopySource) error {
+	index, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint32, deserialize index error: %v", io.ErrUnexpectedEOF)
+	}
+	peerAddress := new(common.Address)
+	if err := peerAddress.Deserialization(source); err != nil {
+		return fmt.Errorf("address.Deserialize, deserialize peerAddress error: %v", err)
+	}
+	address := new(common.Address)
+	if err := address.Deserialization(source); err != nil {
+		return fmt.Errorf("address.Deserialize, deserialize address error: %v", err)
+	}
+	status := new(Status)
+	if err := status.Deserialization(source); err != nil {
+		return fmt.Errorf("status.Deserialize. deserialize status error: %v", err)
+	}
+	initPos, err := utils.DecodeUint64(source)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize initPos error: %v", err)
+	}
+	totalPos, err := utils.DecodeUint64(source)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize totalPos error: %v", err)
+	}
+	this.Index = index
+	this.PeerAddress = *peerAddress
+	this.Address = *address
+	this.Status = *status
+	this.InitPos = initPos
+	this.TotalPos = totalPos
+	return nil
+}
+
 type AuthorizeInfo struct {
 	PeerPubkey           string
 	Address              common.Address
@@ -30,6 +30,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/states"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 type (
@@ -64,6 +65,17 @@ func (this *NativeService) Register(methodName string, handler Handler) {
 }
 
 func (this *NativeService) Invoke() ([]byte, error) {
+	tracer := this.ContextRef.GetTracer()
+	if tracer == nil {
+		return this.invoke()
+	}
+	tracer.CaptureEnter(trace.NATIVE, this.InvokeParam.Address, this.InvokeParam.Method)
+	result, err := this.invoke()
+	tracer.CaptureExit(err)
+	return result, err
+}
+
+func (this *NativeService) invoke() ([]byte, error) {
 	contract := this.InvokeParam
 	services, ok := Contracts[contract.Address]
 	if !ok {
@@ -20,6 +20,7 @@ package ong
 
 import (
 	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/ontio/ontology/common"
@@ -59,13 +60,74 @@ func OngInit(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("Init ong has been completed!")
 	}
 
+	distribute, err := decodeOngDistribute(native.Input)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
 	item := utils.GenUInt64StorageItem(constants.ONG_TOTAL_SUPPLY)
 	native.CacheDB.Put(ont.GenTotalSupplyKey(contract), item.ToArray())
-	native.CacheDB.Put(append(contract[:], utils.OntContractAddress[:]...), item.ToArray())
+	remain := constants.ONG_TOTAL_SUPPLY
+	for _, state := range distribute {
+		remain -= state.Value
+	}
+	native.CacheDB.Put(ont.GenBalanceKey(contract, utils.OntContractAddress), utils.GenUInt64StorageItem(remain).ToArray())
 	ont.AddNotifications(native, contract, &ont.State{To: utils.OntContractAddress, Value: constants.ONG_TOTAL_SUPPLY})
+	for _, state := range distribute {
+		balanceKey := ont.GenBalanceKey(contract, state.To)
+		balance, err := utils.GetStorageUInt64(native, balanceKey)
+		if err != nil {
+			return utils.BYTE_FALSE, err
+		}
+		native.CacheDB.Put(balanceKey, utils.GenUInt64StorageItem(balance+state.Value).ToArray())
+		ont.AddNotifications(native, contract, state)
+	}
 	return utils.BYTE_TRUE, nil
 }
 
+//decodeOngDistribute decode the optional genesis allocation of ONG, which is taken from the ONT contract holding the
+//whole supply. The public networks init ONG without allocation
+func decodeOngDistribute(data []byte) ([]*ont.State, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	buf, _, irregular, eof := common.NewZeroCopySource(data).NextVarBytes()
+	if eof {
+		return nil, fmt.Errorf("read distribute error:%s", io.ErrUnexpectedEOF)
+	}
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	input := common.NewZeroCopySource(buf)
+	num, err := utils.DecodeVarUint(input)
+	if err != nil {
+		return nil, fmt.Errorf("read number error:%v", err)
+	}
+	var distribute []*ont.State
+	sum := uint64(0)
+	overflow := false
+	for i := uint64(0); i < num; i++ {
+		addr, err := utils.DecodeAddress(input)
+		if err != nil {
+			return nil, fmt.Errorf("read address error:%v", err)
+		}
+		value, err := utils.DecodeVarUint(input)
+		if err != nil {
+			return nil, fmt.Errorf("read value error:%v", err)
+		}
+		sum, overflow = common.SafeAdd(sum, value)
+		if overflow || sum > constants.ONG_TOTAL_SUPPLY {
+			return nil, fmt.Errorf("wrong config. distribute %d over total supply %d", sum, constants.ONG_TOTAL_SUPPLY)
+		}
+		if value > 0 {
+			distribute = append(distribute, &ont.State{From: utils.OntContractAddress, To: addr, Value: value})
+		}
+	}
+	return distribute, nil
+}
+
 func OngTransfer(native *native.NativeService) ([]byte, error) {
 	var transfers ont.Transfers
 	source := common.NewZeroCopySource(native.Input)
@@ -39,6 +39,8 @@ var (
 	STORAGE_GET_GAS               uint64 = 200
 	STORAGE_PUT_GAS               uint64 = 4000
 	STORAGE_DELETE_GAS            uint64 = 100
+	STORAGE_FIND_GAS              uint64 = 200 // Per find base cost, each item of the page costs a storage get.
+	STORAGE_BYTE_GAS              uint64 = 0   // Storage fee per byte of contract storage, an input of storage fees which are not charged yet.
 	RUNTIME_CHECKWITNESS_GAS      uint64 = 200
 	RUNTIME_VERIFYMUTISIG_GAS     uint64 = 400
 	RUNTIME_ADDRESSTOBASE58_GAS   uint64 = 40
@@ -56,6 +58,8 @@ var (
 	DUPLICATE_STACK_SIZE = 1024 * 2
 	VM_STEP_LIMIT        = 400000
 
+	STORAGE_FIND_MAX_LIMIT int64 = 100 // Max items of a storage find page
+
 	// API Name
 	ATTRIBUTE_GETUSAGE_NAME = "Ontology.Attribute.GetUsage"
 	ATTRIBUTE_GETDATA_NAME  = "Ontology.Attribute.GetData"
@@ -94,6 +98,7 @@ var (
 	STORAGE_DELETE_NAME             = "System.Storage.Delete"
 	STORAGE_GETCONTEXT_NAME         = "System.Storage.GetContext"
 	STORAGE_GETREADONLYCONTEXT_NAME = "System.Storage.GetReadOnlyContext"
+	STORAGE_FIND_NAME               = "Ontology.Storage.Find"
 
 	STORAGECONTEXT_ASREADONLY_NAME = "System.StorageContext.AsReadOnly"
 
@@ -125,6 +130,7 @@ var (
 	HASH256_NAME              = "HASH256"
 	UINT_DEPLOY_CODE_LEN_NAME = "Deploy.Code.Gas"
 	UINT_INVOKE_CODE_LEN_NAME = "Invoke.Code.Gas"
+	STORAGE_BYTE_NAME         = "Storage.Byte.Gas"
 
 	GAS_TABLE = initGAS_TABLE()
 
@@ -149,6 +155,7 @@ var (
 		UINT_DEPLOY_CODE_LEN_NAME,
 		UINT_INVOKE_CODE_LEN_NAME,
 		config.WASM_GAS_FACTOR,
+		STORAGE_BYTE_NAME,
 	}
 
 	INIT_GAS_TABLE = map[string]uint64{
@@ -202,6 +209,8 @@ func initGAS_TABLE() *sync.Map {
 
 	m.Store(RUNTIME_VERIFYMUTISIG_NAME, RUNTIME_VERIFYMUTISIG_GAS)
 	m.Store(WASM_INVOKE_NAME, APPCALL_GAS)
+	m.Store(STORAGE_FIND_NAME, STORAGE_FIND_GAS)
+	m.Store(STORAGE_BYTE_NAME, STORAGE_BYTE_GAS)
 
 	m.Store(config.WASM_GAS_FACTOR, config.DEFAULT_WASM_GAS_FACTOR)
 
@@ -39,10 +39,35 @@ func StoreGasCost(gasTable map[string]uint64, engine *vm.Executor) (uint64, erro
 	}
 }
 
+func StorageFindGasCost(gasTable map[string]uint64, engine *vm.Executor) (uint64, error) {
+	item, err := engine.EvalStack.Peek(3)
+	if err != nil {
+		return 0, err
+	}
+	limit, err := item.AsInt64()
+	if err != nil {
+		return 0, err
+	}
+	if limit <= 0 || limit > STORAGE_FIND_MAX_LIMIT {
+		return 0, errors.NewErr("[StorageFindGasCost] invalid limit")
+	}
+	findCost, ok := gasTable[STORAGE_FIND_NAME]
+	if !ok {
+		return 0, errors.NewErr("[StorageFindGasCost] get STORAGE_FIND_NAME gas failed")
+	}
+	getCost, ok := gasTable[STORAGE_GET_NAME]
+	if !ok {
+		return 0, errors.NewErr("[StorageFindGasCost] get STORAGE_GET_NAME gas failed")
+	}
+	return findCost + uint64(limit)*getCost, nil
+}
+
 func GasPrice(gasTable map[string]uint64, engine *vm.Executor, name string) (uint64, error) {
 	switch name {
 	case STORAGE_PUT_NAME:
 		return StoreGasCost(gasTable, engine)
+	case STORAGE_FIND_NAME:
+		return StorageFindGasCost(gasTable, engine)
 	default:
 		if value, ok := gasTable[name]; ok {
 			return value, nil
@@ -32,6 +32,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/context"
 	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/smartcontract/trace"
 	vm "github.com/ontio/ontology/vm/neovm"
 	vmty "github.com/ontio/ontology/vm/neovm/types"
 )
@@ -66,6 +67,11 @@ var (
 		BLOCKCHAIN_GETHEADER_NAME: BlockChainGetHeaderNew,
 	}
 
+	// Services enabled from the storage find height
+	ServiceMapStorageFind = map[string]ServiceHandler{
+		STORAGE_FIND_NAME: StorageFind,
+	}
+
 	// Register all service for smart contract execute
 	ServiceMap = map[string]ServiceHandler{
 		BLOCKCHAIN_GETCONTRACT_NAME: BlockChainGetContract,
@@ -139,10 +145,23 @@ type NeoVmService struct {
 
 // Invoke a smart contract
 func (this *NeoVmService) Invoke() (interface{}, error) {
+	tracer := this.ContextRef.GetTracer()
+	if tracer == nil {
+		return this.invoke()
+	}
+	this.Engine.Tracer = tracer
+	tracer.CaptureEnter(trace.NEOVM, scommon.AddressFromVmCode(this.Code), "")
+	result, err := this.invoke()
+	tracer.CaptureExit(err)
+	return result, err
+}
+
+func (this *NeoVmService) invoke() (interface{}, error) {
 	if len(this.Code) == 0 {
 		return nil, ERR_EXECUTE_CODE
 	}
 	this.ContextRef.PushContext(&context.Context{ContractAddress: scommon.AddressFromVmCode(this.Code), Code: this.Code})
+	tracer := this.ContextRef.GetTracer()
 	var gasTable [256]uint64
 	for {
 		//check the execution step count
@@ -155,10 +174,14 @@ func (this *NeoVmService) Invoke() (interface{}, error) {
 		if this.Engine.Context.GetInstructionPointer() >= len(this.Engine.Context.Code) {
 			break
 		}
+		ip := this.Engine.Context.GetInstructionPointer()
 		opCode, eof := this.Engine.Context.ReadOpCode()
 		if eof {
 			return nil, io.EOF
 		}
+		if this.Engine.Tracer != nil {
+			this.Engine.Tracer.CaptureOp(this.Engine, ip, opCode)
+		}
 
 		price := gasTable[opCode]
 		if opCode >= vm.PUSHBYTES1 && opCode <= vm.PUSHBYTES75 {
@@ -177,6 +200,9 @@ func (this *NeoVmService) Invoke() (interface{}, error) {
 		if !this.ContextRef.CheckUseGas(price) {
 			return nil, ERR_GAS_INSUFFICIENT
 		}
+		if tracer != nil {
+			tracer.CaptureOpGas(opCode, price)
+		}
 
 		switch opCode {
 		case vm.SYSCALL:
@@ -263,10 +289,16 @@ func (this *NeoVmService) SystemCall(engine *vm.Executor) error {
 			serviceHandler, ok = ServiceMapNew[serviceName]
 		}
 	}
+	if !ok && this.Height >= config.GetStorageFindHeight() {
+		serviceHandler, ok = ServiceMapStorageFind[serviceName]
+	}
 
 	if !ok {
 		return errors.NewErr(fmt.Sprintf("[SystemCall] the given service is not supported: %s", serviceName))
 	}
+	if tracer := this.ContextRef.GetTracer(); tracer != nil {
+		tracer.CaptureSyscall(serviceName)
+	}
 	price, err := GasPrice(this.GasTable, engine, serviceName)
 	if err != nil {
 		return err
@@ -274,6 +306,9 @@ func (this *NeoVmService) SystemCall(engine *vm.Executor) error {
 	if !this.ContextRef.CheckUseGas(price) {
 		return ERR_GAS_INSUFFICIENT
 	}
+	if tracer := this.ContextRef.GetTracer(); tracer != nil {
+		tracer.CaptureGas(serviceName, price)
+	}
 	if err := serviceHandler(this, engine); err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "[SystemCall] service execution error!")
 	}
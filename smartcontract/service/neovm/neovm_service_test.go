@@ -26,5 +26,8 @@ func TestNeoVmServiceMap(t *testing.T) {
 		if ServiceMapDeprecated[k] != nil || ServiceMapNew[k] != nil {
 			panic("key in ServiceMap also in ServiceMapDeprecated or ServiceMapNew")
 		}
+		if ServiceMapStorageFind[k] != nil {
+			panic("key in ServiceMap also in ServiceMapStorageFind")
+		}
 	}
 }
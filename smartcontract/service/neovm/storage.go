@@ -25,6 +25,7 @@ import (
 	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/errors"
 	vm "github.com/ontio/ontology/vm/neovm"
+	vmtypes "github.com/ontio/ontology/vm/neovm/types"
 )
 
 // StoragePut put smart contract storage item to cache
@@ -105,6 +106,64 @@ func StorageGet(service *NeoVmService, engine *vm.Executor) error {
 	return engine.EvalStack.PushBytes(value)
 }
 
+// StorageFind push a page of the storage items whose keys have a prefix to vm stack, as [[struct{key, value}...], next key].
+// The page starts from the key not less than start, empty start means from the first item, and next key is empty if no item left
+func StorageFind(service *NeoVmService, engine *vm.Executor) error {
+	context, err := getContext(engine)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[StorageFind] get pop context error!")
+	}
+	prefix, err := engine.EvalStack.PopAsBytes()
+	if err != nil {
+		return err
+	}
+	start, err := engine.EvalStack.PopAsBytes()
+	if err != nil {
+		return err
+	}
+	limit, err := engine.EvalStack.PopAsInt64()
+	if err != nil {
+		return err
+	}
+	if limit <= 0 || limit > STORAGE_FIND_MAX_LIMIT {
+		return errors.NewErr("[StorageFind] invalid limit")
+	}
+	if len(start) == 0 {
+		start = nil
+	}
+
+	items, next, err := service.CacheDB.FindStorage(context.Address, prefix, start, int(limit))
+	if err != nil {
+		return err
+	}
+	page := vmtypes.NewArrayValue()
+	for _, item := range items {
+		key, err := vmtypes.VmValueFromBytes(item.Key)
+		if err != nil {
+			return err
+		}
+		value, err := vmtypes.VmValueFromBytes(item.Value)
+		if err != nil {
+			return err
+		}
+		pair := vmtypes.NewStructValue()
+		if err := pair.Append(key); err != nil {
+			return err
+		}
+		if err := pair.Append(value); err != nil {
+			return err
+		}
+		if err := page.Append(vmtypes.VmValueFromStructVal(pair)); err != nil {
+			return err
+		}
+	}
+	nextKey, err := vmtypes.VmValueFromBytes(next)
+	if err != nil {
+		return err
+	}
+	return engine.EvalStack.PushAsArray([]vmtypes.VmValue{vmtypes.VmValueFromArrayVal(page), nextKey})
+}
+
 // StorageGetContext push smart contract storage context to vm stack
 func StorageGetContext(service *NeoVmService, engine *vm.Executor) error {
 	return engine.EvalStack.PushAsInteropValue(NewStorageContext(service.ContextRef.CurrentContext().ContractAddress))
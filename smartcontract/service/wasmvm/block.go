@@ -23,6 +23,7 @@ import (
 
 func GetCurrentBlockHash(proc *exec.Process, ptr uint32) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_current_blockhash")
 	self.checkGas(CURRENT_BLOCK_HASH_GAS)
 	blockhash := self.Service.BlockHash
 
@@ -35,8 +35,14 @@ var (
 	STORAGE_GET_GAS          uint64 = 200
 	STORAGE_PUT_GAS          uint64 = 4000
 	STORAGE_DELETE_GAS       uint64 = 100
+	STORAGE_FIND_GAS         uint64 = 200 //each item of the page costs a storage get besides
 	UINT_DEPLOY_CODE_LEN_GAS uint64 = 200000
 	PER_UNIT_CODE_LEN        uint64 = 1024
 
 	SHA256_GAS uint64 = 10
+
+	STORAGE_FIND_MAX_LIMIT uint32 = 100
 )
+
+//the name which the gas of calling native contract is profiled as, the same as the neovm syscall
+const NATIVE_INVOKE_NAME = "Ontology.Native.Invoke"
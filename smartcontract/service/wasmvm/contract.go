@@ -81,6 +81,7 @@ func ContractCreate(proc *exec.Process,
 	descLen uint32,
 	newAddressPtr uint32) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_contract_create")
 	code, err := ReadWasmMemory(proc, codePtr, codeLen)
 	if err != nil {
 		panic(err)
@@ -123,7 +124,7 @@ func ContractCreate(proc *exec.Process,
 	if err != nil {
 		panic(err)
 	}
-	_, err = ReadWasmModule(wasmCode, config.DefConfig.Common.WasmVerifyMethod)
+	_, err = ReadWasmModule(wasmCode, config.DefConfig.Common.WasmVerifyMethod, self.Service.Height)
 	if err != nil {
 		panic(err)
 	}
@@ -160,6 +161,7 @@ func ContractMigrate(proc *exec.Process,
 	newAddressPtr uint32) uint32 {
 
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_contract_migrate")
 
 	code, err := ReadWasmMemory(proc, codePtr, codeLen)
 	if err != nil {
@@ -203,7 +205,7 @@ func ContractMigrate(proc *exec.Process,
 	if err != nil {
 		panic(err)
 	}
-	_, err = ReadWasmModule(wasmCode, config.DefConfig.Common.WasmVerifyMethod)
+	_, err = ReadWasmModule(wasmCode, config.DefConfig.Common.WasmVerifyMethod, self.Service.Height)
 	if err != nil {
 		panic(err)
 	}
@@ -229,6 +231,7 @@ func ContractMigrate(proc *exec.Process,
 
 func ContractDestroy(proc *exec.Process) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_contract_destroy")
 	err := deleteContractStorage(self.Service)
 	if err != nil {
 		panic(err)
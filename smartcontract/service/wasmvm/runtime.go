@@ -25,6 +25,7 @@ import (
 	"reflect"
 
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/types"
@@ -34,6 +35,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 	"github.com/ontio/ontology/smartcontract/service/util"
 	"github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/trace"
 	"github.com/ontio/ontology/vm/crossvm_codec"
 	neotypes "github.com/ontio/ontology/vm/neovm/types"
 	"github.com/ontio/wagon/exec"
@@ -54,22 +56,28 @@ type Runtime struct {
 	Input      []byte
 	Output     []byte
 	CallOutPut []byte
+
+	syscall string // the host function being traced
+	gasMark uint64 // the gas left when the wasm instruction gas is captured last time
 }
 
 func Timestamp(proc *exec.Process) uint64 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_timestamp")
 	self.checkGas(TIMESTAMP_GAS)
 	return uint64(self.Service.Time)
 }
 
 func BlockHeight(proc *exec.Process) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_block_height")
 	self.checkGas(BLOCK_HEGHT_GAS)
 	return self.Service.Height
 }
 
 func SelfAddress(proc *exec.Process, dst uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_self_address")
 	self.checkGas(SELF_ADDRESS_GAS)
 	selfaddr := self.Service.ContextRef.CurrentContext().ContractAddress
 	_, err := proc.WriteAt(selfaddr[:], int64(dst))
@@ -80,6 +88,7 @@ func SelfAddress(proc *exec.Process, dst uint32) {
 
 func Sha256(proc *exec.Process, src uint32, slen uint32, dst uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_sha256")
 	cost := uint64((slen/1024)+1) * SHA256_GAS
 	self.checkGas(cost)
 
@@ -100,6 +109,7 @@ func Sha256(proc *exec.Process, src uint32, slen uint32, dst uint32) {
 
 func CallerAddress(proc *exec.Process, dst uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_caller_address")
 	self.checkGas(CALLER_ADDRESS_GAS)
 	if self.Service.ContextRef.CallingContext() != nil {
 		calleraddr := self.Service.ContextRef.CallingContext().ContractAddress
@@ -118,6 +128,7 @@ func CallerAddress(proc *exec.Process, dst uint32) {
 
 func EntryAddress(proc *exec.Process, dst uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_entry_address")
 	self.checkGas(ENTRY_ADDRESS_GAS)
 	entryAddress := self.Service.ContextRef.EntryContext().ContractAddress
 	_, err := proc.WriteAt(entryAddress[:], int64(dst))
@@ -128,6 +139,7 @@ func EntryAddress(proc *exec.Process, dst uint32) {
 
 func Checkwitness(proc *exec.Process, dst uint32) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_check_witness")
 	self.checkGas(CHECKWITNESS_GAS)
 	var addr common.Address
 	_, err := proc.ReadAt(addr[:], int64(dst))
@@ -184,6 +196,7 @@ func notify(service *WasmVmService, bs []byte) error {
 
 func Notify(proc *exec.Process, ptr uint32, l uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_notify")
 	bs, err := ReadWasmMemory(proc, ptr, l)
 	if err != nil {
 		panic(err)
@@ -223,6 +236,7 @@ func GetCallOut(proc *exec.Process, dst uint32) {
 
 func GetCurrentTxHash(proc *exec.Process, ptr uint32) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_current_txhash")
 	self.checkGas(CURRENT_TX_HASH_GAS)
 
 	txhash := self.Service.Tx.Hash()
@@ -247,6 +261,7 @@ func RaiseException(proc *exec.Process, ptr uint32, len uint32) {
 
 func CallContract(proc *exec.Process, contractAddr uint32, inputPtr uint32, inputLen uint32) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_call_contract")
 
 	self.checkGas(CALL_CONTRACT_GAS)
 	var contractAddress common.Address
@@ -264,10 +279,29 @@ func CallContract(proc *exec.Process, contractAddr uint32, inputPtr uint32, inpu
 	if err != nil {
 		panic(err)
 	}
+	// the gas used by the callee is captured in its own frame
+	self.gasMark = *self.Service.GasLimit
 	self.CallOutPut = result
 	return uint32(len(self.CallOutPut))
 }
 
+//hostFuncHeights is the block heights at which the host functions added after the launch of wasm vm are enabled
+var hostFuncHeights = map[string]func() uint32{
+	"ontio_storage_find": config.GetStorageFindHeight,
+}
+
+//newHostModuleAt return the host module exporting only the host functions enabled at the block height, so that
+//a contract importing the others is rejected as the nodes before the functions are added do
+func newHostModuleAt(height uint32) *wasm.Module {
+	m := NewHostModule()
+	for name, enabledHeight := range hostFuncHeights {
+		if height < enabledHeight() {
+			delete(m.Export.Entries, name)
+		}
+	}
+	return m
+}
+
 func NewHostModule() *wasm.Module {
 	m := wasm.NewModule()
 	paramTypes := make([]wasm.ValueType, 14)
@@ -464,6 +498,11 @@ func NewHostModule() *wasm.Module {
 			Host: reflect.ValueOf(Sha256),
 			Body: &wasm.FunctionBody{}, // create a dummy wasm body (the actual value will be taken from Host.)
 		},
+		{ //24
+			Sig:  &m.Types.Entries[6],
+			Host: reflect.ValueOf(StorageFind),
+			Body: &wasm.FunctionBody{}, // create a dummy wasm body (the actual value will be taken from Host.)
+		},
 	}
 
 	m.Export = &wasm.SectionExports{
@@ -588,6 +627,11 @@ func NewHostModule() *wasm.Module {
 				Kind:     wasm.ExternalFunction,
 				Index:    23,
 			},
+			"ontio_storage_find": {
+				FieldStr: "ontio_storage_find",
+				Kind:     wasm.ExternalFunction,
+				Index:    24,
+			},
 		},
 	}
 
@@ -627,11 +671,32 @@ func checkGasInner(gasLimit *uint64, cost uint64) error {
 	return nil
 }
 
+func (self *Runtime) traceSyscall(name string) {
+	if tracer := self.Service.ContextRef.GetTracer(); tracer != nil {
+		self.captureWasmGas(tracer)
+		self.syscall = name
+		tracer.CaptureSyscall(name)
+	}
+}
+
 func (self *Runtime) checkGas(gaslimit uint64) {
 	err := checkGasInner(self.Service.vm.ExecMetrics.GasLimit, gaslimit)
 	if err != nil {
 		panic(err)
 	}
+	if tracer := self.Service.ContextRef.GetTracer(); tracer != nil {
+		tracer.CaptureGas(self.syscall, gaslimit)
+		self.gasMark = *self.Service.vm.ExecMetrics.GasLimit
+	}
+}
+
+//captureWasmGas report the gas of wasm instructions executed since last captured
+func (self *Runtime) captureWasmGas(tracer trace.Tracer) {
+	gasLeft := *self.Service.GasLimit
+	if self.gasMark > gasLeft {
+		tracer.CaptureWasmGas(self.gasMark - gasLeft)
+	}
+	self.gasMark = gasLeft
 }
 
 func serializeStorageKey(contractAddress common.Address, key []byte) []byte {
@@ -689,6 +754,9 @@ func callContractInner(service *WasmVmService, contractAddress common.Address, i
 		if err != nil {
 			return []byte{}, errors.NewErr("[wasm_Service]Insufficient gas limit")
 		}
+		if tracer := service.ContextRef.GetTracer(); tracer != nil {
+			tracer.CaptureGas(NATIVE_INVOKE_NAME, NATIVE_INVOKE_GAS)
+		}
 
 		native := &native2.NativeService{
 			CacheDB:     service.CacheDB,
@@ -21,6 +21,8 @@ import (
 	"errors"
 	"math"
 
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/wagon/exec"
 )
@@ -57,6 +59,7 @@ func storageRead(service *WasmVmService, keybytes []byte, klen uint32, vlen uint
 
 func StorageRead(proc *exec.Process, keyPtr uint32, klen uint32, val uint32, vlen uint32, offset uint32) uint32 {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_storage_read")
 	self.checkGas(STORAGE_GET_GAS)
 	keybytes, err := ReadWasmMemory(proc, keyPtr, klen)
 	if err != nil {
@@ -81,6 +84,7 @@ func StorageRead(proc *exec.Process, keyPtr uint32, klen uint32, val uint32, vle
 
 func StorageWrite(proc *exec.Process, keyPtr uint32, keyLen uint32, valPtr uint32, valLen uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_storage_write")
 	keybytes, err := ReadWasmMemory(proc, keyPtr, keyLen)
 	if err != nil {
 		panic(err)
@@ -101,6 +105,7 @@ func StorageWrite(proc *exec.Process, keyPtr uint32, keyLen uint32, valPtr uint3
 
 func StorageDelete(proc *exec.Process, keyPtr uint32, keyLen uint32) {
 	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_storage_delete")
 	self.checkGas(STORAGE_DELETE_GAS)
 	keybytes, err := ReadWasmMemory(proc, keyPtr, keyLen)
 	if err != nil {
@@ -110,3 +115,51 @@ func StorageDelete(proc *exec.Process, keyPtr uint32, keyLen uint32) {
 
 	self.Service.CacheDB.Delete(key)
 }
+
+func storageFind(service *WasmVmService, prefix []byte, start []byte, limit uint32) ([]byte, error) {
+	if len(start) == 0 {
+		start = nil
+	}
+	items, next, err := service.CacheDB.FindStorage(service.ContextRef.CurrentContext().ContractAddress, prefix, start, int(limit))
+	if err != nil {
+		return nil, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(items)))
+	for _, item := range items {
+		sink.WriteVarBytes(item.Key)
+		sink.WriteVarBytes(item.Value)
+	}
+	sink.WriteVarBytes(next)
+	return sink.Bytes(), nil
+}
+
+//StorageFind put a page of at most limit storage items whose keys have the prefix to call output and return its length.
+//The page starts from the key not less than start, empty start means from the first item. The output is serialized as
+//varuint count, count pairs of varbytes key and value, then varbytes next key which is empty if no item left
+func StorageFind(proc *exec.Process, prefixPtr uint32, prefixLen uint32, startPtr uint32, startLen uint32, limit uint32) uint32 {
+	self := proc.HostData().(*Runtime)
+	self.traceSyscall("ontio_storage_find")
+	if self.Service.Height < config.GetStorageFindHeight() {
+		panic(errors.New("ontio_storage_find is not enabled"))
+	}
+	if limit == 0 || limit > STORAGE_FIND_MAX_LIMIT {
+		panic(errors.New("invalid storage find limit"))
+	}
+	self.checkGas(STORAGE_FIND_GAS + uint64(limit)*STORAGE_GET_GAS)
+	prefix, err := ReadWasmMemory(proc, prefixPtr, prefixLen)
+	if err != nil {
+		panic(err)
+	}
+	start, err := ReadWasmMemory(proc, startPtr, startLen)
+	if err != nil {
+		panic(err)
+	}
+
+	output, err := storageFind(self.Service, prefix, start, limit)
+	if err != nil {
+		panic(err)
+	}
+	self.CallOutPut = output
+	return uint32(len(output))
+}
@@ -80,11 +80,12 @@ func checkOntoWasm(m *wasm.Module) error {
 	return nil
 }
 
-func ReadWasmModule(code []byte, verify config.VerifyMethod) (*exec.CompiledModule, error) {
+//ReadWasmModule read and compile the wasm code which can only import the host functions enabled at the block height
+func ReadWasmModule(code []byte, verify config.VerifyMethod, height uint32) (*exec.CompiledModule, error) {
 	m, err := wasm.ReadModule(bytes.NewReader(code), func(name string) (*wasm.Module, error) {
 		switch name {
 		case "env":
-			return NewHostModule(), nil
+			return newHostModuleAt(height), nil
 		}
 		return nil, fmt.Errorf("module %q unknown", name)
 	})
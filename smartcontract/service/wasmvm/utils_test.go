@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package wasmvm
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common/config"
+	"github.com/stretchr/testify/assert"
+)
+
+//newFindModule return a wasm module which imports ontio_storage_find if imported, or only mentions its name in a
+//custom section
+func newFindModule(imported bool) []byte {
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+	section := func(id byte, content []byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	if !imported {
+		return append(code, section(0, append(name("ontio_storage_find"), 0x01))...)
+	}
+	// (i32, i32, i32, i32, i32) -> i32
+	code = append(code, section(1, []byte{0x01, 0x60, 0x05, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f})...)
+	entry := append(append([]byte{0x01}, name("env")...), name("ontio_storage_find")...)
+	return append(code, section(2, append(entry, 0x00, 0x00))...)
+}
+
+func TestReadWasmModuleHostFuncHeight(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	code := newFindModule(true)
+	_, err := ReadWasmModule(code, config.NoneVerifyMethod, config.GetStorageFindHeight()-1)
+	assert.NotNil(t, err)
+	compiled, err := ReadWasmModule(code, config.NoneVerifyMethod, config.GetStorageFindHeight())
+	assert.Nil(t, err)
+	assert.True(t, requireInterpreter(compiled.RawModule))
+
+	compiled, err = ReadWasmModule(newFindModule(false), config.NoneVerifyMethod, 0)
+	assert.Nil(t, err)
+	assert.False(t, requireInterpreter(compiled.RawModule))
+}
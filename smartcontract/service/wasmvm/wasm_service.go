@@ -29,7 +29,9 @@ import (
 	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/states"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/smartcontract/trace"
 	"github.com/ontio/wagon/exec"
+	"github.com/ontio/wagon/wasm"
 )
 
 type WasmVmService struct {
@@ -148,11 +150,24 @@ func (this *WasmVmService) Invoke() (interface{}, error) {
 
 	this.ContextRef.PushContext(&context.Context{ContractAddress: contract.Address, Code: wasmCode})
 
+	compiled, err := this.compiledModule(contract.Address, wasmCode)
+	if err != nil {
+		return nil, err
+	}
+
 	var output []byte
-	if this.JitMode {
+	// the jit engine can not be traced, traced execution always runs in interpreter
+	tracer := this.ContextRef.GetTracer()
+	if this.JitMode && tracer == nil && !requireInterpreter(compiled.RawModule) {
 		output, err = invokeJit(this, contract, wasmCode)
 	} else {
-		output, err = invokeInterpreter(this, contract, wasmCode)
+		if tracer != nil {
+			tracer.CaptureEnter(trace.WASMVM, contract.Address, "")
+		}
+		output, err = invokeInterpreter(this, contract, compiled)
+		if tracer != nil {
+			tracer.CaptureExit(err)
+		}
 	}
 
 	if err != nil {
@@ -163,25 +178,36 @@ func (this *WasmVmService) Invoke() (interface{}, error) {
 	return output, nil
 }
 
-func invokeInterpreter(this *WasmVmService, contract *states.WasmContractParam, wasmCode []byte) ([]byte, error) {
-	host := &Runtime{Service: this, Input: contract.Args}
-
-	var compiled *exec.CompiledModule
-	if CodeCache != nil {
-		cached, ok := CodeCache.Get(contract.Address.ToHexString())
-		if ok {
-			compiled = cached.(*exec.CompiledModule)
-		}
+//compiledModule return the cached compiled module of the contract, compile and cache it if missing
+func (this *WasmVmService) compiledModule(address common.Address, wasmCode []byte) (*exec.CompiledModule, error) {
+	if cached, ok := CodeCache.Get(address.ToHexString()); ok {
+		return cached.(*exec.CompiledModule), nil
 	}
+	compiled, err := ReadWasmModule(wasmCode, config.NoneVerifyMethod, this.Height)
+	if err != nil {
+		return nil, err
+	}
+	CodeCache.Add(address.ToHexString(), compiled)
+	return compiled, nil
+}
 
-	if compiled == nil {
-		module, err := ReadWasmModule(wasmCode, config.NoneVerifyMethod)
-		if err != nil {
-			return nil, err
+// the host functions added after the jit engine are only provided by interpreter
+var interpreterOnlyImports = map[string]bool{"ontio_storage_find": true}
+
+func requireInterpreter(m *wasm.Module) bool {
+	if m.Import == nil {
+		return false
+	}
+	for _, entry := range m.Import.Entries {
+		if entry.ModuleName == "env" && interpreterOnlyImports[entry.FieldName] {
+			return true
 		}
-		compiled = module
-		CodeCache.Add(contract.Address.ToHexString(), compiled)
 	}
+	return false
+}
+
+func invokeInterpreter(this *WasmVmService, contract *states.WasmContractParam, compiled *exec.CompiledModule) ([]byte, error) {
+	host := &Runtime{Service: this, Input: contract.Args}
 
 	vm, err := exec.NewVMWithCompiled(compiled, WASM_MEM_LIMITATION)
 	if err != nil {
@@ -219,7 +245,11 @@ func invokeInterpreter(this *WasmVmService, contract *states.WasmContractParam,
 	//no args for passed in, all args in runtime input buffer
 	this.vm = vm
 
+	host.gasMark = *this.GasLimit
 	_, err = vm.ExecCode(index)
+	if tracer := this.ContextRef.GetTracer(); tracer != nil {
+		host.captureWasmGas(tracer)
+	}
 
 	if err != nil {
 		return nil, errors.NewErr("[Call]ExecCode error!" + err.Error())
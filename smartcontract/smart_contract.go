@@ -31,6 +31,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/service/neovm"
 	"github.com/ontio/ontology/smartcontract/service/wasmvm"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/smartcontract/trace"
 	vm "github.com/ontio/ontology/vm/neovm"
 )
 
@@ -53,6 +54,7 @@ type SmartContract struct {
 	PreExec       bool
 	internelErr   bool
 	CrossHashes   []common.Uint256
+	Tracer        trace.Tracer // nil if the execution is not traced
 }
 
 // Config describe smart contract need parameters configuration
@@ -255,3 +257,7 @@ func (this *SmartContract) SetInternalErr() {
 func (this *SmartContract) IsInternalErr() bool {
 	return this.internelErr
 }
+
+func (this *SmartContract) GetTracer() trace.Tracer {
+	return this.Tracer
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/trace"
 )
 
 // Invoke smart contract struct
@@ -76,8 +77,9 @@ func (this *ContractInvokeParam) Deserialization(source *common.ZeroCopySource)
 }
 
 type PreExecResult struct {
-	State  byte
-	Gas    uint64
-	Result interface{}
-	Notify []*event.NotifyEventInfo
+	State   byte
+	Gas     uint64
+	Result  interface{}
+	Notify  []*event.NotifyEventInfo
+	Profile *trace.GasProfile // nil if the gas is not profiled
 }
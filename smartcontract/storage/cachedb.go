@@ -21,6 +21,7 @@ package storage
 import (
 	comm "github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/store/overlaydb"
 	"github.com/syndtr/goleveldb/leveldb/util"
@@ -32,6 +33,14 @@ type CacheDB struct {
 	memdb      *overlaydb.MemDB
 	backend    *overlaydb.OverlayDB
 	keyScratch []byte
+	tracer     StorageTracer
+}
+
+// StorageTracer observes the contract storage accesses, key is the contract address followed by the storage key
+type StorageTracer interface {
+	CaptureStorageRead(key, value []byte)
+	// CaptureStorageWrite is called before the value of key is changed, value is empty when key is deleted
+	CaptureStorageWrite(key, prev, value []byte)
 }
 
 const initCap = 1024
@@ -49,6 +58,11 @@ func (self *CacheDB) Reset() {
 	self.memdb.Reset()
 }
 
+// SetTracer set the tracer of contract storage accesses, nil to disable tracing
+func (self *CacheDB) SetTracer(tracer StorageTracer) {
+	self.tracer = tracer
+}
+
 func ensureBuffer(b []byte, n int) []byte {
 	if cap(b) < n {
 		return make([]byte, n)
@@ -75,6 +89,10 @@ func (self *CacheDB) Commit() {
 }
 
 func (self *CacheDB) Put(key []byte, value []byte) {
+	if self.tracer != nil {
+		prev, _ := self.get(common.ST_STORAGE, key)
+		self.tracer.CaptureStorageWrite(key, prev, value)
+	}
 	self.put(common.ST_STORAGE, key, value)
 }
 
@@ -115,7 +133,11 @@ func (self *CacheDB) DeleteContract(address comm.Address) {
 }
 
 func (self *CacheDB) Get(key []byte) ([]byte, error) {
-	return self.get(common.ST_STORAGE, key)
+	value, err := self.get(common.ST_STORAGE, key)
+	if err == nil && self.tracer != nil {
+		self.tracer.CaptureStorageRead(key, value)
+	}
+	return value, err
 }
 
 func (self *CacheDB) get(prefix common.DataEntryPrefix, key []byte) ([]byte, error) {
@@ -133,6 +155,10 @@ func (self *CacheDB) get(prefix common.DataEntryPrefix, key []byte) ([]byte, err
 }
 
 func (self *CacheDB) Delete(key []byte) {
+	if self.tracer != nil {
+		prev, _ := self.get(common.ST_STORAGE, key)
+		self.tracer.CaptureStorageWrite(key, prev, nil)
+	}
 	self.delete(common.ST_STORAGE, key)
 }
 
@@ -153,10 +179,58 @@ func (self *CacheDB) NewIterator(key []byte) common.StoreIterator {
 	return &Iter{overlaydb.NewJoinIter(memIter, backIter)}
 }
 
+// NewRangeIterator return the iterator of storage keys in range [start, end), nil end means unbounded
+func (self *CacheDB) NewRangeIterator(start, end []byte) common.StoreIterator {
+	storageRange := util.BytesPrefix([]byte{byte(common.ST_STORAGE)})
+	pstart := makePrefixedKey(nil, byte(common.ST_STORAGE), start)
+	pend := storageRange.Limit
+	if end != nil {
+		pend = makePrefixedKey(nil, byte(common.ST_STORAGE), end)
+	}
+	backIter := self.backend.NewRangeIterator(pstart, pend)
+	memIter := self.memdb.NewIterator(&util.Range{Start: pstart, Limit: pend})
+
+	return &Iter{overlaydb.NewJoinIter(memIter, backIter)}
+}
+
+// FindStorage read a page of at most limit storage items of contract whose keys have the prefix, starting from the key
+// not less than start. Keys are relative to the contract and values are decoded from storage items. next is the start
+// of the following page, nil if no item left.
+func (self *CacheDB) FindStorage(contract comm.Address, prefix, start []byte, limit int) (items []*common.KeyValue, next []byte, err error) {
+	iter := self.NewIterator(append(contract[:], prefix...))
+	defer iter.Release()
+	if start != nil {
+		start = append(contract[:], start...)
+	}
+	items, next, err = common.ReadPage(iter, start, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, item := range items {
+		if self.tracer != nil {
+			self.tracer.CaptureStorageRead(item.Key, item.Value)
+		}
+		item.Key = item.Key[comm.ADDR_LEN:]
+		item.Value, err = states.GetValueFromRawStorageItem(item.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if next != nil {
+		next = next[comm.ADDR_LEN:]
+	}
+	return items, next, nil
+}
+
 type Iter struct {
 	*overlaydb.JoinIter
 }
 
+// Seek the first storage key greater than or equal to key
+func (self *Iter) Seek(key []byte) bool {
+	return self.JoinIter.Seek(makePrefixedKey(nil, byte(common.ST_STORAGE), key))
+}
+
 func (self *Iter) Key() []byte {
 	key := self.JoinIter.Key()
 	if len(key) != 0 {
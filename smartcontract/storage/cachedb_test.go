@@ -18,9 +18,12 @@
 package storage
 
 import (
+	"fmt"
 	"math/rand"
 	"testing"
 
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/store/leveldbstore"
 	"github.com/ontio/ontology/core/store/overlaydb"
@@ -83,3 +86,44 @@ func TestCacheDB(t *testing.T) {
 	}
 
 }
+
+func TestFindStorage(t *testing.T) {
+	memback, _ := leveldbstore.NewMemLevelDBStore()
+	overlay := overlaydb.NewOverlayDB(memback)
+	contract := comm.Address{1}
+	other := comm.Address{2}
+
+	cache := NewCacheDB(overlay)
+	for i := 0; i < 10; i++ {
+		key := append(contract[:], []byte(fmt.Sprintf("key%d", i))...)
+		cache.Put(key, states.GenRawStorageItem([]byte(fmt.Sprintf("value%d", i))))
+	}
+	cache.Put(append(other[:], []byte("key0")...), states.GenRawStorageItem([]byte("other")))
+	cache.Commit()
+	cache = NewCacheDB(overlay)
+	cache.Delete(append(contract[:], []byte("key3")...))
+	cache.Put(append(contract[:], []byte("key10")...), states.GenRawStorageItem([]byte("value10")))
+
+	items, next, err := cache.FindStorage(contract, []byte("key"), nil, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("key4"), next)
+	assert.Equal(t, 4, len(items))
+	assert.Equal(t, &common.KeyValue{Key: []byte("key0"), Value: []byte("value0")}, items[0])
+	assert.Equal(t, []byte("key1"), items[1].Key)
+	assert.Equal(t, []byte("key10"), items[2].Key)
+	assert.Equal(t, []byte("key2"), items[3].Key)
+
+	items, next, err = cache.FindStorage(contract, []byte("key"), next, 10)
+	assert.Nil(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, 6, len(items))
+	assert.Equal(t, []byte("key9"), items[5].Key)
+
+	iter := cache.NewRangeIterator(append(contract[:], []byte("key5")...), append(contract[:], []byte("key7")...))
+	defer iter.Release()
+	assert.True(t, iter.Last())
+	assert.Equal(t, append(contract[:], []byte("key6")...), iter.Key())
+	assert.True(t, iter.Seek(append(contract[:], []byte("key")...)))
+	assert.Equal(t, append(contract[:], []byte("key5")...), iter.Key())
+	assert.False(t, iter.Prev())
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trace
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/vm/neovm"
+)
+
+// max opcodes recorded by opcode tracer, the following opcodes are dropped
+const MAX_TRACE_OPS = 100000
+
+// CallFrame is a contract invocation in the call tree
+type CallFrame struct {
+	VmType   VmType           `json:"vmType"`
+	Contract string           `json:"contract"`
+	Method   string           `json:"method,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Syscalls []string         `json:"syscalls,omitempty"`
+	Storage  []*StorageAccess `json:"storage,omitempty"`
+	Ops      []*OpLog         `json:"ops,omitempty"`
+	Calls    []*CallFrame     `json:"calls,omitempty"`
+}
+
+// StorageAccess is a storage read or write of the contract
+type StorageAccess struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// OpLog is an executed neovm opcode with the evaluation stack before executed, top item first
+type OpLog struct {
+	IP     int      `json:"ip"`
+	Opcode string   `json:"opcode"`
+	Stack  []string `json:"stack"`
+}
+
+// CallTracer records the call tree of contracts
+type CallTracer struct {
+	NoopTracer
+	detail    bool
+	opCount   int
+	root      *CallFrame
+	stack     []*CallFrame
+	truncated bool
+}
+
+// CallTrace is the result of CallTracer
+type CallTrace struct {
+	Root      *CallFrame `json:"root"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// NewOpcodeTracer return a call tracer which also records the opcodes, syscalls and storage accesses of every frame
+func NewOpcodeTracer() *CallTracer {
+	return &CallTracer{detail: true}
+}
+
+func (self *CallTracer) current() *CallFrame {
+	if len(self.stack) == 0 {
+		return nil
+	}
+	return self.stack[len(self.stack)-1]
+}
+
+func (self *CallTracer) CaptureEnter(vmType VmType, contract common.Address, method string) {
+	frame := &CallFrame{VmType: vmType, Contract: contract.ToHexString(), Method: method}
+	if parent := self.current(); parent != nil {
+		parent.Calls = append(parent.Calls, frame)
+	} else if self.root == nil {
+		self.root = frame
+	} else {
+		return
+	}
+	self.stack = append(self.stack, frame)
+}
+
+func (self *CallTracer) CaptureExit(err error) {
+	frame := self.current()
+	if frame == nil {
+		return
+	}
+	if err != nil {
+		frame.Error = err.Error()
+	}
+	self.stack = self.stack[:len(self.stack)-1]
+}
+
+func (self *CallTracer) CaptureSyscall(name string) {
+	if frame := self.current(); frame != nil && self.detail {
+		frame.Syscalls = append(frame.Syscalls, name)
+	}
+}
+
+func (self *CallTracer) CaptureStorageRead(key, value []byte) {
+	self.captureStorage("read", key, value)
+}
+
+func (self *CallTracer) CaptureStorageWrite(key, prev, value []byte) {
+	if len(value) == 0 {
+		self.captureStorage("delete", key, nil)
+		return
+	}
+	self.captureStorage("write", key, value)
+}
+
+func (self *CallTracer) captureStorage(op string, key, value []byte) {
+	frame := self.current()
+	if frame == nil || !self.detail {
+		return
+	}
+	frame.Storage = append(frame.Storage, &StorageAccess{
+		Op:    op,
+		Key:   common.ToHexString(key),
+		Value: common.ToHexString(value),
+	})
+}
+
+func (self *CallTracer) CaptureOp(engine *neovm.Executor, ip int, opcode neovm.OpCode) {
+	frame := self.current()
+	if frame == nil || !self.detail {
+		return
+	}
+	if self.opCount >= MAX_TRACE_OPS {
+		self.truncated = true
+		return
+	}
+	self.opCount += 1
+
+	count := engine.EvalStack.Count()
+	stack := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		item, err := engine.EvalStack.Peek(int64(i))
+		if err != nil {
+			break
+		}
+		stack = append(stack, item.Dump())
+	}
+	frame.Ops = append(frame.Ops, &OpLog{IP: ip, Opcode: opcodeName(opcode), Stack: stack})
+}
+
+func (self *CallTracer) Result() interface{} {
+	// close the frames not exited for the execution is aborted
+	for len(self.stack) != 0 {
+		self.CaptureExit(nil)
+	}
+	return &CallTrace{Root: self.root, Truncated: self.truncated}
+}
+
+func opcodeName(opcode neovm.OpCode) string {
+	if opcode >= neovm.PUSHBYTES1 && opcode <= neovm.PUSHBYTES75 {
+		return fmt.Sprintf("PUSHBYTES%d", opcode)
+	}
+	if name := neovm.OpExecList[opcode].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", byte(opcode))
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trace
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/vm/neovm"
+)
+
+// neovm opcode classes of gas profile
+const (
+	OPCODE_CLASS_CONSTANT   = "constant"
+	OPCODE_CLASS_FLOW       = "flow"
+	OPCODE_CLASS_STACK      = "stack"
+	OPCODE_CLASS_SPLICE     = "splice"
+	OPCODE_CLASS_BITWISE    = "bitwise"
+	OPCODE_CLASS_ARITHMETIC = "arithmetic"
+	OPCODE_CLASS_CRYPTO     = "crypto"
+	OPCODE_CLASS_ARRAY      = "array"
+	OPCODE_CLASS_EXCEPTION  = "exception"
+)
+
+// APPCALL_SYSCALL is the syscall name which the gas of neovm APPCALL and TAILCALL is counted to
+const APPCALL_SYSCALL = "AppCall"
+
+// GasProfile is the result of GasProfiler
+type GasProfile struct {
+	// Total is the gas charged during execution, the invoke code length fee and minimum gas are not included
+	Total uint64 `json:"total"`
+	// Opcodes is the gas of neovm opcodes by class
+	Opcodes map[string]uint64 `json:"opcodes"`
+	// Syscalls is the gas of neovm syscalls and wasm host functions by name
+	Syscalls map[string]uint64 `json:"syscalls"`
+	// Contracts is the gas charged in the frames of contract, keyed by contract address
+	Contracts map[string]*ContractGas `json:"contracts"`
+	// StorageBytesWritten is the total length of keys and values written as stored, deletes are not included
+	StorageBytesWritten uint64 `json:"storageBytesWritten"`
+}
+
+// ContractGas is the gas profile of a contract
+type ContractGas struct {
+	VmType VmType `json:"vmType"`
+	Calls  uint64 `json:"calls"`
+	// Gas is charged in the frames of the contract, excluding the contracts it called
+	Gas                 uint64 `json:"gas"`
+	StorageBytesWritten uint64 `json:"storageBytesWritten"`
+	// WasmGas is the gas of wasm instructions, included in Gas
+	WasmGas uint64 `json:"wasmGas,omitempty"`
+}
+
+// GasProfiler breaks the gas down by neovm opcode class, syscall and contract
+type GasProfiler struct {
+	NoopTracer
+	profile *GasProfile
+	stack   []*ContractGas
+}
+
+func NewGasProfiler() *GasProfiler {
+	return &GasProfiler{profile: &GasProfile{
+		Opcodes:   make(map[string]uint64),
+		Syscalls:  make(map[string]uint64),
+		Contracts: make(map[string]*ContractGas),
+	}}
+}
+
+func (self *GasProfiler) CaptureEnter(vmType VmType, contract common.Address, method string) {
+	addr := contract.ToHexString()
+	frame, ok := self.profile.Contracts[addr]
+	if !ok {
+		frame = &ContractGas{VmType: vmType}
+		self.profile.Contracts[addr] = frame
+	}
+	frame.Calls += 1
+	self.stack = append(self.stack, frame)
+}
+
+func (self *GasProfiler) CaptureExit(err error) {
+	if len(self.stack) != 0 {
+		self.stack = self.stack[:len(self.stack)-1]
+	}
+}
+
+func (self *GasProfiler) CaptureOpGas(opcode neovm.OpCode, gas uint64) {
+	if opcode == neovm.APPCALL || opcode == neovm.TAILCALL {
+		self.profile.Syscalls[APPCALL_SYSCALL] += gas
+	} else {
+		self.profile.Opcodes[OpcodeClass(opcode)] += gas
+	}
+	self.charge(gas)
+}
+
+func (self *GasProfiler) CaptureGas(name string, gas uint64) {
+	self.profile.Syscalls[name] += gas
+	self.charge(gas)
+}
+
+func (self *GasProfiler) CaptureWasmGas(gas uint64) {
+	if frame := self.current(); frame != nil {
+		frame.WasmGas += gas
+	}
+	self.charge(gas)
+}
+
+func (self *GasProfiler) CaptureStorageWrite(key, prev, value []byte) {
+	if len(value) == 0 {
+		return
+	}
+	addr, k := splitStorageKey(key)
+	size := uint64(len(k) + len(value))
+	self.profile.StorageBytesWritten += size
+	if frame, ok := self.profile.Contracts[addr.ToHexString()]; ok {
+		frame.StorageBytesWritten += size
+	}
+}
+
+func (self *GasProfiler) Result() interface{} {
+	return self.profile
+}
+
+func (self *GasProfiler) current() *ContractGas {
+	if len(self.stack) == 0 {
+		return nil
+	}
+	return self.stack[len(self.stack)-1]
+}
+
+func (self *GasProfiler) charge(gas uint64) {
+	self.profile.Total += gas
+	if frame := self.current(); frame != nil {
+		frame.Gas += gas
+	}
+}
+
+// OpcodeClass return the class of neovm opcode
+func OpcodeClass(opcode neovm.OpCode) string {
+	switch {
+	case opcode <= neovm.PUSH16:
+		return OPCODE_CLASS_CONSTANT
+	case opcode <= neovm.TAILCALL, opcode == neovm.DCALL:
+		return OPCODE_CLASS_FLOW
+	case opcode <= neovm.TUCK:
+		return OPCODE_CLASS_STACK
+	case opcode <= neovm.SIZE:
+		return OPCODE_CLASS_SPLICE
+	case opcode <= neovm.EQUAL:
+		return OPCODE_CLASS_BITWISE
+	case opcode <= neovm.WITHIN:
+		return OPCODE_CLASS_ARITHMETIC
+	case opcode <= neovm.CHECKMULTISIG:
+		return OPCODE_CLASS_CRYPTO
+	case opcode < neovm.THROW:
+		return OPCODE_CLASS_ARRAY
+	default:
+		return OPCODE_CLASS_EXCEPTION
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trace
+
+import (
+	"github.com/ontio/ontology/common"
+)
+
+// StorageDiff is the change of a storage key by the transaction
+type StorageDiff struct {
+	Contract string `json:"contract"`
+	Key      string `json:"key"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+}
+
+// StorageDiffTracer records the storage keys changed by the transaction
+type StorageDiffTracer struct {
+	NoopTracer
+	keys  []string
+	diffs map[string]*storageChange
+}
+
+type storageChange struct {
+	before []byte
+	after  []byte
+}
+
+func NewStorageDiffTracer() *StorageDiffTracer {
+	return &StorageDiffTracer{diffs: make(map[string]*storageChange)}
+}
+
+func (self *StorageDiffTracer) CaptureStorageWrite(key, prev, value []byte) {
+	change, ok := self.diffs[string(key)]
+	if !ok {
+		change = &storageChange{before: copyBytes(prev)}
+		self.diffs[string(key)] = change
+		self.keys = append(self.keys, string(key))
+	}
+	change.after = copyBytes(value)
+}
+
+// Result return the changed keys in the order of first written, keys written back to original value are excluded
+func (self *StorageDiffTracer) Result() interface{} {
+	diffs := make([]*StorageDiff, 0, len(self.keys))
+	for _, key := range self.keys {
+		change := self.diffs[key]
+		if string(change.before) == string(change.after) {
+			continue
+		}
+		addr, k := splitStorageKey([]byte(key))
+		diffs = append(diffs, &StorageDiff{
+			Contract: addr.ToHexString(),
+			Key:      common.ToHexString(k),
+			Before:   common.ToHexString(change.before),
+			After:    common.ToHexString(change.after),
+		})
+	}
+	return diffs
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package trace provides the tracers which record the execution of a transaction
+package trace
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/vm/neovm"
+)
+
+type VmType string
+
+const (
+	NEOVM  VmType = "neovm"
+	WASMVM VmType = "wasmvm"
+	NATIVE VmType = "native"
+)
+
+// tracer names
+const (
+	OPCODE_TRACER  = "opcode"  // call tree with opcodes, stack snapshots, syscalls and storage accesses
+	CALL_TRACER    = "call"    // call tree only
+	STORAGE_TRACER = "storage" // storage diff only
+	GAS_PROFILER   = "gas"     // gas broken down by opcode class, syscall, contract and wasm function
+)
+
+// Tracer receives the execution events of a transaction.
+// Opcodes are only captured by neovm, wasm contracts are traced at host function level.
+type Tracer interface {
+	neovm.OpTracer
+	storage.StorageTracer
+
+	// CaptureEnter is called when a contract frame starts, method is empty if it is decided by the contract input
+	CaptureEnter(vmType VmType, contract common.Address, method string)
+	// CaptureExit is called when the current contract frame ends, err is nil if succeed
+	CaptureExit(err error)
+	// CaptureSyscall is called before the system service is executed
+	CaptureSyscall(name string)
+	// CaptureOpGas is called after the gas of neovm opcode is charged
+	CaptureOpGas(opcode neovm.OpCode, gas uint64)
+	// CaptureGas is called after the gas of system service or wasm host function is charged
+	CaptureGas(name string, gas uint64)
+	// CaptureWasmGas is called with the gas of wasm instructions charged since last call
+	CaptureWasmGas(gas uint64)
+	// Result return the trace result
+	Result() interface{}
+}
+
+// NewTracer create the tracer by name
+func NewTracer(name string) (Tracer, error) {
+	switch name {
+	case OPCODE_TRACER:
+		return NewOpcodeTracer(), nil
+	case CALL_TRACER, "":
+		return NewCallTracer(), nil
+	case STORAGE_TRACER:
+		return NewStorageDiffTracer(), nil
+	case GAS_PROFILER:
+		return NewGasProfiler(), nil
+	default:
+		return nil, fmt.Errorf("unknown tracer: %s", name)
+	}
+}
+
+// NoopTracer ignores all the events, embed it to implement part of the events
+type NoopTracer struct{}
+
+func (self NoopTracer) CaptureOp(engine *neovm.Executor, ip int, opcode neovm.OpCode)      {}
+func (self NoopTracer) CaptureStorageRead(key, value []byte)                               {}
+func (self NoopTracer) CaptureStorageWrite(key, prev, value []byte)                        {}
+func (self NoopTracer) CaptureEnter(vmType VmType, contract common.Address, method string) {}
+func (self NoopTracer) CaptureExit(err error)                                              {}
+func (self NoopTracer) CaptureSyscall(name string)                                         {}
+func (self NoopTracer) CaptureOpGas(opcode neovm.OpCode, gas uint64)                       {}
+func (self NoopTracer) CaptureGas(name string, gas uint64)                                 {}
+func (self NoopTracer) CaptureWasmGas(gas uint64)                                          {}
+func (self NoopTracer) Result() interface{}                                                { return nil }
+
+// splitStorageKey split the storage key of CacheDB into contract address and key
+func splitStorageKey(key []byte) (common.Address, []byte) {
+	var addr common.Address
+	if len(key) < common.ADDR_LEN {
+		return addr, copyBytes(key)
+	}
+	copy(addr[:], key[:common.ADDR_LEN])
+	return addr, copyBytes(key[common.ADDR_LEN:])
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte{}, data...)
+}
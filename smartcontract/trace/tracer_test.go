@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trace
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/vm/neovm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallTracer(t *testing.T) {
+	tracer := NewOpcodeTracer()
+	var entry, callee common.Address
+	entry[0], callee[0] = 1, 2
+
+	tracer.CaptureEnter(NEOVM, entry, "")
+	engine := neovm.NewExecutor([]byte{byte(neovm.PUSH1), byte(neovm.PUSH2), byte(neovm.ADD)}, neovm.VmFeatureFlag{})
+	engine.Tracer = tracer
+	assert.Nil(t, engine.Execute())
+	tracer.CaptureSyscall("System.Storage.Get")
+	tracer.CaptureEnter(NATIVE, callee, "transfer")
+	tracer.CaptureStorageWrite(append(callee[:], 'k'), nil, []byte("v"))
+	tracer.CaptureExit(errors.New("failed"))
+	tracer.CaptureExit(nil)
+
+	res := tracer.Result().(*CallTrace)
+	root := res.Root
+	assert.Equal(t, entry.ToHexString(), root.Contract)
+	assert.Equal(t, 3, len(root.Ops))
+	assert.Equal(t, "ADD", root.Ops[2].Opcode)
+	assert.Equal(t, []string{"int(2)", "int(1)"}, root.Ops[2].Stack)
+	assert.Equal(t, []string{"System.Storage.Get"}, root.Syscalls)
+	assert.Equal(t, 1, len(root.Calls))
+	assert.Equal(t, "transfer", root.Calls[0].Method)
+	assert.Equal(t, "failed", root.Calls[0].Error)
+	assert.Equal(t, "write", root.Calls[0].Storage[0].Op)
+
+	callTracer := NewCallTracer()
+	callTracer.CaptureEnter(NEOVM, entry, "")
+	engine = neovm.NewExecutor([]byte{byte(neovm.PUSH1)}, neovm.VmFeatureFlag{})
+	engine.Tracer = callTracer
+	assert.Nil(t, engine.Execute())
+	root = callTracer.Result().(*CallTrace).Root
+	assert.Nil(t, root.Ops)
+}
+
+func TestStorageDiffTracer(t *testing.T) {
+	tracer := NewStorageDiffTracer()
+	var addr common.Address
+	addr[0] = 1
+	key1, key2 := append(addr[:], 1), append(addr[:], 2)
+
+	tracer.CaptureStorageRead(key1, []byte("a"))
+	tracer.CaptureStorageWrite(key1, []byte("a"), []byte("b"))
+	tracer.CaptureStorageWrite(key1, []byte("b"), []byte("c"))
+	tracer.CaptureStorageWrite(key2, nil, []byte("x"))
+	tracer.CaptureStorageWrite(key2, []byte("x"), nil)
+
+	diffs := tracer.Result().([]*StorageDiff)
+	assert.Equal(t, 1, len(diffs))
+	assert.Equal(t, addr.ToHexString(), diffs[0].Contract)
+	assert.Equal(t, "01", diffs[0].Key)
+	assert.Equal(t, common.ToHexString([]byte("a")), diffs[0].Before)
+	assert.Equal(t, common.ToHexString([]byte("c")), diffs[0].After)
+
+	_, err := NewTracer("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestGasProfiler(t *testing.T) {
+	profiler := NewGasProfiler()
+	var entry, callee common.Address
+	entry[0], callee[0] = 1, 2
+
+	profiler.CaptureEnter(NEOVM, entry, "")
+	profiler.CaptureOpGas(neovm.PUSH1, 1)
+	profiler.CaptureOpGas(neovm.ADD, 1)
+	profiler.CaptureOpGas(neovm.APPCALL, 10)
+	profiler.CaptureEnter(WASMVM, callee, "")
+	profiler.CaptureWasmGas(5)
+	profiler.CaptureGas("ontio_storage_write", 4000)
+	profiler.CaptureStorageWrite(append(callee[:], 'k'), nil, []byte("v"))
+	profiler.CaptureStorageWrite(append(callee[:], 'k'), []byte("v"), nil)
+	profiler.CaptureWasmGas(2)
+	profiler.CaptureExit(nil)
+	profiler.CaptureGas("System.Runtime.Notify", 1)
+	profiler.CaptureExit(nil)
+
+	profile := profiler.Result().(*GasProfile)
+	assert.Equal(t, uint64(4020), profile.Total)
+	assert.Equal(t, map[string]uint64{OPCODE_CLASS_CONSTANT: 1, OPCODE_CLASS_ARITHMETIC: 1}, profile.Opcodes)
+	assert.Equal(t, map[string]uint64{APPCALL_SYSCALL: 10, "ontio_storage_write": 4000, "System.Runtime.Notify": 1}, profile.Syscalls)
+	assert.Equal(t, uint64(2), profile.StorageBytesWritten)
+	assert.Equal(t, &ContractGas{VmType: NEOVM, Calls: 1, Gas: 13}, profile.Contracts[entry.ToHexString()])
+	assert.Equal(t, &ContractGas{VmType: WASMVM, Calls: 1, Gas: 4007, StorageBytesWritten: 2,
+		WasmGas: 7}, profile.Contracts[callee.ToHexString()])
+}
+
+func TestOpcodeClass(t *testing.T) {
+	assert.Equal(t, OPCODE_CLASS_CONSTANT, OpcodeClass(neovm.PUSHBYTES1))
+	assert.Equal(t, OPCODE_CLASS_FLOW, OpcodeClass(neovm.JMPIF))
+	assert.Equal(t, OPCODE_CLASS_FLOW, OpcodeClass(neovm.DCALL))
+	assert.Equal(t, OPCODE_CLASS_STACK, OpcodeClass(neovm.SWAP))
+	assert.Equal(t, OPCODE_CLASS_SPLICE, OpcodeClass(neovm.CAT))
+	assert.Equal(t, OPCODE_CLASS_BITWISE, OpcodeClass(neovm.EQUAL))
+	assert.Equal(t, OPCODE_CLASS_ARITHMETIC, OpcodeClass(neovm.WITHIN))
+	assert.Equal(t, OPCODE_CLASS_CRYPTO, OpcodeClass(neovm.SHA256))
+	assert.Equal(t, OPCODE_CLASS_ARRAY, OpcodeClass(neovm.NEWMAP))
+	assert.Equal(t, OPCODE_CLASS_EXCEPTION, OpcodeClass(neovm.THROWIFNOT))
+}
@@ -20,6 +20,7 @@
 package common
 
 import (
+	"container/heap"
 	"sort"
 	"sync"
 
@@ -48,7 +49,13 @@ type TXEntry struct {
 // in the ledger.
 type TXPool struct {
 	sync.RWMutex
-	txList map[common.Uint256]*TXEntry // Transactions which have been verified
+	txList     map[common.Uint256]*TXEntry            // Transactions which have been verified
+	payerTxs   map[common.Address]map[uint32]*TXEntry // Verified transactions of each payer by nonce
+	priceHeap  txPriceHeap                            // The cheapest transaction on the top
+	priceItems map[common.Uint256]*txPriceItem        // Position of the transactions in the price heap
+	arrivals   uint64                                 // Count of the transactions ever added, as their arrival order
+	capacity   int                                    // Max transactions in the pool
+	payerLimit int                                    // Max transactions of a payer in the pool
 }
 
 // Init creates a new transaction pool to gather.
@@ -56,23 +63,130 @@ func (tp *TXPool) Init() {
 	tp.Lock()
 	defer tp.Unlock()
 	tp.txList = make(map[common.Uint256]*TXEntry)
+	tp.payerTxs = make(map[common.Address]map[uint32]*TXEntry)
+	tp.priceHeap = make(txPriceHeap, 0)
+	tp.priceItems = make(map[common.Uint256]*txPriceItem)
+	tp.capacity = MAX_CAPACITY
+	tp.payerLimit = MAX_TX_PER_PAYER
+	if cfg := config.DefConfig.TxPool; cfg != nil {
+		if cfg.MaxTxInPool > 0 {
+			tp.capacity = int(cfg.MaxTxInPool)
+		}
+		if cfg.MaxTxPerPayer > 0 {
+			tp.payerLimit = int(cfg.MaxTxPerPayer)
+		}
+	}
 }
 
-// AddTxList adds a valid transaction to the transaction pool. If the
-// transaction is already in the pool, just return false. Parameter
+// AddTxList adds a valid transaction to the transaction pool. Parameter
 // txEntry includes transaction, fee, and verified information(height,
-// validator, error code).
-func (tp *TXPool) AddTxList(txEntry *TXEntry) bool {
+// validator, error code). A transaction with the same payer and nonce
+// as a pooled one replaces it only if it pays a higher gas price; when
+// the pool is full, the cheapest transaction is evicted to make room
+// for a more expensive one.
+func (tp *TXPool) AddTxList(txEntry *TXEntry) errors.ErrCode {
 	tp.Lock()
 	defer tp.Unlock()
 	txHash := txEntry.Tx.Hash()
 	if _, ok := tp.txList[txHash]; ok {
 		log.Infof("AddTxList: transaction %x is already in the pool", txHash)
-		return false
+		return errors.ErrDuplicateInput
+	}
+
+	replaced, evicted, errCode := tp.checkAdmission(txEntry.Tx)
+	if errCode != errors.ErrNoError {
+		log.Debugf("AddTxList: transaction %x is rejected: %s", txHash, errCode.Error())
+		return errCode
+	}
+	if replaced != nil {
+		log.Infof("AddTxList: transaction %x is replaced by %x with higher gas price %d",
+			replaced.Tx.Hash(), txHash, txEntry.Tx.GasPrice)
+		tp.removeEntry(replaced)
+	}
+	if evicted != nil {
+		log.Debugf("AddTxList: transaction %x with gas price %d is evicted",
+			evicted.Tx.Hash(), evicted.Tx.GasPrice)
+		tp.removeEntry(evicted)
 	}
 
 	tp.txList[txHash] = txEntry
-	return true
+	nonces := tp.payerTxs[txEntry.Tx.Payer]
+	if nonces == nil {
+		nonces = make(map[uint32]*TXEntry)
+		tp.payerTxs[txEntry.Tx.Payer] = nonces
+	}
+	nonces[txEntry.Tx.Nonce] = txEntry
+	tp.arrivals++
+	item := &txPriceItem{entry: txEntry, arrival: tp.arrivals}
+	tp.priceItems[txHash] = item
+	heap.Push(&tp.priceHeap, item)
+	return errors.ErrNoError
+}
+
+// CheckTxAdmission checks whether a transaction could be added to the
+// pool without changing it, so that a transaction doomed to be rejected
+// can be dropped before verification.
+func (tp *TXPool) CheckTxAdmission(tx *types.Transaction) errors.ErrCode {
+	tp.RLock()
+	defer tp.RUnlock()
+	_, _, errCode := tp.checkAdmission(tx)
+	return errCode
+}
+
+// checkAdmission returns the pooled transaction to be replaced and the one
+// to be evicted when adding tx, must be called with the lock held.
+func (tp *TXPool) checkAdmission(tx *types.Transaction) (replaced, evicted *TXEntry,
+	errCode errors.ErrCode) {
+	nonces := tp.payerTxs[tx.Payer]
+	if old, ok := nonces[tx.Nonce]; ok {
+		if tx.GasPrice <= old.Tx.GasPrice {
+			return nil, nil, errors.ErrGasPrice
+		}
+		// the replaced one frees its own slot
+		return old, nil, errors.ErrNoError
+	}
+
+	if len(nonces) >= tp.payerLimit {
+		var cheapest *txPriceItem
+		for _, entry := range nonces {
+			item := tp.priceItems[entry.Tx.Hash()]
+			if cheapest == nil || lessGasPrice(item, cheapest) {
+				cheapest = item
+			}
+		}
+		if tx.GasPrice <= cheapest.entry.Tx.GasPrice {
+			return nil, nil, errors.ErrTxPoolFull
+		}
+		return nil, cheapest.entry, errors.ErrNoError
+	}
+
+	if len(tp.txList) >= tp.capacity {
+		if len(tp.priceHeap) == 0 || tx.GasPrice <= tp.priceHeap[0].entry.Tx.GasPrice {
+			return nil, nil, errors.ErrTxPoolFull
+		}
+		return nil, tp.priceHeap[0].entry, errors.ErrNoError
+	}
+	return nil, nil, errors.ErrNoError
+}
+
+// removeEntry drops a transaction from all the indexes of the pool, must
+// be called with the lock held.
+func (tp *TXPool) removeEntry(txEntry *TXEntry) {
+	tx := txEntry.Tx
+	txHash := tx.Hash()
+	delete(tp.txList, txHash)
+	if nonces, ok := tp.payerTxs[tx.Payer]; ok {
+		if nonces[tx.Nonce] == txEntry {
+			delete(nonces, tx.Nonce)
+		}
+		if len(nonces) == 0 {
+			delete(tp.payerTxs, tx.Payer)
+		}
+	}
+	if item, ok := tp.priceItems[txHash]; ok {
+		heap.Remove(&tp.priceHeap, item.index)
+		delete(tp.priceItems, txHash)
+	}
 }
 
 // CleanTransactionList cleans the transaction list included in the ledger.
@@ -82,8 +196,8 @@ func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 	tp.Lock()
 	defer tp.Unlock()
 	for _, tx := range txs {
-		if _, ok := tp.txList[tx.Hash()]; ok {
-			delete(tp.txList, tx.Hash())
+		if txEntry, ok := tp.txList[tx.Hash()]; ok {
+			tp.removeEntry(txEntry)
 			cleaned++
 		}
 	}
@@ -97,11 +211,11 @@ func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 func (tp *TXPool) DelTxList(tx *types.Transaction) bool {
 	tp.Lock()
 	defer tp.Unlock()
-	txHash := tx.Hash()
-	if _, ok := tp.txList[txHash]; !ok {
+	txEntry, ok := tp.txList[tx.Hash()]
+	if !ok {
 		return false
 	}
-	delete(tp.txList, txHash)
+	tp.removeEntry(txEntry)
 	return true
 }
 
@@ -121,17 +235,13 @@ func (tp *TXPool) compareTxHeight(txEntry *TXEntry, height uint32) bool {
 // GetTxPool gets the transaction lists from the pool for the consensus,
 // if the byCount is marked, return the configured number at most; if the
 // the byCount is not marked, return all of the current transaction pool.
+// Transactions are ordered by gas price, while the ones of the same payer
+// keep their arrival order, since the nonce of a transaction is random.
 func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 	[]*types.Transaction) {
 	tp.RLock()
 	defer tp.RUnlock()
 
-	orderByFee := make([]*TXEntry, 0, len(tp.txList))
-	for _, txEntry := range tp.txList {
-		orderByFee = append(orderByFee, txEntry)
-	}
-	sort.Sort(OrderByNetWorkFee(orderByFee))
-
 	count := int(config.DefConfig.Consensus.MaxTxInBlock)
 	if count <= 0 {
 		byCount = false
@@ -140,18 +250,36 @@ func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 		count = len(tp.txList)
 	}
 
-	var num int
 	txList := make([]*TXEntry, 0, count)
 	oldTxList := make([]*types.Transaction, 0)
-	for _, txEntry := range orderByFee {
-		if !tp.compareTxHeight(txEntry, height) {
-			oldTxList = append(oldTxList, txEntry.Tx)
+	queues := make(payerQueues, 0, len(tp.payerTxs))
+	for _, nonces := range tp.payerTxs {
+		queue := make([]*txPriceItem, 0, len(nonces))
+		for _, txEntry := range nonces {
+			if !tp.compareTxHeight(txEntry, height) {
+				oldTxList = append(oldTxList, txEntry.Tx)
+				continue
+			}
+			queue = append(queue, tp.priceItems[txEntry.Tx.Hash()])
+		}
+		if len(queue) == 0 {
 			continue
 		}
-		txList = append(txList, txEntry)
-		num++
-		if num >= count {
-			break
+		sort.Slice(queue, func(i, j int) bool {
+			return queue[i].arrival < queue[j].arrival
+		})
+		queues = append(queues, queue)
+	}
+	heap.Init(&queues)
+
+	for len(queues) > 0 && len(txList) < count {
+		queue := queues[0]
+		txList = append(txList, queue[0].entry)
+		if len(queue) > 1 {
+			queues[0] = queue[1:]
+			heap.Fix(&queues, 0)
+		} else {
+			heap.Pop(&queues)
 		}
 	}
 
@@ -224,7 +352,7 @@ func (tp *TXPool) GetUnverifiedTxs(txs []*types.Transaction,
 		}
 
 		if !tp.compareTxHeight(txEntry, height) {
-			delete(tp.txList, tx.Hash())
+			tp.removeEntry(txEntry)
 			res.OldTxs = append(res.OldTxs, txEntry.Tx)
 			continue
 		}
@@ -252,7 +380,7 @@ func (tp *TXPool) RemoveTxsBelowGasPrice(gasPrice uint64) {
 	defer tp.Unlock()
 	for _, txEntry := range tp.txList {
 		if txEntry.Tx.GasPrice < gasPrice {
-			delete(tp.txList, txEntry.Tx.Hash())
+			tp.removeEntry(txEntry)
 		}
 	}
 }
@@ -265,8 +393,83 @@ func (tp *TXPool) Remain() []*types.Transaction {
 	txList := make([]*types.Transaction, 0, len(tp.txList))
 	for _, txEntry := range tp.txList {
 		txList = append(txList, txEntry.Tx)
-		delete(tp.txList, txEntry.Tx.Hash())
 	}
+	tp.txList = make(map[common.Uint256]*TXEntry)
+	tp.payerTxs = make(map[common.Address]map[uint32]*TXEntry)
+	tp.priceHeap = make(txPriceHeap, 0)
+	tp.priceItems = make(map[common.Uint256]*txPriceItem)
 
 	return txList
 }
+
+// txPriceItem is a pooled transaction with its arrival order and position
+// in the price heap.
+type txPriceItem struct {
+	entry   *TXEntry
+	arrival uint64
+	index   int
+}
+
+// txPriceHeap is a min-heap of the pooled transactions by gas price, the
+// top one is the first to be evicted when the pool is full.
+type txPriceHeap []*txPriceItem
+
+func (h txPriceHeap) Len() int { return len(h) }
+
+func (h txPriceHeap) Less(i, j int) bool { return lessGasPrice(h[i], h[j]) }
+
+func (h txPriceHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *txPriceHeap) Push(x interface{}) {
+	item := x.(*txPriceItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *txPriceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// lessGasPrice tells which transaction is cheaper and evicted first.
+func lessGasPrice(a, b *txPriceItem) bool {
+	if a.entry.Tx.GasPrice != b.entry.Tx.GasPrice {
+		return a.entry.Tx.GasPrice < b.entry.Tx.GasPrice
+	}
+	// the later arrival goes first
+	return a.arrival > b.arrival
+}
+
+// payerQueues is a max-heap of the arrival ordered transaction queues of
+// the payers by the gas price of the queue head.
+type payerQueues [][]*txPriceItem
+
+func (q payerQueues) Len() int { return len(q) }
+
+func (q payerQueues) Less(i, j int) bool {
+	a, b := q[i][0], q[j][0]
+	if a.entry.Tx.GasPrice != b.entry.Tx.GasPrice {
+		return a.entry.Tx.GasPrice > b.entry.Tx.GasPrice
+	}
+	return a.arrival < b.arrival
+}
+
+func (q payerQueues) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *payerQueues) Push(x interface{}) { *q = append(*q, x.([]*txPriceItem)) }
+
+func (q *payerQueues) Pop() interface{} {
+	old := *q
+	n := len(old)
+	queue := old[n-1]
+	*q = old[:n-1]
+	return queue
+}
@@ -22,9 +22,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,13 +57,13 @@ func TestTxPool(t *testing.T) {
 	}
 
 	ret := txPool.AddTxList(txEntry)
-	if ret == false {
+	if ret != errors.ErrNoError {
 		t.Error("Failed to add tx to the pool")
 		return
 	}
 
 	ret = txPool.AddTxList(txEntry)
-	if ret == true {
+	if ret != errors.ErrDuplicateInput {
 		t.Error("Failed to add tx to the pool")
 		return
 	}
@@ -99,3 +102,94 @@ func TestTxPool(t *testing.T) {
 		return
 	}
 }
+
+func newTestTxEntry(t *testing.T, payer byte, nonce uint32, gasPrice uint64) *TXEntry {
+	mutable := &types.MutableTransaction{
+		TxType:   types.InvokeNeo,
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Payer:    common.Address{payer},
+		Payload:  &payload.InvokeCode{Code: []byte{}},
+	}
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	return &TXEntry{Tx: tx, Attrs: []*TXAttr{}}
+}
+
+func TestTxPoolOrder(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+
+	entries := []*TXEntry{
+		newTestTxEntry(t, 1, 1, 500),
+		newTestTxEntry(t, 1, 2, 3000),
+		newTestTxEntry(t, 2, 9, 1000),
+		newTestTxEntry(t, 2, 2, 800),
+		newTestTxEntry(t, 3, 7, 2000),
+	}
+	for _, entry := range entries {
+		assert.Equal(t, errors.ErrNoError, txPool.AddTxList(entry))
+	}
+
+	txList, oldTxList := txPool.GetTxPool(false, 0)
+	assert.Equal(t, 0, len(oldTxList))
+	// the transactions of a payer keep the arrival order rather than the random nonce order
+	expected := []*TXEntry{entries[4], entries[2], entries[3], entries[0], entries[1]}
+	assert.Equal(t, len(expected), len(txList))
+	for i, entry := range expected {
+		assert.Equal(t, entry.Tx.Hash(), txList[i].Tx.Hash())
+	}
+}
+
+func TestTxPoolReplace(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+
+	origin := newTestTxEntry(t, 1, 1, 500)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(origin))
+
+	underpriced := newTestTxEntry(t, 1, 1, 400)
+	assert.Equal(t, errors.ErrGasPrice, txPool.AddTxList(underpriced))
+
+	replacement := newTestTxEntry(t, 1, 1, 600)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(replacement))
+	assert.Nil(t, txPool.GetTransaction(origin.Tx.Hash()))
+	assert.NotNil(t, txPool.GetTransaction(replacement.Tx.Hash()))
+	assert.Equal(t, 1, txPool.GetTransactionCount())
+}
+
+func TestTxPoolEviction(t *testing.T) {
+	cfg := *config.DefConfig.TxPool
+	defer func() { *config.DefConfig.TxPool = cfg }()
+	config.DefConfig.TxPool.MaxTxInPool = 3
+	config.DefConfig.TxPool.MaxTxPerPayer = 2
+	txPool := &TXPool{}
+	txPool.Init()
+
+	cheap := newTestTxEntry(t, 1, 1, 500)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(cheap))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newTestTxEntry(t, 1, 2, 700)))
+	assert.Equal(t, errors.ErrTxPoolFull, txPool.AddTxList(newTestTxEntry(t, 1, 3, 500)))
+
+	// the payer limit evicts the cheapest transaction of the payer
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newTestTxEntry(t, 1, 3, 600)))
+	assert.Nil(t, txPool.GetTransaction(cheap.Tx.Hash()))
+
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newTestTxEntry(t, 2, 1, 800)))
+	assert.Equal(t, 3, txPool.GetTransactionCount())
+
+	// the pool capacity evicts the cheapest transaction of all
+	assert.Equal(t, errors.ErrTxPoolFull, txPool.CheckTxAdmission(newTestTxEntry(t, 3, 1, 600).Tx))
+	assert.Equal(t, errors.ErrTxPoolFull, txPool.AddTxList(newTestTxEntry(t, 3, 1, 600)))
+	expensive := newTestTxEntry(t, 3, 1, 900)
+	assert.Equal(t, errors.ErrNoError, txPool.CheckTxAdmission(expensive.Tx))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(expensive))
+	assert.Equal(t, 3, txPool.GetTransactionCount())
+
+	txList, _ := txPool.GetTxPool(false, 0)
+	prices := make([]uint64, 0, len(txList))
+	for _, entry := range txList {
+		prices = append(prices, entry.Tx.GasPrice)
+	}
+	assert.Equal(t, []uint64{900, 800, 700}, prices)
+}
@@ -26,6 +26,7 @@ import (
 
 const (
 	MAX_CAPACITY     = 100140                           // The tx pool's capacity that holds the verified txs
+	MAX_TX_PER_PAYER = 10000                            // The max verified txs of a payer in the tx pool
 	MAX_PENDING_TXN  = 4096 * 10                        // The max length of pending txs
 	MAX_WORKER_NUM   = 2                                // The max concurrent workers
 	MAX_RCV_TXN_LEN  = MAX_WORKER_NUM * MAX_PENDING_TXN // The max length of the queue that server can hold
@@ -87,6 +88,25 @@ const (
 	MaxStats
 )
 
+func (stats TxnStatsType) String() string {
+	switch stats {
+	case RcvStats:
+		return "received"
+	case SuccessStats:
+		return "success"
+	case FailureStats:
+		return "failure"
+	case DuplicateStats:
+		return "duplicate"
+	case SigErrStats:
+		return "sig_error"
+	case StateErrStats:
+		return "state_error"
+	default:
+		return "unknown"
+	}
+}
+
 // CheckBlkResult contains a verifed tx list,
 // an unverified tx list and an old tx list
 // to be re-verifed
@@ -253,11 +273,3 @@ func (this LBSlice) Swap(i, j int) {
 func (this LBSlice) Less(i, j int) bool {
 	return this[i].Size < this[j].Size
 }
-
-type OrderByNetWorkFee []*TXEntry
-
-func (n OrderByNetWorkFee) Len() int { return len(n) }
-
-func (n OrderByNetWorkFee) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
-
-func (n OrderByNetWorkFee) Less(i, j int) bool { return n[j].Tx.GasPrice < n[i].Tx.GasPrice }
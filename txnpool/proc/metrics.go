@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proc
+
+import (
+	"time"
+
+	tc "github.com/ontio/ontology/txnpool/common"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	txPoolSizeMetric = prom.NewGaugeVec(prom.GaugeOpts{
+		Name: "ontology_txpool_size",
+		Help: "ontology tx pool size, verified txs in the pool and pending txs on verifying",
+	}, []string{"pool"})
+
+	txStatsMetric = prom.NewCounterVec(prom.CounterOpts{
+		Name: "ontology_txpool_txs_total",
+		Help: "ontology tx pool statistics per stats type",
+	}, []string{"type"})
+
+	txVerifyLatencyMetric = prom.NewHistogram(prom.HistogramOpts{
+		Name:    "ontology_txpool_verify_seconds",
+		Help:    "ontology tx pool latency of verifying a tx by the validators",
+		Buckets: prom.ExponentialBuckets(0.0005, 2, 16),
+	})
+)
+
+func init() {
+	prom.MustRegister(txPoolSizeMetric, txStatsMetric, txVerifyLatencyMetric)
+}
+
+func updateVerifiedSizeMetric(size int) {
+	txPoolSizeMetric.WithLabelValues("verified").Set(float64(size))
+}
+
+func updatePendingSizeMetric(size int) {
+	txPoolSizeMetric.WithLabelValues("pending").Set(float64(size))
+}
+
+func increaseStatsMetric(v tc.TxnStatsType) {
+	txStatsMetric.WithLabelValues(v.String()).Inc()
+}
+
+func observeVerifyLatency(start time.Time) {
+	txVerifyLatencyMetric.Observe(time.Since(start).Seconds())
+}
@@ -140,14 +140,17 @@ func (ta *TxActor) handleTransaction(sender tc.SenderType, self *actor.PID,
 			replyTxResult(txResultCh, txn.Hash(), errors.ErrDuplicateInput,
 				fmt.Sprintf("transaction %x is already in the tx pool", txn.Hash()))
 		}
-	} else if ta.server.getTransactionCount() >= tc.MAX_CAPACITY {
-		log.Debugf("handleTransaction: transaction pool is full for tx %x",
-			txn.Hash())
+	} else if errCode := ta.server.checkTxAdmission(txn); errCode != errors.ErrNoError {
+		log.Debugf("handleTransaction: transaction %x is rejected by the txn pool: %s",
+			txn.Hash(), errCode.Error())
 
 		ta.server.increaseStats(tc.FailureStats)
 		if sender == tc.HttpSender && txResultCh != nil {
-			replyTxResult(txResultCh, txn.Hash(), errors.ErrTxPoolFull,
-				"transaction pool is full")
+			desc := "transaction pool is full"
+			if errCode == errors.ErrGasPrice {
+				desc = "replacement transaction underpriced"
+			}
+			replyTxResult(txResultCh, txn.Hash(), errCode, desc)
 		}
 	} else {
 		if _, overflow := common.SafeMul(txn.GasLimit, txn.GasPrice); overflow {
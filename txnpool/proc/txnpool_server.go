@@ -35,6 +35,8 @@ import (
 	"github.com/ontio/ontology/core/ledger"
 	tx "github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/errors"
+	"github.com/ontio/ontology/events"
+	"github.com/ontio/ontology/events/message"
 	httpcom "github.com/ontio/ontology/http/base/common"
 	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
@@ -261,6 +263,21 @@ func (s *TXPoolServer) getGasPrice() uint64 {
 // is in the block from consensus.
 func (s *TXPoolServer) removePendingTx(hash common.Uint256,
 	err errors.ErrCode) {
+	s.finishPendingTx(hash, err, err)
+}
+
+// removeVerifiedTx removes a valid transaction from the pending list, err
+// is the result of adding it to the tx pool and only replied to the http
+// sender, the pending block still takes the transaction as valid.
+func (s *TXPoolServer) removeVerifiedTx(hash common.Uint256,
+	err errors.ErrCode) {
+	s.finishPendingTx(hash, err, errors.ErrNoError)
+}
+
+// finishPendingTx removes a transaction from the pending list, replies
+// err to the sender and reports verifyErr to the pending block.
+func (s *TXPoolServer) finishPendingTx(hash common.Uint256,
+	err, verifyErr errors.ErrCode) {
 
 	s.mu.Lock()
 
@@ -282,7 +299,16 @@ func (s *TXPoolServer) removePendingTx(hash common.Uint256,
 		replyTxResult(pt.ch, hash, err, err.Error())
 	}
 
+	if pt.sender != tc.NilSender {
+		if err == errors.ErrNoError {
+			publishTxStatus(pt.tx, message.TX_STATUS_VERIFIED, "")
+		} else {
+			publishTxStatus(pt.tx, message.TX_STATUS_FAILED, err.Error())
+		}
+	}
+
 	delete(s.allPendingTxs, hash)
+	updatePendingSizeMetric(len(s.allPendingTxs))
 
 	if len(s.allPendingTxs) < tc.MAX_LIMITATION {
 		select {
@@ -296,7 +322,7 @@ func (s *TXPoolServer) removePendingTx(hash common.Uint256,
 
 	// Check if the tx is in the pending block and
 	// the pending block is verified
-	s.checkPendingBlockOk(hash, err)
+	s.checkPendingBlockOk(hash, verifyErr)
 }
 
 // setPendingTx adds a transaction to the pending list, if the
@@ -319,6 +345,7 @@ func (s *TXPoolServer) setPendingTx(tx *tx.Transaction,
 	}
 
 	s.allPendingTxs[tx.Hash()] = pt
+	updatePendingSizeMetric(len(s.allPendingTxs))
 	return true
 }
 
@@ -349,10 +376,22 @@ func (s *TXPoolServer) assignTxToWorker(tx *tx.Transaction,
 		lb[i] = entry
 	}
 	sort.Sort(lb)
+	if sender != tc.NilSender {
+		publishTxStatus(tx, message.TX_STATUS_POOLED, "")
+	}
 	s.workers[lb[0].WorkerID].rcvTXCh <- tx
 	return true
 }
 
+// publishTxStatus notifies the subscribers the status change of a tx
+func publishTxStatus(tx *tx.Transaction, status, desc string) {
+	if events.DefActorPublisher == nil {
+		return
+	}
+	events.DefActorPublisher.Publish(message.TOPIC_TX_STATUS,
+		&message.TxStatusMsg{Tx: tx, Status: status, Desc: desc})
+}
+
 // assignRspToWorker assigns a check response from the validator to
 // the correct worker.
 func (s *TXPoolServer) assignRspToWorker(rsp *types.CheckResponse) bool {
@@ -583,20 +622,32 @@ func (s *TXPoolServer) cleanTransactionList(txs []*tx.Transaction, height uint32
 			s.reVerifyStateful(t, tc.NilSender)
 		}
 	}
+	updateVerifiedSizeMetric(s.txPool.GetTransactionCount())
 }
 
 // delTransaction deletes a transaction in the tx pool.
 func (s *TXPoolServer) delTransaction(t *tx.Transaction) {
 	s.txPool.DelTxList(t)
+	updateVerifiedSizeMetric(s.txPool.GetTransactionCount())
 }
 
 // addTxList adds a valid transaction to the tx pool.
-func (s *TXPoolServer) addTxList(txEntry *tc.TXEntry) bool {
-	ret := s.txPool.AddTxList(txEntry)
-	if !ret {
+func (s *TXPoolServer) addTxList(txEntry *tc.TXEntry) errors.ErrCode {
+	errCode := s.txPool.AddTxList(txEntry)
+	switch errCode {
+	case errors.ErrNoError:
+		updateVerifiedSizeMetric(s.txPool.GetTransactionCount())
+	case errors.ErrDuplicateInput:
 		s.increaseStats(tc.DuplicateStats)
+	default:
+		s.increaseStats(tc.FailureStats)
 	}
-	return ret
+	return errCode
+}
+
+// checkTxAdmission checks whether the tx pool has room for a transaction.
+func (s *TXPoolServer) checkTxAdmission(t *tx.Transaction) errors.ErrCode {
+	return s.txPool.CheckTxAdmission(t)
 }
 
 // increaseStats increases the count with the stats type
@@ -604,6 +655,7 @@ func (s *TXPoolServer) increaseStats(v tc.TxnStatsType) {
 	s.stats.Lock()
 	defer s.stats.Unlock()
 	s.stats.count[v-1]++
+	increaseStatsMetric(v)
 }
 
 // getStats returns the transaction statistics
@@ -104,6 +104,7 @@ func (worker *txPoolWorker) handleRsp(rsp *types.CheckResponse) {
 		//Verify fail
 		log.Debugf("handleRsp: validator %d transaction %x invalid: %s",
 			rsp.Type, rsp.Hash, rsp.ErrCode.Error())
+		observeVerifyLatency(pt.valTime)
 		delete(worker.pendingTxList, rsp.Hash)
 		atomic.StoreInt64(&worker.pendingTxLen, int64(len(worker.pendingTxList)))
 		worker.server.removePendingTx(rsp.Hash, rsp.ErrCode)
@@ -128,6 +129,7 @@ func (worker *txPoolWorker) handleRsp(rsp *types.CheckResponse) {
 	}
 
 	if pt.flag&0xf == tc.VERIFY_MASK {
+		observeVerifyLatency(pt.valTime)
 		worker.putTxPool(pt)
 		delete(worker.pendingTxList, rsp.Hash)
 		atomic.StoreInt64(&worker.pendingTxLen, int64(len(worker.pendingTxList)))
@@ -172,9 +174,9 @@ func (worker *txPoolWorker) putTxPool(pt *pendingTx) bool {
 		Tx:    pt.tx,
 		Attrs: pt.ret,
 	}
-	worker.server.addTxList(txEntry)
-	worker.server.removePendingTx(pt.tx.Hash(), errors.ErrNoError)
-	return true
+	errCode := worker.server.addTxList(txEntry)
+	worker.server.removeVerifiedTx(pt.tx.Hash(), errCode)
+	return errCode == errors.ErrNoError
 }
 
 // verifyTx prepares a check request and sends it to the validators.
@@ -22,10 +22,14 @@ import (
 	"reflect"
 
 	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/manifest"
+	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/errors"
+	"github.com/ontio/ontology/smartcontract/service/wasmvm"
 	"github.com/ontio/ontology/validator/db"
 	vatypes "github.com/ontio/ontology/validator/types"
 )
@@ -78,6 +82,11 @@ func (self *validator) Receive(context actor.Context) {
 			errCode = errors.ErrUnknown
 		} else if exist {
 			errCode = errors.ErrDuplicatedTx
+		} else if deploy, ok := msg.Tx.Payload.(*payload.DeployCode); ok {
+			if err := checkDeployHeight(deploy, height+1); err != nil {
+				log.Info("stateful-validator: ", err)
+				errCode = errors.ErrTransactionPayload
+			}
 		}
 
 		response := &vatypes.CheckResponse{
@@ -106,6 +115,18 @@ func (self *validator) Receive(context actor.Context) {
 
 }
 
+//checkDeployHeight check the deploy code only uses the features enabled at the block height
+func checkDeployHeight(deploy *payload.DeployCode, height uint32) error {
+	if err := manifest.Verify(deploy.GetManifest(), height, config.GetContractManifestHeight()); err != nil {
+		return err
+	}
+	if deploy.VmType() == payload.WASMVM_TYPE {
+		_, err := wasmvm.ReadWasmModule(deploy.GetRawCode(), config.NoneVerifyMethod, height)
+		return err
+	}
+	return nil
+}
+
 func (self *validator) VerifyType() vatypes.VerifyType {
 	return vatypes.Stateful
 }
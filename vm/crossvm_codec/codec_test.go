@@ -50,3 +50,42 @@ func TestDeserializeNotify(t *testing.T) {
 
 	assert.Equal(t, DeserializeNotify(EncodeNotify(t, value)), interface{}(expected))
 }
+
+func TestTypedEvent(t *testing.T) {
+	addr := common.AddressFromVmCode([]byte("123"))
+	fields := []*EventField{
+		{Name: "from", Value: addr},
+		{Name: "amount", Value: big.NewInt(100)},
+		{Name: "memo", Value: "hello"},
+		{Name: "data", Value: []byte("1234")},
+		{Name: "ok", Value: true},
+		{Name: "hash", Value: common.UINT256_EMPTY},
+		{Name: "list", Value: []interface{}{"a", 1}},
+	}
+	data, err := EncodeEvent("transfer", fields)
+	assert.Nil(t, err)
+	assert.True(t, IsTypedEvent(data))
+
+	evt, err := DecodeEvent(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "transfer", evt.Name)
+	assert.Equal(t, []*EventField{
+		{Name: "from", Type: EVENT_TYPE_ADDRESS, Value: addr.ToBase58()},
+		{Name: "amount", Type: EVENT_TYPE_INTEGER, Value: "100"},
+		{Name: "memo", Type: EVENT_TYPE_STRING, Value: "hello"},
+		{Name: "data", Type: EVENT_TYPE_BYTEARRAY, Value: hex.EncodeToString([]byte("1234"))},
+		{Name: "ok", Type: EVENT_TYPE_BOOLEAN, Value: true},
+		{Name: "hash", Type: EVENT_TYPE_HASH256, Value: common.UINT256_EMPTY.ToHexString()},
+		{Name: "list", Type: EVENT_TYPE_ARRAY, Value: []interface{}{"a", "1"}},
+	}, evt.Fields)
+
+	_, err = DecodeEvent(data[:len(data)-1])
+	assert.NotNil(t, err)
+	_, err = DecodeEvent(append(data, 0))
+	assert.NotNil(t, err)
+
+	legacy := []byte("hello")
+	assert.False(t, IsTypedEvent(legacy))
+	assert.Equal(t, legacy, DeserializeNotify(legacy))
+	assert.Equal(t, data, DeserializeNotify(data))
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package crossvm_codec
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ontio/ontology/common"
+)
+
+//EVENT_PREFIX is the prefix of the typed event notified by wasm contract
+const EVENT_PREFIX = "evt\x01"
+
+//type names of the event fields, the same as the parameter types of contract manifest
+const (
+	EVENT_TYPE_BYTEARRAY = "bytearray"
+	EVENT_TYPE_STRING    = "string"
+	EVENT_TYPE_ADDRESS   = "address"
+	EVENT_TYPE_BOOLEAN   = "boolean"
+	EVENT_TYPE_INTEGER   = "integer"
+	EVENT_TYPE_HASH256   = "hash256"
+	EVENT_TYPE_ARRAY     = "array"
+)
+
+//EventField is a named field of typed event
+type EventField struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+//Event is the typed event notified by wasm contract
+type Event struct {
+	Name   string
+	Fields []*EventField
+}
+
+//IsTypedEvent check whether the notify is encoded as typed event
+func IsTypedEvent(input []byte) bool {
+	return bytes.HasPrefix(input, []byte(EVENT_PREFIX))
+}
+
+// EncodeEvent encode the typed event with the following format
+// evt\1(4byte) + name(string) + field count(4 bytes) + [field name(string) + field value]...
+// field values are encoded by EncodeValue
+func EncodeEvent(name string, fields []*EventField) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBytes([]byte(EVENT_PREFIX))
+	EncodeString(sink, name)
+	sink.WriteUint32(uint32(len(fields)))
+	for _, field := range fields {
+		EncodeString(sink, field.Name)
+		val, err := EncodeValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(val) == 0 {
+			return nil, fmt.Errorf("encode event: unsupported type of field %s", field.Name)
+		}
+		sink.WriteBytes(val)
+	}
+	return sink.Bytes(), nil
+}
+
+//DecodeEvent decode the typed event, field values are stringified the same as DeserializeNotify
+func DecodeEvent(input []byte) (*Event, error) {
+	if !IsTypedEvent(input) {
+		return nil, ERROR_PARAM_FORMAT
+	}
+	source := common.NewZeroCopySource(input[len(EVENT_PREFIX):])
+	name, err := decodeString(source)
+	if err != nil {
+		return nil, err
+	}
+	count, eof := source.NextUint32()
+	if eof || uint64(count) > source.Len() {
+		return nil, ERROR_PARAM_FORMAT
+	}
+	evt := &Event{Name: name, Fields: make([]*EventField, 0, count)}
+	for i := uint32(0); i < count; i++ {
+		fieldName, err := decodeString(source)
+		if err != nil {
+			return nil, err
+		}
+		val, err := DecodeValue(source)
+		if err != nil {
+			return nil, err
+		}
+		evt.Fields = append(evt.Fields, &EventField{Name: fieldName, Type: typeName(val), Value: stringify(val)})
+	}
+	if source.Len() != 0 {
+		return nil, ERROR_PARAM_FORMAT
+	}
+	return evt, nil
+}
+
+func decodeString(source *common.ZeroCopySource) (string, error) {
+	val, err := DecodeValue(source)
+	if err != nil {
+		return "", err
+	}
+	str, ok := val.(string)
+	if !ok {
+		return "", ERROR_PARAM_FORMAT
+	}
+	return str, nil
+}
+
+func typeName(val interface{}) string {
+	switch val.(type) {
+	case []byte:
+		return EVENT_TYPE_BYTEARRAY
+	case string:
+		return EVENT_TYPE_STRING
+	case common.Address:
+		return EVENT_TYPE_ADDRESS
+	case bool:
+		return EVENT_TYPE_BOOLEAN
+	case *big.Int:
+		return EVENT_TYPE_INTEGER
+	case common.Uint256:
+		return EVENT_TYPE_HASH256
+	default:
+		return EVENT_TYPE_ARRAY
+	}
+}
@@ -48,6 +48,12 @@ func NewExecutor(code []byte, feature VmFeatureFlag) *Executor {
 	return &engine
 }
 
+// OpTracer observes the opcodes executed by Executor
+type OpTracer interface {
+	// CaptureOp is called before the opcode read at ip of current context is executed
+	CaptureOp(engine *Executor, ip int, opcode OpCode)
+}
+
 type Executor struct {
 	EvalStack *ValueStack
 	AltStack  *ValueStack
@@ -55,6 +61,7 @@ type Executor struct {
 	Features  VmFeatureFlag
 	Callers   []*ExecutionContext
 	Context   *ExecutionContext
+	Tracer    OpTracer
 }
 
 func (self *Executor) PopContext() (*ExecutionContext, error) {
@@ -85,10 +92,14 @@ func (self *Executor) Execute() error {
 			break
 		}
 
+		ip := self.Context.GetInstructionPointer()
 		opcode, eof := self.Context.ReadOpCode()
 		if eof {
 			break
 		}
+		if self.Tracer != nil {
+			self.Tracer.CaptureOp(self, ip, opcode)
+		}
 
 		var err error
 		self.State, err = self.ExecuteOp(opcode, self.Context)